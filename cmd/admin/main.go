@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/mirai-box/mirai-box/internal/cli"
+	"github.com/mirai-box/mirai-box/internal/config"
+)
+
+func main() {
+	conf, err := config.GetApplicationConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Commands print their results to stdout, logs go to stderr and only warnings
+	// are shown unless LOG_LEVEL asks for debug output.
+	level := slog.LevelWarn
+	if conf.LogLevel < slog.LevelInfo {
+		level = conf.LogLevel
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	// Commands connect on first use, so that config print works without a database.
+	db, err := gorm.Open(postgres.Open(conf.Database.ConnectionString()), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger: gormlogger.New(log.New(os.Stderr, "", log.LstdFlags), gormlogger.Config{
+			SlowThreshold:             time.Second,
+			LogLevel:                  gormlogger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	c, err := cli.New("miraibox-admin", db, conf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up commands: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(c.Run(context.Background(), os.Args[1:]))
+}
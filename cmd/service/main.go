@@ -1,19 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	_ "github.com/lib/pq"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/mirai-box/mirai-box/internal/app"
+	"github.com/mirai-box/mirai-box/internal/cli"
 	"github.com/mirai-box/mirai-box/internal/config"
 	"github.com/mirai-box/mirai-box/internal/database"
 	"github.com/mirai-box/mirai-box/internal/logger"
+	"github.com/mirai-box/mirai-box/internal/tracing"
 )
 
 func main() {
@@ -28,6 +32,11 @@ func main() {
 	// Setup logger
 	logger.Setup(conf)
 
+	// Administrative commands run instead of the server, see cmd/admin
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(conf, os.Args[1:]))
+	}
+
 	// Ensure database and user are created
 	if err := database.CreateDatabaseAndUser(conf); err != nil {
 		slog.Error("Failed to create database and user", "error", err)
@@ -35,28 +44,73 @@ func main() {
 	}
 
 	// Database connection
-	dbConnectionString := conf.Database.ConnectionString()
-	slog.Info("database connection string", "dsn", dbConnectionString)
-	db, err := gorm.Open(postgres.Open(dbConnectionString), &gorm.Config{})
+	slog.Info("Connecting to database",
+		"host", conf.Database.Host, "port", conf.Database.Port, "database", conf.Database.Database)
+	db, err := gorm.Open(postgres.Open(conf.Database.ConnectionString()), &gorm.Config{})
 	if err != nil {
 		slog.Error("Failed to connect to database", "error", err)
 		os.Exit(1)
 	}
 
+	// Trace statements with the context of the repository calls
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		slog.Error("Failed to install tracing", "error", err)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), conf)
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
 	// Run migrations
 	if err := database.RunMigrations(db); err != nil {
 		slog.Error("Failed to run migrations", "error", err)
 		os.Exit(1)
 	}
 
-	// Initialize the app
-	router := app.SetupRoutes(db, conf)
+	// Stop on SIGINT or SIGTERM, draining in-flight requests
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
-	// Start the server
-	slog.Info("Starting server", "port", conf.Port)
-	err = http.ListenAndServe(":"+conf.Port, router)
+	// Initialize the app and serve until stopped
+	server := app.NewServer(db, conf)
+	err = server.Run(ctx)
+	stop()
+
+	// Export the spans of the last requests
+	flushCtx, cancel := context.WithTimeout(context.Background(), conf.Server.ShutdownTimeout)
+	if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+		slog.Error("Failed to flush traces", "error", flushErr)
+	}
+	cancel()
 	if err != nil {
-		slog.Error("Failed to start server", "error", err)
+		slog.Error("Server failed", "error", err)
 		os.Exit(1)
 	}
 }
+
+// runCommand runs an administrative command and returns its exit code. The database is
+// connected on first use and only created for commands that use it, so that config
+// print works without a database.
+func runCommand(conf *config.Config, args []string) int {
+	if args[0] != "config" {
+		if err := database.CreateDatabaseAndUser(conf); err != nil {
+			slog.Error("Failed to create database and user", "error", err)
+			return 1
+		}
+	}
+
+	db, err := gorm.Open(postgres.Open(conf.Database.ConnectionString()), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		return 1
+	}
+
+	c, err := cli.New("miraibox", db, conf)
+	if err != nil {
+		slog.Error("Failed to set up commands", "error", err)
+		return 1
+	}
+	return c.Run(context.Background(), args)
+}
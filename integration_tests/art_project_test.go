@@ -32,7 +32,7 @@ func TestArtProjectIntegration(t *testing.T) {
 	artProjectRepo := repo.NewArtProjectRepository(db)
 
 	testUser := createTestUserRest(t, router, db)
-	sessionCookie := loginTestUser(t, router, testUser)
+	session := loginTestUser(t, router, testUser)
 
 	var createdArtProjectID string
 
@@ -60,7 +60,7 @@ func TestArtProjectIntegration(t *testing.T) {
 
 		req := httptest.NewRequest(http.MethodPost, "/self/artprojects", body)
 		req.Header.Set("Content-Type", writer.FormDataContentType())
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -110,7 +110,7 @@ func TestArtProjectIntegration(t *testing.T) {
 
 		req := httptest.NewRequest(http.MethodPost, "/self/artprojects/"+createdArtProjectID+"/revisions", body)
 		req.Header.Set("Content-Type", writer.FormDataContentType())
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -137,7 +137,7 @@ func TestArtProjectIntegration(t *testing.T) {
 
 	t.Run("ListArtProjects", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/self/artprojects", nil)
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -155,7 +155,7 @@ func TestArtProjectIntegration(t *testing.T) {
 
 	t.Run("GetArtProjectByID", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/self/artprojects/"+createdArtProjectID, nil)
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -172,7 +172,7 @@ func TestArtProjectIntegration(t *testing.T) {
 
 	t.Run("ListRevisions", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/self/artprojects/"+createdArtProjectID+"/revisions", nil)
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -0,0 +1,42 @@
+//go:build integration
+// +build integration
+
+package integration_tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirai-box/mirai-box/internal/app"
+)
+
+func TestHealthIntegration(t *testing.T) {
+	db, conf, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	router := app.SetupRoutes(db, conf)
+
+	for _, path := range []string{"/healthz", "/readyz", "/version"} {
+		t.Run(path, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+
+			assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		})
+	}
+
+	t.Run("Not ready without database", func(t *testing.T) {
+		sqlDB, err := db.DB()
+		assert.NoError(t, err)
+		sqlDB.Close()
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		assert.JSONEq(t, `{"status":"failing","checks":{"database":"failing","storage":"OK","migrations":"failing"}}`, resp.Body.String())
+	})
+}
@@ -0,0 +1,202 @@
+//go:build integration
+// +build integration
+
+package integration_tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/database"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func TestMigrationsIntegration(t *testing.T) {
+	db, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	migrations, err := database.Migrations()
+	require.NoError(t, err)
+	migrator := database.NewMigrator(db, migrations)
+
+	t.Run("All applied at startup", func(t *testing.T) {
+		status, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, status, len(migrations))
+		for _, s := range status {
+			assert.NotNil(t, s.AppliedAt, "migration %d_%s", s.Version, s.Name)
+		}
+
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, applied)
+	})
+
+	t.Run("Down and up again", func(t *testing.T) {
+		require.NoError(t, migrator.Down(ctx, len(migrations)))
+		assert.False(t, db.Migrator().HasTable(&model.User{}))
+
+		err := migrator.Down(ctx, 1)
+		assert.ErrorIs(t, err, database.ErrNoMigration)
+
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, len(migrations), applied)
+		assert.True(t, db.Migrator().HasTable(&model.User{}))
+
+		var permissions int64
+		require.NoError(t, db.Model(&model.RolePermission{}).Count(&permissions).Error)
+		assert.NotZero(t, permissions, "default role permissions are seeded")
+	})
+
+	t.Run("Upgrade a database created by AutoMigrate", func(t *testing.T) {
+		require.NoError(t, migrator.Down(ctx, len(migrations)))
+		require.NoError(t, db.Exec("DROP TABLE schema_migrations").Error)
+
+		require.NoError(t, db.AutoMigrate(
+			&baselineUser{}, &baselineStash{}, &baselineArtProject{}, &baselineRevision{},
+			&baselineCollection{}, &baselineCollectionArtProject{}, &baselineWebPage{},
+		))
+		user := baselineUser{Username: "baseline", Password: "hash", Role: "user"}
+		require.NoError(t, db.Create(&user).Error)
+		collection := baselineCollection{CollectionID: "baseline", UserID: user.ID, Title: "Baseline"}
+		require.NoError(t, db.Create(&collection).Error)
+
+		require.NoError(t, database.RunMigrations(db))
+
+		columns := map[any][]string{
+			&model.User{}:                 {"disabled", "totp_secret", "totp_enabled", "totp_last_step"},
+			&model.WebPage{}:              {"format", "source"},
+			&model.Collection{}:           {"description", "cover_revision_id", "public", "type", "rule"},
+			&model.CollectionArtProject{}: {"position", "caption", "contributor_id", "created_at"},
+		}
+		for table, names := range columns {
+			for _, name := range names {
+				assert.True(t, db.Migrator().HasColumn(table, name), "%T.%s", table, name)
+			}
+		}
+		assert.True(t, db.Migrator().HasTable(&model.CollectionMember{}))
+		assert.True(t, db.Migrator().HasIndex(&model.Session{}, "idx_sessions_token_hash"))
+
+		var upgraded model.User
+		require.NoError(t, db.First(&upgraded, "id = ?", user.ID).Error)
+		assert.False(t, upgraded.Disabled)
+
+		var upgradedCollection model.Collection
+		require.NoError(t, db.First(&upgradedCollection, "id = ?", collection.ID).Error)
+		assert.Equal(t, model.CollectionTypeManual, upgradedCollection.Type)
+		assert.True(t, upgradedCollection.Public, "existing collections stay public")
+
+		created := model.Collection{CollectionID: "created", UserID: user.ID, Title: "Created"}
+		require.NoError(t, db.Create(&created).Error)
+		require.NoError(t, db.First(&created, "id = ?", created.ID).Error)
+		assert.False(t, created.Public, "new collections are private")
+
+		var permissions int64
+		require.NoError(t, db.Model(&model.RolePermission{}).Count(&permissions).Error)
+		assert.NotZero(t, permissions, "default role permissions are seeded")
+	})
+}
+
+// The baseline models are the tables as AutoMigrate created them before versioned
+// migrations, they exercise the upgrade of such databases.
+
+type baselineUser struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	Username  string    `gorm:"type:varchar(255);unique;not null"`
+	Password  string    `gorm:"type:varchar(255);not null"`
+	Role      string    `gorm:"type:varchar(50);not null"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:now()"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:now()"`
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineStash struct {
+	ID          uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	UserID      uuid.UUID    `gorm:"type:uuid;not null"`
+	ArtProjects uint64       `gorm:"type:bigint;default:0"`
+	Files       uint64       `gorm:"type:bigint;default:0"`
+	UsedSpace   int64        `gorm:"type:bigint;default:0"`
+	CreatedAt   time.Time    `gorm:"type:timestamp;default:now()"`
+	UpdatedAt   time.Time    `gorm:"type:timestamp;default:now()"`
+	User        baselineUser `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (baselineStash) TableName() string { return "stashes" }
+
+type baselineArtProject struct {
+	ID                  uuid.UUID     `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	Title               string        `gorm:"type:varchar(255);not null"`
+	CreatedAt           time.Time     `gorm:"type:timestamp;default:now()"`
+	UpdatedAt           time.Time     `gorm:"type:timestamp;default:now()"`
+	ContentType         string        `gorm:"type:varchar(255)"`
+	Filename            string        `gorm:"type:varchar(255)"`
+	Public              bool          `gorm:"type:boolean;default:false"`
+	LatestRevisionID    uuid.UUID     `gorm:"type:uuid"`
+	PublishedRevisionID *uuid.UUID    `gorm:"type:uuid"`
+	StashID             uuid.UUID     `gorm:"type:uuid;not null"`
+	Stash               baselineStash `gorm:"foreignKey:StashID;constraint:OnDelete:CASCADE"`
+	UserID              uuid.UUID     `gorm:"type:uuid;not null"`
+	User                baselineUser  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (baselineArtProject) TableName() string { return "art_projects" }
+
+type baselineRevision struct {
+	ID           uuid.UUID          `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	ArtID        string             `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Version      int                `gorm:"type:int"`
+	FilePath     string             `gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time          `gorm:"type:timestamp;default:now()"`
+	Comment      string             `gorm:"type:text"`
+	Size         int64              `gorm:"type:bigint;not null;default:0"`
+	ArtProjectID uuid.UUID          `gorm:"type:uuid;not null"`
+	ArtProject   baselineArtProject `gorm:"foreignKey:ArtProjectID;constraint:OnDelete:CASCADE"`
+	UserID       uuid.UUID          `gorm:"type:uuid;not null"`
+	User         baselineUser       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (baselineRevision) TableName() string { return "revisions" }
+
+type baselineCollection struct {
+	ID           uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	CollectionID string       `gorm:"type:varchar(255);not null;uniqueIndex"`
+	UserID       uuid.UUID    `gorm:"type:uuid;not null"`
+	Title        string       `gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time    `gorm:"type:timestamp;default:now()"`
+	UpdatedAt    time.Time    `gorm:"type:timestamp;default:now()"`
+	User         baselineUser `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (baselineCollection) TableName() string { return "collections" }
+
+type baselineCollectionArtProject struct {
+	CollectionID uuid.UUID          `gorm:"type:uuid;not null;primaryKey"`
+	RevisionID   uuid.UUID          `gorm:"type:uuid;not null;primaryKey"`
+	Collection   baselineCollection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE"`
+	Revision     baselineRevision   `gorm:"foreignKey:RevisionID;constraint:OnDelete:CASCADE"`
+}
+
+func (baselineCollectionArtProject) TableName() string { return "collection_art_projects" }
+
+type baselineWebPage struct {
+	ID        uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primary_key"`
+	UserID    uuid.UUID    `gorm:"type:uuid;not null"`
+	Title     string       `gorm:"type:varchar(255);not null"`
+	Html      string       `gorm:"type:text;not null"`
+	PageType  string       `gorm:"type:varchar(255);default:'main'"`
+	Public    bool         `gorm:"type:boolean;default:false"`
+	CreatedAt time.Time    `gorm:"type:timestamp;default:now()"`
+	UpdatedAt time.Time    `gorm:"type:timestamp;default:now()"`
+	User      baselineUser `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (baselineWebPage) TableName() string { return "web_pages" }
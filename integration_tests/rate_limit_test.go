@@ -0,0 +1,62 @@
+//go:build integration
+// +build integration
+
+package integration_tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/app"
+	"github.com/mirai-box/mirai-box/internal/config"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func TestRateLimitIntegration(t *testing.T) {
+	db, conf, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	conf.RateLimitStore = config.RateLimitStorePostgres
+	conf.RateLimits = map[string]model.RateLimit{
+		model.RateLimitAuth: {Requests: 2, Period: time.Minute},
+	}
+
+	// Two routers share the buckets in the database like two instances of the service.
+	routers := []http.Handler{app.SetupRoutes(db, conf), app.SetupRoutes(db, conf)}
+
+	login := func(router http.Handler) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"nobody","password":"wrong"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := login(routers[0])
+	assert.NotEqual(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("RateLimit-Remaining"))
+
+	resp = login(routers[1])
+	assert.NotEqual(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "0", resp.Header().Get("RateLimit-Remaining"))
+
+	resp = login(routers[0])
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+	assert.Equal(t, "2;w=60", resp.Header().Get("RateLimit-Policy"))
+
+	var buckets int64
+	require.NoError(t, db.Model(&model.RateLimitBucket{}).Count(&buckets).Error)
+	assert.Equal(t, int64(1), buckets)
+
+	// Routes of other classes are not limited.
+	resp = httptest.NewRecorder()
+	routers[0].ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/login/check", nil))
+	assert.Empty(t, resp.Header().Get("RateLimit-Limit"))
+}
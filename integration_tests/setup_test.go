@@ -24,6 +24,7 @@ import (
 
 	"github.com/mirai-box/mirai-box/internal/config"
 	"github.com/mirai-box/mirai-box/internal/database"
+	"github.com/mirai-box/mirai-box/internal/middleware"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/repo"
 	"github.com/mirai-box/mirai-box/internal/service"
@@ -45,6 +46,7 @@ func setupTestEnvironment(t *testing.T) (*gorm.DB, *config.Config, func()) {
 	os.Setenv("DB_PASSWORD", "testpass")
 	os.Setenv("DB_NAME", "testdb")
 	os.Setenv("DB_POSTGRES_PASSWORD", "postgrespass")
+	os.Setenv("REGISTRATION_MODE", "open")
 
 	// Start PostgreSQL container
 	ctx := context.Background()
@@ -96,7 +98,19 @@ func setupTestEnvironment(t *testing.T) (*gorm.DB, *config.Config, func()) {
 	return db, conf, cleanup
 }
 
-func loginTestUser(t *testing.T, router http.Handler, user *model.User) *http.Cookie {
+// testSession is a signed in session of a test user.
+type testSession struct {
+	cookie    *http.Cookie
+	csrfToken string
+}
+
+// authorize adds the session cookie and the CSRF token to a request.
+func (s *testSession) authorize(req *http.Request) {
+	req.AddCookie(s.cookie)
+	req.Header.Set(middleware.CSRFHeader, s.csrfToken)
+}
+
+func loginTestUser(t *testing.T, router http.Handler, user *model.User) *testSession {
 	t.Helper()
 	loginReqBody := map[string]interface{}{
 		"username":     user.Username,
@@ -130,7 +144,10 @@ func loginTestUser(t *testing.T, router http.Handler, user *model.User) *http.Co
 	require.NotNil(t, sessionCookie)
 	assert.True(t, sessionCookie.MaxAge > 0)
 
-	return sessionCookie
+	csrfToken := loginResp.Header().Get(middleware.CSRFHeader)
+	require.NotEmpty(t, csrfToken)
+
+	return &testSession{cookie: sessionCookie, csrfToken: csrfToken}
 }
 
 func createTestUser(t *testing.T, db *gorm.DB) *model.User {
@@ -138,7 +155,7 @@ func createTestUser(t *testing.T, db *gorm.DB) *model.User {
 
 	username := fmt.Sprintf("test_%d", rand.Intn(1000))
 	userRepo := repo.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, repo.NewInviteRepository(db), repo.NewIdentityRepository(db), service.NewPasswordPolicy(8, nil), model.RegistrationModeOpen)
 
 	user, err := userService.CreateUser(
 		context.Background(),
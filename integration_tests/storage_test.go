@@ -0,0 +1,59 @@
+//go:build integration
+// +build integration
+
+package integration_tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/repo"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+func TestStorageIntegration(t *testing.T) {
+	db, _, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user := createTestUser(t, db)
+	userRepo := repo.NewUserRepository(db)
+
+	// The shared storage root of the tests is the system temp directory, repairs must not touch it.
+	storageService := service.NewStorageService(userRepo, repo.NewArtProjectRepository(db), repo.NewFileStorageRepository(db, t.TempDir()))
+
+	stash, err := userRepo.GetStashByUserID(ctx, user.ID.String())
+	require.NoError(t, err)
+	stash.Files = 5
+	stash.UsedSpace = 1024
+	require.NoError(t, userRepo.UpdateStash(ctx, stash))
+
+	t.Run("Verify finds drifted stash", func(t *testing.T) {
+		report, err := storageService.Verify(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, report.Stashes, 1)
+		assert.Equal(t, user.ID, report.Stashes[0].UserID)
+		assert.EqualValues(t, 5, report.Stashes[0].Recorded.Files)
+		assert.EqualValues(t, 0, report.Stashes[0].Actual.Files)
+		assert.False(t, report.Stashes[0].Repaired)
+	})
+
+	t.Run("Repair recomputes stash", func(t *testing.T) {
+		report, err := storageService.Repair(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, report.Unrepaired())
+
+		stash, err := userRepo.GetStashByUserID(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, stash.Files)
+		assert.EqualValues(t, 0, stash.UsedSpace)
+
+		report, err = storageService.Verify(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, report.Stashes)
+	})
+}
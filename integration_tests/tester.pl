@@ -209,7 +209,7 @@ sub test_picture_upload_and_revisions {
         POST "http://localhost:8080/self/collections",
         'Cookie' => $session_cookie,
         Content_Type => 'application/json',
-        Content => encode_json({ title => "Main" })
+        Content => encode_json({ title => "Main", public => JSON::PP::true })
     );
 
     is($new_collection->code, 201, "New collection is created successful");
@@ -258,7 +258,65 @@ sub test_picture_upload_and_revisions {
     is($art_project1_latest_revision->header('Content-Type'), "image/png", "Content type is correct");
 }
 
+sub test_api_tokens {
+    my ($session_cookie) = @_;
+
+    # Create a token limited to uploading art
+    my $create_token_response = $ua->request(
+        POST "$BASE_URL/self/tokens",
+        'Cookie' => $session_cookie,
+        Content_Type => 'application/json',
+        Content => encode_json({
+            name => "uploader",
+            scopes => ["art:write"],
+            expires_in_days => 1
+        })
+    );
+    is($create_token_response->code, 201, "API token created");
+    my $token = decode_json($create_token_response->content)->{token};
+    ok($token, "API token value received");
+
+    my $bearer_ua = LWP::UserAgent->new();
+
+    # Upload an art project with the token
+    my $upload_response = $bearer_ua->request(
+        POST "$BASE_URL/self/artprojects",
+        'Authorization' => "Bearer $token",
+        Content_Type => 'form-data',
+        Content => [
+            file => ['data/1.png'],
+            title => 'Uploaded with a token'
+        ]
+    );
+    is($upload_response->code, 201, "Art project created with API token");
+
+    # The token is not scoped for webpages
+    my $webpage_response = $bearer_ua->request(
+        POST "$BASE_URL/self/webpages",
+        'Authorization' => "Bearer $token",
+        Content_Type => 'application/json',
+        Content => encode_json({ title => "Not allowed", content => "none" })
+    );
+    is($webpage_response->code, 403, "API token without pages:write scope denied");
+
+    # A token cannot create more tokens
+    my $token_response = $bearer_ua->request(
+        POST "$BASE_URL/self/tokens",
+        'Authorization' => "Bearer $token",
+        Content_Type => 'application/json',
+        Content => encode_json({ name => "escalate", scopes => ["art:write"] })
+    );
+    is($token_response->code, 403, "API token cannot create tokens");
+
+    my $invalid_response = $bearer_ua->request(
+        GET "$BASE_URL/self/artprojects",
+        'Authorization' => "Bearer mb_invalid",
+    );
+    is($invalid_response->code, 401, "Invalid API token rejected");
+}
+
 my $session_cookie = test_user_flow();
 test_picture_upload_and_revisions($session_cookie);
+test_api_tokens($session_cookie);
 
 done_testing();
\ No newline at end of file
@@ -29,7 +29,7 @@ func TestWebPageIntegration(t *testing.T) {
 	// Create a test user
 	testUser := createTestUser(t, db)
 	// Login to get a session cookie
-	sessionCookie := loginTestUser(t, router, testUser)
+	session := loginTestUser(t, router, testUser)
 
 	t.Run("CreateWebPage", func(t *testing.T) {
 		reqBody := map[string]interface{}{
@@ -40,7 +40,7 @@ func TestWebPageIntegration(t *testing.T) {
 
 		req := httptest.NewRequest(http.MethodPost, "/self/webpages", bytes.NewBuffer(jsonBody))
 		req.Header.Set("Content-Type", "application/json")
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -67,7 +67,7 @@ func TestWebPageIntegration(t *testing.T) {
 
 	t.Run("ListWebPages", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/self/webpages", nil)
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -89,7 +89,7 @@ func TestWebPageIntegration(t *testing.T) {
 		require.NotEmpty(t, webPages)
 
 		req := httptest.NewRequest(http.MethodGet, "/self/webpages/"+webPages[0].ID.String(), nil)
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
@@ -116,7 +116,7 @@ func TestWebPageIntegration(t *testing.T) {
 
 		req := httptest.NewRequest(http.MethodPut, "/self/webpages/"+webPages[0].ID.String(), bytes.NewBuffer(jsonBody))
 		req.Header.Set("Content-Type", "application/json")
-		req.AddCookie(sessionCookie)
+		session.authorize(req)
 		resp := httptest.NewRecorder()
 
 		router.ServeHTTP(resp, req)
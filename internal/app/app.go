@@ -1,99 +1,240 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/gorilla/sessions"
 	"github.com/rs/cors"
 	"gorm.io/gorm"
 
 	"github.com/mirai-box/mirai-box/internal/config"
+	"github.com/mirai-box/mirai-box/internal/database"
 	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/metrics"
 	am "github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/notifier"
 	"github.com/mirai-box/mirai-box/internal/repo"
 	"github.com/mirai-box/mirai-box/internal/service"
+	"github.com/mirai-box/mirai-box/internal/sessionstore"
+	"github.com/mirai-box/mirai-box/internal/tracing"
 )
 
-var corsConfig = cors.New(cors.Options{
-	AllowedOrigins:   []string{"http://localhost:3000"}, // Allow frontend origin
-	AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-	AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-	ExposedHeaders:   []string{"Link"},
-	AllowCredentials: true,
-	MaxAge:           300,
-})
+// newCORS allows the configured frontend origins to call the API with the session cookie.
+func newCORS(conf *config.CORSConfig) *cors.Cors {
+	return cors.New(cors.Options{
+		AllowedOrigins:   conf.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", am.RequestIDHeader},
+		ExposedHeaders:   append([]string{"Link", am.CSRFHeader, am.RequestIDHeader}, am.RateLimitHeaders...),
+		AllowCredentials: true,
+		MaxAge:           int(conf.MaxAge.Seconds()),
+	})
+}
 
 func SetupRoutes(db *gorm.DB, conf *config.Config) http.Handler {
+	return setupRoutes(db, conf, newHealthHandler(db, conf), newMetrics(db))
+}
+
+func setupRoutes(db *gorm.DB, conf *config.Config, healthHandler *handler.HealthHandler, appMetrics *metrics.Metrics) http.Handler {
 	r := chi.NewRouter()
 
 	// Middleware
-	r.Use(middleware.Logger)
+	r.Use(am.RequestID)
+	r.Use(am.LogRequests("/healthz", "/readyz", "/version", "/metrics"))
+	r.Use(tracing.Middleware)
+	r.Use(appMetrics.Middleware)
 	r.Use(middleware.Recoverer)
-	r.Use(corsConfig.Handler)
+	r.Use(newCORS(conf.CORS).Handler)
 
 	// Initialize repositories
 	ur := repo.NewUserRepository(db)
 	ar := repo.NewArtProjectRepository(db)
-	fsr := repo.NewFileStorageRepository(db, conf.StorageRoot)
+	fsr := tracing.InstrumentFileStorage(repo.NewFileStorageRepository(db, conf.StorageRoot))
 	wpr := repo.NewWebPageRepository(db)
 	cr := repo.NewCollectionRepository(db)
+	alr := repo.NewAuditLogRepository(db)
+	pr := repo.NewPermissionRepository(db)
+	sr := repo.NewSessionRepository(db)
+	ir := repo.NewInviteRepository(db)
+	tr := repo.NewAPITokenRepository(db)
+	lar := repo.NewLoginAttemptRepository(db)
+	tfr := repo.NewTwoFactorRepository(db)
+	prr := repo.NewPasswordResetRepository(db)
+	idr := repo.NewIdentityRepository(db)
+	rlr := repo.NewMemoryRateLimitRepository()
+	if conf.RateLimitStore == config.RateLimitStorePostgres {
+		rlr = repo.NewRateLimitRepository(db)
+	}
 
 	// Initialize services
-	userService := service.NewUserService(ur)
-	artProjectService := service.NewArtProjectService(ur, ar, fsr, conf.SecretKey)
+	userLoginPolicy := service.DefaultUserLoginPolicy
+	userLoginPolicy.MaxFailures = conf.LoginMaxFailures
+	userLoginPolicy.Lockout = conf.LoginLockout
+	loginThrottle := service.NewLoginThrottleService(lar, userLoginPolicy, service.DefaultIPLoginPolicy)
+
+	resetNotifier, err := notifier.New(conf.Notifier, conf.NotifierFile)
+	if err != nil {
+		// The configuration validates the kind, but routes may be set up from a built config.
+		slog.Warn("Falling back to the log notifier", "notifier", conf.Notifier, "error", err)
+		resetNotifier = notifier.NewLogNotifier()
+	}
+	passwordPolicy := service.NewPasswordPolicy(conf.PasswordMinLength, conf.BreachedPasswords)
+
+	userService := service.NewUserService(ur, ir, idr, passwordPolicy, conf.RegistrationMode)
+	thumbnailService := service.NewThumbnailService(fsr)
+	artProjectService := appMetrics.InstrumentArtProjects(tracing.InstrumentArtProjects(service.NewArtProjectService(ur, ar, fsr, conf.SecretKey)))
 	webPageService := service.NewWebPageService(wpr)
-	cs := service.NewCollectionService(cr, ar, conf.SecretKey)
+	cs := service.NewCollectionService(cr, ar, ur, conf.SecretKey)
+	feedService := service.NewFeedService(cs, ar, ur, thumbnailService, conf.PublicURL)
+	adminService := service.NewAdminService(ur, alr, ir, sr, loginThrottle, tfr, pr, passwordPolicy)
+	permissionService := service.NewPermissionService(pr)
+	sessionService := service.NewSessionService(sr)
+	apiTokenService := service.NewAPITokenService(tr, ur, permissionService)
+	twoFactorService := service.NewTwoFactorService(tfr, ur, conf.SecretKey)
+	oidcService := service.NewOIDCService(idr, userService, oidcProviders(conf))
+	passwordService := service.NewPasswordService(ur, prr, sr, resetNotifier, passwordPolicy, conf.PasswordResetTTL, conf.PublicURL)
+	rateLimitService := service.NewRateLimitService(rlr, conf.RateLimits)
 
-	cookieStore := sessions.NewCookieStore([]byte(conf.SessionKey))
-	m := am.NewMiddleware(cookieStore, userService)
+	sessionStore := sessionstore.NewStore(sr, max(conf.Session.TTL, conf.Session.RememberTTL), []byte(conf.SessionKey))
+	sessionStore.Options.SameSite = conf.CookieSameSite
+	sessionStore.Options.Secure = conf.CookieSecure
+	m := am.NewMiddleware(sessionStore, userService, permissionService, apiTokenService)
 
 	// Initialize handlers
-	userHandler := handler.NewUserHandler(userService, cookieStore)
-	artProjectHandler := handler.NewArtProjectHandler(artProjectService)
+	userHandler := handler.NewUserHandler(userService, sessionService, loginThrottle, twoFactorService, sessionStore,
+		conf.Session.TTL, conf.Session.RememberTTL)
+	sessionHandler := handler.NewSessionHandler(sessionService, sessionStore)
+	artProjectHandler := handler.NewArtProjectHandler(artProjectService, thumbnailService)
 	webPageHandler := handler.NewWebPageHandler(webPageService)
 	ch := handler.NewCollectionHandler(cs)
+	feedHandler := handler.NewFeedHandler(feedService)
+	adminHandler := handler.NewAdminHandler(adminService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	passwordHandler := handler.NewPasswordHandler(passwordService, sessionStore)
+	oidcHandler := handler.NewOIDCHandler(oidcService, userHandler, []byte(conf.SessionKey), conf.SecretKey, conf.CookieSecure)
+	requireTwoFactor := am.RequireTwoFactor(twoFactorService)
+
+	// Authenticated routes are limited per user, so their limits run after AuthMiddleware.
+	authLimit := am.RateLimit(rateLimitService, model.RateLimitAuth)
+	uploadLimit := am.RateLimit(rateLimitService, model.RateLimitUpload)
+	downloadLimit := am.RateLimit(rateLimitService, model.RateLimitDownload)
+	apiLimit := am.RateLimit(rateLimitService, model.RateLimitAPI)
+	maxUploadSize := am.MaxBodySize(conf.UploadMaxSize)
 
-	r.Post("/login", userHandler.Login)
+	r.Get("/healthz", healthHandler.Live)
+	r.Get("/readyz", healthHandler.Ready)
+	r.Get("/version", healthHandler.Version)
+	if conf.Features.Metrics {
+		r.Method(http.MethodGet, "/metrics", appMetrics.Handler(conf.MetricsToken))
+	}
+
+	r.With(authLimit).Post("/login", userHandler.Login)
+	r.With(authLimit).Post("/login/2fa", userHandler.LoginTwoFactor)
+	r.With(m.CSRFProtect).Post("/logout", userHandler.Logout)
 	r.Get("/login/check", userHandler.LoginCheck)
-	r.Get("/art/{artID}", artProjectHandler.GetArtByID)
-	r.Get("/collection/{id}", ch.ListPublicRevisions)
+	r.Get("/login/oidc", oidcHandler.ListProviders)
+	r.With(authLimit).Get("/login/oidc/{provider}", oidcHandler.Login)
+	r.With(authLimit).Get("/login/oidc/{provider}/callback", oidcHandler.Callback)
+	r.With(authLimit).Post("/password/reset", passwordHandler.RequestReset)
+	r.With(authLimit).Post("/password/reset/confirm", passwordHandler.ConfirmReset)
+	r.With(downloadLimit).Get("/art/{artID}", artProjectHandler.GetArtByID)
+	r.With(downloadLimit).Get("/art/{artID}/thumbnail", artProjectHandler.GetArtThumbnail)
+	r.With(downloadLimit).Get("/collection/{id}", ch.ListPublicRevisions)
+	if conf.Features.Feeds {
+		r.With(downloadLimit).Get("/collection/{id}/feed.{format}", feedHandler.CollectionFeed)
+		r.With(downloadLimit).Get("/gallery/{username}/feed.{format}", feedHandler.GalleryFeed)
+	}
+
+	// Two-factor enrolment stays reachable for users whose role requires it before they enrol.
+	r.Route("/self/2fa", func(r chi.Router) {
+		r.Use(m.AuthMiddleware)
+		r.Use(apiLimit)
+		r.Use(m.CSRFProtect)
+
+		r.Get("/", twoFactorHandler.Status)
+		r.Delete("/", twoFactorHandler.Disable)
+		r.Post("/enrol", twoFactorHandler.Enrol)
+		r.Post("/confirm", twoFactorHandler.Confirm)
+		r.Post("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+	})
 
 	r.Route("/self", func(r chi.Router) {
 		r.Use(m.AuthMiddleware)
-		r.Use(m.RequireRole("self", "any"))
+		r.Use(apiLimit)
+		r.Use(m.CSRFProtect)
+		r.Use(requireTwoFactor)
+
+		artWrite := m.RequirePermission(model.PermArtWrite)
+		pagesWrite := m.RequirePermission(model.PermPagesWrite)
+		collectionsWrite := m.RequirePermission(model.PermCollectionsWrite)
 
 		r.Get("/stash", userHandler.MyStash)
-		r.Post("/webpages", webPageHandler.CreateWebPage)
-		r.Get("/webpages", webPageHandler.MyWebPages)
-		r.With(am.ValidateUUID("id")).Get("/webpages/{id}", webPageHandler.MyWebPageByID)
-		r.With(am.ValidateUUID("id")).Put("/webpages/{id}", webPageHandler.UpdateWebPage)
-		r.With(am.ValidateUUID("id")).Delete("/webpages/{id}", webPageHandler.DeleteWebPage)
+		r.Post("/password", passwordHandler.ChangePassword)
+
+		r.Get("/identities", oidcHandler.ListIdentities)
+		r.Get("/identities/{provider}/link", oidcHandler.Link)
+		r.With(am.ValidateUUID("id")).Delete("/identities/{id}", oidcHandler.UnlinkIdentity)
+
+		r.Get("/sessions", sessionHandler.ListSessions)
+		r.Delete("/sessions", sessionHandler.RevokeOtherSessions)
+		r.With(am.ValidateUUID("id")).Delete("/sessions/{id}", sessionHandler.RevokeSession)
+
+		r.Get("/tokens", apiTokenHandler.ListTokens)
+		r.Post("/tokens", apiTokenHandler.CreateToken)
+		r.With(am.ValidateUUID("id")).Delete("/tokens/{id}", apiTokenHandler.RevokeToken)
+
+		if conf.Features.WebPages {
+			r.With(pagesWrite).Post("/webpages", webPageHandler.CreateWebPage)
+			r.Get("/webpages", webPageHandler.MyWebPages)
+			r.With(am.ValidateUUID("id")).Get("/webpages/{id}", webPageHandler.MyWebPageByID)
+			r.With(pagesWrite, am.ValidateUUID("id")).Put("/webpages/{id}", webPageHandler.UpdateWebPage)
+			r.With(pagesWrite, am.ValidateUUID("id")).Delete("/webpages/{id}", webPageHandler.DeleteWebPage)
+		}
 
 		r.Get("/artprojects", artProjectHandler.MyArtProjects)
 		r.With(am.ValidateUUID("artID")).Get("/artprojects/{artID}", artProjectHandler.MyArtProjectByID)
 
 		r.With(am.ValidateUUID("artID")).
 			Get("/artprojects/{artID}/revisions", artProjectHandler.ListRevisions)
-		r.With(am.ValidateUUID("artID")).
+		r.With(artWrite, uploadLimit, maxUploadSize, am.ValidateUUID("artID")).
 			Post("/artprojects/{artID}/revisions", artProjectHandler.AddRevision)
-		r.Post("/artprojects", artProjectHandler.CreateArtProject)
-		r.With(am.ValidateUUID("artID")).With(am.ValidateUUID("revisionID")).
+		r.With(artWrite, uploadLimit, maxUploadSize).Post("/artprojects", artProjectHandler.CreateArtProject)
+		r.With(downloadLimit, am.ValidateUUID("artID")).With(am.ValidateUUID("revisionID")).
 			Get("/artprojects/{artID}/revisions/{revisionID}", artProjectHandler.RevisionDownload)
 
 		r.Route("/collections", func(r chi.Router) {
-			r.Post("/", ch.CreateCollection)
+			r.With(collectionsWrite).Post("/", ch.CreateCollection)
 			r.Get("/", ch.GetUserCollections)
+			r.Get("/invitations", ch.ListInvitations)
 			r.With(am.ValidateUUID("id")).Get("/{id}", ch.GetCollection)
-			r.With(am.ValidateUUID("id")).Put("/{id}", ch.UpdateCollection)
-			r.With(am.ValidateUUID("id")).Delete("/{id}", ch.DeleteCollection)
-			r.With(am.ValidateUUID("id")).
+			r.With(collectionsWrite, am.ValidateUUID("id")).Put("/{id}", ch.UpdateCollection)
+			r.With(collectionsWrite, am.ValidateUUID("id")).Delete("/{id}", ch.DeleteCollection)
+			r.With(collectionsWrite, am.ValidateUUID("id")).
 				Post("/{id}/revisions", ch.AddRevisionToCollection)
 			r.With(am.ValidateUUID("id")).
 				Get("/{id}/revisions", ch.ListRevisions)
-			r.With(am.ValidateUUID("id")).With(am.ValidateUUID("revisionID")).
+			r.With(collectionsWrite, am.ValidateUUID("id")).
+				Put("/{id}/revisions/order", ch.ReorderRevisions)
+			r.With(collectionsWrite, am.ValidateUUID("id")).With(am.ValidateUUID("revisionID")).
+				Put("/{id}/revisions/{revisionID}", ch.UpdateRevisionInCollection)
+			r.With(collectionsWrite, am.ValidateUUID("id")).With(am.ValidateUUID("revisionID")).
 				Delete("/{id}/revisions/{revisionID}", ch.RemoveRevisionFromCollection)
+			r.With(am.ValidateUUID("id")).
+				Post("/{id}/invitation", ch.AcceptInvitation)
+			r.With(am.ValidateUUID("id")).
+				Get("/{id}/members", ch.ListMembers)
+			r.With(collectionsWrite, am.ValidateUUID("id")).
+				Post("/{id}/members", ch.InviteMember)
+			r.With(collectionsWrite, am.ValidateUUID("id")).With(am.ValidateUUID("userID")).
+				Put("/{id}/members/{userID}", ch.UpdateMember)
+			r.With(am.ValidateUUID("id")).With(am.ValidateUUID("userID")).
+				Delete("/{id}/members/{userID}", ch.RemoveMember)
 		})
 	})
 
@@ -101,12 +242,101 @@ func SetupRoutes(db *gorm.DB, conf *config.Config) http.Handler {
 	r.Route("/api", func(r chi.Router) {
 		// Public routes
 		// user registration route
-		r.Post("/users", userHandler.CreateUser)
+		// self-registration, see config.RegistrationMode
+		r.With(authLimit).Post("/users", userHandler.CreateUser)
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(m.AuthMiddleware)
+			r.Use(apiLimit)
+			r.Use(m.CSRFProtect)
+			r.Use(requireTwoFactor)
+			r.Use(m.RequirePermission(model.PermUsersAdmin))
+
+			r.Get("/users", adminHandler.ListUsers)
+			r.With(am.ValidateUUID("id")).Get("/users/{id}", adminHandler.GetUser)
+			r.With(am.ValidateUUID("id")).Put("/users/{id}/role", adminHandler.UpdateRole)
+			r.With(am.ValidateUUID("id")).Post("/users/{id}/password-reset", adminHandler.ResetPassword)
+			r.With(am.ValidateUUID("id")).Post("/users/{id}/disable", adminHandler.DisableUser)
+			r.With(am.ValidateUUID("id")).Post("/users/{id}/enable", adminHandler.EnableUser)
+			r.With(am.ValidateUUID("id")).Post("/users/{id}/unlock", adminHandler.UnlockUser)
+			r.With(am.ValidateUUID("id")).Delete("/users/{id}", adminHandler.DeleteUser)
+			r.Get("/audit-log", adminHandler.ListAuditLog)
 
-		r.Group(func(r chi.Router) {
-			// r.Use(m.RequireRole("admin"))
+			r.Get("/invites", adminHandler.ListInvites)
+			r.Post("/invites", adminHandler.CreateInvite)
+			r.With(am.ValidateUUID("id")).Delete("/invites/{id}", adminHandler.RevokeInvite)
+
+			r.Get("/roles/{role}/policy", adminHandler.GetRolePolicy)
+			r.Put("/roles/{role}/policy", adminHandler.UpdateRolePolicy)
 		})
 	})
 
 	return r
 }
+
+// newMetrics creates the metrics with the stash gauges and the statistics of the connection pool.
+func newMetrics(db *gorm.DB) *metrics.Metrics {
+	m := metrics.New()
+	m.RegisterStashes(repo.NewUserRepository(db).ListStashes)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		slog.Error("Failed to get connection pool for metrics", "error", err)
+		return m
+	}
+	m.RegisterDB(sqlDB)
+	return m
+}
+
+// newHealthHandler creates the probes, readiness checks the database connection, the
+// storage root and that every migration is applied.
+func newHealthHandler(db *gorm.DB, conf *config.Config) *handler.HealthHandler {
+	fsr := repo.NewFileStorageRepository(db, conf.StorageRoot)
+	migrations, migrationsErr := database.Migrations()
+	migrator := database.NewMigrator(db, migrations)
+
+	healthService := service.NewHealthService(
+		service.HealthCheck{Name: "database", Check: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}},
+		service.HealthCheck{Name: "storage", Check: fsr.CheckWritable},
+		service.HealthCheck{Name: "migrations", Check: func(ctx context.Context) error {
+			if migrationsErr != nil {
+				return migrationsErr
+			}
+
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if pending > 0 {
+				return fmt.Errorf("%d migrations are pending", pending)
+			}
+			return nil
+		}},
+	)
+
+	return handler.NewHealthHandler(healthService)
+}
+
+// oidcProviders converts the configured OpenID Connect providers for the OIDC service.
+func oidcProviders(conf *config.Config) []service.OIDCProvider {
+	providers := make([]service.OIDCProvider, 0, len(conf.OIDCProviders))
+	for _, p := range conf.OIDCProviders {
+		providers = append(providers, service.OIDCProvider{
+			Name:          p.Name,
+			Issuer:        p.Issuer,
+			ClientID:      p.ClientID,
+			ClientSecret:  p.ClientSecret,
+			RedirectURL:   p.RedirectURL,
+			Scopes:        p.Scopes,
+			UsernameClaim: p.UsernameClaim,
+			AllowSignup:   p.AllowSignup,
+		})
+	}
+	return providers
+}
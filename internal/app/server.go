@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mirai-box/mirai-box/internal/config"
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/metrics"
+	"github.com/mirai-box/mirai-box/internal/repo"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// Server is the HTTP server of the service together with its background tasks.
+type Server struct {
+	db      *gorm.DB
+	conf    *config.Config
+	health  *handler.HealthHandler
+	metrics *metrics.Metrics
+	http    *http.Server
+}
+
+// NewServer creates a Server listening on the configured port with the configured timeouts.
+func NewServer(db *gorm.DB, conf *config.Config) *Server {
+	health := newHealthHandler(db, conf)
+	appMetrics := newMetrics(db)
+
+	return &Server{
+		db:      db,
+		conf:    conf,
+		health:  health,
+		metrics: appMetrics,
+		http: &http.Server{
+			Addr:              ":" + conf.Port,
+			Handler:           setupRoutes(db, conf, health, appMetrics),
+			ReadHeaderTimeout: conf.Server.ReadHeaderTimeout,
+			ReadTimeout:       conf.Server.ReadTimeout,
+			WriteTimeout:      conf.Server.WriteTimeout,
+			IdleTimeout:       conf.Server.IdleTimeout,
+		},
+	}
+}
+
+// Run serves requests and runs the background tasks until ctx is done. It then fails
+// readiness for the shutdown delay, drains in-flight requests within the shutdown
+// timeout and waits for the background tasks to stop.
+func (s *Server) Run(ctx context.Context) error {
+	logger := slog.With("method", "Run", "addr", s.http.Addr)
+
+	tasksCtx, stopTasks := context.WithCancel(context.Background())
+	defer stopTasks()
+
+	var tasks sync.WaitGroup
+	s.startBackgroundTasks(tasksCtx, &tasks)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Starting server")
+		serveErr <- s.http.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		stopTasks()
+		tasks.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down, draining connections",
+		"delay", s.conf.Server.ShutdownDelay, "timeout", s.conf.Server.ShutdownTimeout)
+	s.health.SetDraining()
+	time.Sleep(s.conf.Server.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.conf.Server.ShutdownTimeout)
+	defer cancel()
+
+	err := s.http.Shutdown(shutdownCtx)
+	if err != nil {
+		logger.Error("Failed to drain connections, closing them", "error", err)
+		_ = s.http.Close()
+	}
+
+	stopTasks()
+	tasks.Wait()
+
+	if serveErr := <-serveErr; serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+
+	logger.Info("Server stopped")
+	return err
+}
+
+// startBackgroundTasks starts the periodic tasks of the service, they stop when ctx is done.
+func (s *Server) startBackgroundTasks(ctx context.Context, tasks *sync.WaitGroup) {
+	if s.conf.StorageCheckInterval > 0 {
+		storageService := s.metrics.InstrumentStorage(service.NewStorageService(
+			repo.NewUserRepository(s.db),
+			repo.NewArtProjectRepository(s.db),
+			repo.NewFileStorageRepository(s.db, s.conf.StorageRoot),
+		))
+
+		tasks.Add(1)
+		go func() {
+			defer tasks.Done()
+			service.RunStorageChecks(ctx, storageService, s.conf.StorageCheckInterval, s.conf.StorageCheckRepair)
+		}()
+	}
+}
@@ -0,0 +1,62 @@
+// Package buildinfo holds the build metadata of the binaries. The variables are set
+// at build time with
+//
+//	go build -ldflags "-X github.com/mirai-box/mirai-box/internal/buildinfo.Version=v1.2.3 ..."
+//
+// and fall back to the VCS information Go records in the binary.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	// Version is the released version, like v1.2.3.
+	Version = "dev"
+	// Commit is the git commit the binary was built from.
+	Commit = ""
+	// Date is the build time in RFC 3339.
+	Date = ""
+)
+
+// Info is the build metadata reported by the version endpoint.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
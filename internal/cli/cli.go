@@ -0,0 +1,179 @@
+// Package cli implements the administrative commands of the service, so that
+// operators can manage users, storage and the schema without psql.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+	"gorm.io/gorm"
+
+	"github.com/mirai-box/mirai-box/internal/config"
+	"github.com/mirai-box/mirai-box/internal/database"
+	"github.com/mirai-box/mirai-box/internal/repo"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// errUsage makes Run print the usage and exit with exitUsage.
+var errUsage = errors.New("invalid usage")
+
+const usage = `Usage: %s <command> [flags] [arguments]
+
+Commands:
+  user create [-role user|admin] <username>
+                         create a user, the password is read from stdin
+  user list [-search text] [-role role] [-page n] [-per-page n]
+                         list users
+  user set-role <username> <role>
+                         change the role of a user
+  user reset-password <username>
+                         replace the password with a temporary one and print it
+  stash recompute [-all] [username...]
+                         recount the art projects, files and used space of stashes
+  migrate up|down [n]|status
+                         apply, roll back or list database migrations
+  storage verify [-json]
+                         check revision files, orphan files and stash counters
+  storage repair [-json]
+                         remove orphan files and recompute drifted stashes
+  export-user [-o file] <username>
+                         write a zip archive with the data and files of a user
+  config print [-redact]
+                         print the resolved configuration as a config file
+`
+
+// CLI runs administrative commands with the services of the application.
+type CLI struct {
+	Name   string
+	Config *config.Config
+
+	Users    service.UserService
+	Admin    service.AdminService
+	Storage  service.StorageService
+	Export   service.ExportService
+	Migrator *database.Migrator
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// New creates a CLI with the services of the application, reading from stdin and
+// writing to stdout and stderr.
+func New(name string, db *gorm.DB, conf *config.Config) (*CLI, error) {
+	migrations, err := database.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	ur := repo.NewUserRepository(db)
+	ar := repo.NewArtProjectRepository(db)
+	fsr := repo.NewFileStorageRepository(db, conf.StorageRoot)
+	sr := repo.NewSessionRepository(db)
+	lar := repo.NewLoginAttemptRepository(db)
+
+	loginPolicy := service.DefaultUserLoginPolicy
+	loginPolicy.MaxFailures = conf.LoginMaxFailures
+	loginPolicy.Lockout = conf.LoginLockout
+	loginThrottle := service.NewLoginThrottleService(lar, loginPolicy, service.DefaultIPLoginPolicy)
+	passwordPolicy := service.NewPasswordPolicy(conf.PasswordMinLength, conf.BreachedPasswords)
+
+	return &CLI{
+		Name:     name,
+		Config:   conf,
+		Users:    service.NewUserService(ur, repo.NewInviteRepository(db), repo.NewIdentityRepository(db), passwordPolicy, conf.RegistrationMode),
+		Admin:    service.NewAdminService(ur, repo.NewAuditLogRepository(db), repo.NewInviteRepository(db), sr, loginThrottle, repo.NewTwoFactorRepository(db), repo.NewPermissionRepository(db), passwordPolicy),
+		Storage:  service.NewStorageService(ur, ar, fsr),
+		Export:   service.NewExportService(ur, ar, repo.NewCollectionRepository(db), repo.NewWebPageRepository(db), fsr),
+		Migrator: database.NewMigrator(db, migrations),
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+	}, nil
+}
+
+// Run runs the command in args and returns the exit code, 2 for invalid usage.
+func (c *CLI) Run(ctx context.Context, args []string) int {
+	err := c.run(ctx, args)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(c.Stderr, usage, c.Name)
+		return exitUsage
+	default:
+		fmt.Fprintf(c.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+}
+
+func (c *CLI) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "user":
+		return c.user(ctx, args[1:])
+	case "stash":
+		return c.stash(ctx, args[1:])
+	case "migrate":
+		return c.migrate(ctx, args[1:])
+	case "storage":
+		return c.storage(ctx, args[1:])
+	case "export-user":
+		return c.exportUser(ctx, args[1:])
+	case "config":
+		return c.config(args[1:])
+	default:
+		return errUsage
+	}
+}
+
+// readPassword prompts twice for a password without echo on a terminal, otherwise it
+// reads the first line of stdin.
+func (c *CLI) readPassword() (string, error) {
+	if f, ok := c.Stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(c.Stderr, "Password: ")
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(c.Stderr)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprint(c.Stderr, "Repeat password: ")
+		repeated, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(c.Stderr)
+		if err != nil {
+			return "", err
+		}
+
+		if string(password) != string(repeated) {
+			return "", errors.New("passwords do not match")
+		}
+		return string(password), nil
+	}
+
+	line, err := bufio.NewReader(c.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("no password on stdin")
+	}
+	return password, nil
+}
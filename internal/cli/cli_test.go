@@ -0,0 +1,252 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/cli"
+	"github.com/mirai-box/mirai-box/internal/config"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+type testCLI struct {
+	*cli.CLI
+	users   *mocks.UserService
+	admin   *mocks.AdminService
+	storage *mocks.StorageService
+	export  *mocks.ExportService
+	stdout  *bytes.Buffer
+	stderr  *bytes.Buffer
+}
+
+func newTestCLI(t *testing.T, stdin string) *testCLI {
+	c := &testCLI{
+		users:   mocks.NewUserService(t),
+		admin:   mocks.NewAdminService(t),
+		storage: mocks.NewStorageService(t),
+		export:  mocks.NewExportService(t),
+		stdout:  &bytes.Buffer{},
+		stderr:  &bytes.Buffer{},
+	}
+	c.CLI = &cli.CLI{
+		Name:    "miraibox-admin",
+		Users:   c.users,
+		Admin:   c.admin,
+		Storage: c.storage,
+		Export:  c.export,
+		Stdin:   strings.NewReader(stdin),
+		Stdout:  c.stdout,
+		Stderr:  c.stderr,
+	}
+	return c
+}
+
+func (c *testCLI) run(args ...string) int {
+	return c.Run(context.Background(), args)
+}
+
+func TestUsage(t *testing.T) {
+	c := newTestCLI(t, "")
+
+	assert.Equal(t, 2, c.run())
+	assert.Equal(t, 2, c.run("unknown"))
+	assert.Equal(t, 2, c.run("user", "create"))
+	assert.Contains(t, c.stderr.String(), "Usage: miraibox-admin")
+}
+
+func TestUserCreate(t *testing.T) {
+	t.Run("Password from stdin", func(t *testing.T) {
+		c := newTestCLI(t, "s3cret-password\n")
+		user := &model.User{ID: uuid.New(), Username: "igor", Role: model.RoleAdmin}
+		c.users.On("CreateUser", mock.Anything, "igor", "s3cret-password", model.RoleAdmin).Return(user, nil).Once()
+
+		require.Equal(t, 0, c.run("user", "create", "-role", "admin", "igor"))
+		assert.Contains(t, c.stdout.String(), "Created admin igor")
+	})
+
+	t.Run("Weak password", func(t *testing.T) {
+		c := newTestCLI(t, "short\n")
+		c.users.On("CreateUser", mock.Anything, "igor", "short", model.RoleUser).Return(nil, model.ErrWeakPassword).Once()
+
+		assert.Equal(t, 1, c.run("user", "create", "igor"))
+		assert.Contains(t, c.stderr.String(), "password")
+	})
+
+	t.Run("Empty stdin", func(t *testing.T) {
+		c := newTestCLI(t, "")
+
+		assert.Equal(t, 1, c.run("user", "create", "igor"))
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		c := newTestCLI(t, "s3cret-password\n")
+
+		assert.Equal(t, 1, c.run("user", "create", "-role", "owner", "igor"))
+	})
+}
+
+func TestUserSetRole(t *testing.T) {
+	c := newTestCLI(t, "")
+	user := &model.User{ID: uuid.New(), Username: "igor", Role: model.RoleUser}
+	c.users.On("GetUserByUsername", mock.Anything, "igor").Return(user, nil).Once()
+	c.admin.On("SetRole", mock.Anything, model.SystemActorID.String(), user.ID.String(), model.RoleAdmin).
+		Return(&model.User{ID: user.ID, Username: "igor", Role: model.RoleAdmin}, nil).Once()
+
+	require.Equal(t, 0, c.run("user", "set-role", "igor", "admin"))
+	assert.Contains(t, c.stdout.String(), "igor is now admin")
+}
+
+func TestUserResetPassword(t *testing.T) {
+	c := newTestCLI(t, "")
+	user := &model.User{ID: uuid.New(), Username: "igor"}
+	c.users.On("GetUserByUsername", mock.Anything, "igor").Return(user, nil).Once()
+	c.admin.On("ResetPassword", mock.Anything, model.SystemActorID.String(), user.ID.String()).Return("temporary", nil).Once()
+
+	require.Equal(t, 0, c.run("user", "reset-password", "igor"))
+	assert.Contains(t, c.stdout.String(), "temporary")
+}
+
+func TestStashRecompute(t *testing.T) {
+	t.Run("All users", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		users := []model.User{{ID: uuid.New(), Username: "a"}, {ID: uuid.New(), Username: "b"}}
+		c.admin.On("ListUsers", mock.Anything, model.SystemActorID.String(), mock.MatchedBy(func(q model.UserListQuery) bool { return q.Page == 1 })).
+			Return(users[:1], model.Pagination{CurrentPage: 1, TotalPages: 2}, nil).Once()
+		c.admin.On("ListUsers", mock.Anything, model.SystemActorID.String(), mock.MatchedBy(func(q model.UserListQuery) bool { return q.Page == 2 })).
+			Return(users[1:], model.Pagination{CurrentPage: 2, TotalPages: 2}, nil).Once()
+		for _, user := range users {
+			c.users.On("RecomputeStash", mock.Anything, user.ID.String()).Return(&model.Stash{Files: 3}, nil).Once()
+		}
+
+		require.Equal(t, 0, c.run("stash", "recompute", "-all"))
+		assert.Contains(t, c.stdout.String(), "b: 0 art projects, 3 files")
+	})
+
+	t.Run("Users or all", func(t *testing.T) {
+		c := newTestCLI(t, "")
+
+		assert.Equal(t, 2, c.run("stash", "recompute"))
+		assert.Equal(t, 2, c.run("stash", "recompute", "-all", "igor"))
+	})
+}
+
+func TestStorageVerify(t *testing.T) {
+	t.Run("Consistent", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		c.storage.On("Verify", mock.Anything).Return(&model.StorageReport{Revisions: 4, Files: 4}, nil).Once()
+
+		require.Equal(t, 0, c.run("storage", "verify"))
+		assert.Contains(t, c.stdout.String(), "Checked 4 revisions and 4 files, 0 problems")
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		c.storage.On("Verify", mock.Anything).Return(&model.StorageReport{
+			Revisions: 4,
+			Problems:  []model.StorageProblem{{RevisionID: uuid.New(), Kind: model.StorageProblemMissing, FilePath: "/storage/v1"}},
+		}, nil).Once()
+
+		assert.Equal(t, 1, c.run("storage", "verify"))
+		assert.Contains(t, c.stdout.String(), "missing")
+	})
+}
+
+func TestStorageRepair(t *testing.T) {
+	t.Run("Repaired", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		c.storage.On("Repair", mock.Anything).Return(&model.StorageReport{
+			Problems: []model.StorageProblem{{Kind: model.StorageProblemOrphan, FilePath: "/storage/v1", Repaired: true}},
+			Stashes:  []model.StashDrift{{UserID: uuid.New(), Repaired: true}},
+		}, nil).Once()
+
+		require.Equal(t, 0, c.run("storage", "repair"))
+		assert.Contains(t, c.stdout.String(), "orphan")
+		assert.Contains(t, c.stdout.String(), "stash_drift")
+	})
+
+	t.Run("Missing file is not repaired", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		c.storage.On("Repair", mock.Anything).Return(&model.StorageReport{
+			Problems: []model.StorageProblem{{Kind: model.StorageProblemMissing, FilePath: "/storage/v1"}},
+		}, nil).Once()
+
+		assert.Equal(t, 1, c.run("storage", "repair", "-json"))
+		assert.Contains(t, c.stdout.String(), `"kind": "missing"`)
+	})
+}
+
+func TestExportUser(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Username: "igor"}
+
+	t.Run("To file", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		output := filepath.Join(t.TempDir(), "igor.zip")
+		c.users.On("GetUserByUsername", mock.Anything, "igor").Return(user, nil).Once()
+		c.export.On("ExportUser", mock.Anything, user.ID.String(), mock.Anything).Run(func(args mock.Arguments) {
+			_, _ = args.Get(2).(*os.File).WriteString("archive")
+		}).Return(nil).Once()
+
+		require.Equal(t, 0, c.run("export-user", "-o", output, "igor"))
+
+		content, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Equal(t, "archive", string(content))
+	})
+
+	t.Run("Failed export removes the file", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		output := filepath.Join(t.TempDir(), "igor.zip")
+		c.users.On("GetUserByUsername", mock.Anything, "igor").Return(user, nil).Once()
+		c.export.On("ExportUser", mock.Anything, user.ID.String(), mock.Anything).Return(errors.New("disk full")).Once()
+
+		assert.Equal(t, 1, c.run("export-user", "-o", output, "igor"))
+		assert.NoFileExists(t, output)
+	})
+}
+
+func TestConfigPrint(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SESSION_KEY", "session-key")
+	t.Setenv("SECRET_KEY", strings.Repeat("ab", 32))
+	t.Setenv("DB_PASSWORD", "db-password")
+	t.Setenv("DB_HOST", "postgres")
+
+	conf, err := config.GetApplicationConfig()
+	require.NoError(t, err)
+
+	t.Run("redacted", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		c.Config = conf
+
+		require.Equal(t, 0, c.run("config", "print", "-redact"), c.stderr.String())
+		out := c.stdout.String()
+		assert.Contains(t, out, "host: postgres # from env")
+		assert.Contains(t, out, "password: '[redacted]' # from env")
+		assert.NotContains(t, out, "db-password")
+		assert.NotContains(t, out, "session-key")
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		c.Config = conf
+
+		require.Equal(t, 0, c.run("config", "print"), c.stderr.String())
+		assert.Contains(t, c.stdout.String(), "password: db-password # from env")
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		c := newTestCLI(t, "")
+		assert.Equal(t, 2, c.run("config", "show"))
+	})
+}
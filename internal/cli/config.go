@@ -0,0 +1,15 @@
+package cli
+
+func (c *CLI) config(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errUsage
+	}
+
+	flags := newFlagSet("config print")
+	redact := flags.Bool("redact", false, "replace keys, passwords and tokens")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	return c.Config.WriteYAML(c.Stdout, *redact)
+}
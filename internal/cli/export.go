@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+func (c *CLI) exportUser(ctx context.Context, args []string) error {
+	flags := newFlagSet("export-user")
+	output := flags.String("o", "", "archive file, stdout by default")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	user, err := c.findUser(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		if err := c.Export.ExportUser(ctx, user.ID.String(), c.Stdout); err != nil {
+			return fmt.Errorf("failed to export %s: %w", user.Username, err)
+		}
+		return nil
+	}
+
+	f, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = c.Export.ExportUser(ctx, user.ID.String(), f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+		return fmt.Errorf("failed to export %s: %w", user.Username, err)
+	}
+
+	fmt.Fprintf(c.Stderr, "Exported %s to %s\n", user.Username, *output)
+	return nil
+}
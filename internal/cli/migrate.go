@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/database"
+)
+
+func (c *CLI) migrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errUsage
+		}
+
+		applied, err := c.Migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.Stdout, "Applied %d migrations\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return errUsage
+		}
+		if len(args) == 2 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errUsage
+			}
+		}
+
+		err := c.Migrator.Down(ctx, steps)
+		if errors.Is(err, database.ErrNoMigration) {
+			fmt.Fprintln(c.Stdout, "No migration to roll back")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+	case "status":
+		status, err := c.Migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(c.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return errUsage
+	}
+
+	return nil
+}
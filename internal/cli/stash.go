@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func (c *CLI) stash(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "recompute" {
+		return errUsage
+	}
+
+	flags := newFlagSet("stash recompute")
+	all := flags.Bool("all", false, "recompute the stashes of all users")
+	if err := flags.Parse(args[1:]); err != nil || *all == (flags.NArg() > 0) {
+		return errUsage
+	}
+
+	if !*all {
+		for _, username := range flags.Args() {
+			user, err := c.findUser(ctx, username)
+			if err != nil {
+				return err
+			}
+			if err := c.recomputeStash(ctx, user); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	query := model.UserListQuery{Page: 1, PerPage: 100}
+	for {
+		users, pagination, err := c.Admin.ListUsers(ctx, model.SystemActorID.String(), query)
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+
+		for i := range users {
+			if err := c.recomputeStash(ctx, &users[i]); err != nil {
+				return err
+			}
+		}
+
+		if query.Page >= pagination.TotalPages {
+			return nil
+		}
+		query.Page++
+	}
+}
+
+func (c *CLI) recomputeStash(ctx context.Context, user *model.User) error {
+	stash, err := c.Users.RecomputeStash(ctx, user.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to recompute the stash of %s: %w", user.Username, err)
+	}
+
+	fmt.Fprintf(c.Stdout, "%s: %d art projects, %d files, %d bytes\n", user.Username, stash.ArtProjects, stash.Files, stash.UsedSpace)
+	return nil
+}
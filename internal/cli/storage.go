@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func (c *CLI) storage(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	var check func(ctx context.Context) (*model.StorageReport, error)
+	switch args[0] {
+	case "verify":
+		check = c.Storage.Verify
+	case "repair":
+		check = c.Storage.Repair
+	default:
+		return errUsage
+	}
+
+	flags := newFlagSet("storage " + args[0])
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	report, err := check(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to %s storage: %w", args[0], err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(c.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		c.printStorageReport(report)
+	}
+
+	if n := report.Unrepaired(); n > 0 {
+		return fmt.Errorf("%d storage problems are not repaired", n)
+	}
+	return nil
+}
+
+// printStorageReport writes one line per problem and drifted stash and a summary.
+func (c *CLI) printStorageReport(report *model.StorageReport) {
+	for _, problem := range report.Problems {
+		status := ""
+		if problem.Repaired {
+			status = "\trepaired"
+		}
+		if problem.Kind == model.StorageProblemOrphan {
+			fmt.Fprintf(c.Stdout, "%s\t%d bytes\t%s%s\n", problem.Kind, problem.ActualSize, problem.FilePath, status)
+		} else {
+			fmt.Fprintf(c.Stdout, "%s\trevision %s\t%s%s\n", problem.Kind, problem.RevisionID, problem.FilePath, status)
+		}
+	}
+
+	for _, stash := range report.Stashes {
+		status := ""
+		if stash.Repaired {
+			status = "\trepaired"
+		}
+		fmt.Fprintf(c.Stdout, "stash_drift\tuser %s\tart projects %d/%d, files %d/%d, used space %d/%d%s\n",
+			stash.UserID,
+			stash.Recorded.ArtProjects, stash.Actual.ArtProjects,
+			stash.Recorded.Files, stash.Actual.Files,
+			stash.Recorded.UsedSpace, stash.Actual.UsedSpace,
+			status)
+	}
+
+	fmt.Fprintf(c.Stdout, "Checked %d revisions and %d files, %d problems, %d drifted stashes, %d not repaired\n",
+		report.Revisions, report.Files, len(report.Problems), len(report.Stashes), report.Unrepaired())
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func (c *CLI) user(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "create":
+		return c.userCreate(ctx, args[1:])
+	case "list":
+		return c.userList(ctx, args[1:])
+	case "set-role":
+		return c.userSetRole(ctx, args[1:])
+	case "reset-password":
+		return c.userResetPassword(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func (c *CLI) userCreate(ctx context.Context, args []string) error {
+	flags := newFlagSet("user create")
+	role := flags.String("role", model.RoleUser, "role of the new user")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	if *role != model.RoleUser && *role != model.RoleAdmin {
+		return fmt.Errorf("unknown role %q", *role)
+	}
+
+	password, err := c.readPassword()
+	if err != nil {
+		return err
+	}
+
+	user, err := c.Users.CreateUser(ctx, flags.Arg(0), password, *role)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	fmt.Fprintf(c.Stdout, "Created %s %s with ID %s\n", user.Role, user.Username, user.ID)
+	return nil
+}
+
+func (c *CLI) userList(ctx context.Context, args []string) error {
+	flags := newFlagSet("user list")
+	search := flags.String("search", "", "part of the username")
+	role := flags.String("role", "", "only users with this role")
+	page := flags.Int("page", 1, "page number")
+	perPage := flags.Int("per-page", 50, "users per page")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	users, pagination, err := c.Admin.ListUsers(ctx, model.SystemActorID.String(), model.UserListQuery{
+		Search:  *search,
+		Role:    *role,
+		Page:    *page,
+		PerPage: *perPage,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
+
+	w := tabwriter.NewWriter(c.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tROLE\tDISABLED\t2FA\tCREATED")
+	for _, user := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%t\t%s\n",
+			user.ID, user.Username, user.Role, user.Disabled, user.TOTPEnabled, user.CreatedAt.Format(time.DateOnly))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Stdout, "Page %d of %d, %d users\n", pagination.CurrentPage, pagination.TotalPages, pagination.TotalRecords)
+	return nil
+}
+
+func (c *CLI) userSetRole(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	user, err := c.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	user, err = c.Admin.SetRole(ctx, model.SystemActorID.String(), user.ID.String(), args[1])
+	if err != nil {
+		return fmt.Errorf("failed to set role: %w", err)
+	}
+
+	fmt.Fprintf(c.Stdout, "%s is now %s\n", user.Username, user.Role)
+	return nil
+}
+
+func (c *CLI) userResetPassword(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	user, err := c.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	password, err := c.Admin.ResetPassword(ctx, model.SystemActorID.String(), user.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	fmt.Fprintf(c.Stdout, "Temporary password for %s: %s\n", user.Username, password)
+	return nil
+}
+
+// findUser returns the user with the given username.
+func (c *CLI) findUser(ctx context.Context, username string) (*model.User, error) {
+	user, err := c.Users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user %s: %w", username, err)
+	}
+	return user, nil
+}
+
+// newFlagSet creates a flag set that reports errors through the usage of the CLI.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
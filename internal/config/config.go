@@ -1,12 +1,21 @@
 package config
 
 import (
+	"bufio"
 	"encoding/hex"
 	"fmt"
-	"log"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/notifier"
 )
 
 const (
@@ -18,17 +27,174 @@ const (
 	defaultDBUser     = "mirai_box_user"
 	defaultAppStage   = localStage
 	defaultPort       = "8080"
+
+	defaultRegistrationMode = model.RegistrationModeInvite
+	defaultLoginMaxFailures = 10
+	defaultLoginLockout     = 15 * time.Minute
+
+	defaultPasswordMinLength = 8
+	defaultPasswordResetTTL  = time.Hour
+	defaultNotifier          = notifier.KindLog
+
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultReadTimeout       = 10 * time.Minute
+	defaultWriteTimeout      = 10 * time.Minute
+	defaultIdleTimeout       = 2 * time.Minute
+	defaultShutdownTimeout   = 30 * time.Second
+	defaultShutdownDelay     = 5 * time.Second
+
+	defaultRateLimitStore = RateLimitStoreMemory
+
+	defaultStorageBackend = StorageBackendLocal
+	defaultUploadMaxSize  = 100 << 20
+
+	defaultCORSMaxAge         = 5 * time.Minute
+	defaultSessionTTL         = 24 * time.Hour
+	defaultSessionRememberTTL = 7 * 24 * time.Hour
 )
 
+// Rate limit stores, see Config.RateLimitStore.
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+// StorageBackendLocal stores the revision files below Config.StorageRoot.
+const StorageBackendLocal = "local"
+
+// defaultRateLimits are the budgets of the route classes, read from RATE_LIMIT_<CLASS>.
+var defaultRateLimits = map[string]model.RateLimit{
+	model.RateLimitAuth:     {Requests: 20, Period: time.Minute},
+	model.RateLimitUpload:   {Requests: 60, Period: time.Hour},
+	model.RateLimitDownload: {Requests: 600, Period: time.Minute},
+	model.RateLimitAPI:      {Requests: 300, Period: time.Minute},
+}
+
+// defaultCORSOrigins is the frontend served by its development server.
+var defaultCORSOrigins = []string{"http://localhost:3000"}
+
+var validProviderName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
 type Config struct {
 	LogLevel    slog.Level
 	Stage       string
 	Port        string
+	PublicURL   string
 	Database    *DatabaseConfig
+	Server      *ServerConfig
+	CORS        *CORSConfig
+	Session     *SessionConfig
+	Features    *FeatureConfig
 	StorageRoot string
 	ProjectRoot string
 	SessionKey  string
 	SecretKey   []byte
+
+	// StorageBackend is where revision files are stored, only local is supported.
+	StorageBackend string
+	// UploadMaxSize is the largest request body of an upload in bytes, 0 allows any size.
+	UploadMaxSize int64
+
+	// RegistrationMode is one of open, invite or closed.
+	RegistrationMode string
+
+	// LoginMaxFailures is the number of failed logins that locks a username for LoginLockout.
+	LoginMaxFailures int
+	LoginLockout     time.Duration
+
+	// PasswordMinLength is the minimum number of characters of a new password.
+	PasswordMinLength int
+	// BreachedPasswords are rejected as new passwords, loaded from the file in PASSWORD_BREACHED_LIST.
+	BreachedPasswords []string
+	PasswordResetTTL  time.Duration
+
+	// Notifier is one of log or file, NotifierFile is the output of the file notifier.
+	Notifier     string
+	NotifierFile string
+
+	// OIDCProviders are the OpenID Connect providers users can sign in with, by name.
+	OIDCProviders map[string]*OIDCProviderConfig
+
+	// CookieSameSite and CookieSecure apply to the session and login cookies. The local stage
+	// defaults to Lax over plain HTTP, other stages to None over HTTPS for a cross-site frontend.
+	CookieSameSite http.SameSite
+	CookieSecure   bool
+
+	// StorageCheckInterval runs the storage check periodically when positive,
+	// StorageCheckRepair makes it remove orphan files and recompute drifted stashes.
+	StorageCheckInterval time.Duration
+	StorageCheckRepair   bool
+
+	// MetricsToken protects /metrics as bearer token when set.
+	MetricsToken string
+
+	// TracingEndpoint is the OTLP/HTTP URL spans are exported to, like
+	// http://collector:4318, tracing is disabled without it. TracingSampleRatio is the
+	// share of new traces that are recorded.
+	TracingEndpoint    string
+	TracingSampleRatio float64
+
+	// RateLimits are the request budgets by route class, classes turned off are missing.
+	// RateLimitStore is memory, or postgres to share the limits between instances.
+	RateLimits     map[string]model.RateLimit
+	RateLimitStore string
+
+	// settings are the resolved settings with their source, see Settings.
+	settings []Setting
+}
+
+// OIDCProviderConfig configures sign in through an OpenID Connect provider named in OIDC_PROVIDERS.
+// Its settings are read from OIDC_<NAME>_* variables, for example OIDC_STUDIO_ISSUER.
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// UsernameClaim is the ID token claim used as username of provisioned users.
+	UsernameClaim string
+	// AllowSignup creates a user on the first sign in of an unknown identity.
+	AllowSignup bool
+}
+
+// ServerConfig configures the HTTP server. Zero timeouts are disabled.
+type ServerConfig struct {
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout and WriteTimeout bound whole requests and responses, so they must
+	// allow for uploads and downloads of large files.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownDelay is the time readiness fails before the server stops accepting
+	// connections, ShutdownTimeout the time in-flight requests get to finish.
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+}
+
+// CORSConfig lists the origins of the frontends that may call the API with the
+// session cookie, and how long browsers may cache the preflight response.
+type CORSConfig struct {
+	AllowedOrigins []string
+	MaxAge         time.Duration
+}
+
+// SessionConfig configures how long a login lasts, RememberTTL applies to logins
+// that asked to keep the user signed in.
+type SessionConfig struct {
+	TTL         time.Duration
+	RememberTTL time.Duration
+}
+
+// FeatureConfig turns optional parts of the service on or off, the routes of a
+// disabled feature are not served.
+type FeatureConfig struct {
+	// Feeds serves the RSS and Atom feeds of collections and galleries.
+	Feeds bool
+	// WebPages lets users write web pages.
+	WebPages bool
+	// Metrics serves the Prometheus metrics on /metrics.
+	Metrics bool
 }
 
 type DatabaseConfig struct {
@@ -41,53 +207,320 @@ type DatabaseConfig struct {
 	SSLMode          string
 }
 
+// GetApplicationConfig reads the configuration from the YAML file in CONFIG_FILE, if
+// set, and from environment variables, which take precedence over the file. Each
+// setting is named after its environment variable, see newLoader for the file layout.
+// All invalid settings are reported together in a *ValidationError.
 func GetApplicationConfig() (*Config, error) {
-	projectRoot := getEnv("PROJECT_ROOT", getCurrentDir())
-	storageRoot := getEnv("STORAGE_ROOT", filepath.Join(projectRoot, "storage"))
-
-	sessionKey := os.Getenv("SESSION_KEY")
-	if sessionKey == "" {
-		return nil, fmt.Errorf("SESSION_KEY environment variable is not set")
+	l, err := newLoader(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
 	}
+	return l.load()
+}
 
-	hexKey := os.Getenv("SECRET_KEY")
+func (l *loader) load() (*Config, error) {
+	stage := l.string("APP_ENV", defaultAppStage)
+	logLevel := l.logLevel("LOG_LEVEL", defaultDebugLevel)
+
+	projectRoot := l.string("PROJECT_ROOT", getCurrentDir())
+	storageRoot := l.string("STORAGE_ROOT", filepath.Join(projectRoot, "storage"))
+	storageBackend := l.oneOf("STORAGE_BACKEND", defaultStorageBackend, StorageBackendLocal)
+	uploadMaxSize := l.size("UPLOAD_MAX_SIZE", defaultUploadMaxSize, "must be a size like 100MB, or 0 to allow any size")
+
+	sessionKey := l.secret("SESSION_KEY")
 	if sessionKey == "" {
-		return nil, fmt.Errorf("SECRET_KEY environment variable is not set")
+		l.errorf("SESSION_KEY is not set")
 	}
 
-	// Decode the hex string to bytes
+	// The value of the key is left out of the errors.
+	hexKey := l.secret("SECRET_KEY")
 	secretKey, err := hex.DecodeString(hexKey)
-	if err != nil {
-		log.Fatalf("Failed to decode hex key: %v", err)
+	switch {
+	case hexKey == "":
+		l.errorf("SECRET_KEY is not set")
+	case err != nil || len(secretKey) != 32:
+		l.errorf("invalid SECRET_KEY: must be 32 bytes encoded as 64 hex digits")
+	}
+
+	registrationMode := l.oneOf("REGISTRATION_MODE", defaultRegistrationMode,
+		model.RegistrationModeOpen, model.RegistrationModeInvite, model.RegistrationModeClosed)
+
+	loginMaxFailures := l.int("LOGIN_MAX_FAILURES", defaultLoginMaxFailures, 1, "must be a positive number")
+	loginLockout := l.duration("LOGIN_LOCKOUT", defaultLoginLockout, false, "must be a positive duration like 15m")
+
+	passwordMinLength := l.int("PASSWORD_MIN_LENGTH", defaultPasswordMinLength, 1, "must be a positive number")
+
+	var breachedPasswords []string
+	if path := l.string("PASSWORD_BREACHED_LIST", ""); path != "" {
+		var err error
+		breachedPasswords, err = readLines(path)
+		if err != nil {
+			l.errorf("failed to load PASSWORD_BREACHED_LIST: %v", err)
+		}
 	}
 
-	// Check the length of the key
-	if len(secretKey) != 32 {
-		return nil, fmt.Errorf("Invalid key length: %d bytes. Key must be 32 bytes long.", len(secretKey))
+	passwordResetTTL := l.duration("PASSWORD_RESET_TTL", defaultPasswordResetTTL, false, "must be a positive duration like 1h")
+
+	notifierKind := l.oneOf("NOTIFIER", defaultNotifier, notifier.KindLog, notifier.KindFile)
+	notifierFile := l.string("NOTIFIER_FILE", filepath.Join(projectRoot, "notifications.log"))
+
+	storageCheckInterval := l.duration("STORAGE_CHECK_INTERVAL", 0, true, "must be a duration like 24h, or 0 to disable")
+	storageCheckRepair := l.bool("STORAGE_CHECK_REPAIR", false)
+
+	metricsToken := l.secret("METRICS_TOKEN")
+
+	tracingEndpoint := l.string("TRACING_ENDPOINT", "")
+	if tracingEndpoint != "" && !isHTTPURL(tracingEndpoint) {
+		l.fail("TRACING_ENDPOINT", tracingEndpoint, "must be an http or https URL")
+	}
+	tracingSampleRatio := l.float("TRACING_SAMPLE_RATIO", 1, 0, 1, "must be a number from 0 to 1")
+
+	rateLimitStore := l.oneOf("RATE_LIMIT_STORE", defaultRateLimitStore, RateLimitStoreMemory, RateLimitStorePostgres)
+	rateLimits := l.rateLimits()
+
+	cookieSameSite, cookieSecure := l.cookieOptions(stage)
+
+	port := l.string("PORT", defaultPort)
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		l.fail("PORT", port, "must be a port number from 1 to 65535")
 	}
 
-	return &Config{
-		Stage:       getEnv("APP_ENV", defaultAppStage),
-		Port:        getEnv("PORT", defaultPort),
+	publicURL := strings.TrimSuffix(l.string("PUBLIC_URL", "http://localhost:"+port), "/")
+	if !isHTTPURL(publicURL) {
+		l.fail("PUBLIC_URL", publicURL, "must be an http or https URL")
+	}
+
+	conf := &Config{
+		Stage:       stage,
+		Port:        port,
+		PublicURL:   publicURL,
 		StorageRoot: storageRoot,
 		ProjectRoot: projectRoot,
 		SessionKey:  sessionKey,
 		SecretKey:   secretKey,
-		LogLevel:    parseLogLevel(getEnv("LOG_LEVEL", defaultDebugLevel)),
-		Database:    GetDatabaseConfig(),
-	}, nil
+		LogLevel:    logLevel,
+		Database:    l.databaseConfig(),
+		Server:      l.serverConfig(stage),
+		CORS:        l.corsConfig(),
+		Session:     l.sessionConfig(),
+		Features:    l.featureConfig(),
+
+		StorageBackend: storageBackend,
+		UploadMaxSize:  uploadMaxSize,
+
+		RegistrationMode: registrationMode,
+		LoginMaxFailures: loginMaxFailures,
+		LoginLockout:     loginLockout,
+
+		PasswordMinLength: passwordMinLength,
+		BreachedPasswords: breachedPasswords,
+		PasswordResetTTL:  passwordResetTTL,
+		Notifier:          notifierKind,
+		NotifierFile:      notifierFile,
+
+		OIDCProviders: l.oidcProviders(publicURL),
+
+		CookieSameSite: cookieSameSite,
+		CookieSecure:   cookieSecure,
+
+		StorageCheckInterval: storageCheckInterval,
+		StorageCheckRepair:   storageCheckRepair,
+
+		MetricsToken: metricsToken,
+
+		TracingEndpoint:    tracingEndpoint,
+		TracingSampleRatio: tracingSampleRatio,
+
+		RateLimits:     rateLimits,
+		RateLimitStore: rateLimitStore,
+
+		settings: l.settings,
+	}
+
+	if err := l.err(); err != nil {
+		return nil, err
+	}
+	return conf, nil
 }
 
-func GetDatabaseConfig() *DatabaseConfig {
-	return &DatabaseConfig{
-		Host:             getEnv("DB_HOST", defaultDBHost),
-		Port:             getEnv("DB_PORT", defaultDBPort),
-		Username:         getEnv("DB_USER", defaultDBUser),
-		Password:         os.Getenv("DB_PASSWORD"),
-		PostgresPassword: os.Getenv("DB_POSTGRES_PASSWORD"),
-		Database:         getEnv("DB_NAME", defaultDBName),
-		SSLMode:          getEnv("DB_SSLMODE", "disable"),
+// logLevel reads a log level like debug, info, warn or error.
+func (l *loader) logLevel(key, fallback string) slog.Level {
+	v := l.string(key, fallback)
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(v)); err != nil {
+		l.fail(key, v, "must be debug, info, warn or error")
+		return slog.LevelInfo
 	}
+	return level
+}
+
+// cookieOptions reads COOKIE_SAMESITE and COOKIE_SECURE with defaults for the stage.
+func (l *loader) cookieOptions(stage string) (http.SameSite, bool) {
+	sameSiteName, secureDefault := "none", true
+	if stage == localStage {
+		sameSiteName, secureDefault = "lax", false
+	}
+
+	var sameSite http.SameSite
+	switch v := l.string("COOKIE_SAMESITE", sameSiteName); strings.ToLower(v) {
+	case "lax":
+		sameSite = http.SameSiteLaxMode
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	default:
+		l.fail("COOKIE_SAMESITE", v, "must be lax, strict or none")
+	}
+
+	secure := l.bool("COOKIE_SECURE", secureDefault)
+
+	// Browsers drop SameSite=None cookies without the Secure attribute.
+	if sameSite == http.SameSiteNoneMode && !secure {
+		l.errorf("COOKIE_SAMESITE none requires COOKIE_SECURE true")
+	}
+
+	return sameSite, secure
+}
+
+// serverConfig reads the HTTP_* timeouts and SHUTDOWN_* options. The local stage
+// shuts down without delay, as there is no load balancer to notice readiness.
+func (l *loader) serverConfig(stage string) *ServerConfig {
+	shutdownDelay := defaultShutdownDelay
+	if stage == localStage {
+		shutdownDelay = 0
+	}
+
+	const hint = "must be a duration like 30s, or 0 to disable"
+	return &ServerConfig{
+		ReadHeaderTimeout: l.duration("HTTP_READ_HEADER_TIMEOUT", defaultReadHeaderTimeout, true, hint),
+		ReadTimeout:       l.duration("HTTP_READ_TIMEOUT", defaultReadTimeout, true, hint),
+		WriteTimeout:      l.duration("HTTP_WRITE_TIMEOUT", defaultWriteTimeout, true, hint),
+		IdleTimeout:       l.duration("HTTP_IDLE_TIMEOUT", defaultIdleTimeout, true, hint),
+		ShutdownDelay:     l.duration("SHUTDOWN_DELAY", shutdownDelay, true, hint),
+		ShutdownTimeout:   l.duration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout, false, "must be a positive duration like 30s"),
+	}
+}
+
+// corsConfig reads the CORS_* options. Origins must be exact, since requests carry
+// the session cookie a wildcard is not allowed.
+func (l *loader) corsConfig() *CORSConfig {
+	conf := &CORSConfig{
+		AllowedOrigins: l.list("CORS_ALLOWED_ORIGINS", defaultCORSOrigins),
+		MaxAge:         l.duration("CORS_MAX_AGE", defaultCORSMaxAge, true, "must be a duration like 5m, or 0 to disable"),
+	}
+
+	for _, origin := range conf.AllowedOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || !isHTTPURL(origin) || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			l.fail("CORS_ALLOWED_ORIGINS", origin, "must be origins like https://app.example.com")
+		}
+	}
+
+	return conf
+}
+
+// sessionConfig reads the SESSION_* lifetimes.
+func (l *loader) sessionConfig() *SessionConfig {
+	return &SessionConfig{
+		TTL:         l.duration("SESSION_TTL", defaultSessionTTL, false, "must be a positive duration like 24h"),
+		RememberTTL: l.duration("SESSION_REMEMBER_TTL", defaultSessionRememberTTL, false, "must be a positive duration like 168h"),
+	}
+}
+
+// featureConfig reads the FEATURE_* toggles, all features are enabled by default.
+func (l *loader) featureConfig() *FeatureConfig {
+	return &FeatureConfig{
+		Feeds:    l.bool("FEATURE_FEEDS", true),
+		WebPages: l.bool("FEATURE_WEBPAGES", true),
+		Metrics:  l.bool("FEATURE_METRICS", true),
+	}
+}
+
+// rateLimits reads the budget of each route class from RATE_LIMIT_<CLASS> as
+// requests per period like 20/1m, or off to not limit the class.
+func (l *loader) rateLimits() map[string]model.RateLimit {
+	limits := map[string]model.RateLimit{}
+
+	for _, class := range model.RateLimitClasses {
+		key := "RATE_LIMIT_" + strings.ToUpper(class)
+		fallback := defaultRateLimits[class]
+		v := l.string(key, fmt.Sprintf("%d/%s", fallback.Requests, formatDuration(fallback.Period)))
+		if strings.EqualFold(v, "off") {
+			continue
+		}
+
+		requests, period, found := strings.Cut(v, "/")
+		limit := model.RateLimit{}
+		var err error
+		if found {
+			limit.Requests, err = strconv.Atoi(requests)
+			if err == nil {
+				limit.Period, err = time.ParseDuration(period)
+			}
+		}
+		if !found || err != nil || limit.Requests < 1 || limit.Period <= 0 {
+			l.fail(key, v, "must be requests per period like 20/1m, or off")
+			continue
+		}
+		limits[class] = limit
+	}
+
+	return limits
+}
+
+// oidcProviders reads the providers listed in OIDC_PROVIDERS, the callback of each
+// provider is served below publicURL.
+func (l *loader) oidcProviders(publicURL string) map[string]*OIDCProviderConfig {
+	providers := map[string]*OIDCProviderConfig{}
+
+	for _, name := range l.list("OIDC_PROVIDERS", nil) {
+		if !validProviderName.MatchString(name) {
+			l.errorf("invalid OIDC provider name %q: use lowercase letters, digits and dashes", name)
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := &OIDCProviderConfig{
+			Name:          name,
+			Issuer:        l.string(prefix+"ISSUER", ""),
+			ClientID:      l.string(prefix+"CLIENT_ID", ""),
+			ClientSecret:  l.secret(prefix + "CLIENT_SECRET"),
+			RedirectURL:   l.string(prefix+"REDIRECT_URL", publicURL+"/login/oidc/"+name+"/callback"),
+			Scopes:        l.list(prefix+"SCOPES", []string{"openid", "profile", "email"}),
+			UsernameClaim: l.string(prefix+"USERNAME_CLAIM", "preferred_username"),
+			AllowSignup:   l.bool(prefix+"ALLOW_SIGNUP", true),
+		}
+
+		if provider.Issuer == "" || provider.ClientID == "" {
+			l.errorf("OIDC provider %q requires %sISSUER and %sCLIENT_ID", name, prefix, prefix)
+		}
+
+		providers[name] = provider
+	}
+
+	return providers
+}
+
+// databaseConfig reads the DB_* connection settings.
+func (l *loader) databaseConfig() *DatabaseConfig {
+	conf := &DatabaseConfig{
+		Host:             l.string("DB_HOST", defaultDBHost),
+		Port:             l.string("DB_PORT", defaultDBPort),
+		Username:         l.string("DB_USER", defaultDBUser),
+		Password:         l.secret("DB_PASSWORD"),
+		PostgresPassword: l.secret("DB_POSTGRES_PASSWORD"),
+		Database:         l.string("DB_NAME", defaultDBName),
+		SSLMode:          l.oneOf("DB_SSLMODE", "disable", "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+	}
+
+	if n, err := strconv.Atoi(conf.Port); err != nil || n < 1 || n > 65535 {
+		l.fail("DB_PORT", conf.Port, "must be a port number from 1 to 65535")
+	}
+
+	return conf
 }
 
 func (conf *Config) IsLocal() bool {
@@ -104,19 +537,31 @@ func (dbConfig *DatabaseConfig) ConnectionPostgres() string {
 		dbConfig.Host, dbConfig.Port, dbConfig.PostgresPassword, dbConfig.SSLMode)
 }
 
-func parseLogLevel(s string) slog.Level {
-	var level slog.Level
-	if err := level.UnmarshalText([]byte(s)); err != nil {
-		return slog.LevelInfo
-	}
-	return level
+// isHTTPURL reports whether s is an absolute http or https URL.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// readLines returns the non-empty lines of a file, lines starting with # are skipped.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
 	}
-	return fallback
+
+	return lines, scanner.Err()
 }
 
 func getCurrentDir() string {
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecretKey = strings.Repeat("ab", 32)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func setRequired(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SESSION_KEY", "session-key")
+	t.Setenv("SECRET_KEY", testSecretKey)
+}
+
+func TestGetApplicationConfig_Layers(t *testing.T) {
+	setRequired(t)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, `
+db:
+  host: file-host
+  name: file-db
+session:
+  ttl: 2h
+cors:
+  allowed-origins:
+    - https://a.example.com
+    - https://b.example.com
+upload:
+  max_size: 10MB
+`))
+
+	conf, err := GetApplicationConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, "env-host", conf.Database.Host)
+	assert.Equal(t, "file-db", conf.Database.Database)
+	assert.Equal(t, defaultDBUser, conf.Database.Username)
+	assert.Equal(t, 2*time.Hour, conf.Session.TTL)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, conf.CORS.AllowedOrigins)
+	assert.Equal(t, int64(10<<20), conf.UploadMaxSize)
+
+	sources := map[string]string{}
+	for _, s := range conf.Settings() {
+		sources[s.Key] = s.Source
+	}
+	assert.Equal(t, SourceEnv, sources["DB_HOST"])
+	assert.Equal(t, SourceFile, sources["DB_NAME"])
+	assert.Equal(t, SourceDefault, sources["DB_USER"])
+}
+
+func TestGetApplicationConfig_ValidationError(t *testing.T) {
+	setRequired(t)
+	t.Setenv("SECRET_KEY", "not-hex")
+	t.Setenv("PORT", "99999")
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, `
+session:
+  ttl: forever
+db:
+  hots: postgres
+`))
+
+	_, err := GetApplicationConfig()
+	require.Error(t, err)
+
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	msg := err.Error()
+	assert.Contains(t, msg, "invalid SECRET_KEY")
+	assert.NotContains(t, msg, "not-hex")
+	assert.Contains(t, msg, `invalid PORT "99999"`)
+	assert.Contains(t, msg, `invalid SESSION_TTL "forever"`)
+	assert.Contains(t, msg, "unknown setting db_hots")
+	assert.Len(t, verr.Errors, 4)
+}
+
+func TestGetApplicationConfig_MissingKeys(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("SESSION_KEY", "")
+	t.Setenv("SECRET_KEY", "")
+
+	_, err := GetApplicationConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SESSION_KEY is not set")
+	assert.Contains(t, err.Error(), "SECRET_KEY is not set")
+}
+
+func TestWriteYAML(t *testing.T) {
+	setRequired(t)
+	t.Setenv("DB_PASSWORD", "db-password")
+	t.Setenv("STORAGE_CHECK_INTERVAL", "24h")
+
+	conf, err := GetApplicationConfig()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, conf.WriteYAML(&buf, true))
+	out := buf.String()
+	assert.Contains(t, out, "password: '[redacted]' # from env")
+	assert.NotContains(t, out, "db-password")
+	assert.NotContains(t, out, testSecretKey)
+	assert.Contains(t, out, "storage:\n")
+	assert.Contains(t, out, "    interval: 24h # from env")
+
+	// The printed configuration loads as a config file with the same settings.
+	buf.Reset()
+	require.NoError(t, conf.WriteYAML(&buf, false))
+	require.NoError(t, os.Unsetenv("DB_PASSWORD"))
+	require.NoError(t, os.Unsetenv("STORAGE_CHECK_INTERVAL"))
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, buf.String()))
+
+	reloaded, err := GetApplicationConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "db-password", reloaded.Database.Password)
+	assert.Equal(t, 24*time.Hour, reloaded.StorageCheckInterval)
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{"0": 0, "512": 512, "1KB": 1 << 10, "100MB": 100 << 20, "2gb": 2 << 30}
+	for in, want := range tests {
+		got, err := parseSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	assert.Equal(t, "100MB", formatSize(100<<20))
+	assert.Equal(t, "1537B", formatSize(1537))
+
+	_, err := parseSize("-1MB")
+	assert.Error(t, err)
+	_, err = parseSize("lots")
+	assert.Error(t, err)
+}
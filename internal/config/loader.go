@@ -0,0 +1,347 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a setting, see Setting.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// ValidationError lists every invalid setting of a configuration, so that they can be
+// fixed at once instead of one per start.
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, err := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// loader resolves settings from the environment first, then from the config file and
+// falls back to the defaults. It records the resolved settings and collects the
+// errors of all invalid settings.
+type loader struct {
+	file     map[string]string
+	path     string
+	used     map[string]bool
+	settings []Setting
+	errs     []error
+}
+
+// newLoader reads the YAML config file at path, an empty path uses the environment only.
+func newLoader(path string) (*loader, error) {
+	l := &loader{file: map[string]string{}, path: path, used: map[string]bool{}}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if len(root.Content) > 0 {
+		if err := flatten(l.file, "", root.Content[0]); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	return l, nil
+}
+
+// flatten stores the scalars of a YAML mapping by the name of their environment
+// variable, nested keys are joined with underscores so that
+//
+//	db:
+//	  host: postgres
+//
+// sets DB_HOST. Sequences of scalars are joined with commas.
+func flatten(settings map[string]string, prefix string, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := strings.ToUpper(strings.ReplaceAll(node.Content[i].Value, "-", "_"))
+			if prefix != "" {
+				key = prefix + "_" + key
+			}
+			if err := flatten(settings, key, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: %s must be a list of values", item.Line, prefix)
+			}
+			values = append(values, item.Value)
+		}
+		settings[prefix] = strings.Join(values, ",")
+	case yaml.ScalarNode:
+		if prefix == "" {
+			return fmt.Errorf("line %d: expected a mapping of settings", node.Line)
+		}
+		settings[prefix] = node.Value
+	case yaml.AliasNode:
+		return flatten(settings, prefix, node.Alias)
+	}
+	return nil
+}
+
+// lookup returns the value of the setting from the environment or the config file.
+func (l *loader) lookup(key string) (string, string, bool) {
+	l.used[key] = true
+	if v, ok := os.LookupEnv(key); ok {
+		return v, SourceEnv, true
+	}
+	if v, ok := l.file[key]; ok {
+		return v, SourceFile, true
+	}
+	return "", SourceDefault, false
+}
+
+func (l *loader) record(key, value, source string, secret bool) {
+	l.settings = append(l.settings, Setting{Key: key, Value: value, Source: source, Secret: secret})
+}
+
+// fail records an invalid setting, hint tells which values are valid.
+func (l *loader) fail(key, value, hint string) {
+	l.errs = append(l.errs, fmt.Errorf("invalid %s %q: %s", key, value, hint))
+}
+
+// errorf records an error that is not about a single value.
+func (l *loader) errorf(format string, args ...any) {
+	l.errs = append(l.errs, fmt.Errorf(format, args...))
+}
+
+func (l *loader) string(key, fallback string) string {
+	v, source, ok := l.lookup(key)
+	if !ok {
+		v = fallback
+	}
+	l.record(key, v, source, false)
+	return v
+}
+
+// secret reads a setting that is redacted when the configuration is printed.
+func (l *loader) secret(key string) string {
+	v, source, _ := l.lookup(key)
+	l.record(key, v, source, true)
+	return v
+}
+
+// oneOf reads a setting that must be one of the given values.
+func (l *loader) oneOf(key, fallback string, values ...string) string {
+	v := l.string(key, fallback)
+	for _, valid := range values {
+		if v == valid {
+			return v
+		}
+	}
+	l.fail(key, v, "must be "+orList(values))
+	return fallback
+}
+
+func (l *loader) int(key string, fallback, min int, hint string) int {
+	v, source, ok := l.lookup(key)
+	if !ok {
+		l.record(key, strconv.Itoa(fallback), source, false)
+		return fallback
+	}
+	l.record(key, v, source, false)
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min {
+		l.fail(key, v, hint)
+		return fallback
+	}
+	return n
+}
+
+func (l *loader) float(key string, fallback, min, max float64, hint string) float64 {
+	v, source, ok := l.lookup(key)
+	if !ok {
+		l.record(key, strconv.FormatFloat(fallback, 'g', -1, 64), source, false)
+		return fallback
+	}
+	l.record(key, v, source, false)
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < min || f > max {
+		l.fail(key, v, hint)
+		return fallback
+	}
+	return f
+}
+
+// duration reads a duration like 15m, zero is only valid when allowZero is set.
+func (l *loader) duration(key string, fallback time.Duration, allowZero bool, hint string) time.Duration {
+	v, source, ok := l.lookup(key)
+	if !ok {
+		l.record(key, formatDuration(fallback), source, false)
+		return fallback
+	}
+	l.record(key, v, source, false)
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 || (d == 0 && !allowZero) {
+		l.fail(key, v, hint)
+		return fallback
+	}
+	return d
+}
+
+func (l *loader) bool(key string, fallback bool) bool {
+	v, source, ok := l.lookup(key)
+	if !ok {
+		l.record(key, strconv.FormatBool(fallback), source, false)
+		return fallback
+	}
+	l.record(key, v, source, false)
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.fail(key, v, "must be true or false")
+		return fallback
+	}
+	return b
+}
+
+// list reads values separated by commas or spaces.
+func (l *loader) list(key string, fallback []string) []string {
+	v, source, ok := l.lookup(key)
+	if !ok {
+		l.record(key, strings.Join(fallback, ","), source, false)
+		return fallback
+	}
+	l.record(key, v, source, false)
+
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// size reads a number of bytes with an optional unit like 100MB, 0 is valid.
+func (l *loader) size(key string, fallback int64, hint string) int64 {
+	v, source, ok := l.lookup(key)
+	if !ok {
+		l.record(key, formatSize(fallback), source, false)
+		return fallback
+	}
+	l.record(key, v, source, false)
+
+	n, err := parseSize(v)
+	if err != nil {
+		l.fail(key, v, hint)
+		return fallback
+	}
+	return n
+}
+
+// err returns the collected errors together with the settings of the file that
+// nothing read, which are most likely misspelled.
+func (l *loader) err() error {
+	var unknown []string
+	for key := range l.file {
+		if !l.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		l.errorf("unknown setting %s in %s", strings.ToLower(key), l.path)
+	}
+
+	if len(l.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: l.errs}
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses a number of bytes with an optional unit of B, KB, MB or GB.
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("negative size")
+	}
+	return n * multiplier, nil
+}
+
+// formatSize formats a number of bytes with the largest unit that divides it.
+func formatSize(n int64) string {
+	for _, unit := range sizeUnits {
+		if n != 0 && n%unit.bytes == 0 {
+			return strconv.FormatInt(n/unit.bytes, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// formatDuration formats a duration without zero units, like 1h instead of 1h0m0s.
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// orList formats values like "a, b or c".
+func orList(values []string) string {
+	if len(values) < 2 {
+		return strings.Join(values, "")
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
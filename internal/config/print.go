@@ -0,0 +1,100 @@
+package config
+
+import (
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces the values of secrets in printed configurations.
+const redacted = "[redacted]"
+
+// Setting is a resolved setting, named after its environment variable.
+type Setting struct {
+	Key   string
+	Value string
+	// Source is where the value comes from, one of SourceDefault, SourceFile or SourceEnv.
+	Source string
+	// Secret settings are redacted when printed.
+	Secret bool
+}
+
+// sections group the settings of a printed configuration, so that DB_HOST is printed
+// as host below db. The providers of OIDC_PROVIDERS add their own sections.
+var sections = []string{
+	"COOKIE", "CORS", "DB", "FEATURE", "HTTP", "LOGIN", "METRICS", "OIDC", "PASSWORD",
+	"RATE_LIMIT", "SESSION", "SHUTDOWN", "STORAGE", "STORAGE_CHECK", "TRACING", "UPLOAD",
+}
+
+// Settings returns the resolved settings in the order they were read.
+func (conf *Config) Settings() []Setting {
+	return conf.settings
+}
+
+// WriteYAML writes the resolved settings as a config file, with the source of values
+// that are not defaults as comments. Secrets are replaced when redact is set.
+func (conf *Config) WriteYAML(w io.Writer, redact bool) error {
+	prefixes := append([]string{}, sections...)
+	for name := range conf.OIDCProviders {
+		prefixes = append(prefixes, "OIDC_"+strings.ToUpper(strings.ReplaceAll(name, "-", "_")))
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, s := range conf.settings {
+		value := s.Value
+		if redact && s.Secret && value != "" {
+			value = redacted
+		}
+
+		node := root
+		path := settingPath(s.Key, prefixes)
+		for _, section := range path[:len(path)-1] {
+			node = child(node, section)
+		}
+
+		v := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+		if value == "" || value != strings.TrimSpace(value) {
+			v.Style = yaml.DoubleQuotedStyle
+		}
+		if s.Source != SourceDefault {
+			v.LineComment = "from " + s.Source
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[len(path)-1]}, v)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// settingPath splits the key at the longest section it starts with, so that
+// STORAGE_CHECK_INTERVAL becomes storage, check and interval.
+func settingPath(key string, prefixes []string) []string {
+	longest := ""
+	for _, prefix := range prefixes {
+		if len(prefix) > len(longest) && strings.HasPrefix(key, prefix+"_") {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return []string{strings.ToLower(key)}
+	}
+	return append(settingPath(longest, prefixes), strings.ToLower(strings.TrimPrefix(key, longest+"_")))
+}
+
+// child returns the mapping below key, adding it if there is none.
+func child(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, mapping)
+	return mapping
+}
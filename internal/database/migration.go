@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -55,25 +56,55 @@ func CreateDatabaseAndUser(conf *config.Config) error {
 	return nil
 }
 
-// RunMigrations runs the database migrations
+// RunMigrations applies the pending migrations, see Migrations.
 func RunMigrations(db *gorm.DB) error {
-	// Ensure uuid-ossp is installed to this database
-	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := NewMigrator(db, migrations).Up(context.Background())
+	if err != nil {
 		return err
 	}
 
-	return db.AutoMigrate(
-		&model.User{},
-		&model.Stash{},
-		&model.ArtProject{},
-		&model.Category{},
-		&model.Tag{},
-		&model.Revision{},
-		&model.Collection{},
-		&model.CollectionArtProject{},
-		&model.Sale{},
-		&model.StorageUsage{},
-		&model.WebPage{},
-		&model.ArtLink{},
-	)
+	slog.Info("Database is up to date", "applied", applied)
+	return nil
+}
+
+// goMigrations are the migrations that need code, their versions share the
+// sequence of the SQL migrations.
+var goMigrations = []Migration{
+	{Version: 9, Name: "seed_role_permissions", Up: seedRolePermissions, Down: deleteRolePermissions},
+}
+
+// seedRolePermissions inserts the default role permissions into an empty table.
+// Once seeded the table is left alone, so operators can adjust the mapping.
+func seedRolePermissions(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&model.RolePermission{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count role permissions: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var rows []model.RolePermission
+	for role, permissions := range model.DefaultRolePermissions {
+		for _, permission := range permissions {
+			rows = append(rows, model.RolePermission{Role: role, Permission: permission})
+		}
+	}
+
+	slog.Info("Seeding default role permissions", "count", len(rows))
+	if err := db.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to seed role permissions: %w", err)
+	}
+
+	return nil
+}
+
+// deleteRolePermissions empties the role permissions table, it was empty before the seed.
+func deleteRolePermissions(db *gorm.DB) error {
+	return db.Exec("DELETE FROM role_permissions").Error
 }
@@ -0,0 +1,15 @@
+-- Drops the initial schema, every table and all of its data.
+
+DROP TABLE IF EXISTS "art_links";
+DROP TABLE IF EXISTS "web_pages";
+DROP TABLE IF EXISTS "storage_usages";
+DROP TABLE IF EXISTS "sales";
+DROP TABLE IF EXISTS "collection_art_projects";
+DROP TABLE IF EXISTS "collections";
+DROP TABLE IF EXISTS "revisions";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "art_project_tags";
+DROP TABLE IF EXISTS "tags";
+DROP TABLE IF EXISTS "art_projects";
+DROP TABLE IF EXISTS "stashes";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,147 @@
+-- Schema of the models at the switch from gorm AutoMigrate to versioned migrations,
+-- before the later migrations. Every statement is guarded so that databases created
+-- by AutoMigrate adopt this version without changes, the later migrations then add
+-- what is missing.
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "username" varchar(255) NOT NULL,
+    "password" varchar(255) NOT NULL,
+    "role" varchar(50) NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_users_username" UNIQUE ("username")
+);
+
+CREATE TABLE IF NOT EXISTS "stashes" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "art_projects" bigint DEFAULT 0,
+    "files" bigint DEFAULT 0,
+    "used_space" bigint DEFAULT 0,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_stashes_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "art_projects" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "title" varchar(255) NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    "content_type" varchar(255),
+    "filename" varchar(255),
+    "public" boolean DEFAULT false,
+    "latest_revision_id" uuid,
+    "published_revision_id" uuid,
+    "stash_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_art_projects_stash" FOREIGN KEY ("stash_id") REFERENCES "stashes" ("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_art_projects_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "tags" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "name" varchar(255) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_tags_name" UNIQUE ("name")
+);
+
+CREATE TABLE IF NOT EXISTS "art_project_tags" (
+    "tag_id" uuid DEFAULT uuid_generate_v4(),
+    "art_project_id" uuid DEFAULT uuid_generate_v4(),
+    PRIMARY KEY ("tag_id", "art_project_id"),
+    CONSTRAINT "fk_art_project_tags_art_project" FOREIGN KEY ("art_project_id") REFERENCES "art_projects" ("id"),
+    CONSTRAINT "fk_art_project_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags" ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_categories_name" UNIQUE ("name")
+);
+
+CREATE TABLE IF NOT EXISTS "revisions" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "art_id" varchar(255) NOT NULL,
+    "version" integer,
+    "file_path" varchar(255) NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "comment" text,
+    "size" bigint NOT NULL DEFAULT 0,
+    "art_project_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_revisions_art_project" FOREIGN KEY ("art_project_id") REFERENCES "art_projects" ("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_revisions_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_revisions_art_id" ON "revisions" ("art_id");
+
+CREATE TABLE IF NOT EXISTS "collections" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "collection_id" varchar(255) NOT NULL,
+    "user_id" uuid NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_collections_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_collections_collection_id" ON "collections" ("collection_id");
+
+CREATE TABLE IF NOT EXISTS "collection_art_projects" (
+    "collection_id" uuid NOT NULL,
+    "revision_id" uuid NOT NULL,
+    PRIMARY KEY ("collection_id", "revision_id"),
+    CONSTRAINT "fk_collection_art_projects_collection" FOREIGN KEY ("collection_id") REFERENCES "collections" ("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_collection_art_projects_revision" FOREIGN KEY ("revision_id") REFERENCES "revisions" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "sales" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "art_project_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "price" numeric(10,2) NOT NULL,
+    "sold_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_sales_art_project" FOREIGN KEY ("art_project_id") REFERENCES "art_projects" ("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_sales_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "storage_usages" (
+    "user_id" uuid,
+    "used_space" bigint DEFAULT 0,
+    "quota" bigint DEFAULT 104857600,
+    PRIMARY KEY ("user_id"),
+    CONSTRAINT "fk_storage_usages_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "web_pages" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "html" text NOT NULL,
+    "page_type" varchar(255) DEFAULT 'main',
+    "public" boolean DEFAULT false,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_web_pages_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "art_links" (
+    "token" text,
+    "revision_id" uuid NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "one_time" boolean NOT NULL DEFAULT false,
+    "used" boolean NOT NULL DEFAULT false,
+    PRIMARY KEY ("token"),
+    CONSTRAINT "fk_art_links_revision" FOREIGN KEY ("revision_id") REFERENCES "revisions" ("id") ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- Drops the markdown sources of web pages, the rendered html is kept.
+
+ALTER TABLE "web_pages" DROP COLUMN IF EXISTS "source";
+ALTER TABLE "web_pages" DROP COLUMN IF EXISTS "format";
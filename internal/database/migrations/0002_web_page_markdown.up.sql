@@ -0,0 +1,4 @@
+-- Adds the markdown authoring mode of web pages, html stays the rendered page.
+
+ALTER TABLE "web_pages" ADD COLUMN IF NOT EXISTS "format" varchar(20) DEFAULT 'html';
+ALTER TABLE "web_pages" ADD COLUMN IF NOT EXISTS "source" text;
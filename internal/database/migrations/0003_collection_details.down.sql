@@ -0,0 +1,9 @@
+-- Drops the collection details and the order and captions of collection items.
+
+ALTER TABLE "collection_art_projects" DROP COLUMN IF EXISTS "created_at";
+ALTER TABLE "collection_art_projects" DROP COLUMN IF EXISTS "caption";
+ALTER TABLE "collection_art_projects" DROP COLUMN IF EXISTS "position";
+
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "public";
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "cover_revision_id";
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "description";
@@ -0,0 +1,22 @@
+-- Adds descriptions, covers and public collections, and ordered, captioned items.
+
+ALTER TABLE "collections" ADD COLUMN IF NOT EXISTS "description" text;
+ALTER TABLE "collections" ADD COLUMN IF NOT EXISTS "cover_revision_id" uuid;
+
+-- Collections used to be reachable by anyone with their link. Existing collections stay
+-- public, only collections created from now on default to private. The column is only
+-- backfilled when it is added here, so visibility chosen before is kept.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'collections' AND column_name = 'public'
+    ) THEN
+        ALTER TABLE "collections" ADD COLUMN "public" boolean DEFAULT true;
+        ALTER TABLE "collections" ALTER COLUMN "public" SET DEFAULT false;
+    END IF;
+END $$;
+
+ALTER TABLE "collection_art_projects" ADD COLUMN IF NOT EXISTS "position" integer NOT NULL DEFAULT 0;
+ALTER TABLE "collection_art_projects" ADD COLUMN IF NOT EXISTS "caption" text;
+ALTER TABLE "collection_art_projects" ADD COLUMN IF NOT EXISTS "created_at" timestamp DEFAULT now();
@@ -0,0 +1,4 @@
+-- Drops smart collections, their rules are lost and they become empty manual collections.
+
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "rule";
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "type";
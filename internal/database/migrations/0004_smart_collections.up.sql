@@ -0,0 +1,4 @@
+-- Adds smart collections, resolved from the saved rule instead of their items.
+
+ALTER TABLE "collections" ADD COLUMN IF NOT EXISTS "type" varchar(20) NOT NULL DEFAULT 'manual';
+ALTER TABLE "collections" ADD COLUMN IF NOT EXISTS "rule" jsonb;
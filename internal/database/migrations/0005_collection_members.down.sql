@@ -0,0 +1,5 @@
+-- Drops the members of collaborative collections and the contributors of items.
+
+DROP TABLE IF EXISTS "collection_members";
+
+ALTER TABLE "collection_art_projects" DROP COLUMN IF EXISTS "contributor_id";
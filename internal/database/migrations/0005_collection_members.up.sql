@@ -0,0 +1,15 @@
+-- Adds the members of collaborative collections and the contributor of every item.
+
+ALTER TABLE "collection_art_projects" ADD COLUMN IF NOT EXISTS "contributor_id" uuid;
+
+CREATE TABLE IF NOT EXISTS "collection_members" (
+    "collection_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "role" varchar(20) NOT NULL,
+    "invited_by" uuid NOT NULL,
+    "accepted_at" timestamp,
+    "created_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("collection_id", "user_id"),
+    CONSTRAINT "fk_collection_members_collection" FOREIGN KEY ("collection_id") REFERENCES "collections" ("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_collection_members_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+-- Drops the audit log, disabled users can sign in again.
+
+DROP TABLE IF EXISTS "audit_logs";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "disabled";
@@ -0,0 +1,17 @@
+-- Adds the audit log of administrative actions and disabled users.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "disabled" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "actor_id" uuid NOT NULL,
+    "action" varchar(100) NOT NULL,
+    "target_id" varchar(255),
+    "details" jsonb,
+    "created_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_target_id" ON "audit_logs" ("target_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_action" ON "audit_logs" ("action");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
@@ -0,0 +1,3 @@
+-- Drops the invites, including unused ones.
+
+DROP TABLE IF EXISTS "invites";
@@ -0,0 +1,13 @@
+-- Adds the single-use invites of the invite registration mode.
+
+CREATE TABLE IF NOT EXISTS "invites" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "code_hash" varchar(64) NOT NULL,
+    "created_by" uuid NOT NULL,
+    "used_by" uuid,
+    "used_at" timestamp,
+    "expires_at" timestamp,
+    "created_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invites_code_hash" ON "invites" ("code_hash");
@@ -0,0 +1,3 @@
+-- Drops the role permissions.
+
+DROP TABLE IF EXISTS "role_permissions";
@@ -0,0 +1,7 @@
+-- Adds the permissions of roles, seeded by the next migration.
+
+CREATE TABLE IF NOT EXISTS "role_permissions" (
+    "role" varchar(50),
+    "permission" varchar(100),
+    PRIMARY KEY ("role", "permission")
+);
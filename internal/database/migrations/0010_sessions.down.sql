@@ -0,0 +1,3 @@
+-- Drops the sessions, every user is signed out.
+
+DROP TABLE IF EXISTS "sessions";
@@ -0,0 +1,18 @@
+-- Adds the server-side sessions of the Postgres session store.
+
+CREATE TABLE IF NOT EXISTS "sessions" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "token_hash" varchar(64) NOT NULL,
+    "user_id" uuid,
+    "data" text NOT NULL,
+    "user_agent" varchar(512),
+    "ip" varchar(64),
+    "created_at" timestamp DEFAULT now(),
+    "last_seen_at" timestamp DEFAULT now(),
+    "expires_at" timestamp NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_sessions_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_sessions_expires_at" ON "sessions" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_sessions_user_id" ON "sessions" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sessions_token_hash" ON "sessions" ("token_hash");
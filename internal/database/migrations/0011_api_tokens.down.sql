@@ -0,0 +1,3 @@
+-- Drops the API tokens, they stop working.
+
+DROP TABLE IF EXISTS "api_tokens";
@@ -0,0 +1,17 @@
+-- Adds the personal API tokens accepted as bearer auth.
+
+CREATE TABLE IF NOT EXISTS "api_tokens" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "scopes" jsonb,
+    "expires_at" timestamp,
+    "last_used_at" timestamp,
+    "created_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_api_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_api_tokens_user_id" ON "api_tokens" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_tokens_token_hash" ON "api_tokens" ("token_hash");
@@ -0,0 +1,3 @@
+-- Drops the failed login counters, locked usernames are unlocked.
+
+DROP TABLE IF EXISTS "login_attempts";
@@ -0,0 +1,10 @@
+-- Adds the failed login counters of the login throttle.
+
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+    "key" varchar(300),
+    "failures" bigint NOT NULL DEFAULT 0,
+    "last_failure_at" timestamp NOT NULL,
+    "locked_until" timestamp,
+    PRIMARY KEY ("key")
+);
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_last_failure_at" ON "login_attempts" ("last_failure_at");
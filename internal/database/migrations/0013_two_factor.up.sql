@@ -0,0 +1,39 @@
+-- Adds TOTP two-factor authentication with recovery codes, the login challenges of
+-- the second step and the roles that require it.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_secret" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_enabled" boolean NOT NULL DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_last_step" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" timestamp,
+    "created_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_recovery_codes_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_code_hash" ON "recovery_codes" ("code_hash");
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "login_challenges" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "token_hash" varchar(64) NOT NULL,
+    "user_id" uuid NOT NULL,
+    "keep_signed_in" boolean NOT NULL DEFAULT false,
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "expires_at" timestamp NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_login_challenges_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_login_challenges_token_hash" ON "login_challenges" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_login_challenges_expires_at" ON "login_challenges" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_login_challenges_user_id" ON "login_challenges" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "role_policies" (
+    "role" varchar(50),
+    "require_two_factor" boolean NOT NULL DEFAULT false,
+    PRIMARY KEY ("role")
+);
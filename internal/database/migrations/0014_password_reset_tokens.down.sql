@@ -0,0 +1,3 @@
+-- Drops the pending password resets.
+
+DROP TABLE IF EXISTS "password_reset_tokens";
@@ -0,0 +1,3 @@
+-- Drops the linked OpenID Connect identities.
+
+DROP TABLE IF EXISTS "external_identities";
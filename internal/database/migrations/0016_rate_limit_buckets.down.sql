@@ -0,0 +1,3 @@
+-- Drops the token buckets of the Postgres rate limit store.
+
+DROP TABLE IF EXISTS "rate_limit_buckets";
@@ -0,0 +1,9 @@
+-- Adds the token buckets of the Postgres rate limit store, see RATE_LIMIT_STORE.
+
+CREATE TABLE IF NOT EXISTS "rate_limit_buckets" (
+    "key" varchar(300),
+    "tokens" double precision NOT NULL,
+    "updated_at" timestamp NOT NULL,
+    PRIMARY KEY ("key")
+);
+CREATE INDEX IF NOT EXISTS "idx_rate_limit_buckets_updated_at" ON "rate_limit_buckets" ("updated_at");
@@ -0,0 +1,68 @@
+package database_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/database"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(sql string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(sql)}
+	}
+
+	t.Run("Sorted by version", func(t *testing.T) {
+		migrations, err := database.LoadMigrations(fstest.MapFS{
+			"0010_add_index.up.sql":       file("CREATE INDEX ..."),
+			"0010_add_index.down.sql":     file("DROP INDEX ..."),
+			"0003_rename_column.up.sql":   file("ALTER TABLE ..."),
+			"0003_rename_column.down.sql": file("ALTER TABLE ..."),
+		})
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+
+		assert.Equal(t, int64(3), migrations[0].Version)
+		assert.Equal(t, "rename_column", migrations[0].Name)
+		assert.Equal(t, int64(10), migrations[1].Version)
+		assert.NotNil(t, migrations[1].Up)
+		assert.NotNil(t, migrations[1].Down)
+	})
+
+	t.Run("Missing down file", func(t *testing.T) {
+		_, err := database.LoadMigrations(fstest.MapFS{
+			"0001_initial.up.sql": file("CREATE TABLE ..."),
+		})
+		assert.ErrorContains(t, err, "needs both an up and a down file")
+	})
+
+	t.Run("Invalid file name", func(t *testing.T) {
+		_, err := database.LoadMigrations(fstest.MapFS{
+			"initial.sql": file("CREATE TABLE ..."),
+		})
+		assert.ErrorContains(t, err, "invalid migration file name")
+	})
+
+	t.Run("Conflicting names", func(t *testing.T) {
+		_, err := database.LoadMigrations(fstest.MapFS{
+			"0001_initial.up.sql": file("CREATE TABLE ..."),
+			"0001_other.down.sql": file("DROP TABLE ..."),
+		})
+		assert.ErrorContains(t, err, "has two names")
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	migrations, err := database.Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version, "versions have no gaps")
+		assert.NotNil(t, m.Up, m.Name)
+		assert.NotNil(t, m.Down, m.Name)
+	}
+}
@@ -0,0 +1,441 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// AdminHandler handles HTTP requests of the administrator API.
+type AdminHandler struct {
+	adminService service.AdminService
+}
+
+// NewAdminHandler creates a new instance of AdminHandler.
+func NewAdminHandler(adminService service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// ListUsers lists users with optional search by username and filter by role.
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ListUsers")
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	query := model.UserListQuery{
+		Search:  r.URL.Query().Get("q"),
+		Role:    r.URL.Query().Get("role"),
+		Page:    queryInt(r, "page"),
+		PerPage: queryInt(r, "per_page"),
+	}
+
+	users, pagination, err := h.adminService.ListUsers(ctx, admin.ID.String(), query)
+	if err != nil {
+		logger.Error("Failed to list users", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+
+	response := make([]model.UserResponse, 0, len(users))
+	for i := range users {
+		response = append(response, convertToUserResponse(&users[i]))
+	}
+
+	logger.Info("Users listed", "count", len(response))
+	SendPaginatedResponse(w, http.StatusOK, response, pagination)
+}
+
+// GetUser retrieves a single user.
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "AdminGetUser", "userID", userID)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	user, err := h.adminService.GetUser(ctx, admin.ID.String(), userID)
+	if err != nil {
+		logger.Error("Failed to get user", "error", err)
+		sendAdminError(w, err, "Failed to get user")
+		return
+	}
+
+	logger.Info("User retrieved")
+	SendJSONResponse(w, http.StatusOK, convertToUserResponse(user))
+}
+
+// UpdateRole changes the role of a user.
+func (h *AdminHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "UpdateRole", "userID", userID)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.adminService.SetRole(ctx, admin.ID.String(), userID, req.Role)
+	if err != nil {
+		logger.Error("Failed to change user role", "error", err)
+		sendAdminError(w, err, "Failed to change user role")
+		return
+	}
+
+	logger.Info("User role changed", "role", user.Role)
+	SendJSONResponse(w, http.StatusOK, convertToUserResponse(user))
+}
+
+// ResetPassword sets a temporary password for a user and returns it once.
+func (h *AdminHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "ResetPassword", "userID", userID)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	password, err := h.adminService.ResetPassword(ctx, admin.ID.String(), userID)
+	if err != nil {
+		logger.Error("Failed to reset password", "error", err)
+		sendAdminError(w, err, "Failed to reset password")
+		return
+	}
+
+	logger.Info("User password reset")
+	SendJSONResponse(w, http.StatusOK, model.PasswordResetResponse{
+		UserID:            uuid.MustParse(userID),
+		TemporaryPassword: password,
+	})
+}
+
+// DisableUser disables a user account.
+func (h *AdminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, true)
+}
+
+// EnableUser enables a previously disabled user account.
+func (h *AdminHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, false)
+}
+
+func (h *AdminHandler) setDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	ctx := r.Context()
+	userID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "SetDisabled", "userID", userID, "disabled", disabled)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	user, err := h.adminService.SetDisabled(ctx, admin.ID.String(), userID, disabled)
+	if err != nil {
+		logger.Error("Failed to update user account", "error", err)
+		sendAdminError(w, err, "Failed to update user account")
+		return
+	}
+
+	logger.Info("User account updated")
+	SendJSONResponse(w, http.StatusOK, convertToUserResponse(user))
+}
+
+// UnlockUser lifts the login lockout of a user after too many failed attempts.
+func (h *AdminHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "UnlockUser", "userID", userID)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.adminService.UnlockUser(ctx, admin.ID.String(), userID); err != nil {
+		logger.Error("Failed to unlock user", "error", err)
+		sendAdminError(w, err, "Failed to unlock user")
+		return
+	}
+
+	logger.Info("User unlocked")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteUser deletes a user account.
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "AdminDeleteUser", "userID", userID)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.adminService.DeleteUser(ctx, admin.ID.String(), userID); err != nil {
+		logger.Error("Failed to delete user", "error", err)
+		sendAdminError(w, err, "Failed to delete user")
+		return
+	}
+
+	logger.Info("User deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAuditLog lists the audit log, newest entries first.
+func (h *AdminHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ListAuditLog")
+
+	entries, pagination, err := h.adminService.ListAuditLog(ctx, queryInt(r, "page"), queryInt(r, "per_page"))
+	if err != nil {
+		logger.Error("Failed to list audit log", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to list audit log")
+		return
+	}
+
+	logger.Info("Audit log listed", "count", len(entries))
+	SendPaginatedResponse(w, http.StatusOK, entries, pagination)
+}
+
+// CreateInvite creates a single-use registration invite. The code is only shown in this response.
+func (h *AdminHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "CreateInvite")
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.InviteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Error("Failed to decode request body", "error", err)
+			SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	invite, code, err := h.adminService.CreateInvite(ctx, admin.ID.String(), time.Duration(req.ValidForHours)*time.Hour)
+	if err != nil {
+		logger.Error("Failed to create invite", "error", err)
+		sendAdminError(w, err, "Failed to create invite")
+		return
+	}
+
+	response := convertToInviteResponse(invite)
+	response.Code = code
+
+	logger.Info("Invite created", "inviteID", invite.ID)
+	SendJSONResponse(w, http.StatusCreated, response)
+}
+
+// ListInvites lists all registration invites.
+func (h *AdminHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ListInvites")
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	invites, err := h.adminService.ListInvites(ctx, admin.ID.String())
+	if err != nil {
+		logger.Error("Failed to list invites", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to list invites")
+		return
+	}
+
+	response := make([]model.InviteResponse, 0, len(invites))
+	for i := range invites {
+		response = append(response, convertToInviteResponse(&invites[i]))
+	}
+
+	logger.Info("Invites listed", "count", len(response))
+	SendJSONResponse(w, http.StatusOK, response)
+}
+
+// RevokeInvite deletes an unused registration invite.
+func (h *AdminHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	inviteID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "RevokeInvite", "inviteID", inviteID)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.adminService.RevokeInvite(ctx, admin.ID.String(), inviteID); err != nil {
+		logger.Error("Failed to revoke invite", "error", err)
+		if errors.Is(err, model.ErrInviteNotFound) {
+			SendErrorResponse(w, http.StatusNotFound, "Invite not found")
+			return
+		}
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to revoke invite")
+		return
+	}
+
+	logger.Info("Invite revoked")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRolePolicy returns the security policy of a role.
+func (h *AdminHandler) GetRolePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	role := chi.URLParam(r, "role")
+	logger := logctx.With(r.Context(), "handler", "GetRolePolicy", "role", role)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	policy, err := h.adminService.GetRolePolicy(ctx, admin.ID.String(), role)
+	if err != nil {
+		logger.Error("Failed to get role policy", "error", err)
+		sendAdminError(w, err, "Failed to get role policy")
+		return
+	}
+
+	SendJSONResponse(w, http.StatusOK, convertToRolePolicyResponse(policy))
+}
+
+// UpdateRolePolicy changes the security policy of a role, such as requiring two-factor authentication.
+func (h *AdminHandler) UpdateRolePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	role := chi.URLParam(r, "role")
+	logger := logctx.With(r.Context(), "handler", "UpdateRolePolicy", "role", role)
+
+	admin, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.RolePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	policy, err := h.adminService.SetRolePolicy(ctx, admin.ID.String(), role, *req.RequireTwoFactor)
+	if err != nil {
+		logger.Error("Failed to update role policy", "error", err)
+		sendAdminError(w, err, "Failed to update role policy")
+		return
+	}
+
+	logger.Info("Role policy updated", "requireTwoFactor", policy.RequireTwoFactor)
+	SendJSONResponse(w, http.StatusOK, convertToRolePolicyResponse(policy))
+}
+
+func convertToRolePolicyResponse(policy *model.RolePolicy) model.RolePolicyResponse {
+	return model.RolePolicyResponse{
+		Role:             policy.Role,
+		RequireTwoFactor: policy.RequireTwoFactor,
+	}
+}
+
+func convertToInviteResponse(invite *model.Invite) model.InviteResponse {
+	return model.InviteResponse{
+		ID:        invite.ID,
+		CreatedBy: invite.CreatedBy,
+		UsedBy:    invite.UsedBy,
+		UsedAt:    invite.UsedAt,
+		ExpiresAt: invite.ExpiresAt,
+		CreatedAt: invite.CreatedAt,
+	}
+}
+
+func sendAdminError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, model.ErrUserNotFound):
+		SendErrorResponse(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, model.ErrInvalidInput):
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, model.ErrInvalidRole):
+		SendErrorResponse(w, http.StatusBadRequest, "Unknown role")
+	default:
+		SendErrorResponse(w, http.StatusInternalServerError, message)
+	}
+}
+
+// queryInt returns the integer value of a query parameter or zero when it is missing or invalid.
+func queryInt(r *http.Request, name string) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,516 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func setupAdminTestServer(t *testing.T) (*httptest.Server, *mocks.AdminService) {
+	r := chi.NewRouter()
+
+	mockService := mocks.NewAdminService(t)
+	cookieStore := sessions.NewCookieStore([]byte("test-secret"))
+	userMock := mocks.NewUserService(t)
+	m := middleware.NewMiddleware(cookieStore, userMock, mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+
+	adminHandler := handler.NewAdminHandler(mockService)
+
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(m.MockAuthMiddleware)
+		r.Use(m.RequirePermission(model.PermUsersAdmin))
+		r.Get("/users", adminHandler.ListUsers)
+		r.With(middleware.ValidateUUID("id")).Get("/users/{id}", adminHandler.GetUser)
+		r.With(middleware.ValidateUUID("id")).Put("/users/{id}/role", adminHandler.UpdateRole)
+		r.With(middleware.ValidateUUID("id")).Post("/users/{id}/password-reset", adminHandler.ResetPassword)
+		r.With(middleware.ValidateUUID("id")).Post("/users/{id}/disable", adminHandler.DisableUser)
+		r.With(middleware.ValidateUUID("id")).Post("/users/{id}/enable", adminHandler.EnableUser)
+		r.With(middleware.ValidateUUID("id")).Post("/users/{id}/unlock", adminHandler.UnlockUser)
+		r.With(middleware.ValidateUUID("id")).Delete("/users/{id}", adminHandler.DeleteUser)
+		r.Get("/audit-log", adminHandler.ListAuditLog)
+		r.Get("/invites", adminHandler.ListInvites)
+		r.Post("/invites", adminHandler.CreateInvite)
+		r.With(middleware.ValidateUUID("id")).Delete("/invites/{id}", adminHandler.RevokeInvite)
+		r.Get("/roles/{role}/policy", adminHandler.GetRolePolicy)
+		r.Put("/roles/{role}/policy", adminHandler.UpdateRolePolicy)
+	})
+
+	return httptest.NewServer(r), mockService
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		users := []model.User{
+			{ID: uuid.New(), Username: "alice", Role: model.RoleUser},
+			{ID: uuid.New(), Username: "alicia", Role: model.RoleUser, Disabled: true},
+		}
+		query := model.UserListQuery{Search: "ali", Role: "user", Page: 2, PerPage: 2}
+		pagination := model.Pagination{CurrentPage: 2, PerPage: 2, TotalPages: 3, TotalRecords: 6}
+
+		mockService.On("ListUsers", mock.Anything, adminID.String(), query).Return(users, pagination, nil).Once()
+
+		req, _ := http.NewRequest("GET", server.URL+"/api/admin/users?q=ali&role=user&page=2&per_page=2", nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response struct {
+			Data       []model.UserResponse `json:"data"`
+			Pagination model.Pagination     `json:"pagination"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Len(t, response.Data, 2)
+		assert.True(t, response.Data[1].Disabled)
+		assert.Equal(t, pagination, response.Pagination)
+	})
+
+	t.Run("Forbidden for regular users", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", server.URL+"/api/admin/users", nil)
+		req.Header.Set("X-User-ID", uuid.New().String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/api/admin/users")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestAdminHandler_GetUser(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		user := &model.User{ID: userID, Username: "alice", Role: model.RoleUser}
+		mockService.On("GetUser", mock.Anything, adminID.String(), userID.String()).Return(user, nil).Once()
+
+		req, _ := http.NewRequest("GET", server.URL+"/api/admin/users/"+userID.String(), nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response model.UserResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", response.Username)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("GetUser", mock.Anything, adminID.String(), userID.String()).Return(nil, model.ErrUserNotFound).Once()
+
+		req, _ := http.NewRequest("GET", server.URL+"/api/admin/users/"+userID.String(), nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestAdminHandler_UpdateRole(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		user := &model.User{ID: userID, Username: "alice", Role: model.RoleAdmin}
+		mockService.On("SetRole", mock.Anything, adminID.String(), userID.String(), model.RoleAdmin).Return(user, nil).Once()
+
+		req, _ := http.NewRequest("PUT", server.URL+"/api/admin/users/"+userID.String()+"/role", bytes.NewBufferString(`{"role": "admin"}`))
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response model.UserResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleAdmin, response.Role)
+	})
+
+	t.Run("Invalid Role", func(t *testing.T) {
+		mockService.On("SetRole", mock.Anything, adminID.String(), userID.String(), "root").
+			Return(nil, model.ErrInvalidRole).Once()
+
+		req, _ := http.NewRequest("PUT", server.URL+"/api/admin/users/"+userID.String()+"/role", bytes.NewBufferString(`{"role": "root"}`))
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Self Demotion", func(t *testing.T) {
+		mockService.On("SetRole", mock.Anything, adminID.String(), adminID.String(), model.RoleUser).
+			Return(nil, fmt.Errorf("%w: administrators can not demote themselves", model.ErrInvalidInput)).Once()
+
+		req, _ := http.NewRequest("PUT", server.URL+"/api/admin/users/"+adminID.String()+"/role", bytes.NewBufferString(`{"role": "user"}`))
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestAdminHandler_ResetPassword(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	mockService.On("ResetPassword", mock.Anything, adminID.String(), userID.String()).Return("temporary", nil).Once()
+
+	req, _ := http.NewRequest("POST", server.URL+"/api/admin/users/"+userID.String()+"/password-reset", nil)
+	req.Header.Set("X-Admin-ID", adminID.String())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response model.PasswordResetResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, userID, response.UserID)
+	assert.Equal(t, "temporary", response.TemporaryPassword)
+}
+
+func TestAdminHandler_DisableEnableUser(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	for _, tt := range []struct {
+		action   string
+		disabled bool
+	}{
+		{action: "disable", disabled: true},
+		{action: "enable", disabled: false},
+	} {
+		t.Run(tt.action, func(t *testing.T) {
+			user := &model.User{ID: userID, Username: "alice", Role: model.RoleUser, Disabled: tt.disabled}
+			mockService.On("SetDisabled", mock.Anything, adminID.String(), userID.String(), tt.disabled).Return(user, nil).Once()
+
+			req, _ := http.NewRequest("POST", server.URL+"/api/admin/users/"+userID.String()+"/"+tt.action, nil)
+			req.Header.Set("X-Admin-ID", adminID.String())
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var response model.UserResponse
+			err = json.NewDecoder(resp.Body).Decode(&response)
+			require.NoError(t, err)
+			assert.Equal(t, tt.disabled, response.Disabled)
+		})
+	}
+}
+
+func TestAdminHandler_DeleteUser(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("DeleteUser", mock.Anything, adminID.String(), userID.String()).Return(nil).Once()
+
+		req, _ := http.NewRequest("DELETE", server.URL+"/api/admin/users/"+userID.String(), nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("Forbidden for regular users", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", server.URL+"/api/admin/users/"+userID.String(), nil)
+		req.Header.Set("X-User-ID", uuid.New().String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestAdminHandler_UnlockUser(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("UnlockUser", mock.Anything, adminID.String(), userID.String()).Return(nil).Once()
+
+		req, _ := http.NewRequest("POST", server.URL+"/api/admin/users/"+userID.String()+"/unlock", nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		mockService.On("UnlockUser", mock.Anything, adminID.String(), userID.String()).Return(model.ErrUserNotFound).Once()
+
+		req, _ := http.NewRequest("POST", server.URL+"/api/admin/users/"+userID.String()+"/unlock", nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestAdminHandler_ListAuditLog(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+	entries := []model.AuditLog{
+		{ID: uuid.New(), ActorID: adminID, Action: model.AuditActionUserDisable, TargetID: uuid.New().String()},
+	}
+	pagination := model.Pagination{CurrentPage: 1, PerPage: 20, TotalPages: 1, TotalRecords: 1}
+
+	mockService.On("ListAuditLog", mock.Anything, 0, 0).Return(entries, pagination, nil).Once()
+
+	req, _ := http.NewRequest("GET", server.URL+"/api/admin/audit-log", nil)
+	req.Header.Set("X-Admin-ID", adminID.String())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []model.AuditLog `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, model.AuditActionUserDisable, response.Data[0].Action)
+}
+
+func TestAdminHandler_Invites(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+	inviteID := uuid.New()
+
+	t.Run("Create", func(t *testing.T) {
+		expiresAt := time.Now().Add(48 * time.Hour)
+		invite := &model.Invite{ID: inviteID, CreatedBy: adminID, ExpiresAt: &expiresAt}
+		mockService.On("CreateInvite", mock.Anything, adminID.String(), 48*time.Hour).Return(invite, "invite-code", nil).Once()
+
+		req, _ := http.NewRequest("POST", server.URL+"/api/admin/invites", bytes.NewBufferString(`{"valid_for_hours": 48}`))
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var response model.InviteResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, inviteID, response.ID)
+		assert.Equal(t, "invite-code", response.Code)
+	})
+
+	t.Run("Create without body", func(t *testing.T) {
+		invite := &model.Invite{ID: inviteID, CreatedBy: adminID}
+		mockService.On("CreateInvite", mock.Anything, adminID.String(), time.Duration(0)).Return(invite, "invite-code", nil).Once()
+
+		req, _ := http.NewRequest("POST", server.URL+"/api/admin/invites", nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("List does not expose codes", func(t *testing.T) {
+		invites := []model.Invite{{ID: inviteID, CodeHash: "hash", CreatedBy: adminID}}
+		mockService.On("ListInvites", mock.Anything, adminID.String()).Return(invites, nil).Once()
+
+		req, _ := http.NewRequest("GET", server.URL+"/api/admin/invites", nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response []model.InviteResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		require.Len(t, response, 1)
+		assert.Empty(t, response[0].Code)
+	})
+
+	t.Run("Revoke used invite", func(t *testing.T) {
+		mockService.On("RevokeInvite", mock.Anything, adminID.String(), inviteID.String()).Return(model.ErrInviteNotFound).Once()
+
+		req, _ := http.NewRequest("DELETE", server.URL+"/api/admin/invites/"+inviteID.String(), nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Forbidden for regular users", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", server.URL+"/api/admin/invites", nil)
+		req.Header.Set("X-User-ID", uuid.New().String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestAdminHandler_RolePolicy(t *testing.T) {
+	server, mockService := setupAdminTestServer(t)
+	defer server.Close()
+
+	adminID := uuid.New()
+
+	t.Run("Get", func(t *testing.T) {
+		mockService.On("GetRolePolicy", mock.Anything, adminID.String(), model.RoleAdmin).
+			Return(&model.RolePolicy{Role: model.RoleAdmin}, nil).Once()
+
+		req, _ := http.NewRequest("GET", server.URL+"/api/admin/roles/admin/policy", nil)
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Require two-factor", func(t *testing.T) {
+		mockService.On("SetRolePolicy", mock.Anything, adminID.String(), model.RoleAdmin, true).
+			Return(&model.RolePolicy{Role: model.RoleAdmin, RequireTwoFactor: true}, nil).Once()
+
+		req, _ := http.NewRequest("PUT", server.URL+"/api/admin/roles/admin/policy",
+			bytes.NewBufferString(`{"require_two_factor":true}`))
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response model.RolePolicyResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.True(t, response.RequireTwoFactor)
+	})
+
+	t.Run("Missing field", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", server.URL+"/api/admin/roles/admin/policy", bytes.NewBufferString(`{}`))
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		mockService.On("SetRolePolicy", mock.Anything, adminID.String(), "guest", false).
+			Return(nil, model.ErrInvalidRole).Once()
+
+		req, _ := http.NewRequest("PUT", server.URL+"/api/admin/roles/guest/policy",
+			bytes.NewBufferString(`{"require_two_factor":false}`))
+		req.Header.Set("X-Admin-ID", adminID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
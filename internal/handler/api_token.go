@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// APITokenHandler handles HTTP requests to manage the personal API tokens of the current user.
+type APITokenHandler struct {
+	apiTokenService service.APITokenService
+}
+
+// NewAPITokenHandler creates a new instance of APITokenHandler.
+func NewAPITokenHandler(apiTokenService service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService: apiTokenService,
+	}
+}
+
+// CreateToken creates a token; its value is only part of this response.
+func (h *APITokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "CreateToken")
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	var req model.APITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	validFor := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	token, value, err := h.apiTokenService.CreateToken(ctx, user.ID.String(), req.Name, req.Scopes, validFor)
+	if err != nil {
+		logger.Error("Failed to create token", "error", err, "userID", user.ID)
+		switch {
+		case errors.Is(err, model.ErrInvalidInput):
+			SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, err.Error())
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to create token")
+		}
+		return
+	}
+
+	response := convertToAPITokenResponse(token)
+	response.Token = value
+
+	logger.Info("API token created", "userID", user.ID, "tokenID", token.ID)
+	SendJSONResponse(w, http.StatusCreated, response)
+}
+
+// ListTokens lists the tokens of the user without their values.
+func (h *APITokenHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ListTokens")
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.apiTokenService.ListTokens(ctx, user.ID.String())
+	if err != nil {
+		logger.Error("Failed to list tokens", "error", err, "userID", user.ID)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to list tokens")
+		return
+	}
+
+	response := make([]model.APITokenResponse, 0, len(tokens))
+	for i := range tokens {
+		response = append(response, convertToAPITokenResponse(&tokens[i]))
+	}
+
+	logger.Info("API tokens listed", "userID", user.ID, "count", len(response))
+	SendJSONResponse(w, http.StatusOK, response)
+}
+
+// RevokeToken deletes one token of the user.
+func (h *APITokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tokenID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "RevokeToken", "tokenID", tokenID)
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	if err := h.apiTokenService.RevokeToken(ctx, user.ID.String(), tokenID); err != nil {
+		logger.Error("Failed to revoke token", "error", err, "userID", user.ID)
+		if errors.Is(err, model.ErrTokenNotFound) {
+			SendErrorResponse(w, http.StatusNotFound, "Token not found")
+			return
+		}
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to revoke token")
+		return
+	}
+
+	logger.Info("API token revoked", "userID", user.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sessionUser returns the current user, refusing requests authenticated by an API token
+// for account security settings, so that a token can never mint tokens or change 2FA.
+func sessionUser(w http.ResponseWriter, r *http.Request, logger *slog.Logger) (*model.User, bool) {
+	ctx := r.Context()
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	if _, viaToken := middleware.GetAPITokenFromContext(ctx); viaToken {
+		logger.Warn("Security settings accessed with an api token", "userID", user.ID)
+		SendErrorResponse(w, http.StatusForbidden, "Not allowed with an API token")
+		return nil, false
+	}
+
+	return user, true
+}
+
+func convertToAPITokenResponse(token *model.APIToken) model.APITokenResponse {
+	return model.APITokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.Scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
@@ -0,0 +1,193 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func setupAPITokenTestServer(t *testing.T) (*httptest.Server, *mocks.APITokenService) {
+	r := chi.NewRouter()
+
+	mockService := mocks.NewAPITokenService(t)
+	cookieStore := sessions.NewCookieStore([]byte("test-secret"))
+	m := middleware.NewMiddleware(cookieStore, mocks.NewUserService(t), mocks.NewPermissionService(t), mockService)
+
+	apiTokenHandler := handler.NewAPITokenHandler(mockService)
+
+	r.Route("/self", func(r chi.Router) {
+		r.Use(m.MockAuthMiddleware)
+		r.Get("/tokens", apiTokenHandler.ListTokens)
+		r.Post("/tokens", apiTokenHandler.CreateToken)
+		r.With(middleware.ValidateUUID("id")).Delete("/tokens/{id}", apiTokenHandler.RevokeToken)
+	})
+
+	r.Route("/bearer", func(r chi.Router) {
+		r.Use(m.AuthMiddleware)
+		r.Post("/tokens", apiTokenHandler.CreateToken)
+	})
+
+	return httptest.NewServer(r), mockService
+}
+
+func TestAPITokenHandler_CreateToken(t *testing.T) {
+	server, mockService := setupAPITokenTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		token := &model.APIToken{
+			ID:     uuid.New(),
+			UserID: userID,
+			Name:   "ci",
+			Prefix: "mb_abcdefg",
+			Scopes: []string{model.PermArtWrite},
+		}
+		mockService.On("CreateToken", mock.Anything, userID.String(), "ci", []string{model.PermArtWrite}, 30*24*time.Hour).
+			Return(token, "mb_abcdefgsecret", nil).Once()
+
+		body, _ := json.Marshal(model.APITokenRequest{Name: "ci", Scopes: []string{model.PermArtWrite}, ExpiresInDays: 30})
+		req, _ := http.NewRequest("POST", server.URL+"/self/tokens", bytes.NewBuffer(body))
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var response model.APITokenResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, "mb_abcdefgsecret", response.Token)
+		assert.Equal(t, token.ID, response.ID)
+	})
+
+	t.Run("Scope not granted", func(t *testing.T) {
+		mockService.On("CreateToken", mock.Anything, userID.String(), "admin", []string{model.PermUsersAdmin}, time.Duration(0)).
+			Return(nil, "", fmt.Errorf("%w: scope not granted", model.ErrUnauthorized)).Once()
+
+		body, _ := json.Marshal(model.APITokenRequest{Name: "admin", Scopes: []string{model.PermUsersAdmin}})
+		req, _ := http.NewRequest("POST", server.URL+"/self/tokens", bytes.NewBuffer(body))
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Missing scopes", func(t *testing.T) {
+		body, _ := json.Marshal(model.APITokenRequest{Name: "ci"})
+		req, _ := http.NewRequest("POST", server.URL+"/self/tokens", bytes.NewBuffer(body))
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Authenticated by api token", func(t *testing.T) {
+		token := &model.APIToken{
+			ID:     uuid.New(),
+			UserID: userID,
+			Scopes: []string{model.PermArtWrite},
+			User:   model.User{ID: userID, Role: model.RoleUser},
+		}
+		mockService.On("Authenticate", mock.Anything, "mb_secret").
+			Return(token, []string{model.PermArtWrite}, nil).Once()
+
+		body, _ := json.Marshal(model.APITokenRequest{Name: "ci", Scopes: []string{model.PermArtWrite}})
+		req, _ := http.NewRequest("POST", server.URL+"/bearer/tokens", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer mb_secret")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestAPITokenHandler_ListTokens(t *testing.T) {
+	server, mockService := setupAPITokenTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	tokens := []model.APIToken{
+		{ID: uuid.New(), UserID: userID, Name: "ci", TokenHash: "hash", Prefix: "mb_abcdefg"},
+	}
+
+	mockService.On("ListTokens", mock.Anything, userID.String()).Return(tokens, nil).Once()
+
+	req, _ := http.NewRequest("GET", server.URL+"/self/tokens", nil)
+	req.Header.Set("X-User-ID", userID.String())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response []model.APITokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Len(t, response, 1)
+	assert.Equal(t, "mb_abcdefg", response[0].Prefix)
+	assert.Empty(t, response[0].Token)
+}
+
+func TestAPITokenHandler_RevokeToken(t *testing.T) {
+	server, mockService := setupAPITokenTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	tokenID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("RevokeToken", mock.Anything, userID.String(), tokenID.String()).Return(nil).Once()
+
+		req, _ := http.NewRequest("DELETE", server.URL+"/self/tokens/"+tokenID.String(), nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockService.On("RevokeToken", mock.Anything, userID.String(), tokenID.String()).
+			Return(model.ErrTokenNotFound).Once()
+
+		req, _ := http.NewRequest("DELETE", server.URL+"/self/tokens/"+tokenID.String(), nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
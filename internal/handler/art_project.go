@@ -5,13 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/middleware"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/service"
@@ -20,19 +20,21 @@ import (
 // ArtProjectHandler handles HTTP requests related to art projects.
 type ArtProjectHandler struct {
 	artProjectService service.ArtProjectService
+	thumbnailService  service.ThumbnailService
 }
 
 // NewArtProjectHandler creates a new ArtProjectHandler instance.
-func NewArtProjectHandler(aps service.ArtProjectService) *ArtProjectHandler {
+func NewArtProjectHandler(aps service.ArtProjectService, ts service.ThumbnailService) *ArtProjectHandler {
 	return &ArtProjectHandler{
 		artProjectService: aps,
+		thumbnailService:  ts,
 	}
 }
 
 // CreateArtProject handles the creation of a new art project.
 func (h *ArtProjectHandler) CreateArtProject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "CreateArtProject")
+	logger := logctx.With(r.Context(), "handler", "CreateArtProject")
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -51,7 +53,7 @@ func (h *ArtProjectHandler) CreateArtProject(w http.ResponseWriter, r *http.Requ
 	file, handler, err := r.FormFile("file")
 	if err != nil {
 		logger.Error("Invalid file upload", "error", err, "userID", user.ID)
-		SendErrorResponse(w, http.StatusBadRequest, "Invalid file upload")
+		sendUploadError(w, err)
 		return
 	}
 	defer file.Close()
@@ -106,7 +108,7 @@ func (h *ArtProjectHandler) CreateArtProject(w http.ResponseWriter, r *http.Requ
 func (h *ArtProjectHandler) AddRevision(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	artProjectID := chi.URLParam(r, "artID")
-	logger := slog.With("handler", "AddRevision", "artProjectID", artProjectID)
+	logger := logctx.With(r.Context(), "handler", "AddRevision", "artProjectID", artProjectID)
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -119,7 +121,7 @@ func (h *ArtProjectHandler) AddRevision(w http.ResponseWriter, r *http.Request)
 	file, handler, err := r.FormFile("file")
 	if err != nil {
 		logger.Error("Invalid file upload for revision", "error", err, "userID", user.ID, "artProjectID", artProjectID)
-		SendErrorResponse(w, http.StatusBadRequest, "Invalid file upload")
+		sendUploadError(w, err)
 		return
 	}
 	defer file.Close()
@@ -147,7 +149,7 @@ func (h *ArtProjectHandler) AddRevision(w http.ResponseWriter, r *http.Request)
 // ListRevisions handles listing all revisions for an art project.
 func (h *ArtProjectHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "ListRevisions")
+	logger := logctx.With(r.Context(), "handler", "ListRevisions")
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok || user == nil {
@@ -173,7 +175,7 @@ func (h *ArtProjectHandler) ListRevisions(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if revisions[0].UserID != user.ID {
+	if !middleware.CanAccess(ctx, revisions[0].UserID) {
 		logger.Warn("User not authorized to view revisions", "artProjectUserID", revisions[0].UserID)
 		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
 		return
@@ -191,7 +193,7 @@ func (h *ArtProjectHandler) ListRevisions(w http.ResponseWriter, r *http.Request
 // MyArtProjects handles listing all art projects for the authenticated user.
 func (h *ArtProjectHandler) MyArtProjects(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "MyArtProjects")
+	logger := logctx.With(r.Context(), "handler", "MyArtProjects")
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok || user == nil {
@@ -228,7 +230,7 @@ func (h *ArtProjectHandler) MyArtProjects(w http.ResponseWriter, r *http.Request
 // MyArtProjectByID handles retrieving a specific art project for the authenticated user.
 func (h *ArtProjectHandler) MyArtProjectByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "MyArtProjectByID")
+	logger := logctx.With(r.Context(), "handler", "MyArtProjectByID")
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -254,7 +256,7 @@ func (h *ArtProjectHandler) MyArtProjectByID(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if artProject.UserID != user.ID {
+	if !middleware.CanAccess(ctx, artProject.UserID) {
 		logger.Warn("User not authorized to view art project", "artProjectUserID", artProject.UserID)
 		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
 		return
@@ -268,7 +270,7 @@ func (h *ArtProjectHandler) MyArtProjectByID(w http.ResponseWriter, r *http.Requ
 // RevisionDownload handles the download of a specific revision.
 func (h *ArtProjectHandler) RevisionDownload(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "RevisionDownload")
+	logger := logctx.With(r.Context(), "handler", "RevisionDownload")
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -288,8 +290,8 @@ func (h *ArtProjectHandler) RevisionDownload(w http.ResponseWriter, r *http.Requ
 }
 
 // handleDownload is a helper function to handle file downloads.
-func (h *ArtProjectHandler) handleDownload(w http.ResponseWriter, _ *http.Request, fetch func() (io.ReadCloser, *model.ArtProject, error), id string) {
-	logger := slog.With("handler", "handleDownload", "ID", id)
+func (h *ArtProjectHandler) handleDownload(w http.ResponseWriter, r *http.Request, fetch func() (io.ReadCloser, *model.ArtProject, error), id string) {
+	logger := logctx.With(r.Context(), "handler", "handleDownload", "ID", id)
 
 	fh, pic, err := fetch()
 	if err != nil {
@@ -320,7 +322,7 @@ func (h *ArtProjectHandler) handleDownload(w http.ResponseWriter, _ *http.Reques
 func (h *ArtProjectHandler) GetArtByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	artID := chi.URLParam(r, "artID")
-	logger := slog.With("handler", "GetArtByID", "artID", artID)
+	logger := logctx.With(r.Context(), "handler", "GetArtByID", "artID", artID)
 	logger.Info("Retrieving art by ID")
 
 	rev, err := h.artProjectService.GetRevisionByArtID(ctx, artID)
@@ -340,8 +342,55 @@ func (h *ArtProjectHandler) GetArtByID(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, rev.FilePath)
 }
 
+// GetArtThumbnail handles retrieving the thumbnail of art by its ID.
+func (h *ArtProjectHandler) GetArtThumbnail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	artID := chi.URLParam(r, "artID")
+	logger := logctx.With(r.Context(), "handler", "GetArtThumbnail", "artID", artID)
+
+	rev, err := h.artProjectService.GetRevisionByArtID(ctx, artID)
+	if err != nil {
+		if errors.Is(err, model.ErrArtProjectNotFound) {
+			logger.Warn("Art not found", "error", err)
+			SendErrorResponse(w, http.StatusNotFound, "Art not found")
+			return
+		}
+
+		logger.Error("Failed to retrieve art", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	thumbnail, err := h.thumbnailService.Thumbnail(ctx, rev)
+	if err != nil {
+		if errors.Is(err, model.ErrNoThumbnail) {
+			logger.Warn("Art has no thumbnail", "error", err)
+			SendErrorResponse(w, http.StatusNotFound, "Thumbnail not found")
+			return
+		}
+
+		logger.Error("Failed to get thumbnail", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	w.Header().Set("Content-Type", thumbnail.ContentType)
+	http.ServeFile(w, r, thumbnail.FilePath)
+}
+
 // Helper functions
 
+// sendUploadError answers a failed upload with 413 when the body exceeded the upload
+// limit, see middleware.MaxBodySize, and with 400 otherwise.
+func sendUploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		SendErrorResponse(w, http.StatusRequestEntityTooLarge, "File is too large")
+		return
+	}
+	SendErrorResponse(w, http.StatusBadRequest, "Invalid file upload")
+}
+
 func detectContentType(file io.Reader) string {
 	buffer := make([]byte, 512)
 	_, err := file.Read(buffer)
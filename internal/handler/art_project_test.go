@@ -30,9 +30,9 @@ func setupArtProjectTestServer(t *testing.T) (*httptest.Server, *mocks.ArtProjec
 	mockService := mocks.NewArtProjectService(t)
 	cookieStore := sessions.NewCookieStore([]byte("abc"))
 	userMock := mocks.NewUserService(t)
-	m := middleware.NewMiddleware(cookieStore, userMock)
+	m := middleware.NewMiddleware(cookieStore, userMock, mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
 
-	artProjectHandler := handler.NewArtProjectHandler(mockService)
+	artProjectHandler := handler.NewArtProjectHandler(mockService, mocks.NewThumbnailService(t))
 
 	r.Get("/art/{artID}", artProjectHandler.GetArtByID)
 
@@ -539,6 +539,54 @@ func TestArtProjectHandler_GetArtByID(t *testing.T) {
 	})
 }
 
+func TestArtProjectHandler_GetArtThumbnail(t *testing.T) {
+	artService := mocks.NewArtProjectService(t)
+	thumbnailService := mocks.NewThumbnailService(t)
+	artProjectHandler := handler.NewArtProjectHandler(artService, thumbnailService)
+
+	r := chi.NewRouter()
+	r.Get("/art/{artID}/thumbnail", artProjectHandler.GetArtThumbnail)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	artID := uuid.New().String()
+	revision := &model.Revision{ID: uuid.New(), ArtID: artID, FilePath: "testing_data/1.png"}
+
+	t.Run("Success", func(t *testing.T) {
+		artService.On("GetRevisionByArtID", mock.Anything, artID).Return(revision, nil).Once()
+		thumbnailService.On("Thumbnail", mock.Anything, revision).
+			Return(&model.Thumbnail{FilePath: "testing_data/1.png", ContentType: "image/jpeg", Size: 1}, nil).Once()
+
+		resp, err := http.Get(server.URL + "/art/" + artID + "/thumbnail")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("No Thumbnail", func(t *testing.T) {
+		artService.On("GetRevisionByArtID", mock.Anything, artID).Return(revision, nil).Once()
+		thumbnailService.On("Thumbnail", mock.Anything, revision).Return(nil, model.ErrNoThumbnail).Once()
+
+		resp, err := http.Get(server.URL + "/art/" + artID + "/thumbnail")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		artService.On("GetRevisionByArtID", mock.Anything, artID).Return(nil, model.ErrArtProjectNotFound).Once()
+
+		resp, err := http.Get(server.URL + "/art/" + artID + "/thumbnail")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
 func TestArtProjectHandler_MyArtProjects(t *testing.T) {
 	server, mockService := setupArtProjectTestServer(t)
 	defer server.Close()
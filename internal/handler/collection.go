@@ -3,12 +3,11 @@ package handler
 import (
 	"encoding/json"
 	"errors"
-	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/middleware"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/service"
@@ -24,12 +23,9 @@ func NewCollectionHandler(cs service.CollectionService) *CollectionHandler {
 
 func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "CreateCollection")
-
-	var req struct {
-		Title string `json:"title"`
-	}
+	logger := logctx.With(r.Context(), "handler", "CreateCollection")
 
+	var req model.CollectionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logger.Error("Failed to decode request body", "error", err)
 		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
@@ -43,10 +39,10 @@ func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	collection, err := h.collectionService.CreateCollection(ctx, user.ID.String(), req.Title)
+	collection, err := h.collectionService.CreateCollection(ctx, user.ID.String(), req)
 	if err != nil {
 		logger.Error("Failed to create collection", "error", err)
-		if err == model.ErrInvalidInput {
+		if errors.Is(err, model.ErrInvalidInput) {
 			SendErrorResponse(w, http.StatusBadRequest, "Invalid input")
 		} else {
 			SendErrorResponse(w, http.StatusInternalServerError, "Failed to create collection")
@@ -55,18 +51,25 @@ func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Requ
 	}
 
 	logger.Info("Collection created successfully", "collectionID", collection.ID)
-	SendJSONResponse(w, http.StatusCreated, collection)
+	SendJSONResponse(w, http.StatusCreated, convertToCollectionResponse(collection))
 }
 
 func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	collectionID := chi.URLParam(r, "id")
-	logger := slog.With("handler", "GetCollection", "collectionID", collectionID)
+	logger := logctx.With(r.Context(), "handler", "GetCollection", "collectionID", collectionID)
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
 
-	collection, err := h.collectionService.FindByID(ctx, collectionID)
+	collection, err := h.collectionService.GetCollection(ctx, user.ID.String(), collectionID)
 	if err != nil {
 		logger.Error("Failed to get collection", "error", err)
-		if err == model.ErrCollectionNotFound {
+		if errors.Is(err, model.ErrCollectionNotFound) {
 			SendErrorResponse(w, http.StatusNotFound, "Collection not found")
 		} else {
 			SendErrorResponse(w, http.StatusInternalServerError, "Failed to get collection")
@@ -75,12 +78,12 @@ func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request
 	}
 
 	logger.Info("Collection retrieved successfully")
-	SendJSONResponse(w, http.StatusOK, collection)
+	SendJSONResponse(w, http.StatusOK, convertToCollectionResponse(collection))
 }
 
 func (h *CollectionHandler) GetUserCollections(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "GetUserCollections")
+	logger := logctx.With(r.Context(), "handler", "GetUserCollections")
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -100,50 +103,77 @@ func (h *CollectionHandler) GetUserCollections(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	response := make([]model.CollectionResponse, len(collections))
+	for i, collection := range collections {
+		response[i] = convertToCollectionResponse(&collection)
+	}
+
 	logger.Info("User collections retrieved successfully", "userID", user.ID, "count", len(collections))
-	SendJSONResponse(w, http.StatusOK, collections)
+	SendJSONResponse(w, http.StatusOK, response)
 }
 
 func (h *CollectionHandler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "UpdateCollection")
+	logger := logctx.With(r.Context(), "handler", "UpdateCollection")
 
-	var collection model.Collection
-	if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+	var req model.CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logger.Error("Failed to decode request body", "error", err)
 		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	collectionID := chi.URLParam(r, "id")
-	collection.ID = uuid.MustParse(collectionID)
 
-	if err := h.collectionService.UpdateCollection(ctx, &collection); err != nil {
+	collection, err := h.collectionService.UpdateCollection(ctx, user.ID.String(), collectionID, req)
+	if err != nil {
 		logger.Error("Failed to update collection", "error", err, "collectionID", collectionID)
-		if err == model.ErrCollectionNotFound {
+		switch {
+		case errors.Is(err, model.ErrCollectionNotFound):
 			SendErrorResponse(w, http.StatusNotFound, "Collection not found")
-		} else {
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Only the owner can update the collection")
+		case errors.Is(err, model.ErrInvalidInput):
+			SendErrorResponse(w, http.StatusBadRequest, "Invalid cover revision or rule")
+		default:
 			SendErrorResponse(w, http.StatusInternalServerError, "Failed to update collection")
 		}
 		return
 	}
 
 	logger.Info("Collection updated successfully", "collectionID", collectionID)
-	SendJSONResponse(w, http.StatusOK, collection)
+	SendJSONResponse(w, http.StatusOK, convertToCollectionResponse(collection))
 }
 
 func (h *CollectionHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	collectionID := chi.URLParam(r, "id")
 
-	logger := slog.With("handler", "DeleteCollection", "collectionID", collectionID)
+	logger := logctx.With(r.Context(), "handler", "DeleteCollection", "collectionID", collectionID)
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
 
-	err := h.collectionService.DeleteCollection(ctx, collectionID)
+	err := h.collectionService.DeleteCollection(ctx, user.ID.String(), collectionID)
 	if err != nil {
 		logger.Error("Failed to delete collection", "error", err)
-		if err == model.ErrCollectionNotFound {
+		switch {
+		case errors.Is(err, model.ErrCollectionNotFound):
 			SendErrorResponse(w, http.StatusNotFound, "Collection not found")
-		} else {
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Only the owner can delete the collection")
+		default:
 			SendErrorResponse(w, http.StatusInternalServerError, "Failed to delete collection")
 		}
 		return
@@ -156,24 +186,39 @@ func (h *CollectionHandler) DeleteCollection(w http.ResponseWriter, r *http.Requ
 func (h *CollectionHandler) AddRevisionToCollection(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	collectionID := chi.URLParam(r, "id")
-	logger := slog.With("handler", "AddRevisionToCollection", "collectionID", collectionID)
+	logger := logctx.With(r.Context(), "handler", "AddRevisionToCollection", "collectionID", collectionID)
 
-	var req struct {
-		RevisionID string `json:"revisionID"`
-	}
+	var req model.CollectionItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logger.Error("Failed to decode request body", "error", err)
 		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if err := h.collectionService.AddRevisionToCollection(ctx, collectionID, req.RevisionID); err != nil {
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var caption string
+	if req.Caption != nil {
+		caption = *req.Caption
+	}
+
+	if err := h.collectionService.AddRevisionToCollection(ctx, user.ID.String(), collectionID, req.RevisionID, caption); err != nil {
 		logger.Error("Failed to add art project to collection", "error", err, "revisionID", req.RevisionID)
-		if errors.Is(err, model.ErrCollectionNotFound) {
+		switch {
+		case errors.Is(err, model.ErrSmartCollection):
+			SendErrorResponse(w, http.StatusConflict, "Smart collection members are defined by its rule")
+		case errors.Is(err, model.ErrCollectionNotFound):
 			SendErrorResponse(w, http.StatusNotFound, "Collection not found")
-			return
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Only owners and editors can add their own revisions")
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to add art project to collection")
 		}
-		SendErrorResponse(w, http.StatusInternalServerError, "Failed to add art project to collection")
 		return
 	}
 
@@ -184,12 +229,19 @@ func (h *CollectionHandler) AddRevisionToCollection(w http.ResponseWriter, r *ht
 func (h *CollectionHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	collectionID := chi.URLParam(r, "id")
-	logger := slog.With("handler", "ListRevisions", "collectionID", collectionID)
+	logger := logctx.With(r.Context(), "handler", "ListRevisions", "collectionID", collectionID)
 
-	revisions, err := h.collectionService.GetRevisionsByCollectionID(ctx, collectionID)
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	items, err := h.collectionService.GetRevisionsByCollectionID(ctx, user.ID.String(), collectionID)
 	if err != nil {
 		logger.Error("Failed to list revisions", "error", err)
-		if err == model.ErrCollectionNotFound {
+		if errors.Is(err, model.ErrCollectionNotFound) {
 			SendErrorResponse(w, http.StatusNotFound, "Revisions not found in collection")
 		} else {
 			SendErrorResponse(w, http.StatusInternalServerError, "Failed list all revisions from collection")
@@ -197,16 +249,21 @@ func (h *CollectionHandler) ListRevisions(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	response := make([]model.CollectionItemResponse, len(items))
+	for i, item := range items {
+		response[i] = convertToCollectionItemResponse(&item)
+	}
+
 	logger.Info("Listed revisions for the collection")
-	SendJSONResponse(w, http.StatusOK, revisions)
+	SendJSONResponse(w, http.StatusOK, response)
 }
 
 func (h *CollectionHandler) ListPublicRevisions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	collectionID := chi.URLParam(r, "id")
-	logger := slog.With("handler", "ListPublicRevisions", "collectionID", collectionID)
+	logger := logctx.With(r.Context(), "handler", "ListPublicRevisions", "collectionID", collectionID)
 
-	revisions, err := h.collectionService.GetRevisionsByPublicCollectionID(ctx, collectionID)
+	items, err := h.collectionService.GetRevisionsByPublicCollectionID(ctx, collectionID)
 	if err != nil {
 		logger.Error("Failed to list revisions", "error", err)
 		if err == model.ErrCollectionNotFound {
@@ -217,10 +274,11 @@ func (h *CollectionHandler) ListPublicRevisions(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	response := make([]model.PublicRevisionResponse, len(revisions))
-	for i, rev := range revisions {
-		// Note: we can make additional check here if the revision is public or something
-		response[i] = convertToPublicRevisionResponse(&rev)
+	response := make([]model.PublicRevisionResponse, len(items))
+	for i, item := range items {
+		response[i] = convertToPublicRevisionResponse(&item.Revision)
+		response[i].Position = item.Position
+		response[i].Caption = item.Caption
 	}
 
 	logger.Info("Listed revisions for the collection")
@@ -229,17 +287,29 @@ func (h *CollectionHandler) ListPublicRevisions(w http.ResponseWriter, r *http.R
 
 func (h *CollectionHandler) RemoveRevisionFromCollection(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "RemoveArtProjectFromCollection")
+	logger := logctx.With(r.Context(), "handler", "RemoveArtProjectFromCollection")
 
 	collectionID := chi.URLParam(r, "id")
 	revisionID := chi.URLParam(r, "revisionID")
 
-	err := h.collectionService.RemoveRevisionFromCollection(ctx, collectionID, revisionID)
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	err := h.collectionService.RemoveRevisionFromCollection(ctx, user.ID.String(), collectionID, revisionID)
 	if err != nil {
 		logger.Error("Failed to remove art project from collection", "error", err, "collectionID", collectionID, "revisionID", revisionID)
-		if err == model.ErrCollectionNotFound {
+		switch {
+		case errors.Is(err, model.ErrSmartCollection):
+			SendErrorResponse(w, http.StatusConflict, "Smart collection members are defined by its rule")
+		case errors.Is(err, model.ErrCollectionNotFound), errors.Is(err, model.ErrRevisionNotFound):
 			SendErrorResponse(w, http.StatusNotFound, "Revision not found in collection")
-		} else {
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Editors can only remove their own revisions")
+		default:
 			SendErrorResponse(w, http.StatusInternalServerError, "Failed to remove art project from collection")
 		}
 		return
@@ -248,3 +318,115 @@ func (h *CollectionHandler) RemoveRevisionFromCollection(w http.ResponseWriter,
 	logger.Info("Art revision removed from collection successfully", "collectionID", collectionID, "revisionID", revisionID)
 	SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Art project removed from collection successfully"})
 }
+
+func (h *CollectionHandler) UpdateRevisionInCollection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	collectionID := chi.URLParam(r, "id")
+	revisionID := chi.URLParam(r, "revisionID")
+	logger := logctx.With(r.Context(), "handler", "UpdateRevisionInCollection", "collectionID", collectionID, "revisionID", revisionID)
+
+	var req model.CollectionItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Caption == nil {
+		logger.Warn("Nothing to update")
+		SendErrorResponse(w, http.StatusBadRequest, "Caption is required")
+		return
+	}
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.collectionService.UpdateRevisionCaption(ctx, user.ID.String(), collectionID, revisionID, *req.Caption); err != nil {
+		logger.Error("Failed to update revision in collection", "error", err)
+		switch {
+		case errors.Is(err, model.ErrSmartCollection):
+			SendErrorResponse(w, http.StatusConflict, "Smart collection members are defined by its rule")
+		case errors.Is(err, model.ErrCollectionNotFound), errors.Is(err, model.ErrRevisionNotFound):
+			SendErrorResponse(w, http.StatusNotFound, "Revision not found in collection")
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Editors can only update their own revisions")
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to update revision in collection")
+		}
+		return
+	}
+
+	logger.Info("Revision caption updated successfully")
+	SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Revision updated successfully"})
+}
+
+func (h *CollectionHandler) ReorderRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	collectionID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "ReorderRevisions", "collectionID", collectionID)
+
+	var req model.ReorderCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.collectionService.ReorderRevisions(ctx, user.ID.String(), collectionID, req.RevisionIDs); err != nil {
+		logger.Error("Failed to reorder revisions", "error", err)
+		switch {
+		case errors.Is(err, model.ErrSmartCollection):
+			SendErrorResponse(w, http.StatusConflict, "Smart collection members are defined by its rule")
+		case errors.Is(err, model.ErrCollectionNotFound):
+			SendErrorResponse(w, http.StatusNotFound, "Collection not found")
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Only the owner can reorder the collection")
+		case errors.Is(err, model.ErrInvalidInput):
+			SendErrorResponse(w, http.StatusBadRequest, "Revision list must contain every revision of the collection once")
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to reorder revisions")
+		}
+		return
+	}
+
+	logger.Info("Revisions reordered successfully", "count", len(req.RevisionIDs))
+	SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Collection reordered successfully"})
+}
+
+// Helper functions
+
+func convertToCollectionResponse(collection *model.Collection) model.CollectionResponse {
+	return model.CollectionResponse{
+		ID:              collection.ID,
+		CollectionID:    collection.CollectionID,
+		UserID:          collection.UserID,
+		Title:           collection.Title,
+		Description:     collection.Description,
+		CoverRevisionID: collection.CoverRevisionID,
+		Public:          collection.Public,
+		Type:            collection.Type,
+		Rule:            collection.Rule,
+		CreatedAt:       collection.CreatedAt,
+		UpdatedAt:       collection.UpdatedAt,
+	}
+}
+
+func convertToCollectionItemResponse(item *model.CollectionArtProject) model.CollectionItemResponse {
+	return model.CollectionItemResponse{
+		RevisionResponse: convertToRevisionResponse(&item.Revision),
+		Position:         item.Position,
+		Caption:          item.Caption,
+		ContributorID:    item.ContributorID,
+	}
+}
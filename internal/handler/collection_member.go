@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func (h *CollectionHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	collectionID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "InviteMember", "collectionID", collectionID)
+
+	var req model.CollectionMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	member, err := h.collectionService.InviteMember(ctx, user.ID.String(), collectionID, req)
+	if err != nil {
+		logger.Error("Failed to invite member", "error", err, "username", req.Username)
+		switch {
+		case errors.Is(err, model.ErrInvalidInput):
+			SendErrorResponse(w, http.StatusBadRequest, "Username and a role of editor or viewer are required")
+		case errors.Is(err, model.ErrCollectionNotFound):
+			SendErrorResponse(w, http.StatusNotFound, "Collection not found")
+		case errors.Is(err, model.ErrUserNotFound):
+			SendErrorResponse(w, http.StatusNotFound, "User not found")
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Only the owner can invite members")
+		case errors.Is(err, model.ErrAlreadyMember):
+			SendErrorResponse(w, http.StatusConflict, "User is already a member of the collection")
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to invite member")
+		}
+		return
+	}
+
+	logger.Info("Member invited successfully", "memberID", member.UserID)
+	SendJSONResponse(w, http.StatusCreated, convertToCollectionMemberResponse(member))
+}
+
+func (h *CollectionHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	collectionID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "ListMembers", "collectionID", collectionID)
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	members, err := h.collectionService.ListMembers(ctx, user.ID.String(), collectionID)
+	if err != nil {
+		logger.Error("Failed to list members", "error", err)
+		if errors.Is(err, model.ErrCollectionNotFound) {
+			SendErrorResponse(w, http.StatusNotFound, "Collection not found")
+		} else {
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to list members")
+		}
+		return
+	}
+
+	response := make([]model.CollectionMemberResponse, len(members))
+	for i, member := range members {
+		response[i] = convertToCollectionMemberResponse(&member)
+	}
+
+	logger.Info("Listed members of the collection", "count", len(members))
+	SendJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *CollectionHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	collectionID := chi.URLParam(r, "id")
+	memberID := chi.URLParam(r, "userID")
+	logger := logctx.With(r.Context(), "handler", "UpdateMember", "collectionID", collectionID, "memberID", memberID)
+
+	var req model.CollectionMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	member, err := h.collectionService.UpdateMemberRole(ctx, user.ID.String(), collectionID, memberID, req.Role)
+	if err != nil {
+		logger.Error("Failed to update member", "error", err)
+		switch {
+		case errors.Is(err, model.ErrInvalidInput):
+			SendErrorResponse(w, http.StatusBadRequest, "Role must be editor or viewer")
+		case errors.Is(err, model.ErrCollectionNotFound), errors.Is(err, model.ErrMemberNotFound):
+			SendErrorResponse(w, http.StatusNotFound, "Member not found")
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Only the owner can change member roles")
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to update member")
+		}
+		return
+	}
+
+	logger.Info("Member updated successfully", "role", member.Role)
+	SendJSONResponse(w, http.StatusOK, convertToCollectionMemberResponse(member))
+}
+
+func (h *CollectionHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	collectionID := chi.URLParam(r, "id")
+	memberID := chi.URLParam(r, "userID")
+	logger := logctx.With(r.Context(), "handler", "RemoveMember", "collectionID", collectionID, "memberID", memberID)
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.collectionService.RemoveMember(ctx, user.ID.String(), collectionID, memberID); err != nil {
+		logger.Error("Failed to remove member", "error", err)
+		switch {
+		case errors.Is(err, model.ErrCollectionNotFound), errors.Is(err, model.ErrMemberNotFound):
+			SendErrorResponse(w, http.StatusNotFound, "Member not found")
+		case errors.Is(err, model.ErrUnauthorized):
+			SendErrorResponse(w, http.StatusForbidden, "Only the owner can remove other members")
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to remove member")
+		}
+		return
+	}
+
+	logger.Info("Member removed successfully")
+	SendJSONResponse(w, http.StatusOK, map[string]string{"message": "Member removed successfully"})
+}
+
+func (h *CollectionHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ListInvitations")
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	invitations, err := h.collectionService.ListInvitations(ctx, user.ID.String())
+	if err != nil {
+		logger.Error("Failed to list invitations", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to list invitations")
+		return
+	}
+
+	response := make([]model.CollectionInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		response[i] = model.CollectionInvitationResponse{
+			CollectionMemberResponse: convertToCollectionMemberResponse(&invitation),
+			Collection:               convertToCollectionResponse(&invitation.Collection),
+		}
+	}
+
+	logger.Info("Listed invitations", "count", len(invitations))
+	SendJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *CollectionHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	collectionID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "AcceptInvitation", "collectionID", collectionID)
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	member, err := h.collectionService.AcceptInvitation(ctx, user.ID.String(), collectionID)
+	if err != nil {
+		logger.Error("Failed to accept invitation", "error", err)
+		if errors.Is(err, model.ErrMemberNotFound) {
+			SendErrorResponse(w, http.StatusNotFound, "Invitation not found")
+		} else {
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to accept invitation")
+		}
+		return
+	}
+
+	logger.Info("Invitation accepted successfully")
+	SendJSONResponse(w, http.StatusOK, convertToCollectionMemberResponse(member))
+}
+
+func convertToCollectionMemberResponse(member *model.CollectionMember) model.CollectionMemberResponse {
+	return model.CollectionMemberResponse{
+		CollectionID: member.CollectionID,
+		UserID:       member.UserID,
+		Username:     member.User.Username,
+		Role:         member.Role,
+		InvitedBy:    member.InvitedBy,
+		AcceptedAt:   member.AcceptedAt,
+		CreatedAt:    member.CreatedAt,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -26,7 +27,7 @@ func setupCollectionTestServer(t *testing.T) (*httptest.Server, *mocks.Collectio
 	mockService := mocks.NewCollectionService(t)
 	cookieStore := sessions.NewCookieStore([]byte("test-secret"))
 	userMock := mocks.NewUserService(t)
-	m := middleware.NewMiddleware(cookieStore, userMock)
+	m := middleware.NewMiddleware(cookieStore, userMock, mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
 
 	collectionHandler := handler.NewCollectionHandler(mockService)
 
@@ -34,13 +35,24 @@ func setupCollectionTestServer(t *testing.T) (*httptest.Server, *mocks.Collectio
 		r.Use(m.MockAuthMiddleware)
 		r.Post("/", collectionHandler.CreateCollection)
 		r.Get("/", collectionHandler.GetUserCollections)
+		r.Get("/invitations", collectionHandler.ListInvitations)
 		r.With(middleware.ValidateUUID("id")).Get("/{id}", collectionHandler.GetCollection)
 		r.With(middleware.ValidateUUID("id")).Put("/{id}", collectionHandler.UpdateCollection)
 		r.With(middleware.ValidateUUID("id")).Delete("/{id}", collectionHandler.DeleteCollection)
 		r.With(middleware.ValidateUUID("id")).Post("/{id}/revisions", collectionHandler.AddRevisionToCollection)
 		r.With(middleware.ValidateUUID("id")).Get("/{id}/revisions", collectionHandler.ListRevisions)
+		r.With(middleware.ValidateUUID("id")).Put("/{id}/revisions/order", collectionHandler.ReorderRevisions)
+		r.With(middleware.ValidateUUID("id")).With(middleware.ValidateUUID("revisionID")).
+			Put("/{id}/revisions/{revisionID}", collectionHandler.UpdateRevisionInCollection)
 		r.With(middleware.ValidateUUID("id")).With(middleware.ValidateUUID("revisionID")).
 			Delete("/{id}/revisions/{revisionID}", collectionHandler.RemoveRevisionFromCollection)
+		r.With(middleware.ValidateUUID("id")).Post("/{id}/invitation", collectionHandler.AcceptInvitation)
+		r.With(middleware.ValidateUUID("id")).Get("/{id}/members", collectionHandler.ListMembers)
+		r.With(middleware.ValidateUUID("id")).Post("/{id}/members", collectionHandler.InviteMember)
+		r.With(middleware.ValidateUUID("id")).With(middleware.ValidateUUID("userID")).
+			Put("/{id}/members/{userID}", collectionHandler.UpdateMember)
+		r.With(middleware.ValidateUUID("id")).With(middleware.ValidateUUID("userID")).
+			Delete("/{id}/members/{userID}", collectionHandler.RemoveMember)
 	})
 
 	r.Get("/collection/{id}", collectionHandler.ListPublicRevisions)
@@ -60,7 +72,7 @@ func TestCollectionHandler_CreateCollection(t *testing.T) {
 			Title: "Test Collection",
 		}
 
-		mockService.On("CreateCollection", mock.Anything, userID.String(), "Test Collection").Return(collection, nil).Once()
+		mockService.On("CreateCollection", mock.Anything, userID.String(), model.CollectionRequest{Title: "Test Collection"}).Return(collection, nil).Once()
 
 		body := bytes.NewBufferString(`{"title": "Test Collection"}`)
 		req, _ := http.NewRequest("POST", server.URL+"/self/collections", body)
@@ -82,13 +94,44 @@ func TestCollectionHandler_CreateCollection(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("Smart Collection", func(t *testing.T) {
+		rule := &model.CollectionRule{Revision: model.RuleRevisionPublished, Tags: []string{"character-design"}, CreatedAfter: "2026-01-01"}
+		collection := &model.Collection{
+			ID:    uuid.New(),
+			Title: "Characters",
+			Type:  model.CollectionTypeSmart,
+			Rule:  rule,
+		}
+
+		mockService.On("CreateCollection", mock.Anything, userID.String(), model.CollectionRequest{Title: "Characters", Rule: rule}).Return(collection, nil).Once()
+
+		body := bytes.NewBufferString(`{"title": "Characters", "rule": {"revision": "published", "tags": ["character-design"], "created_after": "2026-01-01"}}`)
+		req, _ := http.NewRequest("POST", server.URL+"/self/collections", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var response model.CollectionResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, model.CollectionTypeSmart, response.Type)
+		assert.Equal(t, rule, response.Rule)
+
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("Invalid Input", func(t *testing.T) {
 		body := bytes.NewBufferString(`{"title": ""}`)
 		req, _ := http.NewRequest("POST", server.URL+"/self/collections", body)
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("X-User-ID", userID.String())
 
-		mockService.On("CreateCollection", mock.Anything, userID.String(), "").Return(nil, model.ErrInvalidInput).Once()
+		mockService.On("CreateCollection", mock.Anything, userID.String(), model.CollectionRequest{}).Return(nil, model.ErrInvalidInput).Once()
 
 		resp, err := http.DefaultClient.Do(req)
 		require.NoError(t, err)
@@ -124,7 +167,7 @@ func TestCollectionHandler_GetCollection(t *testing.T) {
 			Title: "Test Collection",
 		}
 
-		mockService.On("FindByID", mock.Anything, collectionID.String()).Return(collection, nil).Once()
+		mockService.On("GetCollection", mock.Anything, userID.String(), collectionID.String()).Return(collection, nil).Once()
 
 		req, _ := http.NewRequest("GET", server.URL+"/self/collections/"+collectionID.String(), nil)
 		req.Header.Set("X-User-ID", userID.String())
@@ -145,7 +188,7 @@ func TestCollectionHandler_GetCollection(t *testing.T) {
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mockService.On("FindByID", mock.Anything, collectionID.String()).Return(nil, model.ErrCollectionNotFound).Once()
+		mockService.On("GetCollection", mock.Anything, userID.String(), collectionID.String()).Return(nil, model.ErrCollectionNotFound).Once()
 
 		req, _ := http.NewRequest("GET", server.URL+"/self/collections/"+collectionID.String(), nil)
 		req.Header.Set("X-User-ID", userID.String())
@@ -227,7 +270,8 @@ func TestCollectionHandler_UpdateCollection(t *testing.T) {
 			Title: "Updated Collection",
 		}
 
-		mockService.On("UpdateCollection", mock.Anything, mock.AnythingOfType("*model.Collection")).Return(nil).Once()
+		mockService.On("UpdateCollection", mock.Anything, userID.String(), collectionID.String(), mock.AnythingOfType("model.CollectionRequest")).
+			Return(updatedCollection, nil).Once()
 
 		body, _ := json.Marshal(updatedCollection)
 		req, _ := http.NewRequest("PUT", server.URL+"/self/collections/"+collectionID.String(), bytes.NewBuffer(body))
@@ -255,7 +299,8 @@ func TestCollectionHandler_UpdateCollection(t *testing.T) {
 			Title: "Updated Collection",
 		}
 
-		mockService.On("UpdateCollection", mock.Anything, mock.AnythingOfType("*model.Collection")).Return(model.ErrCollectionNotFound).Once()
+		mockService.On("UpdateCollection", mock.Anything, userID.String(), collectionID.String(), mock.AnythingOfType("model.CollectionRequest")).
+			Return(nil, model.ErrCollectionNotFound).Once()
 
 		body, _ := json.Marshal(updatedCollection)
 		req, _ := http.NewRequest("PUT", server.URL+"/self/collections/"+collectionID.String(), bytes.NewBuffer(body))
@@ -290,7 +335,7 @@ func TestCollectionHandler_DeleteCollection(t *testing.T) {
 	collectionID := uuid.New()
 
 	t.Run("Success", func(t *testing.T) {
-		mockService.On("DeleteCollection", mock.Anything, collectionID.String()).Return(nil).Once()
+		mockService.On("DeleteCollection", mock.Anything, userID.String(), collectionID.String()).Return(nil).Once()
 
 		req, _ := http.NewRequest("DELETE", server.URL+"/self/collections/"+collectionID.String(), nil)
 		req.Header.Set("X-User-ID", userID.String())
@@ -304,7 +349,7 @@ func TestCollectionHandler_DeleteCollection(t *testing.T) {
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mockService.On("DeleteCollection", mock.Anything, collectionID.String()).Return(model.ErrCollectionNotFound).Once()
+		mockService.On("DeleteCollection", mock.Anything, userID.String(), collectionID.String()).Return(model.ErrCollectionNotFound).Once()
 
 		req, _ := http.NewRequest("DELETE", server.URL+"/self/collections/"+collectionID.String(), nil)
 		req.Header.Set("X-User-ID", userID.String())
@@ -338,7 +383,7 @@ func TestCollectionHandler_AddRevisionToCollection(t *testing.T) {
 	revisionID := uuid.New()
 
 	t.Run("Success", func(t *testing.T) {
-		mockService.On("AddRevisionToCollection", mock.Anything, collectionID.String(), revisionID.String()).Return(nil).Once()
+		mockService.On("AddRevisionToCollection", mock.Anything, userID.String(), collectionID.String(), revisionID.String(), "").Return(nil).Once()
 
 		body := bytes.NewBufferString(`{"revisionID": "` + revisionID.String() + `"}`)
 		req, _ := http.NewRequest("POST", server.URL+"/self/collections/"+collectionID.String()+"/revisions", body)
@@ -354,7 +399,7 @@ func TestCollectionHandler_AddRevisionToCollection(t *testing.T) {
 	})
 
 	t.Run("Collection Not Found", func(t *testing.T) {
-		mockService.On("AddRevisionToCollection", mock.Anything, collectionID.String(), revisionID.String()).Return(model.ErrCollectionNotFound).Once()
+		mockService.On("AddRevisionToCollection", mock.Anything, userID.String(), collectionID.String(), revisionID.String(), "").Return(model.ErrCollectionNotFound).Once()
 
 		body := bytes.NewBufferString(`{"revisionID": "` + revisionID.String() + `"}`)
 		req, _ := http.NewRequest("POST", server.URL+"/self/collections/"+collectionID.String()+"/revisions", body)
@@ -368,6 +413,22 @@ func TestCollectionHandler_AddRevisionToCollection(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("Smart Collection", func(t *testing.T) {
+		mockService.On("AddRevisionToCollection", mock.Anything, userID.String(), collectionID.String(), revisionID.String(), "").Return(model.ErrSmartCollection).Once()
+
+		body := bytes.NewBufferString(`{"revisionID": "` + revisionID.String() + `"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/self/collections/"+collectionID.String()+"/revisions", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
 }
 
 func TestCollectionHandler_ListRevisions(t *testing.T) {
@@ -382,8 +443,12 @@ func TestCollectionHandler_ListRevisions(t *testing.T) {
 			{ID: uuid.New(), Comment: "Revision 1"},
 			{ID: uuid.New(), Comment: "Revision 2"},
 		}
+		items := []model.CollectionArtProject{
+			{CollectionID: collectionID, RevisionID: revisions[0].ID, Position: 0, Caption: "First", Revision: revisions[0]},
+			{CollectionID: collectionID, RevisionID: revisions[1].ID, Position: 1, Revision: revisions[1]},
+		}
 
-		mockService.On("GetRevisionsByCollectionID", mock.Anything, collectionID.String()).Return(revisions, nil).Once()
+		mockService.On("GetRevisionsByCollectionID", mock.Anything, userID.String(), collectionID.String()).Return(items, nil).Once()
 
 		req, _ := http.NewRequest("GET", server.URL+"/self/collections/"+collectionID.String()+"/revisions", nil)
 		req.Header.Set("X-User-ID", userID.String())
@@ -394,18 +459,20 @@ func TestCollectionHandler_ListRevisions(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-		var response []model.Revision
+		var response []model.CollectionItemResponse
 		err = json.NewDecoder(resp.Body).Decode(&response)
 		require.NoError(t, err)
 		assert.Len(t, response, 2)
 		assert.Equal(t, revisions[0].ID, response[0].ID)
+		assert.Equal(t, "First", response[0].Caption)
 		assert.Equal(t, revisions[1].ID, response[1].ID)
+		assert.Equal(t, 1, response[1].Position)
 
 		mockService.AssertExpectations(t)
 	})
 
 	t.Run("Collection Not Found", func(t *testing.T) {
-		mockService.On("GetRevisionsByCollectionID", mock.Anything, collectionID.String()).Return(nil, model.ErrCollectionNotFound).Once()
+		mockService.On("GetRevisionsByCollectionID", mock.Anything, userID.String(), collectionID.String()).Return(nil, model.ErrCollectionNotFound).Once()
 
 		req, _ := http.NewRequest("GET", server.URL+"/self/collections/"+collectionID.String()+"/revisions", nil)
 		req.Header.Set("X-User-ID", userID.String())
@@ -441,8 +508,12 @@ func TestCollectionHandler_ListPublicRevisions(t *testing.T) {
 			{ID: uuid.New(), Comment: "Public Revision 1"},
 			{ID: uuid.New(), Comment: "Public Revision 2"},
 		}
+		items := []model.CollectionArtProject{
+			{RevisionID: revisions[0].ID, Position: 0, Revision: revisions[0]},
+			{RevisionID: revisions[1].ID, Position: 1, Caption: "Second", Revision: revisions[1]},
+		}
 
-		mockService.On("GetRevisionsByPublicCollectionID", mock.Anything, collectionID.String()).Return(revisions, nil).Once()
+		mockService.On("GetRevisionsByPublicCollectionID", mock.Anything, collectionID.String()).Return(items, nil).Once()
 
 		req, _ := http.NewRequest("GET", server.URL+"/collection/"+collectionID.String(), nil)
 
@@ -458,6 +529,7 @@ func TestCollectionHandler_ListPublicRevisions(t *testing.T) {
 		assert.Len(t, response, 2)
 		assert.Equal(t, revisions[0].Comment, response[0].Comment)
 		assert.Equal(t, revisions[1].Comment, response[1].Comment)
+		assert.Equal(t, "Second", response[1].Caption)
 
 		mockService.AssertExpectations(t)
 	})
@@ -486,7 +558,7 @@ func TestCollectionHandler_RemoveRevisionFromCollection(t *testing.T) {
 	revisionID := uuid.New()
 
 	t.Run("Success", func(t *testing.T) {
-		mockService.On("RemoveRevisionFromCollection", mock.Anything, collectionID.String(), revisionID.String()).Return(nil).Once()
+		mockService.On("RemoveRevisionFromCollection", mock.Anything, userID.String(), collectionID.String(), revisionID.String()).Return(nil).Once()
 
 		req, _ := http.NewRequest("DELETE", server.URL+"/self/collections/"+collectionID.String()+"/revisions/"+revisionID.String(), nil)
 		req.Header.Set("X-User-ID", userID.String())
@@ -500,7 +572,7 @@ func TestCollectionHandler_RemoveRevisionFromCollection(t *testing.T) {
 	})
 
 	t.Run("Revision Not Found in Collection", func(t *testing.T) {
-		mockService.On("RemoveRevisionFromCollection", mock.Anything, collectionID.String(), revisionID.String()).Return(model.ErrCollectionNotFound).Once()
+		mockService.On("RemoveRevisionFromCollection", mock.Anything, userID.String(), collectionID.String(), revisionID.String()).Return(model.ErrCollectionNotFound).Once()
 
 		req, _ := http.NewRequest("DELETE", server.URL+"/self/collections/"+collectionID.String()+"/revisions/"+revisionID.String(), nil)
 		req.Header.Set("X-User-ID", userID.String())
@@ -534,3 +606,314 @@ func TestCollectionHandler_RemoveRevisionFromCollection(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
 }
+
+func TestCollectionHandler_UpdateRevisionInCollection(t *testing.T) {
+	server, mockService := setupCollectionTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	collectionID := uuid.New()
+	revisionID := uuid.New()
+	url := server.URL + "/self/collections/" + collectionID.String() + "/revisions/" + revisionID.String()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("UpdateRevisionCaption", mock.Anything, userID.String(), collectionID.String(), revisionID.String(), "New caption").
+			Return(nil).Once()
+
+		req, _ := http.NewRequest("PUT", url, bytes.NewBufferString(`{"caption": "New caption"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing Caption", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", url, bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Revision Not Found in Collection", func(t *testing.T) {
+		mockService.On("UpdateRevisionCaption", mock.Anything, userID.String(), collectionID.String(), revisionID.String(), "").
+			Return(model.ErrRevisionNotFound).Once()
+
+		req, _ := http.NewRequest("PUT", url, bytes.NewBufferString(`{"caption": ""}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestCollectionHandler_ReorderRevisions(t *testing.T) {
+	server, mockService := setupCollectionTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	collectionID := uuid.New()
+	order := []string{uuid.NewString(), uuid.NewString()}
+	url := server.URL + "/self/collections/" + collectionID.String() + "/revisions/order"
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("ReorderRevisions", mock.Anything, userID.String(), collectionID.String(), order).Return(nil).Once()
+
+		body, _ := json.Marshal(model.ReorderCollectionRequest{RevisionIDs: order})
+		req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Incomplete Order", func(t *testing.T) {
+		mockService.On("ReorderRevisions", mock.Anything, userID.String(), collectionID.String(), order[:1]).Return(model.ErrInvalidInput).Once()
+
+		body, _ := json.Marshal(model.ReorderCollectionRequest{RevisionIDs: order[:1]})
+		req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestCollectionHandler_InviteMember(t *testing.T) {
+	server, mockService := setupCollectionTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	collectionID := uuid.New()
+	memberID := uuid.New()
+
+	invite := func(t *testing.T, body string) *http.Response {
+		req, _ := http.NewRequest("POST", server.URL+"/self/collections/"+collectionID.String()+"/members", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		request := model.CollectionMemberRequest{Username: "artist", Role: model.CollectionRoleEditor}
+		member := &model.CollectionMember{
+			CollectionID: collectionID,
+			UserID:       memberID,
+			Role:         model.CollectionRoleEditor,
+			InvitedBy:    userID,
+			User:         model.User{ID: memberID, Username: "artist"},
+		}
+
+		mockService.On("InviteMember", mock.Anything, userID.String(), collectionID.String(), request).Return(member, nil).Once()
+
+		resp := invite(t, `{"username": "artist", "role": "editor"}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var response model.CollectionMemberResponse
+		err := json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, memberID, response.UserID)
+		assert.Equal(t, "artist", response.Username)
+		assert.Equal(t, model.CollectionRoleEditor, response.Role)
+		assert.Nil(t, response.AcceptedAt)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Already Member", func(t *testing.T) {
+		request := model.CollectionMemberRequest{Username: "artist", Role: model.CollectionRoleViewer}
+		mockService.On("InviteMember", mock.Anything, userID.String(), collectionID.String(), request).Return(nil, model.ErrAlreadyMember).Once()
+
+		resp := invite(t, `{"username": "artist", "role": "viewer"}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not Owner", func(t *testing.T) {
+		request := model.CollectionMemberRequest{Username: "artist", Role: model.CollectionRoleViewer}
+		mockService.On("InviteMember", mock.Anything, userID.String(), collectionID.String(), request).Return(nil, model.ErrUnauthorized).Once()
+
+		resp := invite(t, `{"username": "artist", "role": "viewer"}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Role", func(t *testing.T) {
+		request := model.CollectionMemberRequest{Username: "artist", Role: model.CollectionRoleOwner}
+		mockService.On("InviteMember", mock.Anything, userID.String(), collectionID.String(), request).Return(nil, model.ErrInvalidInput).Once()
+
+		resp := invite(t, `{"username": "artist", "role": "owner"}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestCollectionHandler_Invitations(t *testing.T) {
+	server, mockService := setupCollectionTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	ownerID := uuid.New()
+	collectionID := uuid.New()
+
+	t.Run("List", func(t *testing.T) {
+		invitations := []model.CollectionMember{
+			{
+				CollectionID: collectionID,
+				UserID:       userID,
+				Role:         model.CollectionRoleViewer,
+				InvitedBy:    ownerID,
+				Collection:   model.Collection{ID: collectionID, UserID: ownerID, Title: "Showcase"},
+			},
+		}
+
+		mockService.On("ListInvitations", mock.Anything, userID.String()).Return(invitations, nil).Once()
+
+		req, _ := http.NewRequest("GET", server.URL+"/self/collections/invitations", nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response []model.CollectionInvitationResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		require.Len(t, response, 1)
+		assert.Equal(t, "Showcase", response[0].Collection.Title)
+		assert.Equal(t, ownerID, response[0].InvitedBy)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		now := time.Now()
+		member := &model.CollectionMember{CollectionID: collectionID, UserID: userID, Role: model.CollectionRoleViewer, AcceptedAt: &now}
+		mockService.On("AcceptInvitation", mock.Anything, userID.String(), collectionID.String()).Return(member, nil).Once()
+
+		req, _ := http.NewRequest("POST", server.URL+"/self/collections/"+collectionID.String()+"/invitation", nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response model.CollectionMemberResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.NotNil(t, response.AcceptedAt)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Accept Without Invitation", func(t *testing.T) {
+		mockService.On("AcceptInvitation", mock.Anything, userID.String(), collectionID.String()).Return(nil, model.ErrMemberNotFound).Once()
+
+		req, _ := http.NewRequest("POST", server.URL+"/self/collections/"+collectionID.String()+"/invitation", nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Decline", func(t *testing.T) {
+		mockService.On("RemoveMember", mock.Anything, userID.String(), collectionID.String(), userID.String()).Return(nil).Once()
+
+		req, _ := http.NewRequest("DELETE", server.URL+"/self/collections/"+collectionID.String()+"/members/"+userID.String(), nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestCollectionHandler_UpdateMember(t *testing.T) {
+	server, mockService := setupCollectionTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	collectionID := uuid.New()
+	memberID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		member := &model.CollectionMember{CollectionID: collectionID, UserID: memberID, Role: model.CollectionRoleViewer}
+		mockService.On("UpdateMemberRole", mock.Anything, userID.String(), collectionID.String(), memberID.String(), model.CollectionRoleViewer).
+			Return(member, nil).Once()
+
+		body := bytes.NewBufferString(`{"role": "viewer"}`)
+		req, _ := http.NewRequest("PUT", server.URL+"/self/collections/"+collectionID.String()+"/members/"+memberID.String(), body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Member Not Found", func(t *testing.T) {
+		mockService.On("UpdateMemberRole", mock.Anything, userID.String(), collectionID.String(), memberID.String(), model.CollectionRoleEditor).
+			Return(nil, model.ErrMemberNotFound).Once()
+
+		body := bytes.NewBufferString(`{"role": "editor"}`)
+		req, _ := http.NewRequest("PUT", server.URL+"/self/collections/"+collectionID.String()+"/members/"+memberID.String(), body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
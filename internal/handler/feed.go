@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/feeds"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// feedContentTypes maps the feed.{format} route suffix to the response content type.
+var feedContentTypes = map[string]string{
+	"atom": "application/atom+xml; charset=utf-8",
+	"rss":  "application/rss+xml; charset=utf-8",
+	"json": "application/feed+json; charset=utf-8",
+}
+
+type FeedHandler struct {
+	feedService service.FeedService
+}
+
+func NewFeedHandler(fs service.FeedService) *FeedHandler {
+	return &FeedHandler{feedService: fs}
+}
+
+// CollectionFeed serves the feed of a public collection.
+func (h *FeedHandler) CollectionFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	collectionID := chi.URLParam(r, "id")
+	format := chi.URLParam(r, "format")
+	logger := logctx.With(r.Context(), "handler", "CollectionFeed", "collectionID", collectionID, "format", format)
+
+	if _, ok := feedContentTypes[format]; !ok {
+		logger.Warn("Unsupported feed format")
+		SendErrorResponse(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+
+	feed, err := h.feedService.CollectionFeed(ctx, collectionID)
+	if err != nil {
+		logger.Error("Failed to build collection feed", "error", err)
+		if errors.Is(err, model.ErrCollectionNotFound) {
+			SendErrorResponse(w, http.StatusNotFound, "Collection not found")
+		} else {
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to build feed")
+		}
+		return
+	}
+
+	writeFeed(w, r, feed, format)
+}
+
+// GalleryFeed serves the feed of the published art of a user.
+func (h *FeedHandler) GalleryFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	username := chi.URLParam(r, "username")
+	format := chi.URLParam(r, "format")
+	logger := logctx.With(r.Context(), "handler", "GalleryFeed", "username", username, "format", format)
+
+	if _, ok := feedContentTypes[format]; !ok {
+		logger.Warn("Unsupported feed format")
+		SendErrorResponse(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+
+	feed, err := h.feedService.GalleryFeed(ctx, username)
+	if err != nil {
+		logger.Error("Failed to build gallery feed", "error", err)
+		if errors.Is(err, model.ErrUserNotFound) {
+			SendErrorResponse(w, http.StatusNotFound, "Gallery not found")
+		} else {
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to build feed")
+		}
+		return
+	}
+
+	writeFeed(w, r, feed, format)
+}
+
+// writeFeed renders the feed and answers conditional requests
+// using an ETag of the rendered body and the feed update time.
+func writeFeed(w http.ResponseWriter, r *http.Request, feed *feeds.Feed, format string) {
+	var (
+		body string
+		err  error
+	)
+
+	switch format {
+	case "atom":
+		body, err = feed.ToAtom()
+	case "rss":
+		body, err = feed.ToRss()
+	case "json":
+		body, err = feed.ToJSON()
+	}
+	if err != nil {
+		logctx.From(r.Context()).Error("Failed to render feed", "format", format, "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to render feed")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(body))
+	w.Header().Set("Content-Type", feedContentTypes[format])
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	http.ServeContent(w, r, "", feed.Updated, strings.NewReader(body))
+}
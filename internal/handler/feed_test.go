@@ -0,0 +1,164 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/feeds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func setupFeedTestServer(t *testing.T) (*httptest.Server, *mocks.FeedService) {
+	r := chi.NewRouter()
+
+	mockService := mocks.NewFeedService(t)
+	feedHandler := handler.NewFeedHandler(mockService)
+
+	r.Get("/collection/{id}/feed.{format}", feedHandler.CollectionFeed)
+	r.Get("/gallery/{username}/feed.{format}", feedHandler.GalleryFeed)
+
+	return httptest.NewServer(r), mockService
+}
+
+func testFeed() *feeds.Feed {
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &feeds.Feed{
+		Title:   "Characters",
+		Link:    &feeds.Link{Href: "https://mirai.example/collection/public-id"},
+		Updated: updated,
+		Items: []*feeds.Item{
+			{
+				Id:          "https://mirai.example/art/art-1",
+				Title:       "Knight",
+				Link:        &feeds.Link{Href: "https://mirai.example/art/art-1"},
+				Description: "Final lineart",
+				Created:     updated,
+				Enclosure:   &feeds.Enclosure{Url: "https://mirai.example/art/art-1", Type: "image/png", Length: "2048"},
+			},
+		},
+	}
+}
+
+func TestFeedHandler_CollectionFeed(t *testing.T) {
+	server, mockService := setupFeedTestServer(t)
+	defer server.Close()
+
+	formats := []struct {
+		format      string
+		contentType string
+		contains    string
+	}{
+		{format: "atom", contentType: "application/atom+xml; charset=utf-8", contains: `rel="enclosure"`},
+		{format: "rss", contentType: "application/rss+xml; charset=utf-8", contains: `<enclosure url="https://mirai.example/art/art-1"`},
+		{format: "json", contentType: "application/feed+json; charset=utf-8", contains: `"image": "https://mirai.example/art/art-1"`},
+	}
+
+	for _, tt := range formats {
+		t.Run(tt.format, func(t *testing.T) {
+			mockService.On("CollectionFeed", mock.Anything, "public-id").Return(testFeed(), nil).Once()
+
+			resp, err := http.Get(server.URL + "/collection/public-id/feed." + tt.format)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+			assert.NotEmpty(t, resp.Header.Get("ETag"))
+			assert.Equal(t, "Sun, 01 Mar 2026 12:00:00 GMT", resp.Header.Get("Last-Modified"))
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "Knight")
+			assert.Contains(t, string(body), tt.contains)
+
+			if tt.format == "json" {
+				var decoded map[string]interface{}
+				assert.NoError(t, json.Unmarshal(body, &decoded))
+			}
+		})
+	}
+
+	t.Run("If-None-Match", func(t *testing.T) {
+		mockService.On("CollectionFeed", mock.Anything, "public-id").Return(testFeed(), nil).Twice()
+
+		resp, err := http.Get(server.URL + "/collection/public-id/feed.atom")
+		require.NoError(t, err)
+		resp.Body.Close()
+		etag := resp.Header.Get("ETag")
+
+		req, _ := http.NewRequest("GET", server.URL+"/collection/public-id/feed.atom", nil)
+		req.Header.Set("If-None-Match", etag)
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	})
+
+	t.Run("If-Modified-Since", func(t *testing.T) {
+		mockService.On("CollectionFeed", mock.Anything, "public-id").Return(testFeed(), nil).Once()
+
+		req, _ := http.NewRequest("GET", server.URL+"/collection/public-id/feed.rss", nil)
+		req.Header.Set("If-Modified-Since", "Sun, 01 Mar 2026 12:00:00 GMT")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	})
+
+	t.Run("Unknown Format", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/collection/public-id/feed.xml")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Collection Not Found", func(t *testing.T) {
+		mockService.On("CollectionFeed", mock.Anything, "private-id").Return(nil, model.ErrCollectionNotFound).Once()
+
+		resp, err := http.Get(server.URL + "/collection/private-id/feed.atom")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestFeedHandler_GalleryFeed(t *testing.T) {
+	server, mockService := setupFeedTestServer(t)
+	defer server.Close()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("GalleryFeed", mock.Anything, "artist").Return(testFeed(), nil).Once()
+
+		resp, err := http.Get(server.URL + "/gallery/artist/feed.json")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		mockService.On("GalleryFeed", mock.Anything, "nobody").Return(nil, model.ErrUserNotFound).Once()
+
+		resp, err := http.Get(server.URL + "/gallery/nobody/feed.atom")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/mirai-box/mirai-box/internal/buildinfo"
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// HealthHandler serves the probes and the build information of the service.
+type HealthHandler struct {
+	healthService service.HealthService
+	draining      atomic.Bool
+}
+
+// NewHealthHandler creates a new instance of HealthHandler.
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// SetDraining makes readiness fail, so load balancers stop sending requests while
+// the server drains its connections before shutdown.
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Live reports that the process serves requests, without checking its dependencies.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	SendJSONResponse(w, http.StatusOK, map[string]string{"status": model.HealthStatusOK})
+}
+
+// Ready reports whether the service accepts traffic: it is not draining, the database
+// is reachable, the storage root is writable and the schema is migrated.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	logger := logctx.With(r.Context(), "handler", "Ready")
+
+	if h.draining.Load() {
+		logger.Info("Not ready while draining")
+		SendJSONResponse(w, http.StatusServiceUnavailable, model.Readiness{Status: model.HealthStatusDraining})
+		return
+	}
+
+	readiness := h.healthService.Readiness(r.Context())
+	if readiness.Status != model.HealthStatusOK {
+		logger.Warn("Not ready", "checks", readiness.Checks)
+		SendJSONResponse(w, http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	SendJSONResponse(w, http.StatusOK, readiness)
+}
+
+// Version returns the build metadata of the running binary.
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	SendJSONResponse(w, http.StatusOK, buildinfo.Get())
+}
@@ -0,0 +1,81 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/buildinfo"
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func TestHealthHandler_Live(t *testing.T) {
+	h := handler.NewHealthHandler(mocks.NewHealthService(t))
+
+	rr := httptest.NewRecorder()
+	h.Live(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	mockService := mocks.NewHealthService(t)
+	h := handler.NewHealthHandler(mockService)
+
+	ready := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.Ready(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rr
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		mockService.On("Readiness", mock.Anything).Return(&model.Readiness{
+			Status: model.HealthStatusOK,
+			Checks: map[string]string{"database": model.HealthStatusOK},
+		}).Once()
+
+		rr := ready()
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"status":"OK","checks":{"database":"OK"}}`, rr.Body.String())
+	})
+
+	t.Run("Failing check", func(t *testing.T) {
+		mockService.On("Readiness", mock.Anything).Return(&model.Readiness{
+			Status: model.HealthStatusFailing,
+			Checks: map[string]string{"database": model.HealthStatusFailing},
+		}).Once()
+
+		rr := ready()
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.JSONEq(t, `{"status":"failing","checks":{"database":"failing"}}`, rr.Body.String())
+	})
+
+	t.Run("Draining", func(t *testing.T) {
+		h.SetDraining()
+
+		rr := ready()
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.JSONEq(t, `{"status":"draining"}`, rr.Body.String())
+	})
+}
+
+func TestHealthHandler_Version(t *testing.T) {
+	h := handler.NewHealthHandler(mocks.NewHealthService(t))
+
+	rr := httptest.NewRecorder()
+	h.Version(rr, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var info buildinfo.Info
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&info))
+	assert.Equal(t, buildinfo.Version, info.Version)
+	assert.NotEmpty(t, info.GoVersion)
+}
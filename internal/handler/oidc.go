@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/securecookie"
+	"golang.org/x/oauth2"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+const (
+	oidcStateCookie = "oidc-state"
+	oidcStatePath   = "/login/oidc"
+	oidcStateMaxAge = 10 * time.Minute
+
+	// oidcRandomBytes is the entropy of the state and the nonce.
+	oidcRandomBytes = 32
+)
+
+// oidcState is kept in an encrypted cookie between the redirect to the provider and the callback.
+type oidcState struct {
+	Provider     string
+	State        string
+	Nonce        string
+	Verifier     string
+	KeepSignedIn bool
+	// LinkUserID is set when a signed in user links the provider account instead of signing in.
+	LinkUserID string
+}
+
+// OIDCHandler handles sign in with OpenID Connect providers and the external identities of users.
+type OIDCHandler struct {
+	oidcService service.OIDCService
+	users       *UserHandler
+	codec       securecookie.Codec
+	secure      bool
+}
+
+// NewOIDCHandler creates a new instance of OIDCHandler, hashKey and blockKey protect the state cookie
+// and secure sets its Secure attribute.
+func NewOIDCHandler(oidcService service.OIDCService, users *UserHandler, hashKey, blockKey []byte, secure bool) *OIDCHandler {
+	codec := securecookie.New(hashKey, blockKey)
+	codec.MaxAge(int(oidcStateMaxAge.Seconds()))
+
+	return &OIDCHandler{
+		oidcService: oidcService,
+		users:       users,
+		codec:       codec,
+		secure:      secure,
+	}
+}
+
+// ListProviders lists the names of the providers users can sign in with.
+func (h *OIDCHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	SendJSONResponse(w, http.StatusOK, map[string][]string{"providers": h.oidcService.Providers()})
+}
+
+// Login redirects to the provider to sign in.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	h.redirect(w, r, "", r.URL.Query().Get("keepSignedIn") == "true")
+}
+
+// Link redirects the current user to the provider to link the provider account.
+func (h *OIDCHandler) Link(w http.ResponseWriter, r *http.Request) {
+	// The link signs the provider account in as the user, so it needs a session and
+	// not an api token.
+	user, ok := sessionUser(w, r, logctx.With(r.Context(), "handler", "LinkIdentity"))
+	if !ok {
+		return
+	}
+
+	h.redirect(w, r, user.ID.String(), false)
+}
+
+// Callback completes the authorization code flow, then signs the user in or links the identity.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	provider := chi.URLParam(r, "provider")
+	logger := logctx.With(r.Context(), "handler", "OIDCCallback", "provider", provider)
+
+	state, ok := h.readState(w, r)
+	if !ok || state.Provider != provider ||
+		subtle.ConstantTimeCompare([]byte(state.State), []byte(r.URL.Query().Get("state"))) != 1 {
+		logger.Warn("Invalid or missing login state")
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid login state, sign in again")
+		return
+	}
+
+	if errCode := r.URL.Query().Get("error"); errCode != "" {
+		logger.Warn("Provider returned an error", "error", errCode, "description", r.URL.Query().Get("error_description"))
+		SendErrorResponse(w, http.StatusUnauthorized, "Sign in was cancelled or denied by the provider")
+		return
+	}
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		logger.Warn("Callback without authorization code")
+		SendErrorResponse(w, http.StatusBadRequest, "Missing authorization code")
+		return
+	}
+
+	if state.LinkUserID != "" {
+		identity, err := h.oidcService.Link(ctx, state.LinkUserID, provider, code, state.Verifier, state.Nonce)
+		if err != nil {
+			h.sendError(w, r, err, "Failed to link identity")
+			return
+		}
+
+		logger.Info("Identity linked", "userID", state.LinkUserID, "identityID", identity.ID)
+		SendJSONResponse(w, http.StatusOK, convertToIdentityResponse(identity))
+		return
+	}
+
+	user, err := h.oidcService.Login(ctx, provider, code, state.Verifier, state.Nonce)
+	if err != nil {
+		h.sendError(w, r, err, "Failed to sign in")
+		return
+	}
+
+	h.users.beginSession(w, r, user, state.KeepSignedIn)
+}
+
+// ListIdentities lists the provider accounts linked to the current user.
+func (h *OIDCHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ListIdentities")
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	identities, err := h.oidcService.ListIdentities(ctx, user.ID.String())
+	if err != nil {
+		logger.Error("Failed to list identities", "error", err, "userID", user.ID)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to list identities")
+		return
+	}
+
+	response := make([]model.ExternalIdentityResponse, 0, len(identities))
+	for i := range identities {
+		response = append(response, convertToIdentityResponse(&identities[i]))
+	}
+
+	SendJSONResponse(w, http.StatusOK, response)
+}
+
+// UnlinkIdentity removes a provider account from the current user.
+func (h *OIDCHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	identityID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "UnlinkIdentity", "identityID", identityID)
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	if err := h.oidcService.UnlinkIdentity(ctx, user.ID.String(), identityID); err != nil {
+		logger.Error("Failed to unlink identity", "error", err, "userID", user.ID)
+		if errors.Is(err, model.ErrIdentityNotFound) {
+			SendErrorResponse(w, http.StatusNotFound, "Identity not found")
+			return
+		}
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to unlink identity")
+		return
+	}
+
+	logger.Info("Identity unlinked", "userID", user.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// redirect stores a new login state in the state cookie and redirects to the provider.
+func (h *OIDCHandler) redirect(w http.ResponseWriter, r *http.Request, linkUserID string, keepSignedIn bool) {
+	provider := chi.URLParam(r, "provider")
+	logger := logctx.With(r.Context(), "handler", "OIDCLogin", "provider", provider)
+
+	stateValue, err := service.GenerateToken(oidcRandomBytes)
+	if err != nil {
+		logger.Error("Failed to generate state", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	nonce, err := service.GenerateToken(oidcRandomBytes)
+	if err != nil {
+		logger.Error("Failed to generate nonce", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	state := oidcState{
+		Provider:     provider,
+		State:        stateValue,
+		Nonce:        nonce,
+		Verifier:     oauth2.GenerateVerifier(),
+		KeepSignedIn: keepSignedIn,
+		LinkUserID:   linkUserID,
+	}
+
+	authURL, err := h.oidcService.AuthCodeURL(r.Context(), provider, state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		h.sendError(w, r, err, "Failed to start sign in")
+		return
+	}
+
+	encoded, err := h.codec.Encode(oidcStateCookie, state)
+	if err != nil {
+		logger.Error("Failed to encode state", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    encoded,
+		Path:     oidcStatePath,
+		MaxAge:   int(oidcStateMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secure,
+		// Lax so that the cookie comes along with the top-level redirect back from the provider.
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// readState decodes the state cookie and clears it, a state is only used once.
+func (h *OIDCHandler) readState(w http.ResponseWriter, r *http.Request) (*oidcState, bool) {
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		return nil, false
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     oidcStatePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secure,
+	})
+
+	var state oidcState
+	if err := h.codec.Decode(oidcStateCookie, cookie.Value, &state); err != nil {
+		logctx.From(r.Context()).Warn("Failed to decode login state", "error", err)
+		return nil, false
+	}
+
+	return &state, true
+}
+
+// sendError maps errors of the OIDC service to responses.
+func (h *OIDCHandler) sendError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	logctx.From(r.Context()).Warn(message, "error", err)
+
+	switch {
+	case errors.Is(err, model.ErrProviderNotFound):
+		SendErrorResponse(w, http.StatusNotFound, "Identity provider not found")
+	case errors.Is(err, model.ErrExternalLogin):
+		SendErrorResponse(w, http.StatusUnauthorized, "Sign in with the identity provider failed")
+	case errors.Is(err, model.ErrAccountDisabled):
+		SendErrorResponse(w, http.StatusForbidden, "Account is disabled")
+	case errors.Is(err, model.ErrSignupDisabled):
+		SendErrorResponse(w, http.StatusForbidden, "No account is linked to this identity")
+	case errors.Is(err, model.ErrIdentityLinked):
+		SendErrorResponse(w, http.StatusConflict, "Identity is linked to another account")
+	case errors.Is(err, model.ErrDuplicateUsername):
+		SendErrorResponse(w, http.StatusConflict, "Username already exists, sign in and link the identity instead")
+	default:
+		SendErrorResponse(w, http.StatusInternalServerError, message)
+	}
+}
+
+func convertToIdentityResponse(identity *model.ExternalIdentity) model.ExternalIdentityResponse {
+	return model.ExternalIdentityResponse{
+		ID:        identity.ID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
@@ -0,0 +1,175 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func setupOIDCTestServer(t *testing.T) (*httptest.Server, *mocks.OIDCService, *mocks.LoginThrottleService) {
+	r := chi.NewRouter()
+
+	mockService := mocks.NewOIDCService(t)
+	throttleMock := mocks.NewLoginThrottleService(t)
+	cookieStore := sessions.NewCookieStore([]byte("test-secret"))
+
+	userHandler := handler.NewUserHandler(mocks.NewUserService(t), mocks.NewSessionService(t), throttleMock, mocks.NewTwoFactorService(t), cookieStore, 24*time.Hour, 7*24*time.Hour)
+	oidcHandler := handler.NewOIDCHandler(mockService, userHandler, []byte("test-secret"), make([]byte, 32), true)
+
+	r.Get("/login/oidc/{provider}", oidcHandler.Login)
+	r.Get("/login/oidc/{provider}/callback", oidcHandler.Callback)
+
+	return httptest.NewServer(r), mockService, throttleMock
+}
+
+func TestOIDCHandler_LoginFlow(t *testing.T) {
+	server, mockService, throttleMock := setupOIDCTestServer(t)
+	defer server.Close()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	var state, nonce string
+	mockService.On("AuthCodeURL", mock.Anything, "studio", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			state, nonce = args.String(2), args.String(3)
+		}).Return("https://idp.example/authorize", nil).Once()
+
+	resp, err := client.Get(server.URL + "/login/oidc/studio?keepSignedIn=true")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "https://idp.example/authorize", resp.Header.Get("Location"))
+
+	var stateCookie *http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "oidc-state" {
+			stateCookie = cookie
+		}
+	}
+	require.NotNil(t, stateCookie)
+	assert.True(t, stateCookie.HttpOnly)
+
+	callback := func(state string) *http.Response {
+		req, _ := http.NewRequest("GET", server.URL+"/login/oidc/studio/callback?"+url.Values{
+			"code":  {"auth-code"},
+			"state": {state},
+		}.Encode(), nil)
+		req.AddCookie(stateCookie)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("State mismatch", func(t *testing.T) {
+		resp := callback("forged")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		user := &model.User{ID: uuid.New(), Username: "alice", Role: model.RoleUser}
+		mockService.On("Login", mock.Anything, "studio", "auth-code", mock.Anything, nonce).Return(user, nil).Once()
+		throttleMock.On("RecordSuccess", mock.Anything, "alice").Return(nil).Once()
+
+		resp := callback(state)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var sessionCookie *http.Cookie
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == model.SessionCookieName {
+				sessionCookie = cookie
+			}
+		}
+		require.NotNil(t, sessionCookie)
+		assert.Equal(t, 7*24*60*60, sessionCookie.MaxAge, "keepSignedIn is carried through the provider")
+	})
+
+	t.Run("Missing state cookie", func(t *testing.T) {
+		resp, err := client.Get(server.URL + "/login/oidc/studio/callback?code=auth-code&state=" + state)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestOIDCHandler_UnknownProvider(t *testing.T) {
+	server, mockService, _ := setupOIDCTestServer(t)
+	defer server.Close()
+
+	mockService.On("AuthCodeURL", mock.Anything, "other", mock.Anything, mock.Anything, mock.Anything).
+		Return("", model.ErrProviderNotFound).Once()
+
+	resp, err := http.Get(server.URL + "/login/oidc/other")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestOIDCHandler_LinkWithAPIToken(t *testing.T) {
+	r := chi.NewRouter()
+
+	tokenService := mocks.NewAPITokenService(t)
+	cookieStore := sessions.NewCookieStore([]byte("test-secret"))
+	m := middleware.NewMiddleware(cookieStore, mocks.NewUserService(t), mocks.NewPermissionService(t), tokenService)
+
+	userHandler := handler.NewUserHandler(mocks.NewUserService(t), mocks.NewSessionService(t), mocks.NewLoginThrottleService(t), mocks.NewTwoFactorService(t), cookieStore, 24*time.Hour, 7*24*time.Hour)
+	oidcHandler := handler.NewOIDCHandler(mocks.NewOIDCService(t), userHandler, []byte("test-secret"), make([]byte, 32), true)
+
+	r.Route("/self", func(r chi.Router) {
+		r.Use(m.AuthMiddleware)
+		r.Get("/identities/{provider}/link", oidcHandler.Link)
+		r.Delete("/identities/{id}", oidcHandler.UnlinkIdentity)
+	})
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	userID := uuid.New()
+	token := &model.APIToken{
+		ID:     uuid.New(),
+		UserID: userID,
+		Scopes: []string{model.PermArtWrite},
+		User:   model.User{ID: userID, Role: model.RoleUser},
+	}
+	tokenService.On("Authenticate", mock.Anything, "mb_secret").Return(token, []string{model.PermArtWrite}, nil).Twice()
+
+	requests := map[string]string{
+		http.MethodGet:    "/self/identities/example/link",
+		http.MethodDelete: "/self/identities/" + uuid.NewString(),
+	}
+	for method, path := range requests {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer mb_secret")
+
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, "%s %s", method, path)
+		assert.Empty(t, resp.Cookies(), "no login state is issued")
+	}
+}
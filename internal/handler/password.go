@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// PasswordHandler handles HTTP requests to change and reset passwords.
+type PasswordHandler struct {
+	passwordService service.PasswordService
+	store           sessions.Store
+}
+
+// NewPasswordHandler creates a new instance of PasswordHandler.
+func NewPasswordHandler(passwordService service.PasswordService, store sessions.Store) *PasswordHandler {
+	return &PasswordHandler{
+		passwordService: passwordService,
+		store:           store,
+	}
+}
+
+// ChangePassword sets a new password for the current user, the current password is required.
+func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ChangePassword")
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.passwordService.ChangePassword(ctx, user.ID.String(), req.CurrentPassword, req.NewPassword, h.currentToken(r))
+	if err != nil {
+		logger.Warn("Failed to change password", "error", err, "userID", user.ID)
+		switch {
+		case errors.Is(err, model.ErrInvalidCredentials):
+			SendErrorResponse(w, http.StatusForbidden, "Current password is incorrect")
+		case errors.Is(err, model.ErrWeakPassword):
+			SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to change password")
+		}
+		return
+	}
+
+	logger.Info("Password changed", "userID", user.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestReset sends a password reset token to the user. The response is the same
+// whether or not the username exists.
+func (h *PasswordHandler) RequestReset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "RequestReset")
+
+	var req model.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.passwordService.RequestReset(ctx, req.Username); err != nil {
+		logger.Error("Failed to request password reset", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to request password reset")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ConfirmReset sets a new password with a password reset token.
+func (h *PasswordHandler) ConfirmReset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ConfirmReset")
+
+	var req model.PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		logger.Warn("Failed to reset password", "error", err)
+		switch {
+		case errors.Is(err, model.ErrInvalidResetToken):
+			SendErrorResponse(w, http.StatusBadRequest, "Invalid or expired reset token")
+		case errors.Is(err, model.ErrAccountDisabled):
+			SendErrorResponse(w, http.StatusForbidden, "Account is disabled")
+		case errors.Is(err, model.ErrWeakPassword):
+			SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to reset password")
+		}
+		return
+	}
+
+	logger.Info("Password reset with token")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentToken returns the session token of the request, empty for stores without server-side sessions.
+func (h *PasswordHandler) currentToken(r *http.Request) string {
+	session, err := h.store.Get(r, model.SessionCookieName)
+	if err != nil {
+		logctx.From(r.Context()).Warn("Failed to get current session", "error", err)
+		return ""
+	}
+	return session.ID
+}
@@ -0,0 +1,135 @@
+package handler_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func setupPasswordTestServer(t *testing.T) (*httptest.Server, *mocks.PasswordService) {
+	r := chi.NewRouter()
+
+	mockService := mocks.NewPasswordService(t)
+	cookieStore := sessions.NewCookieStore([]byte("test-secret"))
+	m := middleware.NewMiddleware(cookieStore, mocks.NewUserService(t), mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+
+	passwordHandler := handler.NewPasswordHandler(mockService, cookieStore)
+
+	r.Post("/password/reset", passwordHandler.RequestReset)
+	r.Post("/password/reset/confirm", passwordHandler.ConfirmReset)
+	r.Route("/self", func(r chi.Router) {
+		r.Use(m.MockAuthMiddleware)
+		r.Post("/password", passwordHandler.ChangePassword)
+	})
+
+	return httptest.NewServer(r), mockService
+}
+
+func TestPasswordHandler_ChangePassword(t *testing.T) {
+	server, mockService := setupPasswordTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "Success", err: nil, wantStatus: http.StatusNoContent},
+		{name: "Wrong current password", err: model.ErrInvalidCredentials, wantStatus: http.StatusForbidden},
+		{name: "Weak password", err: fmt.Errorf("%w: too short", model.ErrWeakPassword), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.On("ChangePassword", mock.Anything, userID.String(), "old-password", "new-password", "").
+				Return(tt.err).Once()
+
+			body := bytes.NewBufferString(`{"current_password":"old-password","new_password":"new-password"}`)
+			req, _ := http.NewRequest("POST", server.URL+"/self/password", body)
+			req.Header.Set("X-User-ID", userID.String())
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+
+	t.Run("Current password required", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"new_password":"new-password"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/self/password", body)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestPasswordHandler_Reset(t *testing.T) {
+	server, mockService := setupPasswordTestServer(t)
+	defer server.Close()
+
+	t.Run("Request", func(t *testing.T) {
+		mockService.On("RequestReset", mock.Anything, "alice").Return(nil).Once()
+
+		body := bytes.NewBufferString(`{"username":"alice"}`)
+		resp, err := http.Post(server.URL+"/password/reset", "application/json", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		mockService.On("ResetPassword", mock.Anything, "token", "new-password").Return(nil).Once()
+
+		body := bytes.NewBufferString(`{"token":"token","new_password":"new-password"}`)
+		resp, err := http.Post(server.URL+"/password/reset/confirm", "application/json", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("Confirm with invalid token", func(t *testing.T) {
+		mockService.On("ResetPassword", mock.Anything, "used", "new-password").Return(model.ErrInvalidResetToken).Once()
+
+		body := bytes.NewBufferString(`{"token":"used","new_password":"new-password"}`)
+		resp, err := http.Post(server.URL+"/password/reset/confirm", "application/json", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Confirm for disabled account", func(t *testing.T) {
+		mockService.On("ResetPassword", mock.Anything, "disabled", "new-password").Return(model.ErrAccountDisabled).Once()
+
+		body := bytes.NewBufferString(`{"token":"disabled","new_password":"new-password"}`)
+		resp, err := http.Post(server.URL+"/password/reset/confirm", "application/json", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/mirai-box/mirai-box/internal/middleware"
 	"github.com/mirai-box/mirai-box/internal/model"
 )
 
@@ -20,25 +21,23 @@ func SendJSONResponse(w ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		slog.Error("Error encoding JSON response", "error", err)
+		slog.Error("Error encoding JSON response", "error", err, "request_id", w.Header().Get(middleware.RequestIDHeader))
 	}
 }
 
-// SendErrorResponse sends a JSON error response with the given status code and message
+// SendErrorResponse sends a JSON error response with the given status code and message.
+// The response carries the request ID the RequestID middleware set on w.
 func SendErrorResponse(w ResponseWriter, status int, message string) {
-	errResp := model.ErrorResponse{
-		Status:  status,
-		Message: message,
-	}
-	SendJSONResponse(w, status, errResp)
+	middleware.WriteError(w, status, message)
 }
 
 // SendValidationErrorResponse sends a JSON response for validation errors
 func SendValidationErrorResponse(w ResponseWriter, errors map[string]string) {
 	errResp := model.ValidationErrorResponse{
-		Status:  http.StatusBadRequest,
-		Message: "Validation failed",
-		Errors:  errors,
+		Status:    http.StatusBadRequest,
+		Message:   "Validation failed",
+		Errors:    errors,
+		RequestID: w.Header().Get(middleware.RequestIDHeader),
 	}
 	SendJSONResponse(w, http.StatusBadRequest, errResp)
 }
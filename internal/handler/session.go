@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// SessionHandler handles HTTP requests to list and revoke the sessions of the current user.
+type SessionHandler struct {
+	sessionService service.SessionService
+	store          sessions.Store
+}
+
+// NewSessionHandler creates a new instance of SessionHandler.
+func NewSessionHandler(sessionService service.SessionService, store sessions.Store) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		store:          store,
+	}
+}
+
+// ListSessions lists the active sessions of the user with device and IP.
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "ListSessions")
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	list, err := h.sessionService.ListSessions(ctx, user.ID.String(), h.currentToken(r))
+	if err != nil {
+		logger.Error("Failed to list sessions", "error", err, "userID", user.ID)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	response := make([]model.SessionResponse, 0, len(list))
+	for i := range list {
+		response = append(response, convertToSessionResponse(&list[i]))
+	}
+
+	logger.Info("Sessions listed", "userID", user.ID, "count", len(response))
+	SendJSONResponse(w, http.StatusOK, response)
+}
+
+// RevokeSession ends one session of the user.
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sessionID := chi.URLParam(r, "id")
+	logger := logctx.With(r.Context(), "handler", "RevokeSession", "sessionID", sessionID)
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(ctx, user.ID.String(), sessionID); err != nil {
+		logger.Error("Failed to revoke session", "error", err, "userID", user.ID)
+		if errors.Is(err, model.ErrSessionNotFound) {
+			SendErrorResponse(w, http.StatusNotFound, "Session not found")
+			return
+		}
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+
+	logger.Info("Session revoked", "userID", user.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOtherSessions ends every session of the user except the current one.
+func (h *SessionHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "RevokeOtherSessions")
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		logger.Warn("User not found in context")
+		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	count, err := h.sessionService.RevokeOtherSessions(ctx, user.ID.String(), h.currentToken(r))
+	if err != nil {
+		logger.Error("Failed to revoke sessions", "error", err, "userID", user.ID)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	logger.Info("Sessions revoked", "userID", user.ID, "count", count)
+	SendJSONResponse(w, http.StatusOK, map[string]int64{"revoked": count})
+}
+
+// currentToken returns the session token of the request, empty for stores without server-side sessions.
+func (h *SessionHandler) currentToken(r *http.Request) string {
+	session, err := h.store.Get(r, model.SessionCookieName)
+	if err != nil {
+		logctx.From(r.Context()).Warn("Failed to get current session", "error", err)
+		return ""
+	}
+	return session.ID
+}
+
+func convertToSessionResponse(session *model.Session) model.SessionResponse {
+	return model.SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		Current:    session.Current,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
@@ -0,0 +1,125 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func setupSessionTestServer(t *testing.T) (*httptest.Server, *mocks.SessionService) {
+	r := chi.NewRouter()
+
+	mockService := mocks.NewSessionService(t)
+	cookieStore := sessions.NewCookieStore([]byte("test-secret"))
+	m := middleware.NewMiddleware(cookieStore, mocks.NewUserService(t), mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+
+	sessionHandler := handler.NewSessionHandler(mockService, cookieStore)
+
+	r.Route("/self", func(r chi.Router) {
+		r.Use(m.MockAuthMiddleware)
+		r.Get("/sessions", sessionHandler.ListSessions)
+		r.Delete("/sessions", sessionHandler.RevokeOtherSessions)
+		r.With(middleware.ValidateUUID("id")).Delete("/sessions/{id}", sessionHandler.RevokeSession)
+	})
+
+	return httptest.NewServer(r), mockService
+}
+
+func TestSessionHandler_ListSessions(t *testing.T) {
+	server, mockService := setupSessionTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	list := []model.Session{
+		{ID: uuid.New(), TokenHash: "hash", UserAgent: "Firefox", IP: "192.0.2.1", Current: true},
+		{ID: uuid.New(), TokenHash: "other", UserAgent: "curl", IP: "192.0.2.2"},
+	}
+
+	mockService.On("ListSessions", mock.Anything, userID.String(), "").Return(list, nil).Once()
+
+	req, _ := http.NewRequest("GET", server.URL+"/self/sessions", nil)
+	req.Header.Set("X-User-ID", userID.String())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response []model.SessionResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Len(t, response, 2)
+	assert.True(t, response[0].Current)
+	assert.Equal(t, "192.0.2.2", response[1].IP)
+}
+
+func TestSessionHandler_RevokeSession(t *testing.T) {
+	server, mockService := setupSessionTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("RevokeSession", mock.Anything, userID.String(), sessionID.String()).Return(nil).Once()
+
+		req, _ := http.NewRequest("DELETE", server.URL+"/self/sessions/"+sessionID.String(), nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("RevokeSession", mock.Anything, userID.String(), sessionID.String()).Return(model.ErrSessionNotFound).Once()
+
+		req, _ := http.NewRequest("DELETE", server.URL+"/self/sessions/"+sessionID.String(), nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestSessionHandler_RevokeOtherSessions(t *testing.T) {
+	server, mockService := setupSessionTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+
+	mockService.On("RevokeOtherSessions", mock.Anything, userID.String(), "").Return(int64(3), nil).Once()
+
+	req, _ := http.NewRequest("DELETE", server.URL+"/self/sessions", nil)
+	req.Header.Set("X-User-ID", userID.String())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response map[string]int64
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), response["revoked"])
+}
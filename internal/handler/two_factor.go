@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// TwoFactorHandler handles HTTP requests to manage the two-factor authentication of the current user.
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+// NewTwoFactorHandler creates a new instance of TwoFactorHandler.
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// Status reports whether two-factor authentication is enabled or required and the recovery codes left.
+func (h *TwoFactorHandler) Status(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "TwoFactorStatus")
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	status, err := h.twoFactorService.Status(ctx, user.ID.String())
+	if err != nil {
+		logger.Error("Failed to get two-factor status", "error", err, "userID", user.ID)
+		sendTwoFactorError(w, err, "Failed to get two-factor status")
+		return
+	}
+
+	SendJSONResponse(w, http.StatusOK, model.TwoFactorStatusResponse{
+		Enabled:           status.Enabled,
+		Required:          status.Required,
+		RecoveryCodesLeft: int(status.RecoveryCodesLeft),
+	})
+}
+
+// Enrol starts an enrolment and returns the secret with the URI to show as QR code.
+func (h *TwoFactorHandler) Enrol(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "TwoFactorEnrol")
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	secret, uri, err := h.twoFactorService.BeginEnrolment(ctx, user.ID.String())
+	if err != nil {
+		logger.Error("Failed to start enrolment", "error", err, "userID", user.ID)
+		sendTwoFactorError(w, err, "Failed to start enrolment")
+		return
+	}
+
+	logger.Info("Two-factor enrolment started", "userID", user.ID)
+	SendJSONResponse(w, http.StatusOK, model.TwoFactorEnrolmentResponse{Secret: secret, URI: uri})
+}
+
+// Confirm enables two-factor authentication with a code from the authenticator
+// and returns the recovery codes.
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "TwoFactorConfirm")
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeCodeRequest(w, r, logger)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.ConfirmEnrolment(ctx, user.ID.String(), req.Code)
+	if err != nil {
+		logger.Error("Failed to confirm enrolment", "error", err, "userID", user.ID)
+		sendTwoFactorError(w, err, "Failed to confirm enrolment")
+		return
+	}
+
+	logger.Info("Two-factor authentication enabled", "userID", user.ID)
+	SendJSONResponse(w, http.StatusOK, model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, confirmed with a current code.
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "RegenerateRecoveryCodes")
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeCodeRequest(w, r, logger)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(ctx, user.ID.String(), req.Code)
+	if err != nil {
+		logger.Error("Failed to regenerate recovery codes", "error", err, "userID", user.ID)
+		sendTwoFactorError(w, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	logger.Info("Recovery codes regenerated", "userID", user.ID)
+	SendJSONResponse(w, http.StatusOK, model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable turns off two-factor authentication, confirmed with a current code.
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "TwoFactorDisable")
+
+	user, ok := sessionUser(w, r, logger)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeCodeRequest(w, r, logger)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(ctx, user.ID.String(), req.Code); err != nil {
+		logger.Error("Failed to disable two-factor authentication", "error", err, "userID", user.ID)
+		sendTwoFactorError(w, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	logger.Info("Two-factor authentication disabled", "userID", user.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeCodeRequest(w http.ResponseWriter, r *http.Request, logger *slog.Logger) (model.TwoFactorCodeRequest, bool) {
+	var req model.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return req, false
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+
+	return req, true
+}
+
+func sendTwoFactorError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, model.ErrInvalidOTP):
+		SendErrorResponse(w, http.StatusUnauthorized, "Invalid two-factor code")
+	case errors.Is(err, model.ErrTwoFactorEnabled), errors.Is(err, model.ErrTwoFactorNotEnabled):
+		SendErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, model.ErrTwoFactorRequired):
+		SendErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, model.ErrInvalidInput):
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		SendErrorResponse(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,186 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func setupTwoFactorTestServer(t *testing.T) (*httptest.Server, *mocks.TwoFactorService) {
+	r := chi.NewRouter()
+
+	mockService := mocks.NewTwoFactorService(t)
+	cookieStore := sessions.NewCookieStore([]byte("test-secret"))
+	m := middleware.NewMiddleware(cookieStore, mocks.NewUserService(t), mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+
+	twoFactorHandler := handler.NewTwoFactorHandler(mockService)
+
+	r.Route("/self/2fa", func(r chi.Router) {
+		r.Use(m.MockAuthMiddleware)
+		r.Get("/", twoFactorHandler.Status)
+		r.Delete("/", twoFactorHandler.Disable)
+		r.Post("/enrol", twoFactorHandler.Enrol)
+		r.Post("/confirm", twoFactorHandler.Confirm)
+		r.Post("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+	})
+
+	return httptest.NewServer(r), mockService
+}
+
+func TestTwoFactorHandler_Enrolment(t *testing.T) {
+	server, mockService := setupTwoFactorTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+
+	t.Run("Enrol", func(t *testing.T) {
+		mockService.On("BeginEnrolment", mock.Anything, userID.String()).
+			Return("SECRET", "otpauth://totp/Mirai%20Box:alice?secret=SECRET", nil).Once()
+
+		req, _ := http.NewRequest("POST", server.URL+"/self/2fa/enrol", nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response model.TwoFactorEnrolmentResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, "SECRET", response.Secret)
+		assert.Contains(t, response.URI, "otpauth://totp/")
+	})
+
+	t.Run("Enrol when enabled", func(t *testing.T) {
+		mockService.On("BeginEnrolment", mock.Anything, userID.String()).
+			Return("", "", model.ErrTwoFactorEnabled).Once()
+
+		req, _ := http.NewRequest("POST", server.URL+"/self/2fa/enrol", nil)
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		codes := []string{"code1", "code2"}
+		mockService.On("ConfirmEnrolment", mock.Anything, userID.String(), "123456").Return(codes, nil).Once()
+
+		body, _ := json.Marshal(model.TwoFactorCodeRequest{Code: "123456"})
+		req, _ := http.NewRequest("POST", server.URL+"/self/2fa/confirm", bytes.NewBuffer(body))
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response model.RecoveryCodesResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, codes, response.RecoveryCodes)
+	})
+
+	t.Run("Confirm with wrong code", func(t *testing.T) {
+		mockService.On("ConfirmEnrolment", mock.Anything, userID.String(), "000000").Return(nil, model.ErrInvalidOTP).Once()
+
+		body, _ := json.Marshal(model.TwoFactorCodeRequest{Code: "000000"})
+		req, _ := http.NewRequest("POST", server.URL+"/self/2fa/confirm", bytes.NewBuffer(body))
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Confirm without code", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", server.URL+"/self/2fa/confirm", bytes.NewBufferString(`{}`))
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestTwoFactorHandler_Status(t *testing.T) {
+	server, mockService := setupTwoFactorTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+	mockService.On("Status", mock.Anything, userID.String()).
+		Return(&model.TwoFactorStatus{Enabled: true, Required: true, RecoveryCodesLeft: 7}, nil).Once()
+
+	req, _ := http.NewRequest("GET", server.URL+"/self/2fa", nil)
+	req.Header.Set("X-User-ID", userID.String())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response model.TwoFactorStatusResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, model.TwoFactorStatusResponse{Enabled: true, Required: true, RecoveryCodesLeft: 7}, response)
+}
+
+func TestTwoFactorHandler_Disable(t *testing.T) {
+	server, mockService := setupTwoFactorTestServer(t)
+	defer server.Close()
+
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("Disable", mock.Anything, userID.String(), "123456").Return(nil).Once()
+
+		body, _ := json.Marshal(model.TwoFactorCodeRequest{Code: "123456"})
+		req, _ := http.NewRequest("DELETE", server.URL+"/self/2fa", bytes.NewBuffer(body))
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("Required by role", func(t *testing.T) {
+		mockService.On("Disable", mock.Anything, userID.String(), "123456").Return(model.ErrTwoFactorRequired).Once()
+
+		body, _ := json.Marshal(model.TwoFactorCodeRequest{Code: "123456"})
+		req, _ := http.NewRequest("DELETE", server.URL+"/self/2fa", bytes.NewBuffer(body))
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -3,14 +3,17 @@ package handler
 import (
 	"encoding/json"
 	"errors"
-	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/gorilla/sessions"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/middleware"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/service"
@@ -19,16 +22,28 @@ import (
 // use a single instance of Validate, it caches struct info
 var validate *validator.Validate
 
-type userRequest struct {
+// updateUserRequest is the user update payload, passwords are changed through /self/password.
+type updateUserRequest struct {
 	Username string `json:"username"  validate:"required"`
-	Password string `json:"password"  validate:"required"`
-	Role     string `json:"role"      validate:"required,oneof=user admin"`
+}
+
+// registerRequest is the self-registration payload, the role is never taken from the client.
+type registerRequest struct {
+	Username   string `json:"username"    validate:"required"`
+	Password   string `json:"password"    validate:"required"`
+	InviteCode string `json:"invite_code"`
 }
 
 // UserHandler handles HTTP requests related to user operations.
 type UserHandler struct {
-	userService service.UserService
-	store       *sessions.CookieStore
+	userService      service.UserService
+	sessionService   service.SessionService
+	loginThrottle    service.LoginThrottleService
+	twoFactorService service.TwoFactorService
+	store            sessions.Store
+	// sessionTTL is the lifetime of a login, rememberTTL of one that keeps the user signed in.
+	sessionTTL  time.Duration
+	rememberTTL time.Duration
 }
 
 func init() {
@@ -36,19 +51,31 @@ func init() {
 }
 
 // NewUserHandler creates a new instance of UserHandler.
-func NewUserHandler(userService service.UserService, cookieStore *sessions.CookieStore) *UserHandler {
+func NewUserHandler(
+	userService service.UserService,
+	sessionService service.SessionService,
+	loginThrottle service.LoginThrottleService,
+	twoFactorService service.TwoFactorService,
+	store sessions.Store,
+	sessionTTL, rememberTTL time.Duration,
+) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		store:       cookieStore,
+		userService:      userService,
+		sessionService:   sessionService,
+		loginThrottle:    loginThrottle,
+		twoFactorService: twoFactorService,
+		store:            store,
+		sessionTTL:       sessionTTL,
+		rememberTTL:      rememberTTL,
 	}
 }
 
-// CreateUser handles the creation of a new user.
+// CreateUser handles self-registration of a new user.
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "CreateUser")
+	logger := logctx.With(r.Context(), "handler", "CreateUser")
 
-	createUserRequest := userRequest{}
+	createUserRequest := registerRequest{}
 	if err := json.NewDecoder(r.Body).Decode(&createUserRequest); err != nil {
 		logger.Error("Failed to decode user json", "error", err)
 		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
@@ -61,15 +88,21 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdUser, err := h.userService.CreateUser(ctx, createUserRequest.Username, createUserRequest.Password, createUserRequest.Role)
+	createdUser, err := h.userService.Register(ctx, createUserRequest.Username, createUserRequest.Password, createUserRequest.InviteCode)
 	if err != nil {
-		if errors.Is(err, model.ErrDuplicateUsername) {
+		logger.Error("Failed to create user", "error", err, "username", createUserRequest.Username)
+		switch {
+		case errors.Is(err, model.ErrDuplicateUsername):
 			SendErrorResponse(w, http.StatusConflict, "Username already exists")
-			return
+		case errors.Is(err, model.ErrRegistrationClosed):
+			SendErrorResponse(w, http.StatusForbidden, "Registration is closed")
+		case errors.Is(err, model.ErrInvalidInviteCode):
+			SendErrorResponse(w, http.StatusForbidden, "Invalid invite code")
+		case errors.Is(err, model.ErrWeakPassword):
+			SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Failed to create user")
 		}
-
-		logger.Error("Failed to create user", "error", err, "username", createUserRequest.Username)
-		SendErrorResponse(w, http.StatusInternalServerError, "Failed to create user")
 		return
 	}
 
@@ -80,7 +113,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 // Login handles user authentication and session creation.
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "Login")
+	logger := logctx.With(r.Context(), "handler", "Login")
 
 	loginRequest := model.LoginRequest{}
 
@@ -96,13 +129,144 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ip := middleware.ClientIP(r)
+	if !h.allowLogin(w, r, loginRequest.Username, ip) {
+		return
+	}
+
 	user, err := h.userService.Authenticate(ctx, loginRequest.Username, loginRequest.Password)
 	if err != nil {
-		logger.Warn("Authentication failed", "error", err, "username", loginRequest.Username)
+		logger.Warn("Authentication failed", "error", err, "username", loginRequest.Username, "ip", ip)
+		if errors.Is(err, model.ErrAccountDisabled) {
+			SendErrorResponse(w, http.StatusForbidden, "Account is disabled")
+			return
+		}
+		if errors.Is(err, model.ErrInvalidCredentials) {
+			if err := h.loginThrottle.RecordFailure(ctx, loginRequest.Username, ip); err != nil {
+				logger.Error("Failed to record login failure", "error", err)
+			}
+		}
 		SendErrorResponse(w, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
+	h.beginSession(w, r, user, loginRequest.KeepSignedIn)
+}
+
+// beginSession continues the login of a user whose first factor is verified, either
+// with a two-factor challenge or by starting the session right away.
+func (h *UserHandler) beginSession(w http.ResponseWriter, r *http.Request, user *model.User, keepSignedIn bool) {
+	logger := logctx.With(r.Context(), "handler", "Login", "userID", user.ID)
+
+	// The failures of the username are only cleared once the second factor is verified,
+	// otherwise a known password would allow unlimited guesses of the code.
+	if user.TOTPEnabled {
+		challenge, expiresAt, err := h.twoFactorService.CreateChallenge(r.Context(), user.ID.String(), keepSignedIn)
+		if err != nil {
+			logger.Error("Failed to create login challenge", "error", err)
+			SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		logger.Info("First factor verified, second factor required")
+		SendJSONResponse(w, http.StatusAccepted, model.LoginChallengeResponse{
+			TwoFactorRequired: true,
+			Challenge:         challenge,
+			ExpiresAt:         expiresAt,
+		})
+		return
+	}
+
+	h.completeLogin(w, r, user, keepSignedIn)
+}
+
+// LoginTwoFactor completes a login challenge with a TOTP or recovery code.
+func (h *UserHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "LoginTwoFactor")
+
+	var req model.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error("Failed to decode request body", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		logger.Error("Invalid input data", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	challenge, err := h.twoFactorService.FindChallenge(ctx, req.Challenge)
+	if err != nil {
+		logger.Warn("Login challenge not found", "error", err)
+		if errors.Is(err, model.ErrChallengeNotFound) {
+			SendErrorResponse(w, http.StatusUnauthorized, "Login challenge expired, sign in again")
+			return
+		}
+		SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	username := challenge.User.Username
+	ip := middleware.ClientIP(r)
+	if !h.allowLogin(w, r, username, ip) {
+		return
+	}
+
+	if err := h.twoFactorService.CompleteChallenge(ctx, challenge, req.Code); err != nil {
+		logger.Warn("Second factor failed", "error", err, "username", username, "ip", ip)
+		switch {
+		case errors.Is(err, model.ErrAccountDisabled):
+			SendErrorResponse(w, http.StatusForbidden, "Account is disabled")
+		case errors.Is(err, model.ErrChallengeNotFound):
+			SendErrorResponse(w, http.StatusUnauthorized, "Login challenge expired, sign in again")
+		case errors.Is(err, model.ErrInvalidOTP):
+			if err := h.loginThrottle.RecordFailure(ctx, username, ip); err != nil {
+				logger.Error("Failed to record login failure", "error", err)
+			}
+			SendErrorResponse(w, http.StatusUnauthorized, "Invalid two-factor code")
+		default:
+			SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	h.completeLogin(w, r, &challenge.User, challenge.KeepSignedIn)
+}
+
+// allowLogin checks the login throttle and answers with 429 and Retry-After when the
+// username or the client IP is blocked.
+func (h *UserHandler) allowLogin(w http.ResponseWriter, r *http.Request, username, ip string) bool {
+	logger := logctx.With(r.Context(), "handler", "Login", "username", username, "ip", ip)
+
+	retryAfter, err := h.loginThrottle.Check(r.Context(), username, ip)
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, model.ErrTooManyAttempts) {
+		logger.Warn("Login throttled", "retryAfter", retryAfter)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		SendErrorResponse(w, http.StatusTooManyRequests, "Too many failed login attempts, try again later")
+		return false
+	}
+
+	logger.Error("Failed to check login attempts", "error", err)
+	SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+	return false
+}
+
+// completeLogin starts the session of a fully authenticated user.
+func (h *UserHandler) completeLogin(w http.ResponseWriter, r *http.Request, user *model.User, keepSignedIn bool) {
+	ctx := r.Context()
+	logger := logctx.With(r.Context(), "handler", "Login", "userID", user.ID)
+
+	if err := h.loginThrottle.RecordSuccess(ctx, user.Username); err != nil {
+		logger.Error("Failed to reset login attempts", "error", err)
+	}
+
 	session, err := h.store.Get(r, model.SessionCookieName)
 	if err != nil {
 		logger.Error("Failed to get session", "error", err)
@@ -110,8 +274,27 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Start a fresh session on every login so a token known before the login stays anonymous.
+	if session.ID != "" {
+		if err := h.sessionService.RevokeToken(ctx, session.ID); err != nil {
+			logger.Error("Failed to revoke previous session", "error", err)
+			SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		session.ID = ""
+	}
+	session.Values = map[interface{}]interface{}{}
+
 	session.Values[model.SessionUserIDKey] = user.ID.String()
-	session.Options.MaxAge = h.getSessionMaxAge(loginRequest.KeepSignedIn)
+
+	csrfToken, err := service.GenerateToken(middleware.CSRFTokenBytes)
+	if err != nil {
+		logger.Error("Failed to generate CSRF token", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	session.Values[model.SessionCSRFKey] = csrfToken
+	session.Options.MaxAge = h.getSessionMaxAge(keepSignedIn)
 
 	if err := session.Save(r, w); err != nil {
 		logger.Error("Failed to save session", "error", err)
@@ -119,15 +302,39 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Info("User logged in", "userID", user.ID, "username", user.Username)
+	w.Header().Set(middleware.CSRFHeader, csrfToken)
+
+	logger.Info("User logged in", "username", user.Username)
 	SendJSONResponse(w, http.StatusOK, convertToUserResponse(user))
 }
 
+// Logout ends the current session and clears the session cookie.
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	logger := logctx.With(r.Context(), "handler", "Logout")
+
+	session, err := h.store.Get(r, model.SessionCookieName)
+	if err != nil {
+		logger.Error("Failed to get session", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	session.Options.MaxAge = -1
+	if err := session.Save(r, w); err != nil {
+		logger.Error("Failed to delete session", "error", err)
+		SendErrorResponse(w, http.StatusInternalServerError, "Failed to end session")
+		return
+	}
+
+	logger.Info("User logged out", "userID", session.Values[model.SessionUserIDKey])
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // GetUser retrieves user information.
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := chi.URLParam(r, "id")
-	logger := slog.With("handler", "GetUser", "userID", userID)
+	logger := logctx.With(r.Context(), "handler", "GetUser", "userID", userID)
 
 	requestingUser, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -136,8 +343,15 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Allow access if the requesting user is an admin or if they're requesting their own data
-	if requestingUser.Role != "admin" && requestingUser.ID.String() != userID {
+	targetUserID, err := uuid.Parse(userID)
+	if err != nil {
+		logger.Warn("Invalid user ID", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Allow access if the requesting user is a user admin or if they're requesting their own data
+	if !middleware.CanAccess(ctx, targetUserID, model.PermUsersAdmin) {
 		logger.Warn("Unauthorized attempt to view user data",
 			"requestingUserID", requestingUser.ID,
 			"requestingUserRole", requestingUser.Role,
@@ -146,7 +360,7 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.GetUser(ctx, userID)
+	user, err := h.userService.GetUser(ctx, targetUserID.String())
 	if err != nil {
 		logger.Error("Failed to find user", "error", err, "userID", userID)
 		SendErrorResponse(w, http.StatusNotFound, "User not found")
@@ -161,16 +375,16 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := chi.URLParam(r, "id")
-	logger := slog.With("handler", "UpdateUser", "userID", userID)
+	logger := logctx.With(r.Context(), "handler", "UpdateUser", "userID", userID)
 
-	updateUserRequest := userRequest{}
-	if err := json.NewDecoder(r.Body).Decode(&updateUserRequest); err != nil {
+	updateRequest := updateUserRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
 		logger.Error("Failed to decode update user request", "error", err)
 		SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if err := validate.Struct(updateUserRequest); err != nil {
+	if err := validate.Struct(updateRequest); err != nil {
 		logger.Error("Invalid input data", "error", err)
 		SendErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -183,8 +397,15 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Allow access if the requesting user is an admin or if they're requesting their own data
-	if requestingUser.Role != "admin" && requestingUser.ID.String() != userID {
+	targetUserID, err := uuid.Parse(userID)
+	if err != nil {
+		logger.Warn("Invalid user ID", "error", err)
+		SendErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Allow access if the requesting user is a user admin or if they're requesting their own data
+	if !middleware.CanAccess(ctx, targetUserID, model.PermUsersAdmin) {
 		logger.Warn("Unauthorized attempt to update user",
 			"requestingUserID", requestingUser.ID,
 			"requestingUserRole", requestingUser.Role,
@@ -193,18 +414,9 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionUserUUID, err := uuid.Parse(userID)
-	if err != nil {
-		logger.Error("Failed to parse UUID in the session", "error", err, "sessionUserID", sessionUserUUID)
-		SendErrorResponse(w, http.StatusInternalServerError, "Failed to update user")
-		return
-	}
-
 	updatedUser := &model.User{
-		ID:       sessionUserUUID,
-		Username: updateUserRequest.Username,
-		Password: updateUserRequest.Password,
-		Role:     updateUserRequest.Role,
+		ID:       targetUserID,
+		Username: updateRequest.Username,
 	}
 
 	if err := h.userService.UpdateUser(ctx, updatedUser); err != nil {
@@ -221,7 +433,7 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := chi.URLParam(r, "id")
-	logger := slog.With("handler", "DeleteUser", "targetUserID", userID)
+	logger := logctx.With(r.Context(), "handler", "DeleteUser", "targetUserID", userID)
 
 	sessionUser, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -230,7 +442,7 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if sessionUser.Role != "admin" {
+	if !middleware.HasPermission(ctx, model.PermUsersAdmin) {
 		logger.Warn("Unauthorized attempt to delete user", "sessionUserID", sessionUser.ID)
 		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
 		return
@@ -251,9 +463,9 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// LoginCheck verifies if the user is currently logged in.
+// LoginCheck verifies if the user is currently logged in and returns the CSRF token of the session.
 func (h *UserHandler) LoginCheck(w http.ResponseWriter, r *http.Request) {
-	logger := slog.With("handler", "LoginCheck")
+	logger := logctx.With(r.Context(), "handler", "LoginCheck")
 
 	session, err := h.store.Get(r, model.SessionCookieName)
 	if err != nil {
@@ -269,14 +481,16 @@ func (h *UserHandler) LoginCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	csrfToken, _ := session.Values[model.SessionCSRFKey].(string)
+
 	logger.Info("Auth check successful", "userID", userID)
-	SendJSONResponse(w, http.StatusOK, map[string]string{"status": "OK"})
+	SendJSONResponse(w, http.StatusOK, map[string]string{"status": "OK", "csrf_token": csrfToken})
 }
 
 // MyStash retrieves the stash information for the authenticated user.
 func (h *UserHandler) MyStash(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "MyStash")
+	logger := logctx.With(r.Context(), "handler", "MyStash")
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -300,9 +514,9 @@ func (h *UserHandler) MyStash(w http.ResponseWriter, r *http.Request) {
 
 func (h *UserHandler) getSessionMaxAge(keepSignedIn bool) int {
 	if keepSignedIn {
-		return 7 * 24 * 60 * 60 // 1 week
+		return int(h.rememberTTL.Seconds())
 	}
-	return 24 * 60 * 60 // 1 day
+	return int(h.sessionTTL.Seconds())
 }
 
 func convertToUserResponse(user *model.User) model.UserResponse {
@@ -310,8 +524,11 @@ func convertToUserResponse(user *model.User) model.UserResponse {
 		ID:        user.ID,
 		Username:  user.Username,
 		Role:      user.Role,
+		Disabled:  user.Disabled,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
+
+		TwoFactorEnabled: user.TOTPEnabled,
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -21,14 +22,23 @@ import (
 )
 
 func setupUserTestServer(t *testing.T) (*httptest.Server, *mocks.UserService) {
+	server, userMock, _, _ := setupLoginTestServer(t)
+	return server, userMock
+}
+
+func setupLoginTestServer(t *testing.T) (*httptest.Server, *mocks.UserService, *mocks.LoginThrottleService, *mocks.TwoFactorService) {
 	r := chi.NewRouter()
 
 	cookieStore := sessions.NewCookieStore([]byte("abc"))
 	userMock := mocks.NewUserService(t)
-	m := middleware.NewMiddleware(cookieStore, userMock)
-	userHandler := handler.NewUserHandler(userMock, cookieStore)
+	throttleMock := mocks.NewLoginThrottleService(t)
+	twoFactorMock := mocks.NewTwoFactorService(t)
+	m := middleware.NewMiddleware(cookieStore, userMock, mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+	userHandler := handler.NewUserHandler(userMock, mocks.NewSessionService(t), throttleMock, twoFactorMock, cookieStore, 24*time.Hour, 7*24*time.Hour)
 
 	r.Post("/login", userHandler.Login)
+	r.Post("/login/2fa", userHandler.LoginTwoFactor)
+	r.With(m.CSRFProtect).Post("/logout", userHandler.Logout)
 	r.Get("/login/check", userHandler.LoginCheck)
 
 	r.Route("/self", func(r chi.Router) {
@@ -46,7 +56,7 @@ func setupUserTestServer(t *testing.T) (*httptest.Server, *mocks.UserService) {
 		})
 	})
 
-	return httptest.NewServer(r), userMock
+	return httptest.NewServer(r), userMock, throttleMock, twoFactorMock
 }
 
 func TestUserHandler_CreateUser(t *testing.T) {
@@ -60,9 +70,10 @@ func TestUserHandler_CreateUser(t *testing.T) {
 			Role:     "user",
 		}
 
-		mockService.On("CreateUser", mock.Anything, "testuser", "password123", "user").Return(newUser, nil).Once()
+		// A client supplied role is ignored by self-registration.
+		mockService.On("Register", mock.Anything, "testuser", "password123", "").Return(newUser, nil).Once()
 
-		body := bytes.NewBufferString(`{"username":"testuser","password":"password123","role":"user"}`)
+		body := bytes.NewBufferString(`{"username":"testuser","password":"password123","role":"admin"}`)
 		req, _ := http.NewRequest("POST", server.URL+"/api/users", body)
 		req.Header.Set("Content-Type", "application/json")
 
@@ -77,12 +88,63 @@ func TestUserHandler_CreateUser(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, newUser.ID, response.ID)
 		assert.Equal(t, newUser.Username, response.Username)
+		assert.Equal(t, "user", response.Role)
 
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("With Invite Code", func(t *testing.T) {
+		newUser := &model.User{
+			ID:       uuid.New(),
+			Username: "testuser",
+			Role:     "user",
+		}
+
+		mockService.On("Register", mock.Anything, "testuser", "password123", "invite-code").Return(newUser, nil).Once()
+
+		body := bytes.NewBufferString(`{"username":"testuser","password":"password123","invite_code":"invite-code"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/api/users", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("Invalid Invite Code", func(t *testing.T) {
+		mockService.On("Register", mock.Anything, "testuser", "password123", "used-code").
+			Return(nil, fmt.Errorf("failed to create user: %w", model.ErrInvalidInviteCode)).Once()
+
+		body := bytes.NewBufferString(`{"username":"testuser","password":"password123","invite_code":"used-code"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/api/users", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Registration Closed", func(t *testing.T) {
+		mockService.On("Register", mock.Anything, "testuser", "password123", "").
+			Return(nil, model.ErrRegistrationClosed).Once()
+
+		body := bytes.NewBufferString(`{"username":"testuser","password":"password123"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/api/users", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
 	t.Run("Service Error", func(t *testing.T) {
-		mockService.On("CreateUser", mock.Anything, "testuser", "password123", "user").
+		mockService.On("Register", mock.Anything, "testuser", "password123", "").
 			Return(nil, fmt.Errorf("some error")).Once()
 
 		body := bytes.NewBufferString(`{"username":"testuser","password":"password123","role":"user"}`)
@@ -135,7 +197,7 @@ func TestUserHandler_CreateUser(t *testing.T) {
 }
 
 func TestUserHandler_Login(t *testing.T) {
-	server, mockService := setupUserTestServer(t)
+	server, mockService, throttleMock, twoFactorMock := setupLoginTestServer(t)
 	defer server.Close()
 
 	t.Run("Success", func(t *testing.T) {
@@ -145,8 +207,10 @@ func TestUserHandler_Login(t *testing.T) {
 			Role:     "user",
 		}
 
+		throttleMock.On("Check", mock.Anything, "testuser", "127.0.0.1").Return(time.Duration(0), nil).Once()
 		mockService.On("Authenticate", mock.Anything, "testuser", "password123").
 			Return(user, nil).Once()
+		throttleMock.On("RecordSuccess", mock.Anything, "testuser").Return(nil).Once()
 
 		body := bytes.NewBufferString(`{"username":"testuser","password":"password123","keepSignedIn":false}`)
 		req, err := http.NewRequest("POST", server.URL+"/login", body)
@@ -166,9 +230,87 @@ func TestUserHandler_Login(t *testing.T) {
 		assert.Equal(t, user.ID, response.ID)
 		assert.Equal(t, user.Username, response.Username)
 
+		csrfToken := resp.Header.Get(middleware.CSRFHeader)
+		assert.NotEmpty(t, csrfToken)
+
+		// The login check returns the same token to clients that reload.
+		req, err = http.NewRequest("GET", server.URL+"/login/check", nil)
+		require.NoError(t, err)
+		for _, cookie := range resp.Cookies() {
+			req.AddCookie(cookie)
+		}
+
+		checkResp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer checkResp.Body.Close()
+
+		var check map[string]string
+		require.NoError(t, json.NewDecoder(checkResp.Body).Decode(&check))
+		assert.Equal(t, csrfToken, check["csrf_token"])
+
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("Invalid credentials", func(t *testing.T) {
+		throttleMock.On("Check", mock.Anything, "nobody", "127.0.0.1").Return(time.Duration(0), nil).Once()
+		mockService.On("Authenticate", mock.Anything, "nobody", "password123").
+			Return(nil, model.ErrInvalidCredentials).Once()
+		throttleMock.On("RecordFailure", mock.Anything, "nobody", "127.0.0.1").Return(nil).Once()
+
+		body := bytes.NewBufferString(`{"username":"nobody","password":"password123"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/login", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Locked out", func(t *testing.T) {
+		throttleMock.On("Check", mock.Anything, "testuser", "127.0.0.1").
+			Return(90*time.Second+time.Millisecond, model.ErrTooManyAttempts).Once()
+
+		body := bytes.NewBufferString(`{"username":"testuser","password":"password123"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/login", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "91", resp.Header.Get("Retry-After"))
+	})
+
+	t.Run("Two-factor challenge", func(t *testing.T) {
+		user := &model.User{ID: uuid.New(), Username: "secure", TOTPEnabled: true}
+		expiresAt := time.Now().Add(5 * time.Minute).UTC()
+
+		throttleMock.On("Check", mock.Anything, "secure", "127.0.0.1").Return(time.Duration(0), nil).Once()
+		mockService.On("Authenticate", mock.Anything, "secure", "password123").Return(user, nil).Once()
+		twoFactorMock.On("CreateChallenge", mock.Anything, user.ID.String(), true).
+			Return("challenge-token", expiresAt, nil).Once()
+
+		body := bytes.NewBufferString(`{"username":"secure","password":"password123","keepSignedIn":true}`)
+		req, _ := http.NewRequest("POST", server.URL+"/login", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Empty(t, resp.Cookies(), "no session before the second factor")
+
+		var response model.LoginChallengeResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.True(t, response.TwoFactorRequired)
+		assert.Equal(t, "challenge-token", response.Challenge)
+	})
+
 	t.Run("Invalid  JSON", func(t *testing.T) {
 		body := bytes.NewBufferString(`{"username":"testuser","password":"password123"`)
 		req, _ := http.NewRequest("POST", server.URL+"/login", body)
@@ -184,6 +326,126 @@ func TestUserHandler_Login(t *testing.T) {
 	})
 }
 
+func TestUserHandler_LoginTwoFactor(t *testing.T) {
+	server, _, throttleMock, twoFactorMock := setupLoginTestServer(t)
+	defer server.Close()
+
+	challenge := &model.LoginChallenge{
+		ID:     uuid.New(),
+		UserID: uuid.New(),
+		User:   model.User{Username: "secure", TOTPEnabled: true},
+	}
+	challenge.User.ID = challenge.UserID
+
+	post := func(t *testing.T, body string) *http.Response {
+		req, _ := http.NewRequest("POST", server.URL+"/login/2fa", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		twoFactorMock.On("FindChallenge", mock.Anything, "token").Return(challenge, nil).Once()
+		throttleMock.On("Check", mock.Anything, "secure", "127.0.0.1").Return(time.Duration(0), nil).Once()
+		twoFactorMock.On("CompleteChallenge", mock.Anything, challenge, "123456").Return(nil).Once()
+		throttleMock.On("RecordSuccess", mock.Anything, "secure").Return(nil).Once()
+
+		resp := post(t, `{"challenge":"token","code":"123456"}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response model.UserResponse
+		err := json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, challenge.UserID, response.ID)
+		assert.True(t, response.TwoFactorEnabled)
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		twoFactorMock.On("FindChallenge", mock.Anything, "token").Return(challenge, nil).Once()
+		throttleMock.On("Check", mock.Anything, "secure", "127.0.0.1").Return(time.Duration(0), nil).Once()
+		twoFactorMock.On("CompleteChallenge", mock.Anything, challenge, "000000").Return(model.ErrInvalidOTP).Once()
+		throttleMock.On("RecordFailure", mock.Anything, "secure", "127.0.0.1").Return(nil).Once()
+
+		resp := post(t, `{"challenge":"token","code":"000000"}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Expired challenge", func(t *testing.T) {
+		twoFactorMock.On("FindChallenge", mock.Anything, "old").Return(nil, model.ErrChallengeNotFound).Once()
+
+		resp := post(t, `{"challenge":"old","code":"123456"}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestUserHandler_Logout(t *testing.T) {
+	server, _ := setupUserTestServer(t)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/logout", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	var cleared bool
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == model.SessionCookieName {
+			cleared = cookie.MaxAge < 0
+		}
+	}
+	assert.True(t, cleared, "session cookie is cleared")
+}
+
+func TestUserHandler_LogoutCSRF(t *testing.T) {
+	server, mockService, throttleMock, _ := setupLoginTestServer(t)
+	defer server.Close()
+
+	user := &model.User{ID: uuid.New(), Username: "testuser", Role: "user"}
+	throttleMock.On("Check", mock.Anything, "testuser", "127.0.0.1").Return(time.Duration(0), nil).Once()
+	mockService.On("Authenticate", mock.Anything, "testuser", "password123").Return(user, nil).Once()
+	throttleMock.On("RecordSuccess", mock.Anything, "testuser").Return(nil).Once()
+
+	body := bytes.NewBufferString(`{"username":"testuser","password":"password123"}`)
+	resp, err := http.Post(server.URL+"/login", "application/json", body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	cookies := resp.Cookies()
+	csrfToken := resp.Header.Get(middleware.CSRFHeader)
+
+	logout := func(token string) *http.Response {
+		req, err := http.NewRequest("POST", server.URL+"/logout", nil)
+		require.NoError(t, err)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		if token != "" {
+			req.Header.Set(middleware.CSRFHeader, token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp = logout("")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "a cross-site form cannot sign the user out")
+	assert.Empty(t, resp.Cookies(), "session cookie is kept")
+
+	resp = logout(csrfToken)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
 func TestUserHandler_MyStash(t *testing.T) {
 	server, mockService := setupUserTestServer(t)
 	defer server.Close()
@@ -296,10 +558,15 @@ func TestUserHandler_UpdateUser(t *testing.T) {
 		updatedUser := &model.User{
 			ID:       userID,
 			Username: "updateduser",
-			Role:     "admin",
+			Role:     "user",
 		}
 
-		mockService.On("UpdateUser", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil).Once()
+		// The requested role is ignored, the response carries the stored role.
+		mockService.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return u.Role == ""
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*model.User).Role = "user"
+		}).Return(nil).Once()
 
 		body := bytes.NewBufferString(`{"username":"updateduser","password":"newpassword","role":"admin"}`)
 		req, err := http.NewRequest("PUT", fmt.Sprintf("%s/api/users/%s", server.URL, userID.String()), body)
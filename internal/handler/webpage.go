@@ -3,12 +3,12 @@ package handler
 import (
 	"encoding/json"
 	"errors"
-	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/middleware"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/service"
@@ -27,7 +27,7 @@ func NewWebPageHandler(webPageService service.WebPageService) *WebPageHandler {
 // CreateWebPage handles the creation of a new web page.
 func (h *WebPageHandler) CreateWebPage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "CreateWebPage")
+	logger := logctx.With(r.Context(), "handler", "CreateWebPage")
 
 	var webPageRequest model.WebPage
 	if err := json.NewDecoder(r.Body).Decode(&webPageRequest); err != nil {
@@ -48,8 +48,19 @@ func (h *WebPageHandler) CreateWebPage(w http.ResponseWriter, r *http.Request) {
 
 	webPageRequest.UserID = user.ID
 
+	if webPageRequest.Public && !middleware.HasPermission(ctx, model.PermPagesPublish) {
+		logger.Warn("User is not allowed to publish web pages")
+		SendErrorResponse(w, http.StatusForbidden, "You don't have permission to publish web pages")
+		return
+	}
+
 	createdWebPage, err := h.webPageService.CreateWebPage(ctx, &webPageRequest)
 	if err != nil {
+		if errors.Is(err, model.ErrInvalidInput) {
+			logger.Warn("Invalid web page", "error", err)
+			SendErrorResponse(w, http.StatusBadRequest, "Invalid web page format")
+			return
+		}
 		logger.Error("Failed to create web page", "error", err)
 		SendErrorResponse(w, http.StatusInternalServerError, "Failed to create web page")
 		return
@@ -64,7 +75,7 @@ func (h *WebPageHandler) GetWebPage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	webPageID := chi.URLParam(r, "id")
 
-	logger := slog.With("handler", "GetWebPage", "webPageID", webPageID)
+	logger := logctx.With(r.Context(), "handler", "GetWebPage", "webPageID", webPageID)
 
 	webPage, err := h.webPageService.GetWebPage(ctx, webPageID)
 	if err != nil {
@@ -86,7 +97,7 @@ func (h *WebPageHandler) GetWebPage(w http.ResponseWriter, r *http.Request) {
 func (h *WebPageHandler) UpdateWebPage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	webPageID := chi.URLParam(r, "id")
-	logger := slog.With("handler", "UpdateWebPage", "webPageID", webPageID)
+	logger := logctx.With(r.Context(), "handler", "UpdateWebPage", "webPageID", webPageID)
 
 	var updatedWebPage model.WebPage
 	if err := json.NewDecoder(r.Body).Decode(&updatedWebPage); err != nil {
@@ -115,7 +126,7 @@ func (h *WebPageHandler) UpdateWebPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if existingWebPage.UserID != user.ID {
+	if !middleware.CanAccess(ctx, existingWebPage.UserID) {
 		logger.Error("User is not the owner of the web page", "existingWebPage.UserID", existingWebPage.UserID, "userID", user.ID)
 		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
 		return
@@ -123,8 +134,19 @@ func (h *WebPageHandler) UpdateWebPage(w http.ResponseWriter, r *http.Request) {
 
 	updatedWebPage = mergeWebPageData(*existingWebPage, updatedWebPage)
 
+	if updatedWebPage.Public && !existingWebPage.Public && !middleware.HasPermission(ctx, model.PermPagesPublish) {
+		logger.Warn("User is not allowed to publish web pages", "userID", user.ID)
+		SendErrorResponse(w, http.StatusForbidden, "You don't have permission to publish web pages")
+		return
+	}
+
 	webPage, err := h.webPageService.UpdateWebPage(ctx, &updatedWebPage)
 	if err != nil {
+		if errors.Is(err, model.ErrInvalidInput) {
+			logger.Warn("Invalid web page", "error", err)
+			SendErrorResponse(w, http.StatusBadRequest, "Invalid web page format")
+			return
+		}
 		logger.Error("Failed to update web page", "error", err, "webPageID", webPageID)
 		SendErrorResponse(w, http.StatusInternalServerError, "Failed to update web page")
 		return
@@ -137,7 +159,7 @@ func (h *WebPageHandler) UpdateWebPage(w http.ResponseWriter, r *http.Request) {
 // DeleteWebPage handles the deletion of a web page.
 func (h *WebPageHandler) DeleteWebPage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "DeleteWebPage")
+	logger := logctx.With(r.Context(), "handler", "DeleteWebPage")
 
 	id := chi.URLParam(r, "id")
 	webPageID, err := uuid.Parse(id)
@@ -166,7 +188,7 @@ func (h *WebPageHandler) DeleteWebPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if existingWebPage.UserID != user.ID {
+	if !middleware.CanAccess(ctx, existingWebPage.UserID) {
 		logger.Error("User is not the owner of the web page", "existingWebPage.UserID", existingWebPage.UserID, "userID", user.ID)
 		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
 		return
@@ -185,7 +207,7 @@ func (h *WebPageHandler) DeleteWebPage(w http.ResponseWriter, r *http.Request) {
 // ListWebPages handles listing all web pages.
 func (h *WebPageHandler) ListWebPages(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "ListWebPages")
+	logger := logctx.With(r.Context(), "handler", "ListWebPages")
 
 	webPages, err := h.webPageService.ListWebPages(ctx)
 	if err != nil {
@@ -211,7 +233,7 @@ func (h *WebPageHandler) ListWebPages(w http.ResponseWriter, r *http.Request) {
 // ListUserWebPages handles listing web pages for a specific user.
 func (h *WebPageHandler) ListUserWebPages(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "ListUserWebPages")
+	logger := logctx.With(r.Context(), "handler", "ListUserWebPages")
 
 	userID := chi.URLParam(r, "userId")
 	userUUID, err := uuid.Parse(userID)
@@ -245,7 +267,7 @@ func (h *WebPageHandler) ListUserWebPages(w http.ResponseWriter, r *http.Request
 // MyWebPages handles listing web pages for the authenticated user.
 func (h *WebPageHandler) MyWebPages(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "MyWebPages")
+	logger := logctx.With(r.Context(), "handler", "MyWebPages")
 
 	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
@@ -278,7 +300,7 @@ func (h *WebPageHandler) MyWebPages(w http.ResponseWriter, r *http.Request) {
 // MyWebPageByID handles retrieving a specific web page for the authenticated user.
 func (h *WebPageHandler) MyWebPageByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := slog.With("handler", "MyWebPageByID")
+	logger := logctx.With(r.Context(), "handler", "MyWebPageByID")
 
 	id := chi.URLParam(r, "id")
 	webPageID, err := uuid.Parse(id)
@@ -307,7 +329,7 @@ func (h *WebPageHandler) MyWebPageByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if webPage.UserID != user.ID {
+	if !middleware.CanAccess(ctx, webPage.UserID) {
 		logger.Warn("User attempted to access unauthorized web page", "webPageID", webPageID, "userID", user.ID)
 		SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
 		return
@@ -326,6 +348,12 @@ func mergeWebPageData(existing, updated model.WebPage) model.WebPage {
 	if updated.Html != "" {
 		existing.Html = updated.Html
 	}
+	if updated.Format != "" {
+		existing.Format = updated.Format
+	}
+	if updated.Source != "" {
+		existing.Source = updated.Source
+	}
 	if updated.PageType != "" {
 		existing.PageType = updated.PageType
 	}
@@ -339,6 +367,8 @@ func convertToWebPageResponse(webPage *model.WebPage) model.WebPageResponse {
 		UserID:    webPage.UserID,
 		Title:     webPage.Title,
 		Html:      webPage.Html,
+		Format:    webPage.Format,
+		Source:    webPage.Source,
 		PageType:  webPage.PageType,
 		Public:    webPage.Public,
 		CreatedAt: webPage.CreatedAt,
@@ -27,7 +27,7 @@ func setupWebPageTestServer(t *testing.T) (*httptest.Server, *mocks.WebPageServi
 	mockService := mocks.NewWebPageService(t)
 	cookieStore := sessions.NewCookieStore([]byte("abc"))
 	userMock := mocks.NewUserService(t)
-	m := middleware.NewMiddleware(cookieStore, userMock)
+	m := middleware.NewMiddleware(cookieStore, userMock, mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
 
 	webPageHandler := handler.NewWebPageHandler(mockService)
 
@@ -140,6 +140,63 @@ func TestWebPageHandler_CreateWebPage(t *testing.T) {
 		assert.Equal(t, "Failed to create web page", response.Message)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("Markdown page", func(t *testing.T) {
+		server, mockService := setupWebPageTestServer(t)
+		defer server.Close()
+
+		userID := uuid.New()
+		webPage := &model.WebPage{
+			ID:       uuid.New(),
+			UserID:   userID,
+			Title:    "About",
+			Format:   model.WebPageFormatMarkdown,
+			Source:   "# About",
+			Html:     "<h1 id=\"about\">About</h1>\n",
+			PageType: "main",
+		}
+
+		mockService.On("CreateWebPage", mock.Anything, mock.MatchedBy(func(wp *model.WebPage) bool {
+			return wp.Format == model.WebPageFormatMarkdown && wp.Source == "# About"
+		})).Return(webPage, nil)
+
+		body := bytes.NewBufferString(`{"title":"About","format":"markdown","source":"# About"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/self/webpages", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID.String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var response model.WebPageResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, model.WebPageFormatMarkdown, response.Format)
+		assert.Equal(t, webPage.Source, response.Source)
+		assert.Equal(t, webPage.Html, response.Html)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		server, mockService := setupWebPageTestServer(t)
+		defer server.Close()
+
+		mockService.On("CreateWebPage", mock.Anything, mock.AnythingOfType("*model.WebPage")).
+			Return(nil, fmt.Errorf("%w: unsupported web page format", model.ErrInvalidInput))
+
+		body := bytes.NewBufferString(`{"title":"About","format":"rst","source":"About"}`)
+		req, _ := http.NewRequest("POST", server.URL+"/self/webpages", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", uuid.New().String())
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
 }
 
 func TestWebPageHandler_GetWebPage(t *testing.T) {
@@ -0,0 +1,34 @@
+// Package logctx carries a request-scoped logger in a context, so that every layer
+// handling a request logs with the request ID, route and user of that request.
+package logctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries the logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// From returns the logger carried by ctx, or the default logger when there is none,
+// like in background jobs and the admin CLI.
+func From(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With returns the logger carried by ctx with the given attributes added.
+func With(ctx context.Context, args ...any) *slog.Logger {
+	return From(ctx).With(args...)
+}
+
+// WithAttrs returns a copy of ctx whose logger has the given attributes added.
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	return NewContext(ctx, With(ctx, args...))
+}
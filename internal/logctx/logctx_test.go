@@ -0,0 +1,29 @@
+package logctx_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+)
+
+func TestFromWithoutLogger(t *testing.T) {
+	assert.Same(t, slog.Default(), logctx.From(context.Background()))
+}
+
+func TestWithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	ctx := logctx.NewContext(context.Background(), logger)
+	ctx = logctx.WithAttrs(ctx, "request_id", "abc")
+	logctx.With(ctx, "method", "Test").Info("hello")
+
+	assert.Contains(t, buf.String(), "request_id=abc")
+	assert.Contains(t, buf.String(), "method=Test")
+	assert.Contains(t, buf.String(), "msg=hello")
+}
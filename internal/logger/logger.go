@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/mirai-box/mirai-box/internal/config"
+	"github.com/mirai-box/mirai-box/internal/tracing"
 )
 
 func Setup(conf *config.Config) {
@@ -20,6 +21,6 @@ func Setup(conf *config.Config) {
 		})
 	}
 
-	logger := slog.New(logHandler)
+	logger := slog.New(tracing.NewLogHandler(logHandler))
 	slog.SetDefault(logger)
 }
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// InstrumentArtProjects observes the size and duration of revision uploads of s.
+func (m *Metrics) InstrumentArtProjects(s service.ArtProjectService) service.ArtProjectService {
+	return &artProjectService{ArtProjectService: s, metrics: m}
+}
+
+// InstrumentStorage tracks the storage checks of s as background job runs.
+func (m *Metrics) InstrumentStorage(s service.StorageService) service.StorageService {
+	return &storageService{StorageService: s, metrics: m}
+}
+
+type artProjectService struct {
+	service.ArtProjectService
+	metrics *Metrics
+}
+
+func (s *artProjectService) AddRevision(ctx context.Context, revision *model.Revision, fileData io.Reader) error {
+	start := time.Now()
+
+	err := s.ArtProjectService.AddRevision(ctx, revision, fileData)
+
+	s.metrics.uploadDuration.WithLabelValues(result(err)).Observe(time.Since(start).Seconds())
+	if err == nil {
+		s.metrics.uploadSize.Observe(float64(revision.Size))
+	}
+	return err
+}
+
+type storageService struct {
+	service.StorageService
+	metrics *Metrics
+}
+
+func (s *storageService) Verify(ctx context.Context) (*model.StorageReport, error) {
+	done := s.metrics.observeJob("storage_verify")
+	report, err := s.StorageService.Verify(ctx)
+	done(err)
+	return report, err
+}
+
+func (s *storageService) Repair(ctx context.Context) (*model.StorageReport, error) {
+	done := s.metrics.observeJob("storage_repair")
+	report, err := s.StorageService.Repair(ctx)
+	done(err)
+	return report, err
+}
@@ -0,0 +1,197 @@
+// Package metrics exposes Prometheus metrics of the HTTP routes, uploads, stashes,
+// the database pool and background jobs.
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+const namespace = "miraibox"
+
+// stashScrapeTimeout bounds the query for the stash gauges during a scrape.
+const stashScrapeTimeout = 5 * time.Second
+
+// Metrics holds the collectors of the service in its own registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	uploadSize      prometheus.Histogram
+	uploadDuration  *prometheus.HistogramVec
+	jobsRunning     *prometheus.GaugeVec
+	jobDuration     *prometheus.HistogramVec
+}
+
+// New creates the metrics with the Go runtime and process collectors registered.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		uploadSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_bytes",
+			Help:      "Size of stored revision uploads.",
+			Buckets:   prometheus.ExponentialBuckets(64<<10, 4, 8), // 64 KiB to 1 GiB
+		}),
+		uploadDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_duration_seconds",
+			Help:      "Time to store revision uploads by result.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8), // 10ms to 2.7m
+		}, []string{"result"}),
+		jobsRunning: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "background_jobs_running",
+			Help:      "Background jobs currently running by job.",
+		}, []string{"job"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "background_job_duration_seconds",
+			Help:      "Duration of background job runs by job and result.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 4, 8), // 100ms to 27m
+		}, []string{"job", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.uploadSize,
+		m.uploadDuration,
+		m.jobsRunning,
+		m.jobDuration,
+	)
+
+	return m
+}
+
+// RegisterDB adds the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// RegisterStashes adds a gauge of the used space of every stash, listed on each scrape.
+func (m *Metrics) RegisterStashes(list func(ctx context.Context) ([]model.Stash, error)) {
+	m.registry.MustRegister(&stashCollector{
+		list: list,
+		usedSpace: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "stash", "used_bytes"),
+			"Bytes stored in the stash of a user.",
+			[]string{"user_id"}, nil,
+		),
+	})
+}
+
+// Middleware counts requests and observes their latency by chi route pattern, so
+// paths with IDs share a series.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.requests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		m.requestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Handler serves the metrics. A non-empty token is required as bearer token.
+func (m *Metrics) Handler(token string) http.Handler {
+	metrics := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+	if token == "" {
+		return metrics
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			slog.Warn("Unauthorized metrics request", "handler", "Metrics", "remoteAddr", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		metrics.ServeHTTP(w, r)
+	})
+}
+
+// observeJob tracks a run of a background job.
+func (m *Metrics) observeJob(job string) func(err error) {
+	start := time.Now()
+	m.jobsRunning.WithLabelValues(job).Inc()
+
+	return func(err error) {
+		m.jobsRunning.WithLabelValues(job).Dec()
+		m.jobDuration.WithLabelValues(job, result(err)).Observe(time.Since(start).Seconds())
+	}
+}
+
+// result is the result label of an operation.
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// stashCollector reports the used space of the stashes when scraped.
+type stashCollector struct {
+	list      func(ctx context.Context) ([]model.Stash, error)
+	usedSpace *prometheus.Desc
+}
+
+func (c *stashCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.usedSpace
+}
+
+func (c *stashCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), stashScrapeTimeout)
+	defer cancel()
+
+	stashes, err := c.list(ctx)
+	if err != nil {
+		slog.Error("Failed to list stashes for metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.usedSpace, err)
+		return
+	}
+
+	for _, stash := range stashes {
+		ch <- prometheus.MustNewConstMetric(c.usedSpace, prometheus.GaugeValue, float64(stash.UsedSpace), stash.UserID.String())
+	}
+}
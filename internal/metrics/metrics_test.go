@@ -0,0 +1,109 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/metrics"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+// scrape returns the exposition of the metrics handler.
+func scrape(t *testing.T, handler http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestMiddleware_RoutePatterns(t *testing.T) {
+	m := metrics.New()
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/art/{artID}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/art/a1", "/art/b2", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, m.Handler(""), "").Body.String()
+	assert.Contains(t, body, `miraibox_http_requests_total{method="GET",route="/art/{artID}",status="404"} 2`)
+	assert.Contains(t, body, `miraibox_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `miraibox_http_request_duration_seconds_count{method="GET",route="/art/{artID}"} 2`)
+	assert.NotContains(t, body, "/art/a1")
+}
+
+func TestHandler_Token(t *testing.T) {
+	handler := metrics.New().Handler("scrape-token")
+
+	assert.Equal(t, http.StatusUnauthorized, scrape(t, handler, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, scrape(t, handler, "other").Code)
+	assert.Equal(t, http.StatusOK, scrape(t, handler, "scrape-token").Code)
+}
+
+func TestInstrumentArtProjects(t *testing.T) {
+	m := metrics.New()
+	mockService := mocks.NewArtProjectService(t)
+	s := m.InstrumentArtProjects(mockService)
+
+	mockService.On("AddRevision", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.Revision).Size = 1024
+	}).Return(nil).Once()
+	mockService.On("AddRevision", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("disk full")).Once()
+
+	require.NoError(t, s.AddRevision(context.Background(), &model.Revision{}, strings.NewReader("picture")))
+	require.Error(t, s.AddRevision(context.Background(), &model.Revision{}, strings.NewReader("picture")))
+
+	body := scrape(t, m.Handler(""), "").Body.String()
+	assert.Contains(t, body, "miraibox_upload_bytes_sum 1024")
+	assert.Contains(t, body, "miraibox_upload_bytes_count 1")
+	assert.Contains(t, body, `miraibox_upload_duration_seconds_count{result="ok"} 1`)
+	assert.Contains(t, body, `miraibox_upload_duration_seconds_count{result="error"} 1`)
+}
+
+func TestInstrumentStorage(t *testing.T) {
+	m := metrics.New()
+	mockService := mocks.NewStorageService(t)
+	s := m.InstrumentStorage(mockService)
+
+	mockService.On("Verify", mock.Anything).Run(func(mock.Arguments) {
+		body := scrape(t, m.Handler(""), "").Body.String()
+		assert.Contains(t, body, `miraibox_background_jobs_running{job="storage_verify"} 1`)
+	}).Return(&model.StorageReport{}, nil).Once()
+
+	_, err := s.Verify(context.Background())
+	require.NoError(t, err)
+
+	body := scrape(t, m.Handler(""), "").Body.String()
+	assert.Contains(t, body, `miraibox_background_jobs_running{job="storage_verify"} 0`)
+	assert.Contains(t, body, `miraibox_background_job_duration_seconds_count{job="storage_verify",result="ok"} 1`)
+}
+
+func TestRegisterStashes(t *testing.T) {
+	m := metrics.New()
+	userID := uuid.New()
+	m.RegisterStashes(func(ctx context.Context) ([]model.Stash, error) {
+		return []model.Stash{{UserID: userID, UsedSpace: 4096}}, nil
+	})
+
+	body := scrape(t, m.Handler(""), "").Body.String()
+	assert.Contains(t, body, `miraibox_stash_used_bytes{user_id="`+userID.String()+`"} 4096`)
+}
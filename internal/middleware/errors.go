@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// WriteError answers with a JSON error response that carries the request ID set by the
+// RequestID middleware, so that reported errors can be found in the logs.
+func WriteError(w http.ResponseWriter, status int, message string) {
+	requestID := w.Header().Get(RequestIDHeader)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(model.ErrorResponse{
+		Status:    status,
+		Message:   message,
+		RequestID: requestID,
+	})
+	if err != nil {
+		slog.Error("Error encoding JSON error response", "error", err, "request_id", requestID)
+	}
+}
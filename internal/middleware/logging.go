@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// LogRequests logs requests with the chi request logger, except requests to the quiet
+// paths like probes, which are polled often and would drown the other requests.
+func LogRequests(quiet ...string) func(http.Handler) http.Handler {
+	skip := make(map[string]struct{}, len(quiet))
+	for _, path := range quiet {
+		skip[path] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		logged := chimiddleware.Logger(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := skip[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			logged.ServeHTTP(w, r)
+		})
+	}
+}
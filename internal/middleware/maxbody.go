@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+)
+
+// MaxBodySize rejects requests with a body larger than limit bytes with 413, a limit
+// of 0 allows any size. Bodies without a Content-Length are cut off at the limit, so
+// reading past it fails with an *http.MaxBytesError.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				logctx.From(r.Context()).Warn("MaxBodySize: request body too large", "size", r.ContentLength, "limit", limit)
+				WriteError(w, http.StatusRequestEntityTooLarge, "Request Entity Too Large")
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
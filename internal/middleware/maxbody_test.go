@@ -0,0 +1,52 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirai-box/mirai-box/internal/middleware"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	read := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			var tooLarge *http.MaxBytesError
+			assert.True(t, errors.As(err, &tooLarge))
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name    string
+		limit   int64
+		body    string
+		chunked bool
+		want    int
+	}{
+		{name: "within limit", limit: 5, body: "12345", want: http.StatusOK},
+		{name: "content length over limit", limit: 5, body: "123456", want: http.StatusRequestEntityTooLarge},
+		{name: "chunked body over limit", limit: 5, body: "123456", chunked: true, want: http.StatusRequestEntityTooLarge},
+		{name: "no limit", limit: 0, body: "123456", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/self/artprojects", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rr := httptest.NewRecorder()
+			middleware.MaxBodySize(tt.limit)(read).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+		})
+	}
+}
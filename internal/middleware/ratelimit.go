@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// RateLimitHeaders are the response headers of the rate limit, they follow the IETF
+// draft for RateLimit header fields.
+var RateLimitHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"}
+
+// RateLimit limits the requests of the route class per authenticated user, and per
+// client IP for anonymous requests, so it must run after AuthMiddleware on
+// authenticated routes. Requests pass when the limiter fails, an outage of its store
+// should not take the service down.
+func RateLimit(limiter service.RateLimitService, class string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := model.RateLimitIPKey(class, ClientIP(r))
+			if user, ok := GetUserFromContext(r.Context()); ok {
+				key = model.RateLimitUserKey(class, user.ID.String())
+			}
+
+			result, err := limiter.Allow(r.Context(), class, key)
+			if err != nil {
+				logctx.From(r.Context()).Error("RateLimit: failed to check rate limit", "error", err, "class", class)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if result == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(result.Limit.Requests))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", result.Limit.Requests, ceilSeconds(result.Limit.Period)))
+
+			if !result.Allowed {
+				h.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				WriteError(w, http.StatusTooManyRequests, "Too Many Requests")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds formats d as whole seconds, rounded up so clients don't retry too early.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,81 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func TestRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	limit := model.RateLimit{Requests: 10, Period: time.Minute}
+
+	t.Run("anonymous requests are limited per IP", func(t *testing.T) {
+		limiter := mocks.NewRateLimitService(t)
+		limiter.On("Allow", mock.Anything, model.RateLimitAuth, "auth:ip:192.0.2.1").
+			Return(&model.RateLimitResult{Allowed: true, Limit: limit, Remaining: 9, Reset: 6 * time.Second}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "192.0.2.1:4321"
+		rr := httptest.NewRecorder()
+		middleware.RateLimit(limiter, model.RateLimitAuth)(ok).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "10", rr.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "9", rr.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "6", rr.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "10;w=60", rr.Header().Get("RateLimit-Policy"))
+		assert.Empty(t, rr.Header().Get("Retry-After"))
+	})
+
+	t.Run("authenticated requests are limited per user", func(t *testing.T) {
+		userID := uuid.New()
+		limiter := mocks.NewRateLimitService(t)
+		limiter.On("Allow", mock.Anything, model.RateLimitUpload, "upload:user:"+userID.String()).
+			Return(&model.RateLimitResult{Limit: limit, RetryAfter: 5500 * time.Millisecond, Reset: time.Minute}, nil)
+
+		m := middleware.NewMiddleware(sessions.NewCookieStore([]byte("test-secret")), mocks.NewUserService(t), mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+		req := httptest.NewRequest(http.MethodPost, "/self/artprojects", nil)
+		req.Header.Set("X-User-ID", userID.String())
+		rr := httptest.NewRecorder()
+		m.MockAuthMiddleware(middleware.RateLimit(limiter, model.RateLimitUpload)(ok)).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "6", rr.Header().Get("Retry-After"))
+		assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("unlimited class passes without headers", func(t *testing.T) {
+		limiter := mocks.NewRateLimitService(t)
+		limiter.On("Allow", mock.Anything, model.RateLimitAPI, mock.Anything).Return(nil, nil)
+
+		rr := httptest.NewRecorder()
+		middleware.RateLimit(limiter, model.RateLimitAPI)(ok).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+	})
+
+	t.Run("failing limiter lets requests pass", func(t *testing.T) {
+		limiter := mocks.NewRateLimitService(t)
+		limiter.On("Allow", mock.Anything, model.RateLimitDownload, mock.Anything).Return(nil, errors.New("database is down"))
+
+		rr := httptest.NewRecorder()
+		middleware.RateLimit(limiter, model.RateLimitDownload)(ok).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/art/1", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+)
+
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs taken from clients, which end up in every
+// log line of the request.
+const maxRequestIDLength = 128
+
+// RequestID assigns the request an ID, taken from the X-Request-ID header when it is
+// valid and generated otherwise, and returns it in the response header. The request
+// gets a logger with the request ID and route that the handlers, services and
+// repositories log through.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), chimiddleware.RequestIDKey, id)
+		logger := slog.New(routeHandler{
+			Handler: logctx.From(ctx).Handler(),
+			rctx:    chi.RouteContext(ctx),
+		})
+		ctx = logctx.NewContext(ctx, logger.With("request_id", id, "http_method", r.Method))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetRequestID returns the ID of the request, it is empty outside of requests.
+func GetRequestID(ctx context.Context) string {
+	return chimiddleware.GetReqID(ctx)
+}
+
+// validRequestID reports whether id is short and only has printable ASCII characters,
+// so a client can't forge log lines with it.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// routeHandler adds the route of the request to log records. The pattern is complete
+// only once chi routed the request, so it is looked up when a record is logged.
+type routeHandler struct {
+	slog.Handler
+	rctx *chi.Context
+}
+
+func (h routeHandler) Handle(ctx context.Context, record slog.Record) error {
+	route := "unmatched"
+	if h.rctx != nil {
+		if pattern := h.rctx.RoutePattern(); pattern != "" {
+			route = pattern
+		}
+	}
+	record.AddAttrs(slog.String("route", route))
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h routeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return routeHandler{Handler: h.Handler.WithAttrs(attrs), rctx: h.rctx}
+}
+
+func (h routeHandler) WithGroup(name string) slog.Handler {
+	return routeHandler{Handler: h.Handler.WithGroup(name), rctx: h.rctx}
+}
@@ -0,0 +1,131 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/handler"
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "generated", incoming: "", keep: false},
+		{name: "honoured", incoming: "edge-1234", keep: true},
+		{name: "too long", incoming: strings.Repeat("a", 129), keep: false},
+		{name: "control characters", incoming: "abc\ninjected=1", keep: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = middleware.GetRequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.incoming)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			id := rr.Header().Get(middleware.RequestIDHeader)
+			assert.Equal(t, seen, id)
+			if tt.keep {
+				assert.Equal(t, tt.incoming, id)
+			} else {
+				_, err := uuid.Parse(id)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRequestID_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	userID := uuid.New()
+	m := middleware.NewMiddleware(nil, nil, nil, nil)
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.With(m.MockAuthMiddleware).Get("/art/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logctx.From(r.Context()).Info("handled")
+		handler.SendErrorResponse(w, http.StatusNotFound, "Not found")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/art/42", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	req.Header.Set("X-User-ID", userID.String())
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "/art/{id}", line["route"])
+	assert.Equal(t, userID.String(), line["user_id"])
+
+	var resp model.ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, http.StatusNotFound, resp.Status)
+	assert.Equal(t, "req-1", resp.RequestID)
+}
+
+func TestRequestID_MiddlewareErrors(t *testing.T) {
+	m := middleware.NewMiddleware(nil, nil, nil, nil)
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.With(middleware.ValidateUUID("id")).Get("/art/{id}", ok)
+	r.With(m.MockAuthMiddleware).Get("/self", ok)
+
+	tests := []struct {
+		name    string
+		path    string
+		status  int
+		message string
+	}{
+		{name: "invalid uuid", path: "/art/42", status: http.StatusBadRequest, message: "Invalid UUID"},
+		{name: "unauthorized", path: "/self", status: http.StatusUnauthorized, message: "Unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(middleware.RequestIDHeader, "req-2")
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+			var resp model.ErrorResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+			assert.Equal(t, tt.status, resp.Status)
+			assert.Equal(t, tt.message, resp.Message)
+			assert.Equal(t, "req-2", resp.RequestID)
+		})
+	}
+}
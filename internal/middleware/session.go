@@ -2,12 +2,14 @@ package middleware
 
 import (
 	"context"
-	"log/slog"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/sessions"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/service"
 )
@@ -15,20 +17,26 @@ import (
 type contextKey string
 
 const (
-	SessionKey contextKey = "session"
-	UserIDKey  contextKey = "userID"
-	UserKey    contextKey = "user"
+	SessionKey     contextKey = "session"
+	UserIDKey      contextKey = "userID"
+	UserKey        contextKey = "user"
+	PermissionsKey contextKey = "permissions"
+	APITokenKey    contextKey = "apiToken"
 )
 
 type Middleware struct {
-	store       sessions.Store
-	userService service.UserService
+	store             sessions.Store
+	userService       service.UserService
+	permissionService service.PermissionService
+	apiTokenService   service.APITokenService
 }
 
-func NewMiddleware(store sessions.Store, userService service.UserService) *Middleware {
+func NewMiddleware(store sessions.Store, userService service.UserService, permissionService service.PermissionService, apiTokenService service.APITokenService) *Middleware {
 	return &Middleware{
-		store:       store,
-		userService: userService,
+		store:             store,
+		userService:       userService,
+		permissionService: permissionService,
+		apiTokenService:   apiTokenService,
 	}
 }
 
@@ -36,8 +44,8 @@ func (m *Middleware) SessionMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		session, err := m.store.Get(r, model.SessionCookieName)
 		if err != nil {
-			slog.Error("SessionMiddleware: failed to get session", "error", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			logctx.From(r.Context()).Error("SessionMiddleware: failed to get session", "error", err)
+			WriteError(w, http.StatusInternalServerError, "Internal Server Error")
 			return
 		}
 
@@ -69,70 +77,169 @@ func (m *Middleware) MockAuthMiddleware(next http.Handler) http.Handler {
 				Role:     "admin",
 			}
 		} else {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			WriteError(w, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
-		// Add the user to the request context
+		// Add the user and the default permissions of its role to the request context
 		ctx := context.WithValue(r.Context(), UserKey, user)
+		ctx = logctx.WithAttrs(ctx, "user_id", user.ID)
+		ctx = withPermissions(ctx, model.DefaultRolePermissions[user.Role])
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// AuthMiddleware authenticates the request with an API token from the
+// Authorization header when present, and with the session cookie otherwise.
 func (m *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := bearerToken(r); ok {
+			m.authenticateToken(w, r, next, token)
+			return
+		}
+
 		session, _ := m.store.Get(r, model.SessionCookieName)
 		userID, ok := session.Values[model.SessionUserIDKey]
 		if !ok {
-			slog.Error("AuthMiddleware: no user ID in session")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logctx.From(r.Context()).Error("AuthMiddleware: no user ID in session")
+			WriteError(w, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
 		user, err := m.userService.GetUser(r.Context(), userID.(string))
 		if err != nil {
-			slog.Error("AuthMiddleware: failed to find user", "error", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logctx.From(r.Context()).Error("AuthMiddleware: failed to find user", "error", err)
+			WriteError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		if user.Disabled {
+			logctx.From(r.Context()).Warn("AuthMiddleware: account is disabled", "userID", user.ID)
+			WriteError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		permissions, err := m.permissionService.PermissionsForRole(r.Context(), user.Role)
+		if err != nil {
+			logctx.From(r.Context()).Error("AuthMiddleware: failed to load permissions", "error", err, "userID", user.ID)
+			WriteError(w, http.StatusInternalServerError, "Internal Server Error")
 			return
 		}
 
 		ctx := context.WithValue(r.Context(), UserKey, user)
+		ctx = logctx.WithAttrs(ctx, "user_id", user.ID)
+		ctx = withPermissions(ctx, permissions)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func (m *Middleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
+func (m *Middleware) authenticateToken(w http.ResponseWriter, r *http.Request, next http.Handler, value string) {
+	token, permissions, err := m.apiTokenService.Authenticate(r.Context(), value)
+	if err != nil {
+		logctx.From(r.Context()).Warn("AuthMiddleware: api token rejected", "error", err)
+		if errors.Is(err, model.ErrAccountDisabled) {
+			WriteError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserKey, &token.User)
+	ctx = context.WithValue(ctx, APITokenKey, token)
+	ctx = logctx.WithAttrs(ctx, "user_id", token.User.ID, "api_token_id", token.ID)
+	ctx = withPermissions(ctx, permissions)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// RequirePermission lets the request through only if the authenticated user
+// holds all of the given permissions.
+func (m *Middleware) RequirePermission(permissions ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			user, ok := r.Context().Value(UserKey).(*model.User)
+			user, ok := GetUserFromContext(r.Context())
 			if !ok {
-				slog.Error("RequireRole: user not found in context")
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				logctx.From(r.Context()).Error("RequirePermission: user not found in context")
+				WriteError(w, http.StatusUnauthorized, "Unauthorized")
 				return
 			}
 
-			for _, role := range roles {
-				if role == "any" || role == "self" || user.Role == role {
-					next.ServeHTTP(w, r)
+			for _, permission := range permissions {
+				if !HasPermission(r.Context(), permission) {
+					logctx.From(r.Context()).Warn("RequirePermission: permission denied",
+						"required", permission,
+						"role", user.Role,
+						"userID", user.ID,
+					)
+					WriteError(w, http.StatusForbidden, "Forbidden")
 					return
 				}
 			}
 
-			slog.Error("RequireRole: user does not have required role",
-				"required", roles,
-				"actual", user.Role,
-				"userID", user.ID,
-			)
-			http.Error(w, "Forbidden", http.StatusForbidden)
+			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// HasPermission reports whether the authenticated user holds the permission.
+func HasPermission(ctx context.Context, permission string) bool {
+	permissions, _ := ctx.Value(PermissionsKey).(map[string]struct{})
+	_, ok := permissions[permission]
+	return ok
+}
+
+// CanAccess reports whether the authenticated user owns a resource, or holds
+// one of the permissions that grant access to resources of other users.
+func CanAccess(ctx context.Context, ownerID uuid.UUID, overrides ...string) bool {
+	user, ok := GetUserFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	if user.ID == ownerID {
+		return true
+	}
+
+	for _, permission := range overrides {
+		if HasPermission(ctx, permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func withPermissions(ctx context.Context, permissions []string) context.Context {
+	set := make(map[string]struct{}, len(permissions))
+	for _, permission := range permissions {
+		set[permission] = struct{}{}
+	}
+	return context.WithValue(ctx, PermissionsKey, set)
+}
+
 func GetUserFromContext(ctx context.Context) (*model.User, bool) {
 	user, ok := ctx.Value(UserKey).(*model.User)
 	return user, ok
 }
 
+// GetAPITokenFromContext returns the API token the request was authenticated with,
+// it is not set for requests authenticated by the session cookie.
+func GetAPITokenFromContext(ctx context.Context) (*model.APIToken, bool) {
+	token, ok := ctx.Value(APITokenKey).(*model.APIToken)
+	return token, ok
+}
+
 func GetUserIDFromContext(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value(UserIDKey).(string)
 	return userID, ok
@@ -0,0 +1,204 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func TestRequirePermission(t *testing.T) {
+	m := middleware.NewMiddleware(sessions.NewCookieStore([]byte("test-secret")), mocks.NewUserService(t), mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := m.MockAuthMiddleware(m.RequirePermission(model.PermUsersAdmin)(ok))
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "admin", header: "X-Admin-ID", want: http.StatusOK},
+		{name: "user", header: "X-User-ID", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(tt.header, uuid.NewString())
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestCanAccess(t *testing.T) {
+	m := middleware.NewMiddleware(sessions.NewCookieStore([]byte("test-secret")), mocks.NewUserService(t), mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	check := func(header string, owner uuid.UUID, overrides ...string) bool {
+		var allowed bool
+		handler := m.MockAuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed = middleware.CanAccess(r.Context(), owner, overrides...)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(header, userID.String())
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return allowed
+	}
+
+	assert.True(t, check("X-User-ID", userID), "owner")
+	assert.False(t, check("X-User-ID", otherID), "not owner")
+	assert.False(t, check("X-User-ID", otherID, model.PermUsersAdmin), "user without override")
+	assert.True(t, check("X-Admin-ID", otherID, model.PermUsersAdmin), "admin with override")
+	assert.False(t, check("X-Admin-ID", otherID), "admin without override")
+}
+
+func TestAuthMiddleware_BearerToken(t *testing.T) {
+	tokenService := mocks.NewAPITokenService(t)
+	m := middleware.NewMiddleware(sessions.NewCookieStore([]byte("test-secret")), mocks.NewUserService(t), mocks.NewPermissionService(t), tokenService)
+
+	userID := uuid.New()
+	token := &model.APIToken{
+		ID:     uuid.New(),
+		UserID: userID,
+		Scopes: []string{model.PermArtWrite},
+		User:   model.User{ID: userID, Role: model.RoleUser},
+	}
+
+	tokenService.On("Authenticate", mock.Anything, "mb_valid").Return(token, []string{model.PermArtWrite}, nil)
+	tokenService.On("Authenticate", mock.Anything, "mb_revoked").Return(nil, nil, model.ErrInvalidToken)
+	tokenService.On("Authenticate", mock.Anything, "mb_disabled").Return(nil, nil, model.ErrAccountDisabled)
+
+	handler := m.AuthMiddleware(m.RequirePermission(model.PermArtWrite)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := middleware.GetUserFromContext(r.Context())
+			_, viaToken := middleware.GetAPITokenFromContext(r.Context())
+			assert.Equal(t, userID, user.ID)
+			assert.True(t, viaToken)
+			assert.False(t, middleware.HasPermission(r.Context(), model.PermPagesWrite))
+			w.WriteHeader(http.StatusOK)
+		})))
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "valid", authorization: "Bearer mb_valid", want: http.StatusOK},
+		{name: "revoked", authorization: "Bearer mb_revoked", want: http.StatusUnauthorized},
+		{name: "disabled account", authorization: "bearer mb_disabled", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestRequireTwoFactor(t *testing.T) {
+	twoFactorService := mocks.NewTwoFactorService(t)
+	m := middleware.NewMiddleware(sessions.NewCookieStore([]byte("test-secret")), mocks.NewUserService(t), mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+
+	twoFactorService.On("IsRequired", mock.Anything, model.RoleAdmin).Return(true, nil)
+	twoFactorService.On("IsRequired", mock.Anything, model.RoleUser).Return(false, nil)
+
+	handler := m.MockAuthMiddleware(middleware.RequireTwoFactor(twoFactorService)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})))
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "admin without enrolment", header: "X-Admin-ID", want: http.StatusForbidden},
+		{name: "user not required", header: "X-User-ID", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(tt.header, uuid.NewString())
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestCSRFProtect(t *testing.T) {
+	store := sessions.NewCookieStore([]byte("test-secret"))
+	m := middleware.NewMiddleware(store, mocks.NewUserService(t), mocks.NewPermissionService(t), mocks.NewAPITokenService(t))
+
+	handler := m.CSRFProtect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Sign in once to get a session cookie that carries the CSRF token.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	session, _ := store.New(req, model.SessionCookieName)
+	session.Values[model.SessionUserIDKey] = uuid.NewString()
+	session.Values[model.SessionCSRFKey] = "csrf-token"
+	assert.NoError(t, session.Save(req, rec))
+	sessionCookie := rec.Result().Cookies()[0]
+
+	tests := []struct {
+		name   string
+		method string
+		cookie bool
+		header map[string]string
+		want   int
+	}{
+		{name: "safe method", method: http.MethodGet, cookie: true, want: http.StatusOK},
+		{name: "matching token", method: http.MethodPost, cookie: true, header: map[string]string{middleware.CSRFHeader: "csrf-token"}, want: http.StatusOK},
+		{name: "missing token", method: http.MethodDelete, cookie: true, want: http.StatusForbidden},
+		{name: "wrong token", method: http.MethodPut, cookie: true, header: map[string]string{middleware.CSRFHeader: "other"}, want: http.StatusForbidden},
+		{name: "bearer token", method: http.MethodPost, cookie: true, header: map[string]string{"Authorization": "Bearer mb_token"}, want: http.StatusOK},
+		{name: "no session", method: http.MethodPost, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.cookie {
+				req.AddCookie(sessionCookie)
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// RequireTwoFactor is a middleware that blocks users who have not enrolled in
+// two-factor authentication while their role requires it. It must run after
+// AuthMiddleware; the enrolment routes themselves must stay outside of it.
+func RequireTwoFactor(twoFactorService service.TwoFactorService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r.Context())
+			if !ok {
+				logctx.From(r.Context()).Error("RequireTwoFactor: user not found in context")
+				WriteError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			if !user.TOTPEnabled {
+				required, err := twoFactorService.IsRequired(r.Context(), user.Role)
+				if err != nil {
+					logctx.From(r.Context()).Error("RequireTwoFactor: failed to load role policy", "error", err, "role", user.Role)
+					WriteError(w, http.StatusInternalServerError, "Internal server error")
+					return
+				}
+				if required {
+					logctx.From(r.Context()).Warn("RequireTwoFactor: enrolment required", "userID", user.ID, "role", user.Role)
+					WriteError(w, http.StatusForbidden, "Two-factor authentication is required, enrol at /self/2fa")
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -15,7 +15,7 @@ func ValidateUUID(paramName string) func(http.Handler) http.Handler {
 			if param != "" {
 				_, err := uuid.Parse(param)
 				if err != nil {
-					http.Error(w, "Invalid UUID", http.StatusBadRequest)
+					WriteError(w, http.StatusBadRequest, "Invalid UUID")
 					return
 				}
 			}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APITokenPrefix starts every personal API token so leaked tokens are easy to recognise.
+const APITokenPrefix = "mb_"
+
+// APIToken is a personal access token for scripts. Its scopes are permission names
+// and narrow down the permissions of the owner's role. Only the hash is stored.
+type APIToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string     `gorm:"type:varchar(255);not null" json:"name"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	Scopes     []string   `gorm:"type:jsonb;serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `gorm:"type:timestamp" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `gorm:"type:timestamp" json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"type:timestamp;default:now()" json:"created_at"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Expired reports whether the token can no longer be used at t.
+func (t *APIToken) Expired(at time.Time) bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.After(at)
+}
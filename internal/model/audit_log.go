@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionUserList          = "user.list"
+	AuditActionUserView          = "user.view"
+	AuditActionUserRoleChange    = "user.role_change"
+	AuditActionUserPasswordReset = "user.password_reset"
+	AuditActionUserDisable       = "user.disable"
+	AuditActionUserEnable        = "user.enable"
+	AuditActionUserDelete        = "user.delete"
+	AuditActionUserUnlock        = "user.unlock"
+	AuditActionInviteCreate      = "invite.create"
+	AuditActionInviteRevoke      = "invite.revoke"
+	AuditActionRolePolicyUpdate  = "role.policy_update"
+)
+
+// SystemActorID is the actor of administrative actions run by operators outside the HTTP
+// API, for example with the admin command.
+var SystemActorID = uuid.Nil
+
+// AuditLog records an administrative action.
+// Actor and target are kept as plain IDs so entries outlive deleted users.
+type AuditLog struct {
+	ID        uuid.UUID         `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	ActorID   uuid.UUID         `gorm:"type:uuid;not null;index" json:"actor_id"`
+	Action    string            `gorm:"type:varchar(100);not null;index" json:"action"`
+	TargetID  string            `gorm:"type:varchar(255);index" json:"target_id"`
+	Details   map[string]string `gorm:"type:jsonb;serializer:json" json:"details,omitempty"`
+	CreatedAt time.Time         `gorm:"type:timestamp;default:now();index" json:"created_at"`
+}
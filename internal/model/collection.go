@@ -6,12 +6,35 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	CollectionTypeManual = "manual"
+	CollectionTypeSmart  = "smart"
+
+	RuleRevisionLatest    = "latest"
+	RuleRevisionPublished = "published"
+)
+
 type Collection struct {
-	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
-	CollectionID string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"collection_id"`
-	UserID       uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	Title        string    `gorm:"type:varchar(255);not null" json:"title"`
-	CreatedAt    time.Time `gorm:"type:timestamp;default:now()" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"type:timestamp;default:now()" json:"updated_at"`
-	User         User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	ID              uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	CollectionID    string          `gorm:"type:varchar(255);not null;uniqueIndex" json:"collection_id"`
+	UserID          uuid.UUID       `gorm:"type:uuid;not null" json:"user_id"`
+	Title           string          `gorm:"type:varchar(255);not null" json:"title"`
+	Description     string          `gorm:"type:text" json:"description"`
+	CoverRevisionID *uuid.UUID      `gorm:"type:uuid" json:"cover_revision_id"`
+	Public          bool            `gorm:"type:boolean;default:false" json:"public"`
+	Type            string          `gorm:"type:varchar(20);not null;default:'manual'" json:"type"`
+	Rule            *CollectionRule `gorm:"type:jsonb;serializer:json" json:"rule,omitempty"`
+	CreatedAt       time.Time       `gorm:"type:timestamp;default:now()" json:"created_at"`
+	UpdatedAt       time.Time       `gorm:"type:timestamp;default:now()" json:"updated_at"`
+	User            User            `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// CollectionRule is the saved query that defines the members of a smart collection.
+// Dates are given as YYYY-MM-DD or RFC 3339 and compared with the art project creation time.
+type CollectionRule struct {
+	Revision      string   `json:"revision"`
+	Tags          []string `json:"tags,omitempty"`
+	CreatedAfter  string   `json:"created_after,omitempty"`
+	CreatedBefore string   `json:"created_before,omitempty"`
+	Limit         int      `json:"limit,omitempty"`
 }
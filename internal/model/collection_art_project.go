@@ -1,12 +1,18 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type CollectionArtProject struct {
-	CollectionID uuid.UUID  `gorm:"type:uuid;not null;primaryKey" json:"collection_id"`
-	RevisionID   uuid.UUID  `gorm:"type:uuid;not null;primaryKey" json:"revision_id"`
-	Collection   Collection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
-	Revision     Revision   `gorm:"foreignKey:RevisionID;constraint:OnDelete:CASCADE" json:"-"`
+	CollectionID  uuid.UUID  `gorm:"type:uuid;not null;primaryKey" json:"collection_id"`
+	RevisionID    uuid.UUID  `gorm:"type:uuid;not null;primaryKey" json:"revision_id"`
+	Position      int        `gorm:"type:int;not null;default:0" json:"position"`
+	Caption       string     `gorm:"type:text" json:"caption"`
+	ContributorID *uuid.UUID `gorm:"type:uuid" json:"contributor_id"`
+	CreatedAt     time.Time  `gorm:"type:timestamp;default:now()" json:"created_at"`
+	Collection    Collection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
+	Revision      Revision   `gorm:"foreignKey:RevisionID;constraint:OnDelete:CASCADE" json:"-"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CollectionRoleOwner  = "owner"
+	CollectionRoleEditor = "editor"
+	CollectionRoleViewer = "viewer"
+)
+
+// CollectionMember gives a user access to a collection owned by someone else.
+// The membership is a pending invitation until AcceptedAt is set.
+// The owner is never stored as a member, the role follows from Collection.UserID.
+type CollectionMember struct {
+	CollectionID uuid.UUID  `gorm:"type:uuid;not null;primaryKey" json:"collection_id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;primaryKey" json:"user_id"`
+	Role         string     `gorm:"type:varchar(20);not null" json:"role"`
+	InvitedBy    uuid.UUID  `gorm:"type:uuid;not null" json:"invited_by"`
+	AcceptedAt   *time.Time `gorm:"type:timestamp" json:"accepted_at"`
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:now()" json:"created_at"`
+	Collection   Collection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
+	User         User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
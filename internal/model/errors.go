@@ -24,8 +24,33 @@ var (
 	ErrUserNotFound        = errors.New("user not found")
 	ErrInvalidCredentials  = errors.New("invalid credentials")
 	ErrInvalidInput        = errors.New("invalid input parameters")
+	ErrInvalidRole         = errors.New("unknown role")
 	ErrStashCreationFailed = errors.New("failed to create stash")
 	ErrUnauthorized        = errors.New("unauthorized access")
 	ErrDuplicateUsername   = errors.New("username already exists")
 	ErrRevisionNotFound    = errors.New("revision not found")
+	ErrNoThumbnail         = errors.New("revision is not an image with a thumbnail")
+	ErrSmartCollection     = errors.New("smart collection members are defined by its rule")
+	ErrMemberNotFound      = errors.New("collection member not found")
+	ErrAlreadyMember       = errors.New("user is already a member of the collection")
+	ErrAccountDisabled     = errors.New("account is disabled")
+	ErrRegistrationClosed  = errors.New("registration is closed")
+	ErrInvalidInviteCode   = errors.New("invalid or used invite code")
+	ErrInviteNotFound      = errors.New("invite not found")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrTokenNotFound       = errors.New("api token not found")
+	ErrInvalidToken        = errors.New("invalid or expired api token")
+	ErrTooManyAttempts     = errors.New("too many failed login attempts")
+	ErrTwoFactorEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorRequired   = errors.New("two-factor authentication is required for this role")
+	ErrInvalidOTP          = errors.New("invalid two-factor code")
+	ErrChallengeNotFound   = errors.New("login challenge not found or expired")
+	ErrWeakPassword        = errors.New("password does not meet the password policy")
+	ErrInvalidResetToken   = errors.New("invalid or expired password reset token")
+	ErrProviderNotFound    = errors.New("identity provider not found")
+	ErrIdentityNotFound    = errors.New("external identity not found")
+	ErrIdentityLinked      = errors.New("external identity is linked to another user")
+	ErrSignupDisabled      = errors.New("sign up through this identity provider is disabled")
+	ErrExternalLogin       = errors.New("identity provider login failed")
 )
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// UserExport describes the archive of a user, it is written as user.json next to the revision files.
+type UserExport struct {
+	ExportedAt  time.Time          `json:"exported_at"`
+	User        UserResponse       `json:"user"`
+	Stash       *Stash             `json:"stash,omitempty"`
+	ArtProjects []ArtProjectExport `json:"art_projects"`
+	Collections []Collection       `json:"collections"`
+	WebPages    []WebPage          `json:"web_pages"`
+}
+
+// ArtProjectExport is an art project with all of its revisions.
+type ArtProjectExport struct {
+	ArtProject
+	Revisions []RevisionExport `json:"revisions"`
+}
+
+// RevisionExport is a revision with the path of its file inside the archive.
+type RevisionExport struct {
+	Revision
+	File string `json:"file"`
+}
@@ -0,0 +1,13 @@
+package model
+
+const (
+	HealthStatusOK       = "OK"
+	HealthStatusFailing  = "failing"
+	HealthStatusDraining = "draining"
+)
+
+// Readiness is the result of the readiness checks with the status of each check by name.
+type Readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalIdentity links an account at an OpenID Connect provider to a user.
+// The subject is the stable identifier of the account at the provider.
+type ExternalIdentity struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider  string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_external_identity_subject" json:"provider"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identity_subject" json:"subject"`
+	Email     string    `gorm:"type:varchar(255)" json:"email,omitempty"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:now()" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ExternalClaims are the verified claims of an ID token used to find or provision a user.
+type ExternalClaims struct {
+	Provider string
+	Subject  string
+	Username string
+	Email    string
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Registration modes control who can create an account through POST /api/users.
+const (
+	RegistrationModeOpen   = "open"
+	RegistrationModeInvite = "invite"
+	RegistrationModeClosed = "closed"
+)
+
+// Invite is a single-use registration code created by an administrator.
+// Only the SHA-256 hash of the code is stored.
+type Invite struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	CodeHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	CreatedBy uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	UsedBy    *uuid.UUID `gorm:"type:uuid" json:"used_by,omitempty"`
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"used_at,omitempty"`
+	ExpiresAt *time.Time `gorm:"type:timestamp" json:"expires_at,omitempty"`
+	CreatedAt time.Time  `gorm:"type:timestamp;default:now()" json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// LoginAttempt counts the recent failed logins of a throttle key, which is
+// either a username or a client IP, see LoginUserKey and LoginIPKey.
+type LoginAttempt struct {
+	Key           string     `gorm:"type:varchar(300);primary_key" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"type:timestamp;index;not null" json:"last_failure_at"`
+	LockedUntil   *time.Time `gorm:"type:timestamp" json:"locked_until,omitempty"`
+}
+
+// LoginUserKey returns the throttle key for failed logins of a username.
+func LoginUserKey(username string) string {
+	return "user:" + username
+}
+
+// LoginIPKey returns the throttle key for failed logins from a client IP.
+func LoginIPKey(ip string) string {
+	return "ip:" + ip
+}
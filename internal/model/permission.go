@@ -0,0 +1,42 @@
+package model
+
+// Named permissions checked by middleware.RequirePermission and middleware.CanAccess.
+const (
+	PermArtWrite         = "art:write"
+	PermPagesWrite       = "pages:write"
+	PermPagesPublish     = "pages:publish"
+	PermCollectionsWrite = "collections:write"
+	PermUsersAdmin       = "users:admin"
+)
+
+// AllPermissions lists every known permission, in the order they are documented.
+var AllPermissions = []string{
+	PermArtWrite,
+	PermPagesWrite,
+	PermPagesPublish,
+	PermCollectionsWrite,
+	PermUsersAdmin,
+}
+
+// RolePermission grants a permission to every user with the role.
+type RolePermission struct {
+	Role       string `gorm:"type:varchar(50);primaryKey" json:"role"`
+	Permission string `gorm:"type:varchar(100);primaryKey" json:"permission"`
+}
+
+// DefaultRolePermissions seeds the role_permissions table when it is empty.
+var DefaultRolePermissions = map[string][]string{
+	RoleUser: {
+		PermArtWrite,
+		PermPagesWrite,
+		PermPagesPublish,
+		PermCollectionsWrite,
+	},
+	RoleAdmin: {
+		PermArtWrite,
+		PermPagesWrite,
+		PermPagesPublish,
+		PermCollectionsWrite,
+		PermUsersAdmin,
+	},
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Route classes with separate rate limit budgets.
+const (
+	RateLimitAuth     = "auth"
+	RateLimitUpload   = "upload"
+	RateLimitDownload = "download"
+	RateLimitAPI      = "api"
+)
+
+// RateLimitClasses lists the route classes in the order they are configured.
+var RateLimitClasses = []string{RateLimitAuth, RateLimitUpload, RateLimitDownload, RateLimitAPI}
+
+// RateLimit allows Requests per Period. The budget refills continuously, so a client
+// that used it up gets a request again after Period / Requests.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// rate returns the tokens the budget refills per second.
+func (l RateLimit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// RateLimitBucket is the token bucket of a rate limit key, which combines the route
+// class with a user ID or a client IP, see RateLimitUserKey and RateLimitIPKey.
+type RateLimitBucket struct {
+	Key       string    `gorm:"type:varchar(300);primary_key" json:"key"`
+	Tokens    float64   `gorm:"not null" json:"tokens"`
+	UpdatedAt time.Time `gorm:"type:timestamp;index;not null" json:"updated_at"`
+}
+
+// RateLimitResult is the outcome of taking a request from a bucket.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     RateLimit
+	Remaining int
+	// Reset is the time until the budget is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, it is zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// NewRateLimitBucket returns a full bucket for the key.
+func NewRateLimitBucket(key string, limit RateLimit, now time.Time) *RateLimitBucket {
+	return &RateLimitBucket{Key: key, Tokens: float64(limit.Requests), UpdatedAt: now}
+}
+
+// Take refills the bucket for the time passed since its last update and takes a
+// token for one request if there is one.
+func (b *RateLimitBucket) Take(limit RateLimit, now time.Time) RateLimitResult {
+	rate := limit.rate()
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Requests), b.Tokens+elapsed.Seconds()*rate)
+		b.UpdatedAt = now
+	}
+
+	result := RateLimitResult{Limit: limit}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+	result.Remaining = int(b.Tokens)
+	result.Reset = seconds((float64(limit.Requests) - b.Tokens) / rate)
+
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// RateLimitUserKey returns the rate limit key of an authenticated user for the route class.
+func RateLimitUserKey(class, userID string) string {
+	return class + ":user:" + userID
+}
+
+// RateLimitIPKey returns the rate limit key of a client IP for the route class.
+func RateLimitIPKey(class, ip string) string {
+	return class + ":ip:" + ip
+}
@@ -8,15 +8,17 @@ import (
 
 // ErrorResponse represents a standard error response
 type ErrorResponse struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
+	Status    int    `json:"status"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // ValidationErrorResponse represents a response for validation errors
 type ValidationErrorResponse struct {
-	Status  int               `json:"status"`
-	Message string            `json:"message"`
-	Errors  map[string]string `json:"errors"`
+	Status    int               `json:"status"`
+	Message   string            `json:"message"`
+	Errors    map[string]string `json:"errors"`
+	RequestID string            `json:"request_id,omitempty"`
 }
 
 // Pagination represents pagination information
@@ -61,6 +63,8 @@ type PublicRevisionResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	Comment   string    `json:"comment"`
 	Size      int64     `json:"size"`
+	Position  int       `json:"position"`
+	Caption   string    `json:"caption,omitempty"`
 }
 
 // RevisionResponse represents the response for a revision
@@ -80,8 +84,48 @@ type UserResponse struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
 	Role      string    `json:"role"`
+	Disabled  bool      `json:"disabled"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+}
+
+// PasswordResetResponse carries the temporary password set by an administrator
+type PasswordResetResponse struct {
+	UserID            uuid.UUID `json:"user_id"`
+	TemporaryPassword string    `json:"temporary_password"`
+}
+
+// SessionResponse represents an active login session of the user
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// APITokenRequest represents the request to create a personal API token.
+// A zero ExpiresInDays creates a token without expiry.
+type APITokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=255"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"gte=0"`
+}
+
+// APITokenResponse represents a personal API token, Token is only set right after creation
+type APITokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 // StashResponse represents the response for a stash
@@ -97,11 +141,42 @@ type StashResponse struct {
 
 // CollectionResponse represents the response for a collection
 type CollectionResponse struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Title     string    `json:"title"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uuid.UUID       `json:"id"`
+	CollectionID    string          `json:"collection_id"`
+	UserID          uuid.UUID       `json:"user_id"`
+	Title           string          `json:"title"`
+	Description     string          `json:"description"`
+	CoverRevisionID *uuid.UUID      `json:"cover_revision_id,omitempty"`
+	Public          bool            `json:"public"`
+	Type            string          `json:"type"`
+	Rule            *CollectionRule `json:"rule,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// CollectionItemResponse represents the response for a revision in a collection
+type CollectionItemResponse struct {
+	RevisionResponse
+	Position      int        `json:"position"`
+	Caption       string     `json:"caption"`
+	ContributorID *uuid.UUID `json:"contributor_id,omitempty"`
+}
+
+// CollectionMemberResponse represents the response for a collection member or invitation
+type CollectionMemberResponse struct {
+	CollectionID uuid.UUID  `json:"collection_id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Username     string     `json:"username"`
+	Role         string     `json:"role"`
+	InvitedBy    uuid.UUID  `json:"invited_by"`
+	AcceptedAt   *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CollectionInvitationResponse represents a pending invitation together with its collection
+type CollectionInvitationResponse struct {
+	CollectionMemberResponse
+	Collection CollectionResponse `json:"collection"`
 }
 
 // CollectionArtProjectResponse represents the response for a collection art project
@@ -141,6 +216,8 @@ type WebPageResponse struct {
 	UserID    uuid.UUID `json:"user_id"`
 	Title     string    `json:"title"`
 	Html      string    `json:"html"`
+	Format    string    `json:"format"`
+	Source    string    `json:"source"`
 	PageType  string    `json:"page_type"`
 	Public    bool      `json:"public"`
 	CreatedAt time.Time `json:"created_at"`
@@ -167,3 +244,127 @@ type CreateArtProjectRequest struct {
 type AddRevisionRequest struct {
 	Comment string `json:"comment"`
 }
+
+// CollectionRequest represents the request to create or update a collection.
+// Nil fields are left unchanged on update, a nil UUID cover removes the cover.
+// A collection created with a rule is a smart collection.
+type CollectionRequest struct {
+	Title           string          `json:"title"`
+	Description     *string         `json:"description"`
+	CoverRevisionID *uuid.UUID      `json:"cover_revision_id"`
+	Public          *bool           `json:"public"`
+	Rule            *CollectionRule `json:"rule"`
+}
+
+// CollectionItemRequest represents the request to add or update a revision in a collection
+type CollectionItemRequest struct {
+	RevisionID string  `json:"revisionID"`
+	Caption    *string `json:"caption"`
+}
+
+// CollectionMemberRequest represents the request to invite a user or change a member role
+type CollectionMemberRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// InviteRequest represents the request to create a registration invite.
+// A zero ValidForHours creates an invite without expiry.
+type InviteRequest struct {
+	ValidForHours int `json:"valid_for_hours" validate:"gte=0"`
+}
+
+// InviteResponse represents a registration invite, Code is only set right after creation
+type InviteResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Code      string     `json:"code,omitempty"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	UsedBy    *uuid.UUID `json:"used_by,omitempty"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RoleRequest represents the request to change the role of a user
+type RoleRequest struct {
+	Role string `json:"role" validate:"required,max=50"`
+}
+
+// ReorderCollectionRequest represents the request to reorder revisions in a collection
+type ReorderCollectionRequest struct {
+	RevisionIDs []string `json:"revisionIDs"`
+}
+
+// LoginChallengeResponse is returned by /login when the account has two-factor
+// authentication, the challenge is completed at /login/2fa with a code.
+type LoginChallengeResponse struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	Challenge         string    `json:"challenge"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// TwoFactorLoginRequest completes a login challenge with a TOTP or recovery code
+type TwoFactorLoginRequest struct {
+	Challenge string `json:"challenge" validate:"required"`
+	Code      string `json:"code" validate:"required"`
+}
+
+// ChangePasswordRequest represents the request to change the password of the current user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// PasswordResetRequest represents the request to send a password reset token to a user
+type PasswordResetRequest struct {
+	Username string `json:"username" validate:"required"`
+}
+
+// PasswordResetConfirmRequest sets a new password with a password reset token
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// ExternalIdentityResponse represents an identity provider account linked to the current user
+type ExternalIdentityResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TwoFactorCodeRequest confirms a two-factor action with a TOTP or recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorEnrolmentResponse carries the secret of a new enrolment and its provisioning URI for a QR code
+type TwoFactorEnrolmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodesResponse carries newly generated recovery codes, they are only shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorStatusResponse represents the two-factor state of the current user
+type TwoFactorStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// RolePolicyRequest represents the request to change the security policy of a role
+type RolePolicyRequest struct {
+	RequireTwoFactor *bool `json:"require_two_factor" validate:"required"`
+}
+
+// RolePolicyResponse represents the security policy of a role
+type RolePolicyResponse struct {
+	Role             string `json:"role"`
+	RequireTwoFactor bool   `json:"require_two_factor"`
+}
@@ -20,6 +20,13 @@ type Revision struct {
 	User         User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
+// Thumbnail is the downscaled rendition of a revision image, stored next to its file.
+type Thumbnail struct {
+	FilePath    string
+	ContentType string
+	Size        int64
+}
+
 type ArtLink struct {
 	Token      string    `gorm:"primaryKey"`
 	RevisionID uuid.UUID `gorm:"not null"`
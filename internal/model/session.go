@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a server-side login session. The cookie only carries the session token,
+// the table keeps its SHA-256 hash and the encoded session values.
+type Session struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	UserID     *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Data       string     `gorm:"type:text;not null" json:"-"`
+	UserAgent  string     `gorm:"type:varchar(512)" json:"user_agent"`
+	IP         string     `gorm:"type:varchar(64)" json:"ip"`
+	CreatedAt  time.Time  `gorm:"type:timestamp;default:now()" json:"created_at"`
+	LastSeenAt time.Time  `gorm:"type:timestamp;default:now()" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"type:timestamp;not null;index" json:"expires_at"`
+	User       *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	// Current marks the session of the request that listed it.
+	Current bool `gorm:"-" json:"current"`
+}
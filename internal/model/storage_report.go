@@ -0,0 +1,65 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	StorageProblemMissing      = "missing"
+	StorageProblemSizeMismatch = "size_mismatch"
+	StorageProblemOrphan       = "orphan"
+)
+
+// StorageProblem is a revision whose file does not match the database, or an orphan
+// file under the storage root that no revision refers to.
+type StorageProblem struct {
+	RevisionID   uuid.UUID `json:"revision_id"`
+	ArtProjectID uuid.UUID `json:"art_project_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	FilePath     string    `json:"file_path"`
+	Kind         string    `json:"kind"`
+	ExpectedSize int64     `json:"expected_size"`
+	ActualSize   int64     `json:"actual_size"`
+	// Repaired is set when a repair removed the orphan file.
+	Repaired bool `json:"repaired"`
+}
+
+// StashCounters are the counters a stash keeps of its art projects and revisions.
+type StashCounters struct {
+	ArtProjects uint64 `json:"art_projects"`
+	Files       uint64 `json:"files"`
+	UsedSpace   int64  `json:"used_space"`
+}
+
+// StashDrift is a stash whose recorded counters differ from the art projects and
+// revisions it holds.
+type StashDrift struct {
+	StashID  uuid.UUID     `json:"stash_id"`
+	UserID   uuid.UUID     `json:"user_id"`
+	Recorded StashCounters `json:"recorded"`
+	Actual   StashCounters `json:"actual"`
+	// Repaired is set when a repair recomputed the counters.
+	Repaired bool `json:"repaired"`
+}
+
+// StorageReport is the result of checking the storage root and the stashes against the database.
+type StorageReport struct {
+	Revisions int64            `json:"revisions"`
+	Files     int64            `json:"files"`
+	Problems  []StorageProblem `json:"problems"`
+	Stashes   []StashDrift     `json:"stashes"`
+}
+
+// Unrepaired returns the number of problems and drifted stashes that were not repaired.
+func (r *StorageReport) Unrepaired() int {
+	n := 0
+	for _, problem := range r.Problems {
+		if !problem.Repaired {
+			n++
+		}
+	}
+	for _, stash := range r.Stashes {
+		if !stash.Repaired {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the device is lost.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null;index" json:"-"`
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"type:timestamp;default:now()" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// LoginChallenge is the pending second step of a login with two-factor authentication,
+// created once the password is verified. Only the SHA-256 hash of its token is stored.
+type LoginChallenge struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	TokenHash    string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	KeepSignedIn bool      `gorm:"type:boolean;not null;default:false" json:"keep_signed_in"`
+	Attempts     int       `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt    time.Time `gorm:"type:timestamp;not null;index" json:"expires_at"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:now()" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// RolePolicy holds the security requirements of a role.
+type RolePolicy struct {
+	Role             string `gorm:"type:varchar(50);primary_key" json:"role"`
+	RequireTwoFactor bool   `gorm:"type:boolean;not null;default:false" json:"require_two_factor"`
+}
+
+// TwoFactorStatus describes the two-factor authentication state of a user.
+type TwoFactorStatus struct {
+	Enabled           bool
+	Required          bool
+	RecoveryCodesLeft int64
+}
@@ -8,7 +8,11 @@ import (
 
 const (
 	SessionUserIDKey  = "user_id"
+	SessionCSRFKey    = "csrf_token"
 	SessionCookieName = "session-name"
+
+	RoleUser  = "user"
+	RoleAdmin = "admin"
 )
 
 type LoginRequest struct {
@@ -22,6 +26,21 @@ type User struct {
 	Username  string    `gorm:"type:varchar(255);unique;not null" json:"username"`
 	Password  string    `gorm:"type:varchar(255);not null" json:"password"`
 	Role      string    `gorm:"type:varchar(50);not null" json:"role"`
+	Disabled  bool      `gorm:"type:boolean;not null;default:false" json:"disabled"`
 	CreatedAt time.Time `gorm:"type:timestamp;default:now()" json:"-"`
 	UpdatedAt time.Time `gorm:"type:timestamp;default:now()" json:"-"`
+
+	// TOTPSecret is the encrypted TOTP secret, set once enrolment starts.
+	TOTPSecret  string `gorm:"type:text" json:"-"`
+	TOTPEnabled bool   `gorm:"type:boolean;not null;default:false" json:"totp_enabled"`
+	// TOTPLastStep is the time step of the last accepted code, so a code can not be used twice.
+	TOTPLastStep int64 `gorm:"not null;default:0" json:"-"`
+}
+
+// UserListQuery filters and paginates the user list.
+type UserListQuery struct {
+	Search  string
+	Role    string
+	Page    int
+	PerPage int
 }
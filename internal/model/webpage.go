@@ -6,11 +6,18 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	WebPageFormatHTML     = "html"
+	WebPageFormatMarkdown = "markdown"
+)
+
 type WebPage struct {
 	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
 	UserID    uuid.UUID `gorm:"type:uuid;not null"               json:"-"`
 	Title     string    `gorm:"type:varchar(255);not null"       json:"title"`
 	Html      string    `gorm:"type:text;not null"               json:"html"`
+	Format    string    `gorm:"type:varchar(20);default:'html'"  json:"format"`
+	Source    string    `gorm:"type:text"                        json:"source"`
 	PageType  string    `gorm:"type:varchar(255);default:'main'" json:"page_type"`
 	Public    bool      `gorm:"type:boolean;default:false"       json:"public"`
 	CreatedAt time.Time `gorm:"type:timestamp;default:now()"     json:"created_at"`
@@ -0,0 +1,33 @@
+package notifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	n := NewFileNotifier(path)
+
+	require.NoError(t, n.Notify(context.Background(), Message{To: "alice", Subject: "First", Body: "one"}))
+	require.NoError(t, n.Notify(context.Background(), Message{To: "bob", Subject: "Second", Body: "two"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "To: alice\nSubject: First\n\none")
+	assert.Contains(t, string(data), "To: bob\nSubject: Second\n\ntwo")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("smtp", "")
+	assert.Error(t, err)
+
+	n, err := New(KindLog, "")
+	require.NoError(t, err)
+	assert.IsType(t, &LogNotifier{}, n)
+}
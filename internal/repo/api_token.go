@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// APITokenRepository defines the interface for personal API token database operations.
+type APITokenRepository interface {
+	CreateToken(ctx context.Context, token *model.APIToken) error
+	ListByUser(ctx context.Context, userID string) ([]model.APIToken, error)
+	FindByHash(ctx context.Context, tokenHash string) (*model.APIToken, error)
+	TouchToken(ctx context.Context, id string, lastUsed time.Time) error
+	DeleteToken(ctx context.Context, userID, id string) error
+}
+
+type apiTokenRepo struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository creates a new instance of APITokenRepository.
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepo{db: db}
+}
+
+// CreateToken stores a new API token.
+func (r *apiTokenRepo) CreateToken(ctx context.Context, token *model.APIToken) error {
+	logger := logctx.With(ctx, "method", "CreateToken", "userID", token.UserID)
+
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		logger.Error("Failed to create api token", "error", err)
+		return err
+	}
+
+	logger.Info("API token created successfully", "tokenID", token.ID)
+	return nil
+}
+
+// ListByUser retrieves the API tokens of a user, newest first.
+func (r *apiTokenRepo) ListByUser(ctx context.Context, userID string) ([]model.APIToken, error) {
+	logger := logctx.With(ctx, "method", "ListByUser", "userID", userID)
+
+	var tokens []model.APIToken
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		logger.Error("Failed to list api tokens", "error", err)
+		return nil, err
+	}
+
+	logger.Info("API tokens listed successfully", "count", len(tokens))
+	return tokens, nil
+}
+
+// FindByHash retrieves an API token by the hash of its value.
+func (r *apiTokenRepo) FindByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	logger := logctx.With(ctx, "method", "FindByHash")
+
+	var token model.APIToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("API token not found")
+			return nil, model.ErrTokenNotFound
+		}
+		logger.Error("Failed to find api token", "error", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// TouchToken records the last time a token was used.
+func (r *apiTokenRepo) TouchToken(ctx context.Context, id string, lastUsed time.Time) error {
+	logger := logctx.With(ctx, "method", "TouchToken", "tokenID", id)
+
+	err := r.db.WithContext(ctx).Model(&model.APIToken{}).Where("id = ?", id).Update("last_used_at", lastUsed).Error
+	if err != nil {
+		logger.Error("Failed to touch api token", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteToken removes an API token of a user.
+func (r *apiTokenRepo) DeleteToken(ctx context.Context, userID, id string) error {
+	logger := logctx.With(ctx, "method", "DeleteToken", "userID", userID, "tokenID", id)
+
+	result := r.db.WithContext(ctx).Delete(&model.APIToken{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		logger.Error("Failed to delete api token", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("API token not found for deletion")
+		return model.ErrTokenNotFound
+	}
+
+	logger.Info("API token deleted successfully")
+	return nil
+}
@@ -3,10 +3,10 @@ package repo
 import (
 	"context"
 	"errors"
-	"log/slog"
 
 	"gorm.io/gorm"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 )
 
@@ -28,9 +28,9 @@ func NewArtLinkRepository(db *gorm.DB) ArtLinkRepository {
 
 // CreateArtLink adds a new art link to the database.
 func (r *artLinkRepo) CreateArtLink(ctx context.Context, artLink *model.ArtLink) error {
-	logger := slog.With("method", "CreateArtLink", "token", artLink.Token)
+	logger := logctx.With(ctx, "method", "CreateArtLink", "token", artLink.Token)
 
-	if err := r.db.Create(artLink).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(artLink).Error; err != nil {
 		logger.Error("Failed to create art link", "error", err)
 		return err
 	}
@@ -41,9 +41,9 @@ func (r *artLinkRepo) CreateArtLink(ctx context.Context, artLink *model.ArtLink)
 
 // UpdateArtLink updates an existing art link in the database.
 func (r *artLinkRepo) UpdateArtLink(ctx context.Context, artLink *model.ArtLink) error {
-	logger := slog.With("method", "UpdateArtLink", "token", artLink.Token)
+	logger := logctx.With(ctx, "method", "UpdateArtLink", "token", artLink.Token)
 
-	if err := r.db.Save(artLink).Error; err != nil {
+	if err := r.db.WithContext(ctx).Save(artLink).Error; err != nil {
 		logger.Error("Failed to update art link", "error", err)
 		return err
 	}
@@ -54,10 +54,10 @@ func (r *artLinkRepo) UpdateArtLink(ctx context.Context, artLink *model.ArtLink)
 
 // GetArtLinkByToken retrieves an art link by its token.
 func (r *artLinkRepo) GetArtLinkByToken(ctx context.Context, token string) (*model.ArtLink, error) {
-	logger := slog.With("method", "GetArtLinkByToken", "token", token)
+	logger := logctx.With(ctx, "method", "GetArtLinkByToken", "token", token)
 
 	var artLink model.ArtLink
-	if err := r.db.Where("token = ?", token).First(&artLink).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("token = ?", token).First(&artLink).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Info("Art link not found")
 			return nil, model.ErrArtLinkNotFound
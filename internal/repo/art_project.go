@@ -3,11 +3,12 @@ package repo
 import (
 	"context"
 	"errors"
-	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 )
 
@@ -28,6 +29,20 @@ type ArtProjectRepository interface {
 	FindByStashID(ctx context.Context, stashID string) ([]model.ArtProject, error)
 	FindByUserID(ctx context.Context, userID string) ([]model.ArtProject, error)
 	FindRevisionByID(ctx context.Context, id string) (*model.Revision, error)
+	FindRevisions(ctx context.Context, filter RevisionFilter) ([]model.Revision, error)
+	WalkRevisions(ctx context.Context, fn func(revisions []model.Revision) error) error
+}
+
+// RevisionFilter selects one revision per art project of a user, either the latest or the published one.
+type RevisionFilter struct {
+	UserID        string
+	Published     bool
+	Tags          []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Limit         int
+	// OrderByRevision sorts by the creation of the revisions instead of their art projects.
+	OrderByRevision bool
 }
 
 type artProjectRepo struct {
@@ -41,85 +56,85 @@ func NewArtProjectRepository(db *gorm.DB) ArtProjectRepository {
 
 // CreateArtProject adds a new art project to the database.
 func (r *artProjectRepo) CreateArtProject(ctx context.Context, artProject *model.ArtProject) error {
-	logger := slog.With("method", "CreateArtProject", "artProjectID", artProject.ID)
+	logger := logctx.With(ctx, "method", "CreateArtProject", "artProjectID", artProject.ID)
 
-	if err := r.db.Create(artProject).Error; err != nil {
-		logger.Error("Failed to create art project", "error", err)
+	if err := r.db.WithContext(ctx).Create(artProject).Error; err != nil {
+		logger.ErrorContext(ctx, "Failed to create art project", "error", err)
 		return err
 	}
 
-	logger.Info("Art project created successfully")
+	logger.InfoContext(ctx, "Art project created successfully")
 	return nil
 }
 
 // FindArtProjectByID retrieves an art project by its ID.
 func (r *artProjectRepo) FindArtProjectByID(ctx context.Context, id string) (*model.ArtProject, error) {
-	logger := slog.With("method", "FindArtProjectByID", "artProjectID", id)
+	logger := logctx.With(ctx, "method", "FindArtProjectByID", "artProjectID", id)
 
 	var artProject model.ArtProject
-	if err := r.db.Preload("Stash").Preload("User").First(&artProject, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Stash").Preload("User").First(&artProject, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.Info("Art project not found")
+			logger.InfoContext(ctx, "Art project not found")
 			return nil, model.ErrArtProjectNotFound
 		}
-		logger.Error("Failed to find art project", "error", err)
+		logger.ErrorContext(ctx, "Failed to find art project", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Art project found successfully")
+	logger.InfoContext(ctx, "Art project found successfully")
 	return &artProject, nil
 }
 
 // UpdateArtProject updates an existing art project in the database.
 func (r *artProjectRepo) UpdateArtProject(ctx context.Context, artProject *model.ArtProject) error {
-	logger := slog.With("method", "UpdateArtProject", "artProjectID", artProject.ID)
+	logger := logctx.With(ctx, "method", "UpdateArtProject", "artProjectID", artProject.ID)
 
-	result := r.db.Save(artProject)
+	result := r.db.WithContext(ctx).Save(artProject)
 	if result.Error != nil {
-		logger.Error("Failed to update art project", "error", result.Error)
+		logger.ErrorContext(ctx, "Failed to update art project", "error", result.Error)
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		logger.Info("Art project not found for update")
+		logger.InfoContext(ctx, "Art project not found for update")
 		return model.ErrArtProjectNotFound
 	}
 
-	logger.Info("Art project updated successfully")
+	logger.InfoContext(ctx, "Art project updated successfully")
 	return nil
 }
 
 // DeleteArtProject removes an art project from the database.
 func (r *artProjectRepo) DeleteArtProject(ctx context.Context, id string) error {
-	logger := slog.With("method", "DeleteArtProject", "artProjectID", id)
+	logger := logctx.With(ctx, "method", "DeleteArtProject", "artProjectID", id)
 
-	result := r.db.Delete(&model.ArtProject{}, "id = ?", id)
+	result := r.db.WithContext(ctx).Delete(&model.ArtProject{}, "id = ?", id)
 	if result.Error != nil {
-		logger.Error("Failed to delete art project", "error", result.Error)
+		logger.ErrorContext(ctx, "Failed to delete art project", "error", result.Error)
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		logger.Info("Art project not found for deletion")
+		logger.InfoContext(ctx, "Art project not found for deletion")
 		return model.ErrArtProjectNotFound
 	}
 
-	logger.Info("Art project deleted successfully")
+	logger.InfoContext(ctx, "Art project deleted successfully")
 	return nil
 }
 
 // SaveArtProjectAndRevision saves both an art project and its revision in a single transaction.
 func (r *artProjectRepo) SaveArtProjectAndRevision(ctx context.Context, artProject *model.ArtProject, revision *model.Revision) error {
-	logger := slog.With("method", "SaveArtProjectAndRevision", "artProjectID", artProject.ID, "revisionID", revision.ID)
+	logger := logctx.With(ctx, "method", "SaveArtProjectAndRevision", "artProjectID", artProject.ID, "revisionID", revision.ID)
 
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(artProject).Error; err != nil {
-			slog.ErrorContext(ctx, "Failed to create art project in transaction", "error", err, "projectID", artProject.ID)
+			logctx.From(ctx).ErrorContext(ctx, "Failed to create art project in transaction", "error", err, "projectID", artProject.ID)
 			return err
 		}
 
 		if err := tx.Create(revision).Error; err != nil {
-			slog.ErrorContext(ctx, "Failed to create revision in transaction", "error", err, "revisionID", revision.ID)
+			logctx.From(ctx).ErrorContext(ctx, "Failed to create revision in transaction", "error", err, "revisionID", revision.ID)
 			return err
 		}
 
@@ -127,197 +142,262 @@ func (r *artProjectRepo) SaveArtProjectAndRevision(ctx context.Context, artProje
 	})
 
 	if err != nil {
-		logger.Error("Failed to save art project and revision", "error", err)
+		logger.ErrorContext(ctx, "Failed to save art project and revision", "error", err)
 		return err
 	}
 
-	logger.Info("Art project and revision saved successfully")
+	logger.InfoContext(ctx, "Art project and revision saved successfully")
 	return nil
 }
 
 // SaveRevision adds a new revision to the database
 func (r *artProjectRepo) SaveRevision(ctx context.Context, revision *model.Revision) error {
-	logger := slog.With("method", "SaveRevision", "revisionID", revision.ID)
+	logger := logctx.With(ctx, "method", "SaveRevision", "revisionID", revision.ID)
 
-	if err := r.db.Create(revision).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(revision).Error; err != nil {
 		logger.ErrorContext(ctx, "Failed to save new art revision", "error", err)
 		return err
 	}
 
-	slog.InfoContext(ctx, "New art revision saved successfully")
+	logctx.From(ctx).InfoContext(ctx, "New art revision saved successfully")
 	return nil
 }
 
 // UpdateLatestRevision updates the latest revision ID for an art project.
 func (r *artProjectRepo) UpdateLatestRevision(ctx context.Context, artProjectID, revisionID uuid.UUID) error {
-	logger := slog.With("repo", "UpdateLatestRevision",
+	logger := logctx.With(ctx, "method", "UpdateLatestRevision",
 		"artProjectID", artProjectID,
 		"revisionID", revisionID)
 
-	if err := r.db.Model(&model.ArtProject{}).
+	if err := r.db.WithContext(ctx).Model(&model.ArtProject{}).
 		Where("id = ?", artProjectID).
 		Update("latest_revision_id", revisionID).Error; err != nil {
-		logger.Error("Failed to update latest revision", "error", err)
+		logger.ErrorContext(ctx, "Failed to update latest revision", "error", err)
 		return err
 	}
 
-	logger.Info("Latest revision updated successfully")
+	logger.InfoContext(ctx, "Latest revision updated successfully")
 	return nil
 }
 
 // ListLatestRevisions retrieves the latest revisions for all art projects belonging to the specified user.
 func (r *artProjectRepo) ListLatestRevisions(ctx context.Context, userID string) ([]model.Revision, error) {
-	logger := slog.With("method", "ListLatestRevisions", "userID", userID)
+	logger := logctx.With(ctx, "method", "ListLatestRevisions", "userID", userID)
 
 	var revisions []model.Revision
-	if err := r.db.Joins("JOIN art_projects ON art_projects.latest_revision_id = revisions.id").
+	if err := r.db.WithContext(ctx).Joins("JOIN art_projects ON art_projects.latest_revision_id = revisions.id").
 		Where("art_projects.user_id = ?", userID).
 		Find(&revisions).Error; err != nil {
-		logger.Error("Failed to list latest revisions", "error", err)
+		logger.ErrorContext(ctx, "Failed to list latest revisions", "error", err)
 		return nil, err
 	}
 
 	if len(revisions) == 0 {
-		logger.Info("No revisions found for user")
+		logger.InfoContext(ctx, "No revisions found for user")
 		return nil, model.ErrArtProjectNotFound
 	}
 
-	logger.Info("Latest revisions listed successfully", "count", len(revisions))
+	logger.InfoContext(ctx, "Latest revisions listed successfully", "count", len(revisions))
 	return revisions, nil
 }
 
 // ListAllArtProjects retrieves all art projects belonging to the specified user.
 func (r *artProjectRepo) ListAllArtProjects(ctx context.Context, userID string) ([]model.ArtProject, error) {
-	logger := slog.With("method", "ListAllArtProjects", "userID", userID)
+	logger := logctx.With(ctx, "method", "ListAllArtProjects", "userID", userID)
 
 	var artProjects []model.ArtProject
-	if err := r.db.Where("user_id = ?", userID).
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).
 		Order("created_at ASC").
 		Find(&artProjects).Error; err != nil {
-		logger.Error("Failed to list all art projects", "error", err)
+		logger.ErrorContext(ctx, "Failed to list all art projects", "error", err)
 		return nil, err
 	}
 
 	if len(artProjects) == 0 {
-		logger.Info("No art projects found for user")
+		logger.InfoContext(ctx, "No art projects found for user")
 		return nil, model.ErrArtProjectNotFound
 	}
 
-	logger.Info("All art projects listed successfully", "count", len(artProjects))
+	logger.InfoContext(ctx, "All art projects listed successfully", "count", len(artProjects))
 	return artProjects, nil
 }
 
 // ListAllRevisions retrieves all revisions for a specific art project.
 func (r *artProjectRepo) ListAllRevisions(ctx context.Context, artProjectID string) ([]model.Revision, error) {
-	logger := slog.With("method", "ListAllRevisions", "artProjectID", artProjectID)
+	logger := logctx.With(ctx, "method", "ListAllRevisions", "artProjectID", artProjectID)
 
 	var revisions []model.Revision
-	if err := r.db.Where("art_project_id = ?", artProjectID).Find(&revisions).Error; err != nil {
-		logger.Error("Failed to list all revisions", "error", err)
+	if err := r.db.WithContext(ctx).Where("art_project_id = ?", artProjectID).Find(&revisions).Error; err != nil {
+		logger.ErrorContext(ctx, "Failed to list all revisions", "error", err)
 		return nil, err
 	}
 
 	if len(revisions) == 0 {
-		logger.Info("No revisions found for art project")
+		logger.InfoContext(ctx, "No revisions found for art project")
 		return nil, model.ErrArtProjectNotFound
 	}
 
-	logger.Info("All revisions listed successfully", "count", len(revisions))
+	logger.InfoContext(ctx, "All revisions listed successfully", "count", len(revisions))
 	return revisions, nil
 }
 
 // GetMaxRevisionVersion retrieves the maximum revision version for a specific art project.
 func (r *artProjectRepo) GetMaxRevisionVersion(ctx context.Context, artProjectID string) (int, error) {
-	logger := slog.With("method", "GetMaxRevisionVersion", "artProjectID", artProjectID)
+	logger := logctx.With(ctx, "method", "GetMaxRevisionVersion", "artProjectID", artProjectID)
 
 	var maxVersion int
-	if err := r.db.Model(&model.Revision{}).
+	if err := r.db.WithContext(ctx).Model(&model.Revision{}).
 		Where("art_project_id = ?", artProjectID).
 		Select("COALESCE(MAX(version), 0)").
 		Scan(&maxVersion).Error; err != nil {
-		logger.Error("Failed to get max revision version", "error", err)
+		logger.ErrorContext(ctx, "Failed to get max revision version", "error", err)
 		return 0, err
 	}
 
-	logger.Info("Max revision version retrieved successfully", "maxVersion", maxVersion)
+	logger.InfoContext(ctx, "Max revision version retrieved successfully", "maxVersion", maxVersion)
 	return maxVersion, nil
 }
 
 // FindByStashID retrieves all art projects associated with a specific stash ID.
 func (r *artProjectRepo) FindByStashID(ctx context.Context, stashID string) ([]model.ArtProject, error) {
-	logger := slog.With("method", "FindByStashID", "stashID", stashID)
+	logger := logctx.With(ctx, "method", "FindByStashID", "stashID", stashID)
 
 	var artProjects []model.ArtProject
-	if err := r.db.Where("stash_id = ?", stashID).
+	if err := r.db.WithContext(ctx).Where("stash_id = ?", stashID).
 		Order("created_at ASC").
 		Find(&artProjects).Error; err != nil {
-		logger.Error("Failed to find art projects by stash ID", "error", err)
+		logger.ErrorContext(ctx, "Failed to find art projects by stash ID", "error", err)
 		return nil, err
 	}
 
 	if len(artProjects) == 0 {
-		logger.Info("No art projects found for stash ID")
+		logger.InfoContext(ctx, "No art projects found for stash ID")
 		return nil, model.ErrArtProjectNotFound
 	}
 
-	logger.Info("Art projects found successfully", "count", len(artProjects))
+	logger.InfoContext(ctx, "Art projects found successfully", "count", len(artProjects))
 	return artProjects, nil
 }
 
 // FindByUserID retrieves all art projects for a specific user.
 func (r *artProjectRepo) FindByUserID(ctx context.Context, userID string) ([]model.ArtProject, error) {
-	logger := slog.With("method", "FindByUserID", "userID", userID)
+	logger := logctx.With(ctx, "method", "FindByUserID", "userID", userID)
 
 	var artProjects []model.ArtProject
-	if err := r.db.Where("user_id = ?", userID).
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).
 		Order("created_at ASC").
 		Find(&artProjects).Error; err != nil {
-		logger.Error("Failed to find art projects by user ID", "error", err)
+		logger.ErrorContext(ctx, "Failed to find art projects by user ID", "error", err)
 		return nil, err
 	}
 
 	if len(artProjects) == 0 {
-		logger.Info("No art projects found for user")
+		logger.InfoContext(ctx, "No art projects found for user")
 		return nil, model.ErrArtProjectNotFound
 	}
 
-	logger.Info("Art projects found successfully", "count", len(artProjects))
+	logger.InfoContext(ctx, "Art projects found successfully", "count", len(artProjects))
 	return artProjects, nil
 }
 
 // FindRevisionByID retrieves a revision by its ID
 func (r *artProjectRepo) FindRevisionByID(ctx context.Context, id string) (*model.Revision, error) {
-	logger := slog.With("method", "FindRevisionByID", "revisionID", id)
+	logger := logctx.With(ctx, "method", "FindRevisionByID", "revisionID", id)
 
 	var revision model.Revision
-	if err := r.db.Preload("ArtProject").First(&revision, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("ArtProject").First(&revision, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.Info("Revision not found")
+			logger.InfoContext(ctx, "Revision not found")
 			return nil, model.ErrArtProjectNotFound
 		}
-		logger.Error("Failed to get revision by ID", "error", err)
+		logger.ErrorContext(ctx, "Failed to get revision by ID", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Revision retrieved successfully")
+	logger.InfoContext(ctx, "Revision retrieved successfully")
 	return &revision, nil
 }
 
 // GetRevisionByID retrieves a revision by its ID.
 func (r *artProjectRepo) GetRevisionByID(ctx context.Context, id string) (*model.Revision, error) {
-	logger := slog.With("method", "GetRevisionByID", "revisionID", id)
+	logger := logctx.With(ctx, "method", "GetRevisionByID", "revisionID", id)
 
 	var revision model.Revision
-	if err := r.db.First(&revision, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&revision, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.Info("Revision not found")
+			logger.InfoContext(ctx, "Revision not found")
 			return nil, model.ErrArtProjectNotFound
 		}
 
-		logger.Error("Failed to get revision", "error", err)
+		logger.ErrorContext(ctx, "Failed to get revision", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Revision retrieved successfully")
+	logger.InfoContext(ctx, "Revision retrieved successfully")
 	return &revision, nil
 }
+
+// FindRevisions retrieves revisions matching the filter, newest art projects first or
+// newest revisions first with OrderByRevision. Art projects must carry all of the filter tags.
+func (r *artProjectRepo) FindRevisions(ctx context.Context, filter RevisionFilter) ([]model.Revision, error) {
+	logger := logctx.With(ctx, "method", "FindRevisions", "userID", filter.UserID, "published", filter.Published, "tags", filter.Tags)
+
+	join := "JOIN art_projects ON art_projects.latest_revision_id = revisions.id"
+	if filter.Published {
+		join = "JOIN art_projects ON art_projects.published_revision_id = revisions.id"
+	}
+
+	query := r.db.WithContext(ctx).Preload("ArtProject").Joins(join).Where("art_projects.user_id = ?", filter.UserID)
+
+	if len(filter.Tags) > 0 {
+		tagged := r.db.WithContext(ctx).Table("art_project_tags").
+			Select("art_project_tags.art_project_id").
+			Joins("JOIN tags ON tags.id = art_project_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags).
+			Group("art_project_tags.art_project_id").
+			Having("COUNT(DISTINCT tags.name) = ?", len(filter.Tags))
+		query = query.Where("art_projects.id IN (?)", tagged)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("art_projects.created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("art_projects.created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	order := "art_projects.created_at DESC"
+	if filter.OrderByRevision {
+		order = "revisions.created_at DESC"
+	}
+
+	var revisions []model.Revision
+	if err := query.Order(order).Find(&revisions).Error; err != nil {
+		logger.ErrorContext(ctx, "Failed to find revisions", "error", err)
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Revisions found successfully", "count", len(revisions))
+	return revisions, nil
+}
+
+// walkBatchSize is the number of revisions WalkRevisions loads at a time.
+const walkBatchSize = 500
+
+// WalkRevisions calls fn with all revisions in batches ordered by ID, an error from fn stops the walk.
+func (r *artProjectRepo) WalkRevisions(ctx context.Context, fn func(revisions []model.Revision) error) error {
+	logger := logctx.With(ctx, "method", "WalkRevisions")
+
+	var batch []model.Revision
+	result := r.db.WithContext(ctx).FindInBatches(&batch, walkBatchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	})
+	if result.Error != nil {
+		logger.ErrorContext(ctx, "Failed to walk revisions", "error", result.Error)
+		return result.Error
+	}
+
+	return nil
+}
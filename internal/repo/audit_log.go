@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// AuditLogRepository defines the interface for audit log database operations.
+type AuditLogRepository interface {
+	CreateEntry(ctx context.Context, entry *model.AuditLog) error
+	ListEntries(ctx context.Context, page, perPage int) ([]model.AuditLog, int64, error)
+}
+
+type auditLogRepo struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new instance of AuditLogRepository.
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepo{db: db}
+}
+
+// CreateEntry appends an entry to the audit log.
+func (r *auditLogRepo) CreateEntry(ctx context.Context, entry *model.AuditLog) error {
+	logger := logctx.With(ctx, "method", "CreateEntry", "action", entry.Action, "actorID", entry.ActorID)
+
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		logger.Error("Failed to create audit log entry", "error", err)
+		return err
+	}
+
+	logger.Info("Audit log entry created", "targetID", entry.TargetID)
+	return nil
+}
+
+// ListEntries retrieves a page of audit log entries, newest first.
+func (r *auditLogRepo) ListEntries(ctx context.Context, page, perPage int) ([]model.AuditLog, int64, error) {
+	logger := logctx.With(ctx, "method", "ListEntries", "page", page)
+
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&model.AuditLog{}).Count(&total).Error; err != nil {
+		logger.Error("Failed to count audit log entries", "error", err)
+		return nil, 0, err
+	}
+
+	var entries []model.AuditLog
+	err := r.db.WithContext(ctx).Order("created_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&entries).Error
+	if err != nil {
+		logger.Error("Failed to list audit log entries", "error", err)
+		return nil, 0, err
+	}
+
+	logger.Info("Audit log entries listed", "count", len(entries), "total", total)
+	return entries, total, nil
+}
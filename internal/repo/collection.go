@@ -3,11 +3,12 @@ package repo
 import (
 	"context"
 	"errors"
-	"log/slog"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/google/uuid"
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 )
 
@@ -18,9 +19,17 @@ type CollectionRepository interface {
 	UpdateCollection(ctx context.Context, collection *model.Collection) error
 	DeleteCollection(ctx context.Context, id string) error
 	FindByUserID(ctx context.Context, userID string) ([]model.Collection, error)
-	AddRevisionToCollection(ctx context.Context, collectionID, revisionID string) error
+	AddRevisionToCollection(ctx context.Context, item *model.CollectionArtProject) error
 	RemoveRevisionFromCollection(ctx context.Context, collectionID, revisionID string) error
-	GetRevisionsByCollectionID(ctx context.Context, collectionID string) ([]model.Revision, error)
+	UpdateCollectionItem(ctx context.Context, item *model.CollectionArtProject) error
+	ReorderCollectionItems(ctx context.Context, collectionID string, revisionIDs []uuid.UUID) error
+	GetRevisionsByCollectionID(ctx context.Context, collectionID string) ([]model.CollectionArtProject, error)
+	AddMember(ctx context.Context, member *model.CollectionMember) error
+	FindMember(ctx context.Context, collectionID, userID string) (*model.CollectionMember, error)
+	UpdateMember(ctx context.Context, member *model.CollectionMember) error
+	RemoveMember(ctx context.Context, collectionID, userID string) error
+	ListMembers(ctx context.Context, collectionID string) ([]model.CollectionMember, error)
+	ListInvitations(ctx context.Context, userID string) ([]model.CollectionMember, error)
 }
 
 type collectionRepo struct {
@@ -34,9 +43,9 @@ func NewCollectionRepository(db *gorm.DB) CollectionRepository {
 
 // CreateCollection adds a new collection to the database.
 func (r *collectionRepo) CreateCollection(ctx context.Context, collection *model.Collection) error {
-	logger := slog.With("method", "CreateCollection", "collectionID", collection.ID)
+	logger := logctx.With(ctx, "method", "CreateCollection", "collectionID", collection.ID)
 
-	if err := r.db.Create(collection).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(collection).Error; err != nil {
 		logger.Error("Failed to create collection", "error", err)
 		return err
 	}
@@ -47,10 +56,10 @@ func (r *collectionRepo) CreateCollection(ctx context.Context, collection *model
 
 // FindCollectionByID retrieves a collection by its ID.
 func (r *collectionRepo) FindCollectionByID(ctx context.Context, id string) (*model.Collection, error) {
-	logger := slog.With("method", "FindCollectionByID", "collectionID", id)
+	logger := logctx.With(ctx, "method", "FindCollectionByID", "collectionID", id)
 
 	var collection model.Collection
-	if err := r.db.Preload("User").First(&collection, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("User").First(&collection, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Info("Collection not found")
 			return nil, model.ErrCollectionNotFound
@@ -65,9 +74,9 @@ func (r *collectionRepo) FindCollectionByID(ctx context.Context, id string) (*mo
 
 // UpdateCollection updates an existing collection in the database.
 func (r *collectionRepo) UpdateCollection(ctx context.Context, collection *model.Collection) error {
-	logger := slog.With("method", "UpdateCollection", "collectionID", collection.ID)
+	logger := logctx.With(ctx, "method", "UpdateCollection", "collectionID", collection.ID)
 
-	result := r.db.Save(collection)
+	result := r.db.WithContext(ctx).Save(collection)
 	if result.Error != nil {
 		logger.Error("Failed to update collection", "error", result.Error)
 		return result.Error
@@ -84,9 +93,9 @@ func (r *collectionRepo) UpdateCollection(ctx context.Context, collection *model
 
 // DeleteCollection removes a collection from the database.
 func (r *collectionRepo) DeleteCollection(ctx context.Context, id string) error {
-	logger := slog.With("method", "DeleteCollection", "collectionID", id)
+	logger := logctx.With(ctx, "method", "DeleteCollection", "collectionID", id)
 
-	result := r.db.Delete(&model.Collection{}, "id = ?", id)
+	result := r.db.WithContext(ctx).Delete(&model.Collection{}, "id = ?", id)
 	if result.Error != nil {
 		logger.Error("Failed to delete collection", "error", result.Error)
 		return result.Error
@@ -101,12 +110,16 @@ func (r *collectionRepo) DeleteCollection(ctx context.Context, id string) error
 	return nil
 }
 
-// FindByUserID retrieves all collections for a specific user.
+// FindByUserID retrieves all collections owned by a user or shared with them.
 func (r *collectionRepo) FindByUserID(ctx context.Context, userID string) ([]model.Collection, error) {
-	logger := slog.With("method", "FindByUserID", "userID", userID)
+	logger := logctx.With(ctx, "method", "FindByUserID", "userID", userID)
+
+	shared := r.db.WithContext(ctx).Model(&model.CollectionMember{}).
+		Select("collection_id").
+		Where("user_id = ? AND accepted_at IS NOT NULL", userID)
 
 	var collections []model.Collection
-	if err := r.db.Where("user_id = ?", userID).Find(&collections).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ? OR id IN (?)", userID, shared).Find(&collections).Error; err != nil {
 		logger.Error("Failed to find collections by user ID", "error", err)
 		return nil, err
 	}
@@ -120,29 +133,36 @@ func (r *collectionRepo) FindByUserID(ctx context.Context, userID string) ([]mod
 	return collections, nil
 }
 
-// AddRevisionToCollection adds an art project to a collection.
-func (r *collectionRepo) AddRevisionToCollection(ctx context.Context, collectionID, revisionID string) error {
-	logger := slog.With("repo", "AddRevisionToCollection", "collectionID", collectionID, "revisionID", revisionID)
-
-	collectionArtProject := model.CollectionArtProject{
-		CollectionID: uuid.MustParse(collectionID),
-		RevisionID:   uuid.MustParse(revisionID),
-	}
+// AddRevisionToCollection appends a revision to the end of a collection.
+func (r *collectionRepo) AddRevisionToCollection(ctx context.Context, item *model.CollectionArtProject) error {
+	logger := logctx.With(ctx, "method", "AddRevisionToCollection", "collectionID", item.CollectionID, "revisionID", item.RevisionID)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var maxPosition int
+		if err := tx.Model(&model.CollectionArtProject{}).
+			Where("collection_id = ?", item.CollectionID).
+			Select("COALESCE(MAX(position), -1)").
+			Scan(&maxPosition).Error; err != nil {
+			return err
+		}
 
-	if err := r.db.Create(&collectionArtProject).Error; err != nil {
+		item.Position = maxPosition + 1
+		return tx.Create(item).Error
+	})
+	if err != nil {
 		logger.Error("Failed to add revision to collection", "error", err)
 		return err
 	}
 
-	logger.Info("Art project revision added to collection successfully")
+	logger.Info("Art project revision added to collection successfully", "position", item.Position)
 	return nil
 }
 
 // RemoveRevisionFromCollection removes an art project from a collection.
 func (r *collectionRepo) RemoveRevisionFromCollection(ctx context.Context, collectionID, artProjectID string) error {
-	logger := slog.With("repo", "RemoveRevisionFromCollection", "collectionID", collectionID, "artProjectID", artProjectID)
+	logger := logctx.With(ctx, "method", "RemoveRevisionFromCollection", "collectionID", collectionID, "artProjectID", artProjectID)
 
-	result := r.db.Where("collection_id = ? AND revision_id = ?", collectionID, artProjectID).
+	result := r.db.WithContext(ctx).Where("collection_id = ? AND revision_id = ?", collectionID, artProjectID).
 		Delete(&model.CollectionArtProject{})
 
 	if result.Error != nil {
@@ -159,29 +179,192 @@ func (r *collectionRepo) RemoveRevisionFromCollection(ctx context.Context, colle
 	return nil
 }
 
-func (r *collectionRepo) GetRevisionsByCollectionID(ctx context.Context, collectionID string) ([]model.Revision, error) {
-	logger := slog.With("repo", "GetRevisionsByCollectionID", "collectionID", collectionID)
+// UpdateCollectionItem updates the caption of a revision in a collection.
+func (r *collectionRepo) UpdateCollectionItem(ctx context.Context, item *model.CollectionArtProject) error {
+	logger := logctx.With(ctx, "method", "UpdateCollectionItem", "collectionID", item.CollectionID, "revisionID", item.RevisionID)
 
-	var revisions []model.Revision
-	err := r.db.Table("revisions").
-		Joins("JOIN collection_art_projects ON revisions.id = collection_art_projects.revision_id").
-		Where("collection_art_projects.collection_id = ?", collectionID).
-		Find(&revisions).Error
+	result := r.db.WithContext(ctx).Model(&model.CollectionArtProject{}).
+		Where("collection_id = ? AND revision_id = ?", item.CollectionID, item.RevisionID).
+		Update("caption", item.Caption)
 
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.Info("No revisions found for the collection")
-			return nil, model.ErrRevisionNotFound
+	if result.Error != nil {
+		logger.Error("Failed to update collection item", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("Revision not found in collection")
+		return model.ErrRevisionNotFound
+	}
+
+	logger.Info("Collection item updated successfully")
+	return nil
+}
+
+// ReorderCollectionItems sets the position of each revision to its index in revisionIDs.
+func (r *collectionRepo) ReorderCollectionItems(ctx context.Context, collectionID string, revisionIDs []uuid.UUID) error {
+	logger := logctx.With(ctx, "method", "ReorderCollectionItems", "collectionID", collectionID, "count", len(revisionIDs))
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, revisionID := range revisionIDs {
+			result := tx.Model(&model.CollectionArtProject{}).
+				Where("collection_id = ? AND revision_id = ?", collectionID, revisionID).
+				Update("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return model.ErrRevisionNotFound
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to reorder collection items", "error", err)
+		return err
+	}
+
+	logger.Info("Collection items reordered successfully")
+	return nil
+}
+
+// GetRevisionsByCollectionID retrieves the revisions of a collection ordered by their position.
+func (r *collectionRepo) GetRevisionsByCollectionID(ctx context.Context, collectionID string) ([]model.CollectionArtProject, error) {
+	logger := logctx.With(ctx, "method", "GetRevisionsByCollectionID", "collectionID", collectionID)
+
+	var items []model.CollectionArtProject
+	err := r.db.WithContext(ctx).Preload("Revision.ArtProject").
+		Where("collection_id = ?", collectionID).
+		Order("position ASC, created_at ASC").
+		Find(&items).Error
+
+	if err != nil {
 		logger.Error("Failed to retrieve revisions for the collection", "error", err)
 		return nil, err
 	}
 
-	if len(revisions) == 0 {
+	if len(items) == 0 {
 		logger.Info("No revisions found for the collection")
 		return nil, model.ErrRevisionNotFound
 	}
 
-	logger.Info("Revisions retrieved successfully", "count", len(revisions))
-	return revisions, nil
+	logger.Info("Revisions retrieved successfully", "count", len(items))
+	return items, nil
+}
+
+// AddMember stores an invitation of a user to a collection.
+func (r *collectionRepo) AddMember(ctx context.Context, member *model.CollectionMember) error {
+	logger := logctx.With(ctx, "method", "AddMember", "collectionID", member.CollectionID, "userID", member.UserID)
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(member)
+	if result.Error != nil {
+		logger.Error("Failed to add collection member", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("User is already a member of the collection")
+		return model.ErrAlreadyMember
+	}
+
+	logger.Info("Collection member added successfully", "role", member.Role)
+	return nil
+}
+
+// FindMember retrieves the membership of a user in a collection.
+func (r *collectionRepo) FindMember(ctx context.Context, collectionID, userID string) (*model.CollectionMember, error) {
+	logger := logctx.With(ctx, "method", "FindMember", "collectionID", collectionID, "userID", userID)
+
+	var member model.CollectionMember
+	err := r.db.WithContext(ctx).Where("collection_id = ? AND user_id = ?", collectionID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("Collection member not found")
+			return nil, model.ErrMemberNotFound
+		}
+		logger.Error("Failed to find collection member", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Collection member found successfully")
+	return &member, nil
+}
+
+// UpdateMember updates the role and acceptance time of a collection member.
+func (r *collectionRepo) UpdateMember(ctx context.Context, member *model.CollectionMember) error {
+	logger := logctx.With(ctx, "method", "UpdateMember", "collectionID", member.CollectionID, "userID", member.UserID)
+
+	result := r.db.WithContext(ctx).Model(&model.CollectionMember{}).
+		Where("collection_id = ? AND user_id = ?", member.CollectionID, member.UserID).
+		Updates(map[string]interface{}{"role": member.Role, "accepted_at": member.AcceptedAt})
+
+	if result.Error != nil {
+		logger.Error("Failed to update collection member", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("Collection member not found for update")
+		return model.ErrMemberNotFound
+	}
+
+	logger.Info("Collection member updated successfully")
+	return nil
+}
+
+// RemoveMember removes a member or a pending invitation from a collection.
+func (r *collectionRepo) RemoveMember(ctx context.Context, collectionID, userID string) error {
+	logger := logctx.With(ctx, "method", "RemoveMember", "collectionID", collectionID, "userID", userID)
+
+	result := r.db.WithContext(ctx).Where("collection_id = ? AND user_id = ?", collectionID, userID).
+		Delete(&model.CollectionMember{})
+
+	if result.Error != nil {
+		logger.Error("Failed to remove collection member", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("Collection member not found")
+		return model.ErrMemberNotFound
+	}
+
+	logger.Info("Collection member removed successfully")
+	return nil
+}
+
+// ListMembers retrieves the members and pending invitations of a collection.
+func (r *collectionRepo) ListMembers(ctx context.Context, collectionID string) ([]model.CollectionMember, error) {
+	logger := logctx.With(ctx, "method", "ListMembers", "collectionID", collectionID)
+
+	var members []model.CollectionMember
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("collection_id = ?", collectionID).
+		Order("created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		logger.Error("Failed to list collection members", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Collection members listed successfully", "count", len(members))
+	return members, nil
+}
+
+// ListInvitations retrieves the pending invitations of a user.
+func (r *collectionRepo) ListInvitations(ctx context.Context, userID string) ([]model.CollectionMember, error) {
+	logger := logctx.With(ctx, "method", "ListInvitations", "userID", userID)
+
+	var members []model.CollectionMember
+	err := r.db.WithContext(ctx).Preload("Collection").Preload("User").
+		Where("user_id = ? AND accepted_at IS NULL", userID).
+		Order("created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		logger.Error("Failed to list invitations", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Invitations listed successfully", "count", len(members))
+	return members, nil
 }
@@ -2,14 +2,18 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
-	"log/slog"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 )
 
@@ -18,6 +22,12 @@ type FileStorageRepository interface {
 	SaveRevisionFile(ctx context.Context, fileData io.Reader, userID, artProjectID string, version int) (string, os.FileInfo, error)
 	GetRevisionFile(ctx context.Context, userID, artProjectID string, version int) (io.ReadCloser, error)
 	FindStashByUserID(ctx context.Context, userID string) (*model.Stash, error)
+	StatFile(ctx context.Context, path string) (os.FileInfo, error)
+	OpenFile(ctx context.Context, path string) (io.ReadCloser, error)
+	SaveFile(ctx context.Context, path string, data io.Reader) (os.FileInfo, error)
+	WalkFiles(ctx context.Context, fn func(path string, info os.FileInfo) error) error
+	RemoveFile(ctx context.Context, path string) error
+	CheckWritable(ctx context.Context) error
 }
 
 type fileStorageRepo struct {
@@ -32,66 +42,200 @@ func NewFileStorageRepository(db *gorm.DB, root string) FileStorageRepository {
 
 // SaveRevisionFile saves a new revision of a file.
 func (r *fileStorageRepo) SaveRevisionFile(ctx context.Context, fileData io.Reader, userID, artProjectID string, version int) (string, os.FileInfo, error) {
-	logger := slog.With("method", "SaveRevisionFile", "userID", userID, "artProjectID", artProjectID, "version", version)
+	logger := logctx.With(ctx, "method", "SaveRevisionFile", "userID", userID, "artProjectID", artProjectID, "version", version)
 
 	filePath := filepath.Join(r.root, userID, artProjectID, "revisions", "v"+strconv.Itoa(version))
 	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-		logger.Error("Failed to create directories", "error", err)
+		logger.ErrorContext(ctx, "Failed to create directories", "error", err)
 		return "", nil, err
 	}
 
 	file, err := os.Create(filePath)
 	if err != nil {
-		logger.Error("Failed to create file", "error", err)
+		logger.ErrorContext(ctx, "Failed to create file", "error", err)
 		return "", nil, err
 	}
 	defer file.Close()
 
 	if _, err = io.Copy(file, fileData); err != nil {
-		logger.Error("Failed to write data to file", "error", err)
+		logger.ErrorContext(ctx, "Failed to write data to file", "error", err)
 		return "", nil, err
 	}
 
 	fileInfo, err := file.Stat()
 	if err != nil {
-		logger.Error("Failed to get file info", "error", err)
+		logger.ErrorContext(ctx, "Failed to get file info", "error", err)
 		return "", nil, err
 	}
 
-	logger.Info("Revision file saved successfully")
+	logger.InfoContext(ctx, "Revision file saved successfully")
 	return filePath, fileInfo, nil
 }
 
 // GetRevisionFile retrieves a specific revision of a file.
 func (r *fileStorageRepo) GetRevisionFile(ctx context.Context, userID, artProjectID string, version int) (io.ReadCloser, error) {
-	logger := slog.With("method", "GetRevisionFile", "userID", userID, "artProjectID", artProjectID, "version", version)
+	logger := logctx.With(ctx, "method", "GetRevisionFile", "userID", userID, "artProjectID", artProjectID, "version", version)
 
 	filePath := filepath.Join(r.root, userID, artProjectID, "revisions", "v"+strconv.Itoa(version))
 	file, err := os.Open(filePath)
 	if err != nil {
-		logger.Error("Failed to open file", "error", err)
+		logger.ErrorContext(ctx, "Failed to open file", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Revision file retrieved successfully")
+	logger.InfoContext(ctx, "Revision file retrieved successfully")
 	return file, nil
 }
 
 // FindStashByUserID retrieves the stash for a specific user.
 func (r *fileStorageRepo) FindStashByUserID(ctx context.Context, userID string) (*model.Stash, error) {
-	logger := slog.With("method", "FindStashByUserID", "userID", userID)
+	logger := logctx.With(ctx, "method", "FindStashByUserID", "userID", userID)
 
 	var stash model.Stash
-	if err := r.db.Where("user_id = ?", userID).First(&stash).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&stash).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			logger.Info("Stash not found")
+			logger.InfoContext(ctx, "Stash not found")
 			return nil, model.ErrStashNotFound
 		}
 
-		logger.Error("Failed to find stash", "error", err)
+		logger.ErrorContext(ctx, "Failed to find stash", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Stash found successfully")
+	logger.InfoContext(ctx, "Stash found successfully")
 	return &stash, nil
 }
+
+// StatFile returns the file info of a stored file, the error satisfies os.IsNotExist for missing files.
+func (r *fileStorageRepo) StatFile(ctx context.Context, path string) (os.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// OpenFile opens a stored file for reading.
+func (r *fileStorageRepo) OpenFile(ctx context.Context, path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// SaveFile stores data at path under the storage root. The data is written to a
+// temporary file that replaces path once complete, so readers never see a partial file.
+func (r *fileStorageRepo) SaveFile(ctx context.Context, path string, data io.Reader) (os.FileInfo, error) {
+	logger := logctx.With(ctx, "method", "SaveFile", "path", path)
+
+	path = filepath.Clean(path)
+	if !strings.HasPrefix(path, filepath.Clean(r.root)+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s is not under the storage root", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		logger.ErrorContext(ctx, "Failed to create directories", "error", err)
+		return nil, err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".save-*")
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to create file", "error", err)
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to write data to file", "error", err)
+		return nil, err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		logger.ErrorContext(ctx, "Failed to move file into place", "error", err)
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "File saved")
+	return os.Stat(path)
+}
+
+// ThumbnailPath returns where the thumbnail of the revision file at revisionPath is stored.
+func ThumbnailPath(revisionPath string) string {
+	return revisionPath + ".thumb.jpg"
+}
+
+// WalkFiles calls fn for every regular file under the storage root. A missing storage
+// root has no files.
+func (r *fileStorageRepo) WalkFiles(ctx context.Context, fn func(path string, info os.FileInfo) error) error {
+	err := filepath.WalkDir(r.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == r.root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(path, info)
+	})
+	if err != nil {
+		logctx.From(ctx).ErrorContext(ctx, "Failed to walk storage root", "method", "WalkFiles", "root", r.root, "error", err)
+	}
+	return err
+}
+
+// RemoveFile removes a file under the storage root together with the directories it
+// leaves empty.
+func (r *fileStorageRepo) RemoveFile(ctx context.Context, path string) error {
+	logger := logctx.With(ctx, "method", "RemoveFile", "path", path)
+
+	root := filepath.Clean(r.root)
+	path = filepath.Clean(path)
+	if !strings.HasPrefix(path, root+string(filepath.Separator)) {
+		return fmt.Errorf("%s is not under the storage root", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		logger.ErrorContext(ctx, "Failed to remove file", "error", err)
+		return err
+	}
+
+	// Removing a directory fails while it has entries, which ends the cleanup.
+	for dir := filepath.Dir(path); dir != root; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+
+	logger.InfoContext(ctx, "File removed")
+	return nil
+}
+
+// CheckWritable creates and removes a probe file in the storage root.
+func (r *fileStorageRepo) CheckWritable(ctx context.Context) error {
+	if err := os.MkdirAll(r.root, os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(r.root, ".probe-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString("ok")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if removeErr := os.Remove(file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
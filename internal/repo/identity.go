@@ -0,0 +1,136 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// IdentityRepository defines the interface for external identity database operations.
+type IdentityRepository interface {
+	FindIdentity(ctx context.Context, provider, subject string) (*model.ExternalIdentity, error)
+	CreateIdentity(ctx context.Context, identity *model.ExternalIdentity) error
+	CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.ExternalIdentity) error
+	ListByUser(ctx context.Context, userID string) ([]model.ExternalIdentity, error)
+	DeleteIdentity(ctx context.Context, userID, id string) error
+}
+
+type identityRepo struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository creates a new instance of IdentityRepository.
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepo{db: db}
+}
+
+// FindIdentity retrieves the identity of a provider account with its user.
+func (r *identityRepo) FindIdentity(ctx context.Context, provider, subject string) (*model.ExternalIdentity, error) {
+	logger := logctx.With(ctx, "method", "FindIdentity", "provider", provider)
+
+	var identity model.ExternalIdentity
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Debug("External identity not found")
+			return nil, model.ErrIdentityNotFound
+		}
+		logger.Error("Failed to find external identity", "error", err)
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// CreateIdentity links a provider account to an existing user.
+func (r *identityRepo) CreateIdentity(ctx context.Context, identity *model.ExternalIdentity) error {
+	logger := logctx.With(ctx, "method", "CreateIdentity", "userID", identity.UserID, "provider", identity.Provider)
+
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		if isUniqueViolation(err, "idx_external_identity_subject") {
+			logger.Warn("External identity already linked")
+			return model.ErrIdentityLinked
+		}
+		logger.Error("Failed to create external identity", "error", err)
+		return err
+	}
+
+	logger.Info("External identity linked", "identityID", identity.ID)
+	return nil
+}
+
+// CreateUserWithIdentity creates a user and its first external identity in a single transaction.
+func (r *identityRepo) CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.ExternalIdentity) error {
+	logger := logctx.With(ctx, "method", "CreateUserWithIdentity", "userID", user.ID, "provider", identity.Provider)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			if isUniqueViolation(err, "uni_users_username") {
+				return model.ErrDuplicateUsername
+			}
+			return err
+		}
+
+		identity.UserID = user.ID
+		if err := tx.Create(identity).Error; err != nil {
+			if isUniqueViolation(err, "idx_external_identity_subject") {
+				return model.ErrIdentityLinked
+			}
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to create user with external identity", "error", err)
+		return err
+	}
+
+	logger.Info("User created with external identity", "identityID", identity.ID)
+	return nil
+}
+
+// ListByUser retrieves the external identities of a user, oldest first.
+func (r *identityRepo) ListByUser(ctx context.Context, userID string) ([]model.ExternalIdentity, error) {
+	logger := logctx.With(ctx, "method", "ListByUser", "userID", userID)
+
+	var identities []model.ExternalIdentity
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		logger.Error("Failed to list external identities", "error", err)
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// DeleteIdentity unlinks an external identity of a user.
+func (r *identityRepo) DeleteIdentity(ctx context.Context, userID, id string) error {
+	logger := logctx.With(ctx, "method", "DeleteIdentity", "userID", userID, "identityID", id)
+
+	result := r.db.WithContext(ctx).Delete(&model.ExternalIdentity{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		logger.Error("Failed to delete external identity", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("External identity not found")
+		return model.ErrIdentityNotFound
+	}
+
+	logger.Info("External identity unlinked")
+	return nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique violation of the named constraint.
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// InviteRepository defines the interface for registration invite database operations.
+type InviteRepository interface {
+	CreateInvite(ctx context.Context, invite *model.Invite) error
+	ListInvites(ctx context.Context) ([]model.Invite, error)
+	DeleteInvite(ctx context.Context, id string) error
+	RedeemInvite(ctx context.Context, codeHash string, user *model.User) error
+}
+
+type inviteRepo struct {
+	db *gorm.DB
+}
+
+// NewInviteRepository creates a new instance of InviteRepository.
+func NewInviteRepository(db *gorm.DB) InviteRepository {
+	return &inviteRepo{db: db}
+}
+
+// CreateInvite stores a new invite.
+func (r *inviteRepo) CreateInvite(ctx context.Context, invite *model.Invite) error {
+	logger := logctx.With(ctx, "method", "CreateInvite", "createdBy", invite.CreatedBy)
+
+	if err := r.db.WithContext(ctx).Create(invite).Error; err != nil {
+		logger.Error("Failed to create invite", "error", err)
+		return err
+	}
+
+	logger.Info("Invite created successfully", "inviteID", invite.ID)
+	return nil
+}
+
+// ListInvites retrieves all invites, newest first.
+func (r *inviteRepo) ListInvites(ctx context.Context) ([]model.Invite, error) {
+	logger := logctx.With(ctx, "method", "ListInvites")
+
+	var invites []model.Invite
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&invites).Error; err != nil {
+		logger.Error("Failed to list invites", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Invites listed successfully", "count", len(invites))
+	return invites, nil
+}
+
+// DeleteInvite removes an unused invite.
+func (r *inviteRepo) DeleteInvite(ctx context.Context, id string) error {
+	logger := logctx.With(ctx, "method", "DeleteInvite", "inviteID", id)
+
+	result := r.db.WithContext(ctx).Where("used_at IS NULL").Delete(&model.Invite{}, "id = ?", id)
+	if result.Error != nil {
+		logger.Error("Failed to delete invite", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("Unused invite not found for deletion")
+		return model.ErrInviteNotFound
+	}
+
+	logger.Info("Invite deleted successfully")
+	return nil
+}
+
+// RedeemInvite creates the user and marks the invite as used in a single transaction,
+// so an invite can never be claimed by more than one account.
+func (r *inviteRepo) RedeemInvite(ctx context.Context, codeHash string, user *model.User) error {
+	logger := logctx.With(ctx, "method", "RedeemInvite", "userID", user.ID)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uni_users_username" {
+				return model.ErrDuplicateUsername
+			}
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&model.Invite{}).
+			Where("code_hash = ? AND used_at IS NULL", codeHash).
+			Where("expires_at IS NULL OR expires_at > ?", now).
+			Updates(map[string]interface{}{"used_by": user.ID, "used_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrInvalidInviteCode
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to redeem invite", "error", err)
+		return err
+	}
+
+	logger.Info("Invite redeemed successfully")
+	return nil
+}
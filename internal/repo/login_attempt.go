@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// LoginAttemptRepository defines the interface for failed login tracking database operations.
+type LoginAttemptRepository interface {
+	FindAttempts(ctx context.Context, keys ...string) ([]model.LoginAttempt, error)
+	RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*model.LoginAttempt, error)
+	LockKey(ctx context.Context, key string, until time.Time) error
+	ResetAttempts(ctx context.Context, keys ...string) error
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+type loginAttemptRepo struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository creates a new instance of LoginAttemptRepository.
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepo{db: db}
+}
+
+// FindAttempts retrieves the tracked attempts of the given keys, keys without failures are omitted.
+func (r *loginAttemptRepo) FindAttempts(ctx context.Context, keys ...string) ([]model.LoginAttempt, error) {
+	logger := logctx.With(ctx, "method", "FindAttempts")
+
+	var attempts []model.LoginAttempt
+	if err := r.db.WithContext(ctx).Where("key IN ?", keys).Find(&attempts).Error; err != nil {
+		logger.Error("Failed to find login attempts", "error", err)
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// RecordFailure counts a failed login for the key and returns the updated attempt.
+// The count starts over when the previous failure is older than window.
+func (r *loginAttemptRepo) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*model.LoginAttempt, error) {
+	logger := logctx.With(ctx, "method", "RecordFailure", "key", key)
+
+	attempt := model.LoginAttempt{Key: key, Failures: 1, LastFailureAt: at}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures": gorm.Expr(
+					"CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END",
+					at.Add(-window),
+				),
+				"last_failure_at": at,
+			}),
+		}).Create(&attempt).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("key = ?", key).First(&attempt).Error
+	})
+	if err != nil {
+		logger.Error("Failed to record login failure", "error", err)
+		return nil, err
+	}
+
+	logger.Debug("Login failure recorded", "failures", attempt.Failures)
+	return &attempt, nil
+}
+
+// LockKey blocks logins for the key until the given time.
+func (r *loginAttemptRepo) LockKey(ctx context.Context, key string, until time.Time) error {
+	logger := logctx.With(ctx, "method", "LockKey", "key", key)
+
+	err := r.db.WithContext(ctx).Model(&model.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+	if err != nil {
+		logger.Error("Failed to lock login key", "error", err)
+		return err
+	}
+
+	logger.Info("Login key locked", "until", until)
+	return nil
+}
+
+// ResetAttempts forgets the failures and locks of the given keys.
+func (r *loginAttemptRepo) ResetAttempts(ctx context.Context, keys ...string) error {
+	logger := logctx.With(ctx, "method", "ResetAttempts")
+
+	if err := r.db.WithContext(ctx).Delete(&model.LoginAttempt{}, "key IN ?", keys).Error; err != nil {
+		logger.Error("Failed to reset login attempts", "error", err)
+		return err
+	}
+
+	logger.Debug("Login attempts reset", "keys", keys)
+	return nil
+}
+
+// DeleteStale removes attempts whose last failure is before the given time and which are not locked anymore.
+func (r *loginAttemptRepo) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	logger := logctx.With(ctx, "method", "DeleteStale")
+
+	result := r.db.WithContext(ctx).Delete(&model.LoginAttempt{},
+		"last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, time.Now())
+	if result.Error != nil {
+		logger.Error("Failed to delete stale login attempts", "error", result.Error)
+		return 0, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Info("Stale login attempts deleted", "count", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
+}
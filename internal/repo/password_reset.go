@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// PasswordResetRepository defines the interface for password reset token database operations.
+type PasswordResetRepository interface {
+	CreateResetToken(ctx context.Context, token *model.PasswordResetToken) error
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*model.User, error)
+}
+
+type passwordResetRepo struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository creates a new instance of PasswordResetRepository.
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepo{db: db}
+}
+
+// CreateResetToken stores a new reset token, replacing any earlier token of the user.
+func (r *passwordResetRepo) CreateResetToken(ctx context.Context, token *model.PasswordResetToken) error {
+	logger := logctx.With(ctx, "method", "CreateResetToken", "userID", token.UserID)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Delete(&model.PasswordResetToken{}, "user_id = ? OR expires_at <= ?", token.UserID, time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(token).Error
+	})
+	if err != nil {
+		logger.Error("Failed to create password reset token", "error", err)
+		return err
+	}
+
+	logger.Info("Password reset token created", "tokenID", token.ID)
+	return nil
+}
+
+// ResetPassword consumes an unexpired reset token and sets the new password hash of its user.
+// Every reset token, session and API token of the user is removed in the same transaction.
+// Disabled users are refused with model.ErrAccountDisabled.
+func (r *passwordResetRepo) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*model.User, error) {
+	logger := logctx.With(ctx, "method", "ResetPassword")
+
+	var user model.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var token model.PasswordResetToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).
+			First(&token).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return model.ErrInvalidResetToken
+			}
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", token.UserID).Error; err != nil {
+			return err
+		}
+		if user.Disabled {
+			return model.ErrAccountDisabled
+		}
+
+		if err := tx.Model(&user).Update("password", passwordHash).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&model.PasswordResetToken{}, "user_id = ?", token.UserID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&model.Session{}, "user_id = ?", token.UserID).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.APIToken{}, "user_id = ?", token.UserID).Error
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidResetToken) {
+			logger.Info("Password reset token not found or expired")
+			return nil, err
+		}
+		if errors.Is(err, model.ErrAccountDisabled) {
+			logger.Info("Password reset refused for disabled account", "userID", user.ID)
+			return nil, err
+		}
+		logger.Error("Failed to reset password", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Password reset", "userID", user.ID)
+	return &user, nil
+}
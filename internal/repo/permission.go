@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// PermissionRepository defines the interface for role permission database operations.
+type PermissionRepository interface {
+	FindByRole(ctx context.Context, role string) ([]string, error)
+	RoleExists(ctx context.Context, role string) (bool, error)
+}
+
+type permissionRepo struct {
+	db *gorm.DB
+}
+
+// NewPermissionRepository creates a new instance of PermissionRepository.
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionRepo{db: db}
+}
+
+// FindByRole retrieves the names of the permissions granted to a role.
+func (r *permissionRepo) FindByRole(ctx context.Context, role string) ([]string, error) {
+	logger := logctx.With(ctx, "method", "FindByRole", "role", role)
+
+	var permissions []string
+	err := r.db.WithContext(ctx).Model(&model.RolePermission{}).
+		Where("role = ?", role).
+		Order("permission").
+		Pluck("permission", &permissions).Error
+	if err != nil {
+		logger.Error("Failed to find role permissions", "error", err)
+		return nil, err
+	}
+
+	logger.Debug("Role permissions found", "count", len(permissions))
+	return permissions, nil
+}
+
+// RoleExists reports whether the role has permissions, roles are defined by their rows.
+func (r *permissionRepo) RoleExists(ctx context.Context, role string) (bool, error) {
+	logger := logctx.With(ctx, "method", "RoleExists", "role", role)
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RolePermission{}).Where("role = ?", role).Count(&count).Error
+	if err != nil {
+		logger.Error("Failed to count role permissions", "error", err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
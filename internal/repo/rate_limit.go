@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// RateLimitRepository defines the interface for the storage of rate limit buckets.
+type RateLimitRepository interface {
+	Take(ctx context.Context, key string, limit model.RateLimit, now time.Time) (*model.RateLimitResult, error)
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+type rateLimitRepo struct {
+	db *gorm.DB
+}
+
+// NewRateLimitRepository creates a RateLimitRepository that keeps the buckets in the
+// database, so that all instances of the service share the limits.
+func NewRateLimitRepository(db *gorm.DB) RateLimitRepository {
+	return &rateLimitRepo{db: db}
+}
+
+// Take takes a request from the bucket of the key, creating a full bucket first if there
+// is none. The bucket row is locked, so concurrent requests of the key take in turn.
+func (r *rateLimitRepo) Take(ctx context.Context, key string, limit model.RateLimit, now time.Time) (*model.RateLimitResult, error) {
+	logger := logctx.With(ctx, "method", "Take", "key", key)
+
+	var result model.RateLimitResult
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		bucket := model.NewRateLimitBucket(key, limit, now)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(bucket).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(bucket).Error; err != nil {
+			return err
+		}
+
+		result = bucket.Take(limit, now)
+		return tx.Model(bucket).Where("key = ?", key).Updates(map[string]interface{}{
+			"tokens":     bucket.Tokens,
+			"updated_at": bucket.UpdatedAt,
+		}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to take from rate limit bucket", "error", err)
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// DeleteStale removes the buckets not used since the given time.
+func (r *rateLimitRepo) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	logger := logctx.With(ctx, "method", "DeleteStale")
+
+	result := r.db.WithContext(ctx).Delete(&model.RateLimitBucket{}, "updated_at < ?", before)
+	if result.Error != nil {
+		logger.Error("Failed to delete stale rate limit buckets", "error", result.Error)
+		return 0, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Debug("Stale rate limit buckets deleted", "count", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
+}
+
+type memoryRateLimitRepo struct {
+	mu      sync.Mutex
+	buckets map[string]*model.RateLimitBucket
+}
+
+// NewMemoryRateLimitRepository creates a RateLimitRepository that keeps the buckets in
+// memory, each instance of the service then limits on its own.
+func NewMemoryRateLimitRepository() RateLimitRepository {
+	return &memoryRateLimitRepo{buckets: map[string]*model.RateLimitBucket{}}
+}
+
+// Take takes a request from the bucket of the key, creating a full bucket first if there is none.
+func (r *memoryRateLimitRepo) Take(_ context.Context, key string, limit model.RateLimit, now time.Time) (*model.RateLimitResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = model.NewRateLimitBucket(key, limit, now)
+		r.buckets[key] = bucket
+	}
+
+	result := bucket.Take(limit, now)
+	return &result, nil
+}
+
+// DeleteStale removes the buckets not used since the given time.
+func (r *memoryRateLimitRepo) DeleteStale(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, bucket := range r.buckets {
+		if bucket.UpdatedAt.Before(before) {
+			delete(r.buckets, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,188 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// SessionRepository defines the interface for server-side session database operations.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *model.Session) error
+	UpdateSession(ctx context.Context, session *model.Session) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error)
+	TouchSession(ctx context.Context, id string, lastSeen time.Time) error
+	ListByUser(ctx context.Context, userID string) ([]model.Session, error)
+	DeleteSession(ctx context.Context, userID, id string) error
+	DeleteByTokenHash(ctx context.Context, tokenHash string) error
+	DeleteByUser(ctx context.Context, userID, exceptTokenHash string) (int64, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type sessionRepo struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new instance of SessionRepository.
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepo{db: db}
+}
+
+// CreateSession inserts the session of a newly issued token.
+func (r *sessionRepo) CreateSession(ctx context.Context, session *model.Session) error {
+	logger := logctx.With(ctx, "method", "CreateSession", "sessionID", session.ID)
+
+	if err := r.db.WithContext(ctx).Create(session).Error; err != nil {
+		logger.Error("Failed to create session", "error", err)
+		return err
+	}
+
+	logger.Debug("Session created successfully")
+	return nil
+}
+
+// UpdateSession updates the values and expiry of the session with the token hash. It
+// never inserts, a session that was revoked or deleted returns ErrSessionNotFound.
+func (r *sessionRepo) UpdateSession(ctx context.Context, session *model.Session) error {
+	logger := logctx.With(ctx, "method", "UpdateSession")
+
+	result := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("token_hash = ?", session.TokenHash).
+		Updates(map[string]any{
+			"user_id":      session.UserID,
+			"data":         session.Data,
+			"last_seen_at": session.LastSeenAt,
+			"expires_at":   session.ExpiresAt,
+		})
+	if result.Error != nil {
+		logger.Error("Failed to update session", "error", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		logger.Debug("Session not found")
+		return model.ErrSessionNotFound
+	}
+
+	logger.Debug("Session updated successfully")
+	return nil
+}
+
+// FindByTokenHash retrieves a session that has not expired yet.
+func (r *sessionRepo) FindByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	logger := logctx.With(ctx, "method", "FindByTokenHash")
+
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Debug("Session not found")
+			return nil, model.ErrSessionNotFound
+		}
+		logger.Error("Failed to find session", "error", err)
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// TouchSession records the last time a session was used.
+func (r *sessionRepo) TouchSession(ctx context.Context, id string, lastSeen time.Time) error {
+	logger := logctx.With(ctx, "method", "TouchSession", "sessionID", id)
+
+	err := r.db.WithContext(ctx).Model(&model.Session{}).Where("id = ?", id).Update("last_seen_at", lastSeen).Error
+	if err != nil {
+		logger.Error("Failed to touch session", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ListByUser retrieves the active sessions of a user, most recently used first.
+func (r *sessionRepo) ListByUser(ctx context.Context, userID string) ([]model.Session, error) {
+	logger := logctx.With(ctx, "method", "ListByUser", "userID", userID)
+
+	var sessions []model.Session
+	err := r.db.WithContext(ctx).Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		logger.Error("Failed to list sessions", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Sessions listed successfully", "count", len(sessions))
+	return sessions, nil
+}
+
+// DeleteSession removes a single session of a user.
+func (r *sessionRepo) DeleteSession(ctx context.Context, userID, id string) error {
+	logger := logctx.With(ctx, "method", "DeleteSession", "userID", userID, "sessionID", id)
+
+	result := r.db.WithContext(ctx).Delete(&model.Session{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		logger.Error("Failed to delete session", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("Session not found for deletion")
+		return model.ErrSessionNotFound
+	}
+
+	logger.Info("Session deleted successfully")
+	return nil
+}
+
+// DeleteByTokenHash removes the session identified by its token.
+func (r *sessionRepo) DeleteByTokenHash(ctx context.Context, tokenHash string) error {
+	logger := logctx.With(ctx, "method", "DeleteByTokenHash")
+
+	if err := r.db.WithContext(ctx).Delete(&model.Session{}, "token_hash = ?", tokenHash).Error; err != nil {
+		logger.Error("Failed to delete session", "error", err)
+		return err
+	}
+
+	logger.Info("Session deleted successfully")
+	return nil
+}
+
+// DeleteByUser removes all sessions of a user, except the one with exceptTokenHash if set.
+func (r *sessionRepo) DeleteByUser(ctx context.Context, userID, exceptTokenHash string) (int64, error) {
+	logger := logctx.With(ctx, "method", "DeleteByUser", "userID", userID)
+
+	db := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if exceptTokenHash != "" {
+		db = db.Where("token_hash <> ?", exceptTokenHash)
+	}
+
+	result := db.Delete(&model.Session{})
+	if result.Error != nil {
+		logger.Error("Failed to delete sessions", "error", result.Error)
+		return 0, result.Error
+	}
+
+	logger.Info("Sessions deleted successfully", "count", result.RowsAffected)
+	return result.RowsAffected, nil
+}
+
+// DeleteExpired removes all expired sessions.
+func (r *sessionRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	logger := logctx.With(ctx, "method", "DeleteExpired")
+
+	result := r.db.WithContext(ctx).Delete(&model.Session{}, "expires_at <= ?", time.Now())
+	if result.Error != nil {
+		logger.Error("Failed to delete expired sessions", "error", result.Error)
+		return 0, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Info("Expired sessions deleted", "count", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,302 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// TwoFactorRepository defines the interface for two-factor authentication database operations.
+type TwoFactorRepository interface {
+	SaveSecret(ctx context.Context, userID, secret string) error
+	EnableTwoFactor(ctx context.Context, userID string, step int64, codeHashes []string) error
+	DisableTwoFactor(ctx context.Context, userID string) error
+	UseStep(ctx context.Context, userID string, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) error
+	CountRecoveryCodes(ctx context.Context, userID string) (int64, error)
+
+	CreateChallenge(ctx context.Context, challenge *model.LoginChallenge) error
+	FindChallenge(ctx context.Context, tokenHash string) (*model.LoginChallenge, error)
+	AddChallengeAttempt(ctx context.Context, id string) (int, error)
+	DeleteChallenge(ctx context.Context, id string) error
+
+	FindRolePolicy(ctx context.Context, role string) (*model.RolePolicy, error)
+	SaveRolePolicy(ctx context.Context, policy *model.RolePolicy) error
+}
+
+type twoFactorRepo struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository creates a new instance of TwoFactorRepository.
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepo{db: db}
+}
+
+// SaveSecret stores the encrypted secret of a pending enrolment.
+func (r *twoFactorRepo) SaveSecret(ctx context.Context, userID, secret string) error {
+	logger := logctx.With(ctx, "method", "SaveSecret", "userID", userID)
+
+	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("totp_secret", secret)
+	if result.Error != nil {
+		logger.Error("Failed to save totp secret", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("User not found")
+		return model.ErrUserNotFound
+	}
+
+	logger.Info("TOTP secret saved")
+	return nil
+}
+
+// EnableTwoFactor turns on two-factor authentication with its first recovery codes.
+func (r *twoFactorRepo) EnableTwoFactor(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	logger := logctx.With(ctx, "method", "EnableTwoFactor", "userID", userID)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+	if err != nil {
+		logger.Error("Failed to enable two-factor authentication", "error", err)
+		return err
+	}
+
+	logger.Info("Two-factor authentication enabled")
+	return nil
+}
+
+// DisableTwoFactor removes the secret and the recovery codes of a user.
+func (r *twoFactorRepo) DisableTwoFactor(ctx context.Context, userID string) error {
+	logger := logctx.With(ctx, "method", "DisableTwoFactor", "userID", userID)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_secret":    "",
+			"totp_enabled":   false,
+			"totp_last_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.RecoveryCode{}, "user_id = ?", userID).Error
+	})
+	if err != nil {
+		logger.Error("Failed to disable two-factor authentication", "error", err)
+		return err
+	}
+
+	logger.Info("Two-factor authentication disabled")
+	return nil
+}
+
+// UseStep records the time step of an accepted code. It fails with model.ErrInvalidOTP
+// when the step is not newer than the last one, so every code is accepted only once.
+func (r *twoFactorRepo) UseStep(ctx context.Context, userID string, step int64) error {
+	logger := logctx.With(ctx, "method", "UseStep", "userID", userID)
+
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		logger.Error("Failed to record totp step", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Warn("TOTP code already used")
+		return model.ErrInvalidOTP
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of a user.
+func (r *twoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	logger := logctx.With(ctx, "method", "ReplaceRecoveryCodes", "userID", userID)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+	if err != nil {
+		logger.Error("Failed to replace recovery codes", "error", err)
+		return err
+	}
+
+	logger.Info("Recovery codes replaced", "count", len(codeHashes))
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codeHashes []string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Delete(&model.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+		return err
+	}
+
+	codes := make([]model.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, model.RecoveryCode{ID: uuid.New(), UserID: id, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseRecoveryCode marks an unused recovery code as used, it fails with model.ErrInvalidOTP otherwise.
+func (r *twoFactorRepo) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	logger := logctx.With(ctx, "method", "UseRecoveryCode", "userID", userID)
+
+	result := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		logger.Error("Failed to use recovery code", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Warn("Unknown or used recovery code")
+		return model.ErrInvalidOTP
+	}
+
+	logger.Info("Recovery code used")
+	return nil
+}
+
+// CountRecoveryCodes returns the number of unused recovery codes of a user.
+func (r *twoFactorRepo) CountRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	logger := logctx.With(ctx, "method", "CountRecoveryCodes", "userID", userID)
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	if err != nil {
+		logger.Error("Failed to count recovery codes", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CreateChallenge stores a new login challenge.
+func (r *twoFactorRepo) CreateChallenge(ctx context.Context, challenge *model.LoginChallenge) error {
+	logger := logctx.With(ctx, "method", "CreateChallenge", "userID", challenge.UserID)
+
+	if err := r.db.WithContext(ctx).Create(challenge).Error; err != nil {
+		logger.Error("Failed to create login challenge", "error", err)
+		return err
+	}
+
+	// Expired challenges are of no use, drop them while we are here.
+	if err := r.db.WithContext(ctx).Delete(&model.LoginChallenge{}, "expires_at <= ?", time.Now()).Error; err != nil {
+		logger.Warn("Failed to delete expired login challenges", "error", err)
+	}
+
+	logger.Debug("Login challenge created", "challengeID", challenge.ID)
+	return nil
+}
+
+// FindChallenge retrieves an unexpired challenge with its user.
+func (r *twoFactorRepo) FindChallenge(ctx context.Context, tokenHash string) (*model.LoginChallenge, error) {
+	logger := logctx.With(ctx, "method", "FindChallenge")
+
+	var challenge model.LoginChallenge
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).
+		First(&challenge).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Debug("Login challenge not found")
+			return nil, model.ErrChallengeNotFound
+		}
+		logger.Error("Failed to find login challenge", "error", err)
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// AddChallengeAttempt counts an attempt to complete a challenge and returns the number
+// of attempts including it. The counter is incremented in one statement, so concurrent
+// attempts each get their own number. Removed challenges return model.ErrChallengeNotFound.
+func (r *twoFactorRepo) AddChallengeAttempt(ctx context.Context, id string) (int, error) {
+	logger := logctx.With(ctx, "method", "AddChallengeAttempt", "challengeID", id)
+
+	var challenge model.LoginChallenge
+	result := r.db.WithContext(ctx).Model(&challenge).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "attempts"}}}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		logger.Error("Failed to count challenge attempt", "error", result.Error)
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, model.ErrChallengeNotFound
+	}
+
+	return challenge.Attempts, nil
+}
+
+// DeleteChallenge removes a challenge once it is completed or exhausted.
+func (r *twoFactorRepo) DeleteChallenge(ctx context.Context, id string) error {
+	logger := logctx.With(ctx, "method", "DeleteChallenge", "challengeID", id)
+
+	if err := r.db.WithContext(ctx).Delete(&model.LoginChallenge{}, "id = ?", id).Error; err != nil {
+		logger.Error("Failed to delete login challenge", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindRolePolicy returns the policy of a role, roles without a stored policy get the defaults.
+func (r *twoFactorRepo) FindRolePolicy(ctx context.Context, role string) (*model.RolePolicy, error) {
+	logger := logctx.With(ctx, "method", "FindRolePolicy", "role", role)
+
+	policy := model.RolePolicy{Role: role}
+	err := r.db.WithContext(ctx).Where("role = ?", role).First(&policy).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("Failed to find role policy", "error", err)
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// SaveRolePolicy inserts or updates the policy of a role.
+func (r *twoFactorRepo) SaveRolePolicy(ctx context.Context, policy *model.RolePolicy) error {
+	logger := logctx.With(ctx, "method", "SaveRolePolicy", "role", policy.Role)
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"require_two_factor"}),
+	}).Create(policy).Error
+	if err != nil {
+		logger.Error("Failed to save role policy", "error", err)
+		return err
+	}
+
+	logger.Info("Role policy saved", "requireTwoFactor", policy.RequireTwoFactor)
+	return nil
+}
@@ -3,11 +3,13 @@ package repo
 import (
 	"context"
 	"errors"
-	"log/slog"
+	"strings"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 )
 
@@ -18,11 +20,15 @@ type UserRepository interface {
 	FindUserByUsername(ctx context.Context, username string) (*model.User, error)
 	UpdateUser(ctx context.Context, user *model.User) error
 	DeleteUser(ctx context.Context, id string) error
+	ListUsers(ctx context.Context, query model.UserListQuery) ([]model.User, int64, error)
 	GetStorageUsage(ctx context.Context, userID string) (*model.StorageUsage, error)
 	UpdateStorageUsage(ctx context.Context, storageUsage *model.StorageUsage) error
 	CreateStash(ctx context.Context, stash *model.Stash) error
 	UpdateStash(ctx context.Context, stash *model.Stash) error
 	GetStashByUserID(ctx context.Context, id string) (*model.Stash, error)
+	RecomputeStash(ctx context.Context, userID string) (*model.Stash, error)
+	FindStashDrift(ctx context.Context) ([]model.StashDrift, error)
+	ListStashes(ctx context.Context) ([]model.Stash, error)
 }
 
 type userRepo struct {
@@ -36,9 +42,9 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 
 // CreateUser adds a new user to the database.
 func (r *userRepo) CreateUser(ctx context.Context, user *model.User) error {
-	logger := slog.With("method", "CreateUser", "userID", user.ID)
+	logger := logctx.With(ctx, "method", "CreateUser", "userID", user.ID)
 
-	if err := r.db.Create(user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
 		// Check if the error is a unique constraint violation
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uni_users_username" {
@@ -56,10 +62,10 @@ func (r *userRepo) CreateUser(ctx context.Context, user *model.User) error {
 
 // FindUserByID retrieves a user by their ID.
 func (r *userRepo) FindUserByID(ctx context.Context, id string) (*model.User, error) {
-	logger := slog.With("method", "FindUserByID", "userID", id)
+	logger := logctx.With(ctx, "method", "FindUserByID", "userID", id)
 
 	var user model.User
-	if err := r.db.First(&user, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Info("User not found")
 			return nil, model.ErrUserNotFound
@@ -74,10 +80,10 @@ func (r *userRepo) FindUserByID(ctx context.Context, id string) (*model.User, er
 
 // FindUserByUsername retrieves a user by their username.
 func (r *userRepo) FindUserByUsername(ctx context.Context, username string) (*model.User, error) {
-	logger := slog.With("method", "FindUserByUsername", "username", username)
+	logger := logctx.With(ctx, "method", "FindUserByUsername", "username", username)
 
 	var user model.User
-	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Info("User not found")
 			return nil, model.ErrUserNotFound
@@ -92,9 +98,9 @@ func (r *userRepo) FindUserByUsername(ctx context.Context, username string) (*mo
 
 // UpdateUser updates an existing user in the database.
 func (r *userRepo) UpdateUser(ctx context.Context, user *model.User) error {
-	logger := slog.With("method", "UpdateUser", "userID", user.ID)
+	logger := logctx.With(ctx, "method", "UpdateUser", "userID", user.ID)
 
-	result := r.db.Save(user)
+	result := r.db.WithContext(ctx).Save(user)
 	if result.Error != nil {
 		logger.Error("Failed to update user", "error", result.Error)
 		return result.Error
@@ -111,9 +117,9 @@ func (r *userRepo) UpdateUser(ctx context.Context, user *model.User) error {
 
 // DeleteUser removes a user from the database.
 func (r *userRepo) DeleteUser(ctx context.Context, id string) error {
-	logger := slog.With("method", "DeleteUser", "userID", id)
+	logger := logctx.With(ctx, "method", "DeleteUser", "userID", id)
 
-	result := r.db.Delete(&model.User{}, "id = ?", id)
+	result := r.db.WithContext(ctx).Delete(&model.User{}, "id = ?", id)
 	if result.Error != nil {
 		logger.Error("Failed to delete user", "error", result.Error)
 		return result.Error
@@ -130,10 +136,10 @@ func (r *userRepo) DeleteUser(ctx context.Context, id string) error {
 
 // GetStorageUsage retrieves the storage usage for a specific user.
 func (r *userRepo) GetStorageUsage(ctx context.Context, userID string) (*model.StorageUsage, error) {
-	logger := slog.With("method", "GetStorageUsage", "userID", userID)
+	logger := logctx.With(ctx, "method", "GetStorageUsage", "userID", userID)
 
 	var storageUsage model.StorageUsage
-	if err := r.db.Where("user_id = ?", userID).First(&storageUsage).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&storageUsage).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Info("Storage usage not found")
 			return nil, model.ErrUserNotFound
@@ -148,9 +154,9 @@ func (r *userRepo) GetStorageUsage(ctx context.Context, userID string) (*model.S
 
 // UpdateStorageUsage updates the storage usage for a specific user.
 func (r *userRepo) UpdateStorageUsage(ctx context.Context, storageUsage *model.StorageUsage) error {
-	logger := slog.With("method", "UpdateStorageUsage", "userID", storageUsage.UserID)
+	logger := logctx.With(ctx, "method", "UpdateStorageUsage", "userID", storageUsage.UserID)
 
-	result := r.db.Save(storageUsage)
+	result := r.db.WithContext(ctx).Save(storageUsage)
 	if result.Error != nil {
 		logger.Error("Failed to update storage usage", "error", result.Error)
 		return result.Error
@@ -167,9 +173,9 @@ func (r *userRepo) UpdateStorageUsage(ctx context.Context, storageUsage *model.S
 
 // CreateStash adds a new stash to the database
 func (r *userRepo) CreateStash(ctx context.Context, stash *model.Stash) error {
-	logger := slog.With("method", "CreateStash", "stashID", stash.ID, "userID", stash.UserID)
+	logger := logctx.With(ctx, "method", "CreateStash", "stashID", stash.ID, "userID", stash.UserID)
 
-	if err := r.db.Create(stash).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(stash).Error; err != nil {
 		logger.ErrorContext(ctx, "Failed to create stash for a user", "error", err)
 		return err
 	}
@@ -180,11 +186,11 @@ func (r *userRepo) CreateStash(ctx context.Context, stash *model.Stash) error {
 
 // GetStashByUserID retrieves a stash by user ID
 func (r *userRepo) GetStashByUserID(ctx context.Context, userID string) (*model.Stash, error) {
-	logger := slog.With("method", "GetStashByUserID", "userID", userID)
+	logger := logctx.With(ctx, "method", "GetStashByUserID", "userID", userID)
 
 	var stash model.Stash
 
-	if err := r.db.Preload("User").First(&stash, "user_id = ?", userID).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("User").First(&stash, "user_id = ?", userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.InfoContext(ctx, "Stash not found for user")
 			return nil, model.ErrUserNotFound
@@ -197,10 +203,93 @@ func (r *userRepo) GetStashByUserID(ctx context.Context, userID string) (*model.
 	return &stash, nil
 }
 
+// RecomputeStash sets the counters of the stash of a user from the art projects and revisions it holds.
+func (r *userRepo) RecomputeStash(ctx context.Context, userID string) (*model.Stash, error) {
+	logger := logctx.With(ctx, "method", "RecomputeStash", "userID", userID)
+
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE stashes SET
+			art_projects = (SELECT count(*) FROM art_projects WHERE art_projects.stash_id = stashes.id),
+			files = (SELECT count(*) FROM revisions JOIN art_projects ON art_projects.id = revisions.art_project_id
+				WHERE art_projects.stash_id = stashes.id),
+			used_space = (SELECT coalesce(sum(revisions.size), 0) FROM revisions JOIN art_projects ON art_projects.id = revisions.art_project_id
+				WHERE art_projects.stash_id = stashes.id),
+			updated_at = now()
+		WHERE user_id = ?`, userID)
+	if result.Error != nil {
+		logger.Error("Failed to recompute stash", "error", result.Error)
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		logger.Info("Stash not found for user")
+		return nil, model.ErrUserNotFound
+	}
+
+	return r.GetStashByUserID(ctx, userID)
+}
+
+// ListStashes returns the stashes of all users.
+func (r *userRepo) ListStashes(ctx context.Context) ([]model.Stash, error) {
+	var stashes []model.Stash
+	if err := r.db.WithContext(ctx).Order("user_id").Find(&stashes).Error; err != nil {
+		logctx.From(ctx).Error("Failed to list stashes", "method", "ListStashes", "error", err)
+		return nil, err
+	}
+	return stashes, nil
+}
+
+// stashDriftRow is a row of the FindStashDrift query.
+type stashDriftRow struct {
+	StashID           uuid.UUID
+	UserID            uuid.UUID
+	ArtProjects       uint64
+	Files             uint64
+	UsedSpace         int64
+	ActualArtProjects uint64
+	ActualFiles       uint64
+	ActualUsedSpace   int64
+}
+
+// FindStashDrift returns the stashes whose counters differ from the art projects and revisions they hold.
+func (r *userRepo) FindStashDrift(ctx context.Context) ([]model.StashDrift, error) {
+	logger := logctx.With(ctx, "method", "FindStashDrift")
+
+	var rows []stashDriftRow
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT stashes.id AS stash_id, stashes.user_id, stashes.art_projects, stashes.files, stashes.used_space,
+				(SELECT count(*) FROM art_projects WHERE art_projects.stash_id = stashes.id) AS actual_art_projects,
+				(SELECT count(*) FROM revisions JOIN art_projects ON art_projects.id = revisions.art_project_id
+					WHERE art_projects.stash_id = stashes.id) AS actual_files,
+				(SELECT coalesce(sum(revisions.size), 0) FROM revisions JOIN art_projects ON art_projects.id = revisions.art_project_id
+					WHERE art_projects.stash_id = stashes.id) AS actual_used_space
+			FROM stashes
+		) counters
+		WHERE art_projects <> actual_art_projects OR files <> actual_files OR used_space <> actual_used_space
+		ORDER BY user_id`).Scan(&rows).Error
+	if err != nil {
+		logger.Error("Failed to find stash drift", "error", err)
+		return nil, err
+	}
+
+	drift := make([]model.StashDrift, 0, len(rows))
+	for _, row := range rows {
+		drift = append(drift, model.StashDrift{
+			StashID:  row.StashID,
+			UserID:   row.UserID,
+			Recorded: model.StashCounters{ArtProjects: row.ArtProjects, Files: row.Files, UsedSpace: row.UsedSpace},
+			Actual:   model.StashCounters{ArtProjects: row.ActualArtProjects, Files: row.ActualFiles, UsedSpace: row.ActualUsedSpace},
+		})
+	}
+
+	return drift, nil
+}
+
 func (r *userRepo) UpdateStash(ctx context.Context, stash *model.Stash) error {
-	logger := slog.With("method", "UpdateStash", "stashID", stash.ID, "userID", stash.UserID)
+	logger := logctx.With(ctx, "method", "UpdateStash", "stashID", stash.ID, "userID", stash.UserID)
 
-	result := r.db.Save(stash)
+	result := r.db.WithContext(ctx).Save(stash)
 	if result.Error != nil {
 		logger.Error("Failed to update stash", "error", result.Error)
 		return result.Error
@@ -214,3 +303,41 @@ func (r *userRepo) UpdateStash(ctx context.Context, stash *model.Stash) error {
 	logger.Info("Stash updated successfully")
 	return nil
 }
+
+// ListUsers retrieves a page of users ordered by username.
+// Search matches a part of the username, case-insensitively.
+func (r *userRepo) ListUsers(ctx context.Context, query model.UserListQuery) ([]model.User, int64, error) {
+	logger := logctx.With(ctx, "method", "ListUsers", "search", query.Search, "role", query.Role, "page", query.Page)
+
+	db := r.db.WithContext(ctx).Model(&model.User{})
+	if query.Search != "" {
+		db = db.Where("username ILIKE ?", "%"+escapeLike(query.Search)+"%")
+	}
+	if query.Role != "" {
+		db = db.Where("role = ?", query.Role)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		logger.Error("Failed to count users", "error", err)
+		return nil, 0, err
+	}
+
+	var users []model.User
+	err := db.Order("username ASC").
+		Offset((query.Page - 1) * query.PerPage).
+		Limit(query.PerPage).
+		Find(&users).Error
+	if err != nil {
+		logger.Error("Failed to list users", "error", err)
+		return nil, 0, err
+	}
+
+	logger.Info("Users listed successfully", "count", len(users), "total", total)
+	return users, total, nil
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
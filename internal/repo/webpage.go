@@ -3,10 +3,10 @@ package repo
 import (
 	"context"
 	"errors"
-	"log/slog"
 
 	"gorm.io/gorm"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 )
 
@@ -32,9 +32,9 @@ func NewWebPageRepository(db *gorm.DB) WebPageRepository {
 
 // CreateWebPage adds a new webpage to the database.
 func (r *webPageRepo) CreateWebPage(ctx context.Context, webPage *model.WebPage) error {
-	logger := slog.With("method", "CreateWebPage", "webPageID", webPage.ID)
+	logger := logctx.With(ctx, "method", "CreateWebPage", "webPageID", webPage.ID)
 
-	if err := r.db.Create(webPage).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(webPage).Error; err != nil {
 		logger.Error("Failed to create webpage", "error", err)
 		return err
 	}
@@ -45,10 +45,10 @@ func (r *webPageRepo) CreateWebPage(ctx context.Context, webPage *model.WebPage)
 
 // FindWebPageByID retrieves a webpage by its ID.
 func (r *webPageRepo) FindWebPageByID(ctx context.Context, id string) (*model.WebPage, error) {
-	logger := slog.With("method", "FindWebPageByID", "webPageID", id)
+	logger := logctx.With(ctx, "method", "FindWebPageByID", "webPageID", id)
 
 	var webPage model.WebPage
-	if err := r.db.First(&webPage, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&webPage, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Info("Webpage not found")
 			return nil, model.ErrWebPageNotFound
@@ -63,10 +63,10 @@ func (r *webPageRepo) FindWebPageByID(ctx context.Context, id string) (*model.We
 
 // FindAllWebPages retrieves all webpages from the database.
 func (r *webPageRepo) FindAllWebPages(ctx context.Context) ([]model.WebPage, error) {
-	logger := slog.With("method", "FindAllWebPages")
+	logger := logctx.With(ctx, "method", "FindAllWebPages")
 
 	var webPages []model.WebPage
-	if err := r.db.Find(&webPages).Error; err != nil {
+	if err := r.db.WithContext(ctx).Find(&webPages).Error; err != nil {
 		logger.Error("Failed to find all webpages", "error", err)
 		return nil, err
 	}
@@ -81,10 +81,10 @@ func (r *webPageRepo) FindAllWebPages(ctx context.Context) ([]model.WebPage, err
 
 // FindWebPagesByType retrieves all webpages of a specific type.
 func (r *webPageRepo) FindWebPagesByType(ctx context.Context, pageType string) ([]model.WebPage, error) {
-	logger := slog.With("method", "FindWebPagesByType", "pageType", pageType)
+	logger := logctx.With(ctx, "method", "FindWebPagesByType", "pageType", pageType)
 
 	var webPages []model.WebPage
-	if err := r.db.Where("page_type = ?", pageType).Find(&webPages).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("page_type = ?", pageType).Find(&webPages).Error; err != nil {
 		logger.Error("Failed to find webpages by type", "error", err)
 		return nil, err
 	}
@@ -99,10 +99,10 @@ func (r *webPageRepo) FindWebPagesByType(ctx context.Context, pageType string) (
 
 // FindWebPagesByUserID retrieves all webpages for a specific user.
 func (r *webPageRepo) FindWebPagesByUserID(ctx context.Context, userID string) ([]model.WebPage, error) {
-	logger := slog.With("method", "FindWebPagesByUserID", "userID", userID)
+	logger := logctx.With(ctx, "method", "FindWebPagesByUserID", "userID", userID)
 
 	var webPages []model.WebPage
-	if err := r.db.Where("user_id = ?", userID).Find(&webPages).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&webPages).Error; err != nil {
 		logger.Error("Failed to find webpages by user ID", "error", err)
 		return nil, err
 	}
@@ -117,9 +117,9 @@ func (r *webPageRepo) FindWebPagesByUserID(ctx context.Context, userID string) (
 
 // UpdateWebPage updates an existing webpage in the database.
 func (r *webPageRepo) UpdateWebPage(ctx context.Context, webPage *model.WebPage) error {
-	logger := slog.With("method", "UpdateWebPage", "webPageID", webPage.ID)
+	logger := logctx.With(ctx, "method", "UpdateWebPage", "webPageID", webPage.ID)
 
-	result := r.db.Save(webPage)
+	result := r.db.WithContext(ctx).Save(webPage)
 	if result.Error != nil {
 		logger.Error("Failed to update webpage", "error", result.Error)
 		return result.Error
@@ -136,9 +136,9 @@ func (r *webPageRepo) UpdateWebPage(ctx context.Context, webPage *model.WebPage)
 
 // DeleteWebPage removes a webpage from the database.
 func (r *webPageRepo) DeleteWebPage(ctx context.Context, id string) error {
-	logger := slog.With("method", "DeleteWebPage", "webPageID", id)
+	logger := logctx.With(ctx, "method", "DeleteWebPage", "webPageID", id)
 
-	result := r.db.Delete(&model.WebPage{}, "id = ?", id)
+	result := r.db.WithContext(ctx).Delete(&model.WebPage{}, "id = ?", id)
 	if result.Error != nil {
 		logger.Error("Failed to delete webpage", "error", result.Error)
 		return result.Error
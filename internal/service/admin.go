@@ -0,0 +1,482 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+
+	// temporaryPasswordBytes is the minimum entropy of passwords generated by a reset.
+	temporaryPasswordBytes = 12
+
+	// inviteCodeBytes is the entropy of generated invite codes.
+	inviteCodeBytes = 16
+)
+
+// AdminService manages user accounts on behalf of administrators.
+// Every action is recorded to the audit log with the acting administrator.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=AdminService --filename=admin_service.go --output=../../mocks/
+type AdminService interface {
+	ListUsers(ctx context.Context, actorID string, query model.UserListQuery) ([]model.User, model.Pagination, error)
+	GetUser(ctx context.Context, actorID, userID string) (*model.User, error)
+	SetRole(ctx context.Context, actorID, userID, role string) (*model.User, error)
+	ResetPassword(ctx context.Context, actorID, userID string) (string, error)
+	SetDisabled(ctx context.Context, actorID, userID string, disabled bool) (*model.User, error)
+	DeleteUser(ctx context.Context, actorID, userID string) error
+	UnlockUser(ctx context.Context, actorID, userID string) error
+	ListAuditLog(ctx context.Context, page, perPage int) ([]model.AuditLog, model.Pagination, error)
+	CreateInvite(ctx context.Context, actorID string, validFor time.Duration) (*model.Invite, string, error)
+	ListInvites(ctx context.Context, actorID string) ([]model.Invite, error)
+	RevokeInvite(ctx context.Context, actorID, inviteID string) error
+	GetRolePolicy(ctx context.Context, actorID, role string) (*model.RolePolicy, error)
+	SetRolePolicy(ctx context.Context, actorID, role string, requireTwoFactor bool) (*model.RolePolicy, error)
+}
+
+type adminService struct {
+	userRepo      repo.UserRepository
+	auditRepo     repo.AuditLogRepository
+	inviteRepo    repo.InviteRepository
+	sessionRepo   repo.SessionRepository
+	loginThrottle LoginThrottleService
+	twoFactorRepo repo.TwoFactorRepository
+	// permissionRepo defines the roles that can be assigned.
+	permissionRepo repo.PermissionRepository
+	// policy is met by the temporary passwords of resets.
+	policy *PasswordPolicy
+}
+
+func NewAdminService(ur repo.UserRepository, alr repo.AuditLogRepository, ir repo.InviteRepository, sr repo.SessionRepository, lt LoginThrottleService, tfr repo.TwoFactorRepository, pr repo.PermissionRepository, policy *PasswordPolicy) AdminService {
+	return &adminService{
+		userRepo:       ur,
+		auditRepo:      alr,
+		inviteRepo:     ir,
+		sessionRepo:    sr,
+		loginThrottle:  lt,
+		twoFactorRepo:  tfr,
+		permissionRepo: pr,
+		policy:         policy,
+	}
+}
+
+func (s *adminService) ListUsers(ctx context.Context, actorID string, query model.UserListQuery) ([]model.User, model.Pagination, error) {
+	logger := logctx.With(ctx, "method", "ListUsers", "actorID", actorID)
+
+	query.Page, query.PerPage = normalizePage(query.Page, query.PerPage)
+
+	users, total, err := s.userRepo.ListUsers(ctx, query)
+	if err != nil {
+		logger.Error("Failed to list users", "error", err)
+		return nil, model.Pagination{}, err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionUserList, "", map[string]string{
+		"search": query.Search,
+		"role":   query.Role,
+		"page":   strconv.Itoa(query.Page),
+	})
+
+	logger.Info("Users listed", "count", len(users), "total", total)
+	return users, newPagination(query.Page, query.PerPage, total), nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, actorID, userID string) (*model.User, error) {
+	logger := logctx.With(ctx, "method", "GetUser", "actorID", actorID, "userID", userID)
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return nil, err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionUserView, userID, nil)
+
+	logger.Info("User retrieved")
+	return user, nil
+}
+
+// SetRole changes the role of a user. Administrators can not demote themselves
+// so that at least the acting administrator keeps access.
+func (s *adminService) SetRole(ctx context.Context, actorID, userID, role string) (*model.User, error) {
+	logger := logctx.With(ctx, "method", "SetRole", "actorID", actorID, "userID", userID, "role", role)
+
+	if err := s.checkRole(ctx, role); err != nil {
+		logger.Warn("Invalid role", "error", err)
+		return nil, err
+	}
+	if actorID == userID && role != model.RoleAdmin {
+		logger.Warn("Administrator tried to demote themselves")
+		return nil, fmt.Errorf("%w: administrators can not demote themselves", model.ErrInvalidInput)
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return nil, err
+	}
+
+	previous := user.Role
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		logger.Error("Failed to update user role", "error", err)
+		return nil, err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionUserRoleChange, userID, map[string]string{
+		"from": previous,
+		"to":   role,
+	})
+
+	logger.Info("User role changed", "from", previous)
+	return user, nil
+}
+
+// ResetPassword replaces the password of a user with a generated temporary password
+// and returns it. The password itself is never written to the audit log.
+func (s *adminService) ResetPassword(ctx context.Context, actorID, userID string) (string, error) {
+	logger := logctx.With(ctx, "method", "ResetPassword", "actorID", actorID, "userID", userID)
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return "", err
+	}
+
+	password, err := temporaryPassword(s.policy)
+	if err != nil {
+		logger.Error("Failed to generate temporary password", "error", err)
+		return "", err
+	}
+
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		logger.Error("Failed to hash password", "error", err)
+		return "", err
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		logger.Error("Failed to update user password", "error", err)
+		return "", err
+	}
+
+	if _, err := s.sessionRepo.DeleteByUser(ctx, userID, ""); err != nil {
+		logger.Error("Failed to revoke sessions after password reset", "error", err)
+		return "", err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionUserPasswordReset, userID, nil)
+
+	logger.Info("User password reset")
+	return password, nil
+}
+
+// temporaryPassword generates a random password that meets the policy. Every byte
+// encodes to at least one base58 character, so the password is never shorter than
+// the minimum length of the policy.
+func temporaryPassword(policy *PasswordPolicy) (string, error) {
+	n := temporaryPasswordBytes
+	if policy != nil {
+		n = max(n, policy.MinLength)
+	}
+
+	password, err := GenerateToken(n)
+	if err != nil {
+		return "", err
+	}
+
+	if err := policy.Validate(password); err != nil {
+		return "", fmt.Errorf("generated password rejected: %w", err)
+	}
+	return password, nil
+}
+
+// SetDisabled disables or enables a user account. Disabled users can not log in
+// and their existing sessions are rejected.
+func (s *adminService) SetDisabled(ctx context.Context, actorID, userID string, disabled bool) (*model.User, error) {
+	logger := logctx.With(ctx, "method", "SetDisabled", "actorID", actorID, "userID", userID, "disabled", disabled)
+
+	if actorID == userID && disabled {
+		logger.Warn("Administrator tried to disable themselves")
+		return nil, fmt.Errorf("%w: administrators can not disable themselves", model.ErrInvalidInput)
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return nil, err
+	}
+
+	user.Disabled = disabled
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		logger.Error("Failed to update user", "error", err)
+		return nil, err
+	}
+
+	if disabled {
+		if _, err := s.sessionRepo.DeleteByUser(ctx, userID, ""); err != nil {
+			logger.Error("Failed to revoke sessions of disabled user", "error", err)
+			return nil, err
+		}
+	}
+
+	action := model.AuditActionUserEnable
+	if disabled {
+		action = model.AuditActionUserDisable
+	}
+	s.audit(ctx, actorID, action, userID, nil)
+
+	logger.Info("User account updated")
+	return user, nil
+}
+
+// UnlockUser lifts a login lockout of the user caused by failed login attempts.
+func (s *adminService) UnlockUser(ctx context.Context, actorID, userID string) error {
+	logger := logctx.With(ctx, "method", "UnlockUser", "actorID", actorID, "userID", userID)
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return err
+	}
+
+	if err := s.loginThrottle.Unlock(ctx, user.Username); err != nil {
+		logger.Error("Failed to unlock user", "error", err)
+		return err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionUserUnlock, userID, nil)
+
+	logger.Info("User unlocked")
+	return nil
+}
+
+func (s *adminService) DeleteUser(ctx context.Context, actorID, userID string) error {
+	logger := logctx.With(ctx, "method", "DeleteUser", "actorID", actorID, "userID", userID)
+
+	if actorID == userID {
+		logger.Warn("Administrator tried to delete themselves")
+		return fmt.Errorf("%w: administrators can not delete themselves", model.ErrInvalidInput)
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return err
+	}
+
+	if err := s.userRepo.DeleteUser(ctx, userID); err != nil {
+		logger.Error("Failed to delete user", "error", err)
+		return err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionUserDelete, userID, map[string]string{
+		"username": user.Username,
+	})
+
+	logger.Info("User deleted")
+	return nil
+}
+
+func (s *adminService) ListAuditLog(ctx context.Context, page, perPage int) ([]model.AuditLog, model.Pagination, error) {
+	logger := logctx.With(ctx, "method", "ListAuditLog")
+
+	page, perPage = normalizePage(page, perPage)
+
+	entries, total, err := s.auditRepo.ListEntries(ctx, page, perPage)
+	if err != nil {
+		logger.Error("Failed to list audit log", "error", err)
+		return nil, model.Pagination{}, err
+	}
+
+	logger.Info("Audit log listed", "count", len(entries), "total", total)
+	return entries, newPagination(page, perPage, total), nil
+}
+
+// CreateInvite creates a single-use invite and returns it with its code.
+// The code is only returned here, a zero validFor creates an invite without expiry.
+func (s *adminService) CreateInvite(ctx context.Context, actorID string, validFor time.Duration) (*model.Invite, string, error) {
+	logger := logctx.With(ctx, "method", "CreateInvite", "actorID", actorID, "validFor", validFor)
+
+	if validFor < 0 {
+		logger.Warn("Negative invite validity")
+		return nil, "", model.ErrInvalidInput
+	}
+
+	actor, err := uuid.Parse(actorID)
+	if err != nil {
+		logger.Warn("Invalid actor ID", "error", err)
+		return nil, "", model.ErrInvalidInput
+	}
+
+	code, err := GenerateToken(inviteCodeBytes)
+	if err != nil {
+		logger.Error("Failed to generate invite code", "error", err)
+		return nil, "", err
+	}
+
+	invite := &model.Invite{
+		ID:        uuid.New(),
+		CodeHash:  HashToken(code),
+		CreatedBy: actor,
+		CreatedAt: time.Now(),
+	}
+	if validFor > 0 {
+		expiresAt := invite.CreatedAt.Add(validFor)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	if err := s.inviteRepo.CreateInvite(ctx, invite); err != nil {
+		logger.Error("Failed to create invite", "error", err)
+		return nil, "", err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionInviteCreate, invite.ID.String(), nil)
+
+	logger.Info("Invite created", "inviteID", invite.ID)
+	return invite, code, nil
+}
+
+func (s *adminService) ListInvites(ctx context.Context, actorID string) ([]model.Invite, error) {
+	logger := logctx.With(ctx, "method", "ListInvites", "actorID", actorID)
+
+	invites, err := s.inviteRepo.ListInvites(ctx)
+	if err != nil {
+		logger.Error("Failed to list invites", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Invites listed", "count", len(invites))
+	return invites, nil
+}
+
+// RevokeInvite deletes an invite that has not been used yet.
+func (s *adminService) RevokeInvite(ctx context.Context, actorID, inviteID string) error {
+	logger := logctx.With(ctx, "method", "RevokeInvite", "actorID", actorID, "inviteID", inviteID)
+
+	if err := s.inviteRepo.DeleteInvite(ctx, inviteID); err != nil {
+		logger.Error("Failed to delete invite", "error", err)
+		return err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionInviteRevoke, inviteID, nil)
+
+	logger.Info("Invite revoked")
+	return nil
+}
+
+// GetRolePolicy returns whether users of the role must use two-factor authentication.
+func (s *adminService) GetRolePolicy(ctx context.Context, actorID, role string) (*model.RolePolicy, error) {
+	logger := logctx.With(ctx, "method", "GetRolePolicy", "actorID", actorID, "role", role)
+
+	if err := s.checkRole(ctx, role); err != nil {
+		logger.Warn("Invalid role", "error", err)
+		return nil, err
+	}
+
+	policy, err := s.twoFactorRepo.FindRolePolicy(ctx, role)
+	if err != nil {
+		logger.Error("Failed to find role policy", "error", err)
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// SetRolePolicy changes whether users of the role must use two-factor authentication.
+// Users of the role who have not enrolled yet can only reach the enrolment endpoints.
+func (s *adminService) SetRolePolicy(ctx context.Context, actorID, role string, requireTwoFactor bool) (*model.RolePolicy, error) {
+	logger := logctx.With(ctx, "method", "SetRolePolicy", "actorID", actorID, "role", role, "requireTwoFactor", requireTwoFactor)
+
+	if err := s.checkRole(ctx, role); err != nil {
+		logger.Warn("Invalid role", "error", err)
+		return nil, err
+	}
+
+	policy := &model.RolePolicy{Role: role, RequireTwoFactor: requireTwoFactor}
+	if err := s.twoFactorRepo.SaveRolePolicy(ctx, policy); err != nil {
+		logger.Error("Failed to save role policy", "error", err)
+		return nil, err
+	}
+
+	s.audit(ctx, actorID, model.AuditActionRolePolicyUpdate, role, map[string]string{
+		"require_two_factor": strconv.FormatBool(requireTwoFactor),
+	})
+
+	logger.Info("Role policy updated")
+	return policy, nil
+}
+
+// checkRole returns ErrInvalidRole unless the role is defined in the role permissions.
+func (s *adminService) checkRole(ctx context.Context, role string) error {
+	exists, err := s.permissionRepo.RoleExists(ctx, role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return model.ErrInvalidRole
+	}
+	return nil
+}
+
+// audit records an administrative action. A failure to write the entry is logged
+// but does not undo the action that already happened.
+func (s *adminService) audit(ctx context.Context, actorID, action, targetID string, details map[string]string) {
+	logger := logctx.With(ctx, "method", "audit", "actorID", actorID, "action", action, "targetID", targetID)
+
+	actor, err := uuid.Parse(actorID)
+	if err != nil {
+		logger.Error("Invalid actor for audit log entry", "error", err)
+		return
+	}
+
+	entry := &model.AuditLog{
+		ID:        uuid.New(),
+		ActorID:   actor,
+		Action:    action,
+		TargetID:  targetID,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.auditRepo.CreateEntry(ctx, entry); err != nil {
+		logger.Error("Failed to write audit log entry", "error", err)
+	}
+}
+
+// normalizePage applies the default and maximum page size.
+func normalizePage(page, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	return page, perPage
+}
+
+func newPagination(page, perPage int, total int64) model.Pagination {
+	return model.Pagination{
+		CurrentPage:  page,
+		PerPage:      perPage,
+		TotalPages:   int((total + int64(perPage) - 1) / int64(perPage)),
+		TotalRecords: int(total),
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func TestNormalizePage(t *testing.T) {
+	tests := []struct {
+		name            string
+		page, perPage   int
+		wantPage, wantN int
+	}{
+		{name: "defaults", page: 0, perPage: 0, wantPage: 1, wantN: defaultPerPage},
+		{name: "negative", page: -3, perPage: -1, wantPage: 1, wantN: defaultPerPage},
+		{name: "capped", page: 4, perPage: 1000, wantPage: 4, wantN: maxPerPage},
+		{name: "unchanged", page: 2, perPage: 50, wantPage: 2, wantN: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, perPage := normalizePage(tt.page, tt.perPage)
+			assert.Equal(t, tt.wantPage, page)
+			assert.Equal(t, tt.wantN, perPage)
+		})
+	}
+}
+
+func TestNewPagination(t *testing.T) {
+	assert.Equal(t, model.Pagination{CurrentPage: 1, PerPage: 20, TotalPages: 0, TotalRecords: 0}, newPagination(1, 20, 0))
+	assert.Equal(t, model.Pagination{CurrentPage: 2, PerPage: 20, TotalPages: 3, TotalRecords: 41}, newPagination(2, 20, 41))
+}
+
+// rolesRepo is a repo.PermissionRepository with a fixed set of roles.
+type rolesRepo map[string][]string
+
+func (r rolesRepo) FindByRole(ctx context.Context, role string) ([]string, error) {
+	return r[role], nil
+}
+
+func (r rolesRepo) RoleExists(ctx context.Context, role string) (bool, error) {
+	_, ok := r[role]
+	return ok, nil
+}
+
+func TestAdminService_SetRoleUnknownRole(t *testing.T) {
+	s := &adminService{permissionRepo: rolesRepo{
+		model.RoleUser:  {model.PermArtWrite},
+		model.RoleAdmin: {model.PermArtWrite},
+		"editor":        {model.PermPagesWrite},
+	}}
+	ctx := context.Background()
+
+	_, err := s.SetRole(ctx, "actor", "user", "root")
+	assert.ErrorIs(t, err, model.ErrInvalidRole)
+
+	_, err = s.SetRolePolicy(ctx, "actor", "root", true)
+	assert.ErrorIs(t, err, model.ErrInvalidRole)
+
+	_, err = s.GetRolePolicy(ctx, "actor", "root")
+	assert.ErrorIs(t, err, model.ErrInvalidRole)
+
+	assert.NoError(t, s.checkRole(ctx, "editor"), "roles added to the permissions are valid")
+}
+
+func TestTemporaryPassword(t *testing.T) {
+	policy := NewPasswordPolicy(40, nil)
+
+	password, err := temporaryPassword(policy)
+	assert.NoError(t, err)
+	assert.NoError(t, policy.Validate(password))
+
+	password, err = temporaryPassword(nil)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(password), temporaryPasswordBytes)
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+const (
+	// apiTokenBytes is the entropy of generated API tokens.
+	apiTokenBytes = 32
+
+	// apiTokenPrefixLen is the number of leading token characters kept to identify a token.
+	apiTokenPrefixLen = 10
+
+	// tokenTouchInterval limits how often the last used time of a token is written.
+	tokenTouchInterval = time.Minute
+)
+
+// APITokenService manages personal API tokens and authenticates requests made with them.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=APITokenService --filename=api_token_service.go --output=../../mocks/
+type APITokenService interface {
+	CreateToken(ctx context.Context, userID, name string, scopes []string, validFor time.Duration) (*model.APIToken, string, error)
+	ListTokens(ctx context.Context, userID string) ([]model.APIToken, error)
+	RevokeToken(ctx context.Context, userID, tokenID string) error
+	Authenticate(ctx context.Context, token string) (*model.APIToken, []string, error)
+}
+
+type apiTokenService struct {
+	tokenRepo         repo.APITokenRepository
+	userRepo          repo.UserRepository
+	permissionService PermissionService
+}
+
+func NewAPITokenService(tr repo.APITokenRepository, ur repo.UserRepository, ps PermissionService) APITokenService {
+	return &apiTokenService{
+		tokenRepo:         tr,
+		userRepo:          ur,
+		permissionService: ps,
+	}
+}
+
+// CreateToken creates a token with the given scopes and returns it with its value.
+// Scopes must be permissions the user's role holds; the value is only returned here.
+func (s *apiTokenService) CreateToken(ctx context.Context, userID, name string, scopes []string, validFor time.Duration) (*model.APIToken, string, error) {
+	logger := logctx.With(ctx, "method", "CreateToken", "userID", userID, "scopes", scopes)
+
+	if name == "" || len(scopes) == 0 || validFor < 0 {
+		logger.Warn("Invalid input parameters")
+		return nil, "", model.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return nil, "", err
+	}
+
+	granted, err := s.permissionService.PermissionsForRole(ctx, user.Role)
+	if err != nil {
+		logger.Error("Failed to load role permissions", "error", err)
+		return nil, "", err
+	}
+
+	scopes = normalizeScopes(scopes)
+	for _, scope := range scopes {
+		if !slices.Contains(model.AllPermissions, scope) {
+			logger.Warn("Unknown scope", "scope", scope)
+			return nil, "", fmt.Errorf("%w: unknown scope %q", model.ErrInvalidInput, scope)
+		}
+		if !slices.Contains(granted, scope) {
+			logger.Warn("Scope exceeds role permissions", "scope", scope)
+			return nil, "", fmt.Errorf("%w: scope %q is not granted to your role", model.ErrUnauthorized, scope)
+		}
+	}
+
+	random, err := GenerateToken(apiTokenBytes)
+	if err != nil {
+		logger.Error("Failed to generate token", "error", err)
+		return nil, "", err
+	}
+	value := model.APITokenPrefix + random
+
+	token := &model.APIToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: HashToken(value),
+		Prefix:    value[:apiTokenPrefixLen],
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	if validFor > 0 {
+		expiresAt := token.CreatedAt.Add(validFor)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.tokenRepo.CreateToken(ctx, token); err != nil {
+		logger.Error("Failed to create token", "error", err)
+		return nil, "", err
+	}
+
+	logger.Info("API token created", "tokenID", token.ID)
+	return token, value, nil
+}
+
+func (s *apiTokenService) ListTokens(ctx context.Context, userID string) ([]model.APIToken, error) {
+	logger := logctx.With(ctx, "method", "ListTokens", "userID", userID)
+
+	tokens, err := s.tokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to list tokens", "error", err)
+		return nil, err
+	}
+
+	logger.Info("API tokens listed", "count", len(tokens))
+	return tokens, nil
+}
+
+func (s *apiTokenService) RevokeToken(ctx context.Context, userID, tokenID string) error {
+	logger := logctx.With(ctx, "method", "RevokeToken", "userID", userID, "tokenID", tokenID)
+
+	if err := s.tokenRepo.DeleteToken(ctx, userID, tokenID); err != nil {
+		logger.Error("Failed to revoke token", "error", err)
+		return err
+	}
+
+	logger.Info("API token revoked")
+	return nil
+}
+
+// Authenticate resolves a bearer token to its token record, with the owner loaded
+// into User, and the effective permissions: the token scopes the role still holds.
+func (s *apiTokenService) Authenticate(ctx context.Context, value string) (*model.APIToken, []string, error) {
+	logger := logctx.With(ctx, "method", "Authenticate")
+
+	token, err := s.tokenRepo.FindByHash(ctx, HashToken(value))
+	if err != nil {
+		if errors.Is(err, model.ErrTokenNotFound) {
+			logger.Warn("Unknown api token")
+			return nil, nil, model.ErrInvalidToken
+		}
+		logger.Error("Failed to find token", "error", err)
+		return nil, nil, err
+	}
+
+	logger = logger.With("tokenID", token.ID, "userID", token.UserID)
+
+	now := time.Now()
+	if token.Expired(now) {
+		logger.Warn("Expired api token")
+		return nil, nil, model.ErrInvalidToken
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, token.UserID.String())
+	if err != nil {
+		logger.Error("Failed to find token owner", "error", err)
+		return nil, nil, err
+	}
+	if user.Disabled {
+		logger.Warn("API token of disabled account")
+		return nil, nil, model.ErrAccountDisabled
+	}
+	token.User = *user
+
+	granted, err := s.permissionService.PermissionsForRole(ctx, user.Role)
+	if err != nil {
+		logger.Error("Failed to load role permissions", "error", err)
+		return nil, nil, err
+	}
+
+	permissions := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		if slices.Contains(granted, scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > tokenTouchInterval {
+		if err := s.tokenRepo.TouchToken(ctx, token.ID.String(), now); err != nil {
+			logger.Warn("Failed to update token last used time", "error", err)
+		}
+	}
+
+	return token, permissions, nil
+}
+
+// normalizeScopes sorts the scopes and drops duplicates.
+func normalizeScopes(scopes []string) []string {
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	return slices.Compact(scopes)
+}
@@ -3,11 +3,11 @@ package service
 import (
 	"context"
 	"errors"
-	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/repo"
 )
@@ -34,7 +34,7 @@ func NewArtLinkService(artLinkRepo repo.ArtLinkRepository) ArtLinkService {
 
 // CreateArtLink creates a new art link
 func (s *artLinkService) CreateArtLink(ctx context.Context, revisionID uuid.UUID, duration time.Duration, oneTime bool) (string, error) {
-	logger := slog.With("method", "CreateArtLink", "revisionID", revisionID)
+	logger := logctx.With(ctx, "method", "CreateArtLink", "revisionID", revisionID)
 
 	if revisionID == uuid.Nil || duration <= 0 {
 		logger.Warn("Invalid input parameters")
@@ -62,7 +62,7 @@ func (s *artLinkService) CreateArtLink(ctx context.Context, revisionID uuid.UUID
 
 // GetArtLinkByToken retrieves an art link by its token
 func (s *artLinkService) GetArtLinkByToken(ctx context.Context, token string) (*model.ArtLink, error) {
-	logger := slog.With("method", "GetArtLinkByToken", "token", token)
+	logger := logctx.With(ctx, "method", "GetArtLinkByToken", "token", token)
 
 	if token == "" {
 		logger.Warn("Invalid token")
@@ -85,7 +85,7 @@ func (s *artLinkService) GetArtLinkByToken(ctx context.Context, token string) (*
 
 // UpdateArtLink updates an existing art link
 func (s *artLinkService) UpdateArtLink(ctx context.Context, artLink *model.ArtLink) error {
-	logger := slog.With("method", "UpdateArtLink", "token", artLink.Token)
+	logger := logctx.With(ctx, "method", "UpdateArtLink", "token", artLink.Token)
 
 	if artLink.Token == "" {
 		logger.Warn("Invalid art link")
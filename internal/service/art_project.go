@@ -5,10 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log/slog"
 
 	"github.com/google/uuid"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/repo"
 )
@@ -55,14 +55,14 @@ func NewArtProjectService(
 
 // CreateArtProject creates a new art project
 func (s *artProjectService) CreateArtProject(ctx context.Context, artProject *model.ArtProject) error {
-	logger := slog.With("method", "CreateArtProject",
+	logger := logctx.With(ctx, "method", "CreateArtProject",
 		"artProjectID", artProject.ID,
 		"title", artProject.Title,
 	)
 
 	stash, err := s.userRepo.GetStashByUserID(ctx, artProject.UserID.String())
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to find stash for user", "error", err, "userID", artProject.UserID.String())
+		logctx.From(ctx).ErrorContext(ctx, "Failed to find stash for user", "error", err, "userID", artProject.UserID.String())
 		return err
 	}
 
@@ -79,35 +79,35 @@ func (s *artProjectService) CreateArtProject(ctx context.Context, artProject *mo
 
 // GetArtProject finds an art project by its ID
 func (s *artProjectService) GetArtProject(ctx context.Context, id string) (*model.ArtProject, error) {
-	slog.InfoContext(ctx, "ArtProjectService: Finding art project by ID", "artProjectID", id)
+	logctx.From(ctx).InfoContext(ctx, "ArtProjectService: Finding art project by ID", "artProjectID", id)
 
 	artProject, err := s.artRepo.FindArtProjectByID(ctx, id)
 	if err != nil {
-		slog.ErrorContext(ctx, "ArtProjectService: Failed to find art project by ID",
+		logctx.From(ctx).ErrorContext(ctx, "ArtProjectService: Failed to find art project by ID",
 			"error", err,
 			"artProjectID", id,
 		)
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "ArtProjectService: Art project found successfully", "artProjectID", id)
+	logctx.From(ctx).InfoContext(ctx, "ArtProjectService: Art project found successfully", "artProjectID", id)
 	return artProject, nil
 }
 
 // FindByStashID finds all art projects by a stash ID
 func (s *artProjectService) FindByStashID(ctx context.Context, stashID string) ([]model.ArtProject, error) {
-	slog.InfoContext(ctx, "Finding art projects by stash ID", "stashID", stashID)
+	logctx.From(ctx).InfoContext(ctx, "Finding art projects by stash ID", "stashID", stashID)
 
 	artProjects, err := s.artRepo.FindByStashID(ctx, stashID)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to find art projects by stash ID",
+		logctx.From(ctx).ErrorContext(ctx, "Failed to find art projects by stash ID",
 			"error", err,
 			"stashID", stashID,
 		)
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "Art projects found successfully",
+	logctx.From(ctx).InfoContext(ctx, "Art projects found successfully",
 		"stashID", stashID,
 		"count", len(artProjects),
 	)
@@ -115,11 +115,11 @@ func (s *artProjectService) FindByStashID(ctx context.Context, stashID string) (
 }
 
 func (s *artProjectService) FindByUserID(ctx context.Context, userID string) ([]model.ArtProject, error) {
-	slog.InfoContext(ctx, "Finding art projects by user ID", "userID", userID)
+	logctx.From(ctx).InfoContext(ctx, "Finding art projects by user ID", "userID", userID)
 
 	artProjects, err := s.artRepo.FindByUserID(ctx, userID)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to find art projects by user ID",
+		logctx.From(ctx).ErrorContext(ctx, "Failed to find art projects by user ID",
 			"error", err,
 			"userID", userID,
 		)
@@ -130,10 +130,10 @@ func (s *artProjectService) FindByUserID(ctx context.Context, userID string) ([]
 }
 
 func (s *artProjectService) AddRevision(ctx context.Context, revision *model.Revision, fileData io.Reader) error {
-	logger := slog.With("service", "AddRevision", "userID", revision.UserID, "revisionID", revision.ID, "artProjectID", revision.ArtProjectID)
+	logger := logctx.With(ctx, "service", "AddRevision", "userID", revision.UserID, "revisionID", revision.ID, "artProjectID", revision.ArtProjectID)
 
 	if revision.UserID == uuid.Nil || revision.ArtProjectID == uuid.Nil || fileData == nil {
-		logger.Warn("Invalid input parameters")
+		logger.WarnContext(ctx, "Invalid input parameters")
 		return model.ErrInvalidInput
 	}
 
@@ -141,13 +141,13 @@ func (s *artProjectService) AddRevision(ctx context.Context, revision *model.Rev
 
 	filePath, fileInfo, err := s.fileStorageRepo.SaveRevisionFile(ctx, fileData, revision.UserID.String(), revision.ArtProjectID.String(), nextVersion)
 	if err != nil {
-		logger.Error("Failed to store revision file", "error", err)
+		logger.ErrorContext(ctx, "Failed to store revision file", "error", err)
 		return fmt.Errorf("failed to store revision file: %w", err)
 	}
 
 	artID, err := GeneratePublicID(revision.ID, revision.UserID, s.secretKey)
 	if err != nil {
-		logger.Error("Failed to generate artID", "error", err)
+		logger.ErrorContext(ctx, "Failed to generate artID", "error", err)
 		return fmt.Errorf("failed to generate artID: %w", err)
 	}
 
@@ -157,18 +157,18 @@ func (s *artProjectService) AddRevision(ctx context.Context, revision *model.Rev
 	revision.ArtID = artID
 
 	if err := s.artRepo.SaveRevision(ctx, revision); err != nil {
-		logger.Error("Failed to save revision", "error", err)
+		logger.ErrorContext(ctx, "Failed to save revision", "error", err)
 		return fmt.Errorf("failed to save revision: %w", err)
 	}
 
 	if err := s.artRepo.UpdateLatestRevision(ctx, revision.ArtProjectID, revision.ID); err != nil {
-		logger.Error("Failed to update latest revision", "error", err)
+		logger.ErrorContext(ctx, "Failed to update latest revision", "error", err)
 		return fmt.Errorf("failed to update latest revision: %w", err)
 	}
 
 	stash, err := s.userRepo.GetStashByUserID(ctx, revision.UserID.String())
 	if err != nil {
-		logger.Error("Failed to find stash", "error", err)
+		logger.ErrorContext(ctx, "Failed to find stash", "error", err)
 		return fmt.Errorf("failed to find stash: %w", err)
 	}
 
@@ -176,29 +176,29 @@ func (s *artProjectService) AddRevision(ctx context.Context, revision *model.Rev
 	stash.ArtProjects++
 	stash.UsedSpace += revision.Size
 	if err := s.userRepo.UpdateStash(ctx, stash); err != nil {
-		logger.Error("Failed to update stash stats", "error", err)
+		logger.ErrorContext(ctx, "Failed to update stash stats", "error", err)
 		return fmt.Errorf("failed to update stash stats: %w", err)
 	}
 
-	logger.Info("Revision added successfully")
+	logger.InfoContext(ctx, "Revision added successfully")
 	return nil
 }
 
 func (s *artProjectService) GetRevisionByArtID(ctx context.Context, artID string) (*model.Revision, error) {
 	revisionID, userID, err := DecodePublicID(artID, s.secretKey)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to decode artID", "error", err, "artID", artID)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to decode artID", "error", err, "artID", artID)
 		return nil, err
 	}
 
 	revision, err := s.artRepo.FindRevisionByID(ctx, revisionID)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed get revision by ID", "error", err, "revisionID", revisionID)
+		logctx.From(ctx).ErrorContext(ctx, "Failed get revision by ID", "error", err, "revisionID", revisionID)
 		return nil, err
 	}
 
 	if revision.UserID.String() != userID {
-		slog.ErrorContext(ctx, "userID does not match the revision's userID", "revision.UserID", revision.UserID, "userID", userID)
+		logctx.From(ctx).ErrorContext(ctx, "userID does not match the revision's userID", "revision.UserID", revision.UserID, "userID", userID)
 		return nil, errors.New("userID does not match the revision's userID")
 	}
 
@@ -206,107 +206,107 @@ func (s *artProjectService) GetRevisionByArtID(ctx context.Context, artID string
 }
 
 func (s *artProjectService) GetLatestRevision(ctx context.Context, artProjectID string) (*model.Revision, error) {
-	logger := slog.With("method", "GetLatestRevision", "artProjectID", artProjectID)
+	logger := logctx.With(ctx, "method", "GetLatestRevision", "artProjectID", artProjectID)
 
 	artProject, err := s.artRepo.FindArtProjectByID(ctx, artProjectID)
 	if err != nil {
-		logger.Error("Failed to get art project", "error", err)
+		logger.ErrorContext(ctx, "Failed to get art project", "error", err)
 		return nil, err
 	}
 
 	revision, err := s.artRepo.GetRevisionByID(ctx, artProject.LatestRevisionID.String())
 	if err != nil {
-		logger.Error("Failed to get latest revision", "error", err)
+		logger.ErrorContext(ctx, "Failed to get latest revision", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Latest revision retrieved successfully")
+	logger.InfoContext(ctx, "Latest revision retrieved successfully")
 	return revision, nil
 }
 
 func (s *artProjectService) DeleteArtProject(ctx context.Context, id string) error {
-	logger := slog.With("method", "DeleteArtProject", "artProjectID", id)
+	logger := logctx.With(ctx, "method", "DeleteArtProject", "artProjectID", id)
 
 	if id == "" {
-		logger.Warn("Invalid input parameters")
+		logger.WarnContext(ctx, "Invalid input parameters")
 		return model.ErrInvalidInput
 	}
 
 	if err := s.artRepo.DeleteArtProject(ctx, id); err != nil {
-		logger.Error("Failed to delete art project", "error", err)
+		logger.ErrorContext(ctx, "Failed to delete art project", "error", err)
 		return err
 	}
 
-	logger.Info("Art project deleted successfully")
+	logger.InfoContext(ctx, "Art project deleted successfully")
 	return nil
 }
 
 func (s *artProjectService) ListArtProjects(ctx context.Context, userID string) ([]model.ArtProject, error) {
-	logger := slog.With("method", "ListArtProjects", "userID", userID)
+	logger := logctx.With(ctx, "method", "ListArtProjects", "userID", userID)
 
 	if userID == "" {
-		logger.Warn("Invalid input parameters")
+		logger.WarnContext(ctx, "Invalid input parameters")
 		return nil, model.ErrInvalidInput
 	}
 
 	artProjects, err := s.artRepo.ListAllArtProjects(ctx, userID)
 	if err != nil {
-		logger.Error("Failed to list art projects", "error", err)
+		logger.ErrorContext(ctx, "Failed to list art projects", "error", err)
 		return nil, err
 	}
 
 	if len(artProjects) == 0 {
-		logger.Info("No art projects found")
+		logger.InfoContext(ctx, "No art projects found")
 		return []model.ArtProject{}, nil
 	}
 
-	logger.Info("Art projects listed successfully", "count", len(artProjects))
+	logger.InfoContext(ctx, "Art projects listed successfully", "count", len(artProjects))
 	return artProjects, nil
 }
 
 func (s *artProjectService) ListRevisions(ctx context.Context, artProjectID string) ([]model.Revision, error) {
-	logger := slog.With("method", "ListRevisions", "artProjectID", artProjectID)
+	logger := logctx.With(ctx, "method", "ListRevisions", "artProjectID", artProjectID)
 
 	if artProjectID == "" {
-		logger.Warn("Invalid input: empty artProjectID")
+		logger.WarnContext(ctx, "Invalid input: empty artProjectID")
 		return nil, model.ErrInvalidInput
 	}
 
 	revisions, err := s.artRepo.ListAllRevisions(ctx, artProjectID)
 	if err != nil {
-		logger.Error("Failed to list revisions", "error", err)
+		logger.ErrorContext(ctx, "Failed to list revisions", "error", err)
 		return nil, err
 	}
 
 	if len(revisions) == 0 {
-		logger.Info("No revisions found for art project")
+		logger.InfoContext(ctx, "No revisions found for art project")
 		return []model.Revision{}, nil
 	}
 
-	logger.Info("Revisions listed successfully", "count", len(revisions))
+	logger.InfoContext(ctx, "Revisions listed successfully", "count", len(revisions))
 	return revisions, nil
 }
 
 func (s *artProjectService) FindByID(ctx context.Context, id string) (*model.ArtProject, error) {
-	logger := slog.With("method", "FindByID", "artProjectID", id)
+	logger := logctx.With(ctx, "method", "FindByID", "artProjectID", id)
 
 	if id == "" {
-		logger.Warn("Invalid input: empty id")
+		logger.WarnContext(ctx, "Invalid input: empty id")
 		return nil, model.ErrInvalidInput
 	}
 
 	artProject, err := s.artRepo.FindArtProjectByID(ctx, id)
 	if err != nil {
-		logger.Error("Failed to find art project", "error", err)
+		logger.ErrorContext(ctx, "Failed to find art project", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Art project found successfully")
+	logger.InfoContext(ctx, "Art project found successfully")
 	return artProject, nil
 }
 
 func (s *artProjectService) GetArtProjectByRevision(ctx context.Context, userID, artProjectID, revisionID string) (io.ReadCloser, *model.ArtProject, error) {
-	logger := slog.With("service", "GetArtProjectByRevision", "artProjectID", artProjectID, "revisionID", revisionID, "userID", userID)
+	logger := logctx.With(ctx, "service", "GetArtProjectByRevision", "artProjectID", artProjectID, "revisionID", revisionID, "userID", userID)
 
 	rev, err := s.artRepo.FindRevisionByID(ctx, revisionID)
 	if err != nil {
@@ -339,7 +339,7 @@ func (s *artProjectService) GetArtProjectByRevision(ctx context.Context, userID,
 func (s *artProjectService) determineNextVersion(ctx context.Context, artProjectID string) int {
 	maxVersion, err := s.artRepo.GetMaxRevisionVersion(ctx, artProjectID)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to retrieve maximum revision version", "error", err, "artProjectID", artProjectID)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to retrieve maximum revision version", "error", err, "artProjectID", artProjectID)
 		return 1 // Default to version 1 in case of error
 	}
 	return maxVersion + 1
@@ -4,46 +4,58 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/repo"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2 --name=CollectionService --filename=collection_service.go --output=../../mocks/
 type CollectionService interface {
-	CreateCollection(ctx context.Context, userID, title string) (*model.Collection, error)
+	CreateCollection(ctx context.Context, userID string, req model.CollectionRequest) (*model.Collection, error)
 	FindByID(ctx context.Context, id string) (*model.Collection, error)
+	GetCollection(ctx context.Context, userID, id string) (*model.Collection, error)
 	FindByUserID(ctx context.Context, userID string) ([]model.Collection, error)
-	UpdateCollection(ctx context.Context, collection *model.Collection) error
-	DeleteCollection(ctx context.Context, id string) error
-	AddRevisionToCollection(ctx context.Context, collectionID, revisionID string) error
-	RemoveRevisionFromCollection(ctx context.Context, collectionID, revisionID string) error
-	GetRevisionsByCollectionID(ctx context.Context, collectionID string) ([]model.Revision, error)
-	GetRevisionsByPublicCollectionID(ctx context.Context, collectionPublicID string) ([]model.Revision, error)
+	UpdateCollection(ctx context.Context, userID, id string, req model.CollectionRequest) (*model.Collection, error)
+	DeleteCollection(ctx context.Context, userID, id string) error
+	AddRevisionToCollection(ctx context.Context, userID, collectionID, revisionID, caption string) error
+	RemoveRevisionFromCollection(ctx context.Context, userID, collectionID, revisionID string) error
+	UpdateRevisionCaption(ctx context.Context, userID, collectionID, revisionID, caption string) error
+	ReorderRevisions(ctx context.Context, userID, collectionID string, revisionIDs []string) error
+	GetRevisionsByCollectionID(ctx context.Context, userID, collectionID string) ([]model.CollectionArtProject, error)
+	GetRevisionsByPublicCollectionID(ctx context.Context, collectionPublicID string) ([]model.CollectionArtProject, error)
+	FindByPublicID(ctx context.Context, collectionPublicID string) (*model.Collection, error)
+	InviteMember(ctx context.Context, userID, collectionID string, req model.CollectionMemberRequest) (*model.CollectionMember, error)
+	ListMembers(ctx context.Context, userID, collectionID string) ([]model.CollectionMember, error)
+	UpdateMemberRole(ctx context.Context, userID, collectionID, memberID, role string) (*model.CollectionMember, error)
+	RemoveMember(ctx context.Context, userID, collectionID, memberID string) error
+	ListInvitations(ctx context.Context, userID string) ([]model.CollectionMember, error)
+	AcceptInvitation(ctx context.Context, userID, collectionID string) (*model.CollectionMember, error)
 }
 
 type collectionService struct {
 	collectionRepo repo.CollectionRepository
 	artRepo        repo.ArtProjectRepository
+	userRepo       repo.UserRepository
 	secretKey      []byte
 }
 
-func NewCollectionService(cr repo.CollectionRepository, ar repo.ArtProjectRepository, secretKey []byte) CollectionService {
+func NewCollectionService(cr repo.CollectionRepository, ar repo.ArtProjectRepository, ur repo.UserRepository, secretKey []byte) CollectionService {
 	return &collectionService{
 		collectionRepo: cr,
 		artRepo:        ar,
+		userRepo:       ur,
 		secretKey:      secretKey,
 	}
 }
 
-func (s *collectionService) CreateCollection(ctx context.Context, userID, title string) (*model.Collection, error) {
-	logger := slog.With("method", "CreateCollection", "userID", userID, "title", title)
+func (s *collectionService) CreateCollection(ctx context.Context, userID string, req model.CollectionRequest) (*model.Collection, error) {
+	logger := logctx.With(ctx, "method", "CreateCollection", "userID", userID, "title", req.Title)
 
-	if userID == "" || title == "" {
+	if userID == "" || req.Title == "" {
 		logger.Warn("Invalid input parameters")
 		return nil, model.ErrInvalidInput
 	}
@@ -65,11 +77,28 @@ func (s *collectionService) CreateCollection(ctx context.Context, userID, title
 		ID:           collectionID,
 		CollectionID: publicCollectionID,
 		UserID:       parsedUserID,
-		Title:        title,
+		Title:        req.Title,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
+	if req.Description != nil {
+		collection.Description = *req.Description
+	}
+	if req.Public != nil {
+		collection.Public = *req.Public
+	}
+
+	collection.Type = model.CollectionTypeManual
+	if req.Rule != nil {
+		if _, err := ruleFilter(userID, req.Rule); err != nil {
+			logger.Warn("Invalid collection rule", "error", err)
+			return nil, err
+		}
+		collection.Type = model.CollectionTypeSmart
+		collection.Rule = req.Rule
+	}
+
 	logger = logger.With("collectionID", collection.ID)
 	logger.Info("Creating new collection")
 
@@ -83,7 +112,7 @@ func (s *collectionService) CreateCollection(ctx context.Context, userID, title
 }
 
 func (s *collectionService) FindByID(ctx context.Context, id string) (*model.Collection, error) {
-	logger := slog.With("method", "FindByID", "collectionID", id)
+	logger := logctx.With(ctx, "method", "FindByID", "collectionID", id)
 
 	if id == "" {
 		logger.Warn("Invalid input: empty id")
@@ -100,8 +129,15 @@ func (s *collectionService) FindByID(ctx context.Context, id string) (*model.Col
 	return collection, nil
 }
 
+// GetCollection retrieves a collection the user owns or is a member of.
+func (s *collectionService) GetCollection(ctx context.Context, userID, id string) (*model.Collection, error) {
+	collection, _, err := s.authorize(ctx, userID, id, model.CollectionRoleViewer)
+	return collection, err
+}
+
+// FindByUserID retrieves the collections a user owns or has joined.
 func (s *collectionService) FindByUserID(ctx context.Context, userID string) ([]model.Collection, error) {
-	logger := slog.With("method", "FindByUserID", "userID", userID)
+	logger := logctx.With(ctx, "method", "FindByUserID", "userID", userID)
 
 	if userID == "" {
 		logger.Warn("Invalid input: empty userID")
@@ -118,26 +154,70 @@ func (s *collectionService) FindByUserID(ctx context.Context, userID string) ([]
 	return collections, nil
 }
 
-func (s *collectionService) UpdateCollection(ctx context.Context, collection *model.Collection) error {
-	logger := slog.With("method", "UpdateCollection", "collectionID", collection.ID)
+func (s *collectionService) UpdateCollection(ctx context.Context, userID, id string, req model.CollectionRequest) (*model.Collection, error) {
+	logger := logctx.With(ctx, "method", "UpdateCollection", "collectionID", id, "userID", userID)
+
+	collection, _, err := s.authorize(ctx, userID, id, model.CollectionRoleOwner)
+	if err != nil {
+		logger.Error("Failed to authorize collection update", "error", err)
+		return nil, err
+	}
+
+	if req.Title != "" {
+		collection.Title = req.Title
+	}
+	if req.Description != nil {
+		collection.Description = *req.Description
+	}
+	if req.Public != nil {
+		collection.Public = *req.Public
+	}
+	if req.Rule != nil {
+		if collection.Type != model.CollectionTypeSmart {
+			logger.Warn("Rule given for a manual collection")
+			return nil, fmt.Errorf("%w: manual collection can not have a rule", model.ErrInvalidInput)
+		}
+		if _, err := ruleFilter(collection.UserID.String(), req.Rule); err != nil {
+			logger.Warn("Invalid collection rule", "error", err)
+			return nil, err
+		}
+		collection.Rule = req.Rule
+	}
+	if req.CoverRevisionID != nil {
+		if *req.CoverRevisionID == uuid.Nil {
+			collection.CoverRevisionID = nil
+		} else {
+			if _, err := s.findCollectionItem(ctx, collection, *req.CoverRevisionID); err != nil {
+				logger.Warn("Cover revision is not part of the collection", "coverRevisionID", *req.CoverRevisionID)
+				return nil, model.ErrInvalidInput
+			}
+			collection.CoverRevisionID = req.CoverRevisionID
+		}
+	}
+	collection.UpdatedAt = time.Now()
 
 	if err := s.collectionRepo.UpdateCollection(ctx, collection); err != nil {
 		logger.Error("Failed to update collection", "error", err)
-		return err
+		return nil, err
 	}
 
 	logger.Info("Collection updated successfully")
-	return nil
+	return collection, nil
 }
 
-func (s *collectionService) DeleteCollection(ctx context.Context, id string) error {
-	logger := slog.With("method", "DeleteCollection", "collectionID", id)
+func (s *collectionService) DeleteCollection(ctx context.Context, userID, id string) error {
+	logger := logctx.With(ctx, "method", "DeleteCollection", "collectionID", id, "userID", userID)
 
 	if id == "" {
 		logger.Warn("Invalid input: empty id")
 		return model.ErrInvalidInput
 	}
 
+	if _, _, err := s.authorize(ctx, userID, id, model.CollectionRoleOwner); err != nil {
+		logger.Error("Failed to authorize collection deletion", "error", err)
+		return err
+	}
+
 	if err := s.collectionRepo.DeleteCollection(ctx, id); err != nil {
 		logger.Error("Failed to delete collection", "error", err)
 		return err
@@ -147,8 +227,10 @@ func (s *collectionService) DeleteCollection(ctx context.Context, id string) err
 	return nil
 }
 
-func (s *collectionService) AddRevisionToCollection(ctx context.Context, collectionID, revisionID string) error {
-	logger := slog.With("service", "AddRevisionToCollection", "collectionID", collectionID, "revisionID", revisionID)
+// AddRevisionToCollection adds one of the user's own revisions to a collection.
+// Owners and editors may add revisions, the user is recorded as the contributor.
+func (s *collectionService) AddRevisionToCollection(ctx context.Context, userID, collectionID, revisionID, caption string) error {
+	logger := logctx.With(ctx, "service", "AddRevisionToCollection", "collectionID", collectionID, "revisionID", revisionID, "userID", userID)
 
 	if collectionID == "" || revisionID == "" {
 		logger.Warn("Invalid input: empty collectionID or revisionID")
@@ -161,19 +243,32 @@ func (s *collectionService) AddRevisionToCollection(ctx context.Context, collect
 		return model.ErrArtProjectNotFound
 	}
 
-	col, err := s.FindByID(ctx, collectionID)
+	col, _, err := s.authorize(ctx, userID, collectionID, model.CollectionRoleEditor)
 	if err != nil {
-		logger.Error("Failed to find collection", "error", err)
+		logger.Error("Failed to authorize adding a revision", "error", err)
 		return err
 	}
 
-	if rev.UserID != col.UserID {
-		logger.Error("User is not authorized for the action", "error", err,
-			"rev.UserID", rev.UserID, "col.UserID", col.UserID)
+	if col.Type == model.CollectionTypeSmart {
+		logger.Warn("Smart collection can not be changed by hand")
+		return model.ErrSmartCollection
+	}
+
+	if rev.UserID.String() != userID {
+		logger.Error("User is not authorized for the action",
+			"rev.UserID", rev.UserID, "userID", userID)
 		return model.ErrUnauthorized
 	}
 
-	if err := s.collectionRepo.AddRevisionToCollection(ctx, collectionID, revisionID); err != nil {
+	item := &model.CollectionArtProject{
+		CollectionID:  col.ID,
+		RevisionID:    rev.ID,
+		Caption:       caption,
+		ContributorID: &rev.UserID,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := s.collectionRepo.AddRevisionToCollection(ctx, item); err != nil {
 		logger.Error("Failed to add art project to collection", "error", err)
 		return err
 	}
@@ -182,78 +277,326 @@ func (s *collectionService) AddRevisionToCollection(ctx context.Context, collect
 	return nil
 }
 
-func (s *collectionService) RemoveRevisionFromCollection(ctx context.Context, collectionID, revisionID string) error {
-	logger := slog.With("method", "RemoveRevisionFromCollection", "collectionID", collectionID, "revisionID", revisionID)
+// RemoveRevisionFromCollection removes a revision from a collection.
+// Editors may only remove the revisions they contributed.
+func (s *collectionService) RemoveRevisionFromCollection(ctx context.Context, userID, collectionID, revisionID string) error {
+	logger := logctx.With(ctx, "method", "RemoveRevisionFromCollection", "collectionID", collectionID, "revisionID", revisionID, "userID", userID)
 
 	if collectionID == "" || revisionID == "" {
 		logger.Warn("Invalid input: empty collectionID or artProjectID")
 		return model.ErrInvalidInput
 	}
 
-	rev, err := s.artRepo.FindRevisionByID(ctx, revisionID)
+	col, item, err := s.authorizeItem(ctx, userID, collectionID, revisionID)
 	if err != nil {
-		logger.Error("Failed to find revision", "error", err)
-		return model.ErrArtProjectNotFound
+		logger.Error("Failed to authorize removing a revision", "error", err)
+		return err
 	}
 
-	col, err := s.FindByID(ctx, collectionID)
-	if err != nil {
-		logger.Error("Failed to find collection", "error", err)
+	if err := s.collectionRepo.RemoveRevisionFromCollection(ctx, collectionID, revisionID); err != nil {
+		logger.Error("Failed to remove revision from collection", "error", err)
 		return err
 	}
 
-	if rev.UserID != col.UserID {
-		logger.Error("User is not authorized for the action", "error", err,
-			"rev.UserID", rev.UserID, "col.UserID", col.UserID)
-		return model.ErrUnauthorized
+	if col.CoverRevisionID != nil && *col.CoverRevisionID == item.RevisionID {
+		col.CoverRevisionID = nil
+		if err := s.collectionRepo.UpdateCollection(ctx, col); err != nil {
+			logger.Error("Failed to reset collection cover", "error", err)
+			return err
+		}
 	}
 
-	if err := s.collectionRepo.RemoveRevisionFromCollection(ctx, collectionID, revisionID); err != nil {
-		logger.Error("Failed to remove revision from collection", "error", err)
+	logger.Info("Art revision removed from collection successfully")
+	return nil
+}
+
+// UpdateRevisionCaption changes the caption of a revision in a collection.
+// Editors may only caption the revisions they contributed.
+func (s *collectionService) UpdateRevisionCaption(ctx context.Context, userID, collectionID, revisionID, caption string) error {
+	logger := logctx.With(ctx, "method", "UpdateRevisionCaption", "collectionID", collectionID, "revisionID", revisionID, "userID", userID)
+
+	_, item, err := s.authorizeItem(ctx, userID, collectionID, revisionID)
+	if err != nil {
+		logger.Error("Failed to authorize caption update", "error", err)
 		return err
 	}
 
-	logger.Info("Art revision removed from collection successfully")
+	item.Caption = caption
+	if err := s.collectionRepo.UpdateCollectionItem(ctx, item); err != nil {
+		logger.Error("Failed to update revision caption", "error", err)
+		return err
+	}
+
+	logger.Info("Revision caption updated successfully")
 	return nil
 }
 
-func (s *collectionService) GetRevisionsByCollectionID(ctx context.Context, collectionID string) ([]model.Revision, error) {
-	logger := slog.With("service", "GetRevisionsByCollectionID", "collectionID", collectionID)
+// ReorderRevisions changes the order of revisions in a collection.
+// revisionIDs must list every revision of the collection exactly once.
+func (s *collectionService) ReorderRevisions(ctx context.Context, userID, collectionID string, revisionIDs []string) error {
+	logger := logctx.With(ctx, "method", "ReorderRevisions", "collectionID", collectionID, "userID", userID)
 
-	revisions, err := s.collectionRepo.GetRevisionsByCollectionID(ctx, collectionID)
+	collection, _, err := s.authorize(ctx, userID, collectionID, model.CollectionRoleOwner)
+	if err != nil {
+		logger.Error("Failed to authorize reordering", "error", err)
+		return err
+	}
+
+	if collection.Type == model.CollectionTypeSmart {
+		logger.Warn("Smart collection can not be reordered by hand")
+		return model.ErrSmartCollection
+	}
+
+	items, err := s.listCollectionItems(ctx, collection)
 	if err != nil {
 		logger.Error("Failed to get revisions for the collection", "error", err)
+		return err
+	}
+
+	if len(items) != len(revisionIDs) {
+		logger.Warn("Revision list does not match the collection", "expected", len(items), "actual", len(revisionIDs))
+		return model.ErrInvalidInput
+	}
+
+	inCollection := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		inCollection[item.RevisionID] = true
+	}
+
+	ordered := make([]uuid.UUID, 0, len(revisionIDs))
+	for _, id := range revisionIDs {
+		revisionID, err := uuid.Parse(id)
+		if err != nil || !inCollection[revisionID] {
+			logger.Warn("Revision is not part of the collection", "revisionID", id)
+			return model.ErrInvalidInput
+		}
+		// each revision may be listed only once
+		delete(inCollection, revisionID)
+		ordered = append(ordered, revisionID)
+	}
+
+	if err := s.collectionRepo.ReorderCollectionItems(ctx, collectionID, ordered); err != nil {
+		logger.Error("Failed to reorder revisions", "error", err)
+		return err
+	}
+
+	logger.Info("Revisions reordered successfully")
+	return nil
+}
+
+// GetRevisionsByCollectionID lists the revisions of a collection the user can view.
+// Members of a smart collection are resolved from its rule on every call.
+func (s *collectionService) GetRevisionsByCollectionID(ctx context.Context, userID, collectionID string) ([]model.CollectionArtProject, error) {
+	logger := logctx.With(ctx, "service", "GetRevisionsByCollectionID", "collectionID", collectionID, "userID", userID)
+
+	collection, _, err := s.authorize(ctx, userID, collectionID, model.CollectionRoleViewer)
+	if err != nil {
+		logger.Error("Failed to authorize listing revisions", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Listed all revisions for a collection")
-	return revisions, nil
+	return s.listCollectionItems(ctx, collection)
 }
 
-func (s *collectionService) GetRevisionsByPublicCollectionID(ctx context.Context, collectionPublicID string) ([]model.Revision, error) {
-	logger := slog.With("service", "GetRevisionsByPublicCollectionID", "collectionPublicID", collectionPublicID)
+// GetRevisionsByPublicCollectionID lists the revisions of a public collection.
+// Private collections are reported as not found.
+func (s *collectionService) GetRevisionsByPublicCollectionID(ctx context.Context, collectionPublicID string) ([]model.CollectionArtProject, error) {
+	logger := logctx.With(ctx, "service", "GetRevisionsByPublicCollectionID", "collectionPublicID", collectionPublicID)
+
+	collection, err := s.FindByPublicID(ctx, collectionPublicID)
+	if err != nil {
+		logger.Error("Failed to find public collection", "error", err)
+		return nil, err
+	}
+
+	return s.listCollectionItems(ctx, collection)
+}
+
+// FindByPublicID retrieves a public collection by its public ID.
+// Private collections are reported as not found.
+func (s *collectionService) FindByPublicID(ctx context.Context, collectionPublicID string) (*model.Collection, error) {
+	logger := logctx.With(ctx, "service", "FindByPublicID", "collectionPublicID", collectionPublicID)
 
 	collectionID, userID, err := DecodePublicID(collectionPublicID, s.secretKey)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to decode collectionPublicID", "error", err)
+		logger.ErrorContext(ctx, "Failed to decode collectionPublicID", "error", err)
+		return nil, model.ErrCollectionNotFound
+	}
+
+	collection, err := s.FindByID(ctx, collectionID)
+	if err != nil {
+		logger.Error("Failed to find collection", "error", err)
 		return nil, err
 	}
 
-	revisions, err := s.GetRevisionsByCollectionID(ctx, collectionID)
+	if collection.UserID.String() != userID {
+		logger.ErrorContext(ctx, "userID does not match the collection's userID", "collection.UserID", collection.UserID, "userID", userID)
+		return nil, model.ErrCollectionNotFound
+	}
+
+	if !collection.Public {
+		logger.Info("Collection is not public")
+		return nil, model.ErrCollectionNotFound
+	}
+
+	return collection, nil
+}
+
+// listCollectionItems returns the stored items of a manual collection
+// or the resolved members of a smart collection.
+func (s *collectionService) listCollectionItems(ctx context.Context, collection *model.Collection) ([]model.CollectionArtProject, error) {
+	logger := logctx.With(ctx, "method", "listCollectionItems", "collectionID", collection.ID)
+
+	if collection.Type == model.CollectionTypeSmart {
+		return s.resolveSmartCollection(ctx, collection)
+	}
+
+	items, err := s.collectionRepo.GetRevisionsByCollectionID(ctx, collection.ID.String())
 	if err != nil {
+		if errors.Is(err, model.ErrRevisionNotFound) {
+			logger.Info("Collection has no revisions")
+			return []model.CollectionArtProject{}, nil
+		}
 		logger.Error("Failed to get revisions for the collection", "error", err)
 		return nil, err
 	}
 
-	if len(revisions) == 0 {
-		return []model.Revision{}, nil
+	logger.Info("Listed all revisions for a collection")
+	return items, nil
+}
+
+// findCollectionItem returns the item of the revision in the collection.
+func (s *collectionService) findCollectionItem(ctx context.Context, collection *model.Collection, revisionID uuid.UUID) (*model.CollectionArtProject, error) {
+	items, err := s.listCollectionItems(ctx, collection)
+	if err != nil {
+		return nil, err
 	}
 
-	if revisions[0].UserID.String() != userID {
-		logger.ErrorContext(ctx, "userID does not match the revision's userID", "revision.UserID", revisions[0].UserID, "userID", userID)
-		return nil, errors.New("userID does not match the collection userID")
+	for i := range items {
+		if items[i].RevisionID == revisionID {
+			return &items[i], nil
+		}
 	}
 
-	logger.Info("Listed all revisions for a collection")
-	return revisions, nil
+	return nil, model.ErrRevisionNotFound
+}
+
+// authorizeItem checks that the user may change a revision in a manual collection.
+// Owners may change any item, editors only the items they contributed.
+func (s *collectionService) authorizeItem(ctx context.Context, userID, collectionID, revisionID string) (*model.Collection, *model.CollectionArtProject, error) {
+	parsedRevisionID, err := uuid.Parse(revisionID)
+	if err != nil {
+		return nil, nil, model.ErrInvalidInput
+	}
+
+	collection, role, err := s.authorize(ctx, userID, collectionID, model.CollectionRoleEditor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if collection.Type == model.CollectionTypeSmart {
+		return nil, nil, model.ErrSmartCollection
+	}
+
+	item, err := s.findCollectionItem(ctx, collection, parsedRevisionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if role != model.CollectionRoleOwner && contributorOf(collection, item).String() != userID {
+		return nil, nil, model.ErrUnauthorized
+	}
+
+	return collection, item, nil
+}
+
+// contributorOf returns the user who added the item.
+// Items added before contributors were recorded belong to the collection owner.
+func contributorOf(collection *model.Collection, item *model.CollectionArtProject) uuid.UUID {
+	if item.ContributorID != nil {
+		return *item.ContributorID
+	}
+	return collection.UserID
+}
+
+// resolveSmartCollection runs the collection rule and returns the matching revisions
+// in the same shape as the items of a manual collection.
+func (s *collectionService) resolveSmartCollection(ctx context.Context, collection *model.Collection) ([]model.CollectionArtProject, error) {
+	logger := logctx.With(ctx, "method", "resolveSmartCollection", "collectionID", collection.ID)
+
+	filter, err := ruleFilter(collection.UserID.String(), collection.Rule)
+	if err != nil {
+		logger.Error("Stored collection rule is invalid", "error", err)
+		return nil, err
+	}
+
+	revisions, err := s.artRepo.FindRevisions(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to find revisions for the rule", "error", err)
+		return nil, err
+	}
+
+	items := make([]model.CollectionArtProject, 0, len(revisions))
+	for i, rev := range revisions {
+		items = append(items, model.CollectionArtProject{
+			CollectionID:  collection.ID,
+			RevisionID:    rev.ID,
+			Position:      i,
+			ContributorID: &collection.UserID,
+			CreatedAt:     rev.CreatedAt,
+			Revision:      rev,
+		})
+	}
+
+	logger.Info("Smart collection resolved", "count", len(items))
+	return items, nil
+}
+
+// ruleFilter validates a collection rule and converts it into a revision filter.
+// An empty revision selector defaults to the published revision.
+func ruleFilter(userID string, rule *model.CollectionRule) (repo.RevisionFilter, error) {
+	filter := repo.RevisionFilter{UserID: userID}
+
+	if rule == nil {
+		return filter, fmt.Errorf("%w: smart collection has no rule", model.ErrInvalidInput)
+	}
+
+	switch rule.Revision {
+	case "", model.RuleRevisionPublished:
+		rule.Revision = model.RuleRevisionPublished
+		filter.Published = true
+	case model.RuleRevisionLatest:
+	default:
+		return filter, fmt.Errorf("%w: unsupported rule revision %q", model.ErrInvalidInput, rule.Revision)
+	}
+
+	if rule.Limit < 0 {
+		return filter, fmt.Errorf("%w: rule limit must not be negative", model.ErrInvalidInput)
+	}
+	filter.Limit = rule.Limit
+	filter.Tags = rule.Tags
+
+	var err error
+	if filter.CreatedAfter, err = parseRuleDate(rule.CreatedAfter); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBefore, err = parseRuleDate(rule.CreatedBefore); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func parseRuleDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: invalid rule date %q", model.ErrInvalidInput, value)
 }
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// collectionRoleRank orders the collection roles by the permissions they grant.
+var collectionRoleRank = map[string]int{
+	model.CollectionRoleViewer: 1,
+	model.CollectionRoleEditor: 2,
+	model.CollectionRoleOwner:  3,
+}
+
+// authorize returns the collection and the role of the user in it.
+// Users who are neither the owner nor an accepted member get ErrCollectionNotFound,
+// members whose role is below minRole get ErrUnauthorized.
+func (s *collectionService) authorize(ctx context.Context, userID, collectionID, minRole string) (*model.Collection, string, error) {
+	collection, err := s.FindByID(ctx, collectionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	role := model.CollectionRoleOwner
+	if collection.UserID.String() != userID {
+		member, err := s.collectionRepo.FindMember(ctx, collectionID, userID)
+		if err != nil {
+			if errors.Is(err, model.ErrMemberNotFound) {
+				return nil, "", model.ErrCollectionNotFound
+			}
+			return nil, "", err
+		}
+		if member.AcceptedAt == nil {
+			return nil, "", model.ErrCollectionNotFound
+		}
+		role = member.Role
+	}
+
+	if collectionRoleRank[role] < collectionRoleRank[minRole] {
+		return nil, "", model.ErrUnauthorized
+	}
+
+	return collection, role, nil
+}
+
+// InviteMember invites a user by username to join the collection with a role.
+// Only the owner may invite, and the owner role can not be granted.
+func (s *collectionService) InviteMember(ctx context.Context, userID, collectionID string, req model.CollectionMemberRequest) (*model.CollectionMember, error) {
+	logger := logctx.With(ctx, "method", "InviteMember", "collectionID", collectionID, "userID", userID, "username", req.Username)
+
+	if req.Username == "" || !isMemberRole(req.Role) {
+		logger.Warn("Invalid invitation", "role", req.Role)
+		return nil, model.ErrInvalidInput
+	}
+
+	collection, _, err := s.authorize(ctx, userID, collectionID, model.CollectionRoleOwner)
+	if err != nil {
+		logger.Error("Failed to authorize invitation", "error", err)
+		return nil, err
+	}
+
+	invitee, err := s.userRepo.FindUserByUsername(ctx, req.Username)
+	if err != nil {
+		logger.Warn("Failed to find invited user", "error", err)
+		return nil, err
+	}
+
+	if invitee.ID == collection.UserID {
+		logger.Warn("Owner can not be invited to their own collection")
+		return nil, model.ErrAlreadyMember
+	}
+
+	member := &model.CollectionMember{
+		CollectionID: collection.ID,
+		UserID:       invitee.ID,
+		Role:         req.Role,
+		InvitedBy:    collection.UserID,
+		CreatedAt:    time.Now(),
+		User:         *invitee,
+	}
+
+	if err := s.collectionRepo.AddMember(ctx, member); err != nil {
+		logger.Error("Failed to add collection member", "error", err)
+		return nil, err
+	}
+
+	logger.Info("User invited to collection", "inviteeID", invitee.ID, "role", req.Role)
+	return member, nil
+}
+
+// ListMembers lists the members and pending invitations of a collection the user can view.
+func (s *collectionService) ListMembers(ctx context.Context, userID, collectionID string) ([]model.CollectionMember, error) {
+	logger := logctx.With(ctx, "method", "ListMembers", "collectionID", collectionID, "userID", userID)
+
+	if _, _, err := s.authorize(ctx, userID, collectionID, model.CollectionRoleViewer); err != nil {
+		logger.Error("Failed to authorize listing members", "error", err)
+		return nil, err
+	}
+
+	members, err := s.collectionRepo.ListMembers(ctx, collectionID)
+	if err != nil {
+		logger.Error("Failed to list collection members", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Collection members listed successfully", "count", len(members))
+	return members, nil
+}
+
+// UpdateMemberRole changes the role of a member, only the owner may do so.
+func (s *collectionService) UpdateMemberRole(ctx context.Context, userID, collectionID, memberID, role string) (*model.CollectionMember, error) {
+	logger := logctx.With(ctx, "method", "UpdateMemberRole", "collectionID", collectionID, "userID", userID, "memberID", memberID)
+
+	if !isMemberRole(role) {
+		logger.Warn("Invalid member role", "role", role)
+		return nil, model.ErrInvalidInput
+	}
+
+	if _, _, err := s.authorize(ctx, userID, collectionID, model.CollectionRoleOwner); err != nil {
+		logger.Error("Failed to authorize role change", "error", err)
+		return nil, err
+	}
+
+	member, err := s.collectionRepo.FindMember(ctx, collectionID, memberID)
+	if err != nil {
+		logger.Error("Failed to find collection member", "error", err)
+		return nil, err
+	}
+
+	member.Role = role
+	if err := s.collectionRepo.UpdateMember(ctx, member); err != nil {
+		logger.Error("Failed to update collection member", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Collection member role updated", "role", role)
+	return member, nil
+}
+
+// RemoveMember removes a member or revokes an invitation.
+// The owner may remove anyone, members may remove themselves to leave or decline.
+func (s *collectionService) RemoveMember(ctx context.Context, userID, collectionID, memberID string) error {
+	logger := logctx.With(ctx, "method", "RemoveMember", "collectionID", collectionID, "userID", userID, "memberID", memberID)
+
+	if userID != memberID {
+		if _, _, err := s.authorize(ctx, userID, collectionID, model.CollectionRoleOwner); err != nil {
+			logger.Error("Failed to authorize member removal", "error", err)
+			return err
+		}
+	}
+
+	if err := s.collectionRepo.RemoveMember(ctx, collectionID, memberID); err != nil {
+		logger.Error("Failed to remove collection member", "error", err)
+		return err
+	}
+
+	logger.Info("Collection member removed successfully")
+	return nil
+}
+
+// ListInvitations lists the pending collection invitations of a user.
+func (s *collectionService) ListInvitations(ctx context.Context, userID string) ([]model.CollectionMember, error) {
+	logger := logctx.With(ctx, "method", "ListInvitations", "userID", userID)
+
+	invitations, err := s.collectionRepo.ListInvitations(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to list invitations", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Invitations listed successfully", "count", len(invitations))
+	return invitations, nil
+}
+
+// AcceptInvitation turns a pending invitation of the user into a membership.
+func (s *collectionService) AcceptInvitation(ctx context.Context, userID, collectionID string) (*model.CollectionMember, error) {
+	logger := logctx.With(ctx, "method", "AcceptInvitation", "collectionID", collectionID, "userID", userID)
+
+	if _, err := uuid.Parse(userID); err != nil {
+		logger.Warn("Invalid userID format", "error", err)
+		return nil, model.ErrInvalidInput
+	}
+
+	member, err := s.collectionRepo.FindMember(ctx, collectionID, userID)
+	if err != nil {
+		logger.Error("Failed to find invitation", "error", err)
+		return nil, err
+	}
+
+	if member.AcceptedAt != nil {
+		logger.Info("Invitation already accepted")
+		return member, nil
+	}
+
+	now := time.Now()
+	member.AcceptedAt = &now
+	if err := s.collectionRepo.UpdateMember(ctx, member); err != nil {
+		logger.Error("Failed to accept invitation", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Invitation accepted successfully")
+	return member, nil
+}
+
+func isMemberRole(role string) bool {
+	return role == model.CollectionRoleEditor || role == model.CollectionRoleViewer
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func TestRuleFilter(t *testing.T) {
+	userID := "8a0e3b4c-6f2d-4c1a-9e57-3b1f0d2c4a6e"
+
+	t.Run("Defaults to published", func(t *testing.T) {
+		rule := &model.CollectionRule{Tags: []string{"character-design"}}
+		filter, err := ruleFilter(userID, rule)
+		require.NoError(t, err)
+
+		assert.Equal(t, model.RuleRevisionPublished, rule.Revision)
+		assert.True(t, filter.Published)
+		assert.Equal(t, userID, filter.UserID)
+		assert.Equal(t, []string{"character-design"}, filter.Tags)
+		assert.Nil(t, filter.CreatedAfter)
+	})
+
+	t.Run("Latest revision with dates", func(t *testing.T) {
+		rule := &model.CollectionRule{
+			Revision:      model.RuleRevisionLatest,
+			CreatedAfter:  "2026-01-01",
+			CreatedBefore: "2026-06-30T12:00:00Z",
+			Limit:         10,
+		}
+		filter, err := ruleFilter(userID, rule)
+		require.NoError(t, err)
+
+		assert.False(t, filter.Published)
+		assert.Equal(t, 10, filter.Limit)
+		assert.False(t, filter.OrderByRevision, "the limit keeps the newest art projects")
+		require.NotNil(t, filter.CreatedAfter)
+		assert.True(t, filter.CreatedAfter.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+		require.NotNil(t, filter.CreatedBefore)
+		assert.True(t, filter.CreatedBefore.Equal(time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)))
+	})
+
+	invalid := []struct {
+		name string
+		rule *model.CollectionRule
+	}{
+		{name: "Missing rule"},
+		{name: "Unknown revision", rule: &model.CollectionRule{Revision: "first"}},
+		{name: "Invalid date", rule: &model.CollectionRule{CreatedAfter: "01/01/2026"}},
+		{name: "Negative limit", rule: &model.CollectionRule{Limit: -1}},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ruleFilter(userID, tt.rule)
+			assert.ErrorIs(t, err, model.ErrInvalidInput)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// ExportService writes the data of a user to a zip archive.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=ExportService --filename=export_service.go --output=../../mocks/
+type ExportService interface {
+	ExportUser(ctx context.Context, userID string, w io.Writer) error
+}
+
+type exportService struct {
+	userRepo        repo.UserRepository
+	artRepo         repo.ArtProjectRepository
+	collectionRepo  repo.CollectionRepository
+	webPageRepo     repo.WebPageRepository
+	fileStorageRepo repo.FileStorageRepository
+}
+
+// NewExportService creates a new instance of ExportService.
+func NewExportService(ur repo.UserRepository, ar repo.ArtProjectRepository, cr repo.CollectionRepository, wpr repo.WebPageRepository, fsr repo.FileStorageRepository) ExportService {
+	return &exportService{
+		userRepo:        ur,
+		artRepo:         ar,
+		collectionRepo:  cr,
+		webPageRepo:     wpr,
+		fileStorageRepo: fsr,
+	}
+}
+
+// ExportUser writes an archive with user.json, describing the user and everything they
+// own, and the file of every revision below files/<art project>/v<version>.
+func (s *exportService) ExportUser(ctx context.Context, userID string, w io.Writer) error {
+	logger := logctx.With(ctx, "method", "ExportUser", "userID", userID)
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return err
+	}
+
+	export := model.UserExport{
+		ExportedAt: time.Now().UTC(),
+		User: model.UserResponse{
+			ID:               user.ID,
+			Username:         user.Username,
+			Role:             user.Role,
+			Disabled:         user.Disabled,
+			CreatedAt:        user.CreatedAt,
+			UpdatedAt:        user.UpdatedAt,
+			TwoFactorEnabled: user.TOTPEnabled,
+		},
+		ArtProjects: []model.ArtProjectExport{},
+		Collections: []model.Collection{},
+		WebPages:    []model.WebPage{},
+	}
+
+	if export.Stash, err = s.userRepo.GetStashByUserID(ctx, userID); err != nil && !errors.Is(err, model.ErrUserNotFound) {
+		logger.Error("Failed to find stash", "error", err)
+		return err
+	}
+
+	artProjects, err := s.artRepo.FindByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, model.ErrArtProjectNotFound) {
+		logger.Error("Failed to find art projects", "error", err)
+		return err
+	}
+
+	for _, artProject := range artProjects {
+		revisions, err := s.artRepo.ListAllRevisions(ctx, artProject.ID.String())
+		if err != nil && !errors.Is(err, model.ErrArtProjectNotFound) {
+			logger.Error("Failed to list revisions", "error", err, "artProjectID", artProject.ID)
+			return err
+		}
+
+		projectExport := model.ArtProjectExport{ArtProject: artProject, Revisions: []model.RevisionExport{}}
+		for _, revision := range revisions {
+			projectExport.Revisions = append(projectExport.Revisions, model.RevisionExport{
+				Revision: revision,
+				File:     path.Join("files", artProject.ID.String(), "v"+strconv.Itoa(revision.Version)),
+			})
+		}
+		export.ArtProjects = append(export.ArtProjects, projectExport)
+	}
+
+	collections, err := s.collectionRepo.FindByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, model.ErrCollectionNotFound) {
+		logger.Error("Failed to find collections", "error", err)
+		return err
+	}
+	for _, collection := range collections {
+		// Collections shared with the user belong to their owner's export.
+		if collection.UserID == user.ID {
+			export.Collections = append(export.Collections, collection)
+		}
+	}
+
+	webPages, err := s.webPageRepo.FindWebPagesByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, model.ErrWebPageNotFound) {
+		logger.Error("Failed to find web pages", "error", err)
+		return err
+	}
+	export.WebPages = append(export.WebPages, webPages...)
+
+	archive := zip.NewWriter(w)
+
+	metadata, err := archive.Create("user.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(metadata)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		logger.Error("Failed to write user.json", "error", err)
+		return err
+	}
+
+	for _, artProject := range export.ArtProjects {
+		for _, revision := range artProject.Revisions {
+			if err := s.copyRevisionFile(ctx, archive, revision); err != nil {
+				logger.Error("Failed to export revision file", "error", err, "revisionID", revision.ID)
+				return err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		logger.Error("Failed to finish archive", "error", err)
+		return err
+	}
+
+	logger.Info("User exported", "artProjects", len(export.ArtProjects), "collections", len(export.Collections), "webPages", len(export.WebPages))
+	return nil
+}
+
+// copyRevisionFile adds the file of a revision to the archive.
+func (s *exportService) copyRevisionFile(ctx context.Context, archive *zip.Writer, revision model.RevisionExport) error {
+	file, err := s.fileStorageRepo.GetRevisionFile(ctx, revision.UserID.String(), revision.ArtProjectID.String(), revision.Version)
+	if err != nil {
+		return fmt.Errorf("failed to open revision %s: %w", revision.ID, err)
+	}
+	defer file.Close()
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     revision.File,
+		Method:   zip.Deflate,
+		Modified: revision.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, file)
+	return err
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/feeds"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// feedLimit is the maximum number of entries in a user gallery feed.
+const feedLimit = 50
+
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=FeedService --filename=feed_service.go --output=../../mocks/
+type FeedService interface {
+	CollectionFeed(ctx context.Context, collectionPublicID string) (*feeds.Feed, error)
+	GalleryFeed(ctx context.Context, username string) (*feeds.Feed, error)
+}
+
+type feedService struct {
+	collectionService CollectionService
+	artRepo           repo.ArtProjectRepository
+	userRepo          repo.UserRepository
+	thumbnailService  ThumbnailService
+	publicURL         string
+}
+
+// NewFeedService creates a feed service, publicURL is used to build absolute links.
+func NewFeedService(cs CollectionService, ar repo.ArtProjectRepository, ur repo.UserRepository, ts ThumbnailService, publicURL string) FeedService {
+	return &feedService{
+		collectionService: cs,
+		artRepo:           ar,
+		userRepo:          ur,
+		thumbnailService:  ts,
+		publicURL:         publicURL,
+	}
+}
+
+// CollectionFeed builds a feed of the published revisions in a public collection.
+func (s *feedService) CollectionFeed(ctx context.Context, collectionPublicID string) (*feeds.Feed, error) {
+	logger := logctx.With(ctx, "method", "CollectionFeed", "collectionPublicID", collectionPublicID)
+
+	collection, err := s.collectionService.FindByPublicID(ctx, collectionPublicID)
+	if err != nil {
+		logger.Error("Failed to find public collection", "error", err)
+		return nil, err
+	}
+
+	items, err := s.collectionService.GetRevisionsByPublicCollectionID(ctx, collectionPublicID)
+	if err != nil {
+		logger.Error("Failed to list collection revisions", "error", err)
+		return nil, err
+	}
+
+	feed := &feeds.Feed{
+		Id:          s.url("/collection/" + collection.CollectionID),
+		Title:       collection.Title,
+		Link:        &feeds.Link{Href: s.url("/collection/" + collection.CollectionID)},
+		Description: collection.Description,
+		Author:      &feeds.Author{Name: collection.User.Username},
+		Created:     collection.CreatedAt,
+		Updated:     collection.UpdatedAt,
+	}
+
+	for _, item := range items {
+		if !isPublished(&item.Revision) {
+			continue
+		}
+		feed.Add(s.feedItem(ctx, &item.Revision, item.Caption))
+	}
+
+	logger.Info("Collection feed built", "entries", len(feed.Items))
+	return withLatestUpdate(feed), nil
+}
+
+// GalleryFeed builds a feed of the published revisions of a user, newest first.
+func (s *feedService) GalleryFeed(ctx context.Context, username string) (*feeds.Feed, error) {
+	logger := logctx.With(ctx, "method", "GalleryFeed", "username", username)
+
+	user, err := s.userRepo.FindUserByUsername(ctx, username)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return nil, err
+	}
+
+	revisions, err := s.artRepo.FindRevisions(ctx, repo.RevisionFilter{
+		UserID:          user.ID.String(),
+		Published:       true,
+		Limit:           feedLimit,
+		OrderByRevision: true,
+	})
+	if err != nil {
+		logger.Error("Failed to find published revisions", "error", err)
+		return nil, err
+	}
+
+	feed := &feeds.Feed{
+		Id:      s.url("/gallery/" + user.Username),
+		Title:   fmt.Sprintf("%s's gallery", user.Username),
+		Link:    &feeds.Link{Href: s.url("/gallery/" + user.Username)},
+		Author:  &feeds.Author{Name: user.Username},
+		Created: user.CreatedAt,
+		Updated: user.CreatedAt,
+	}
+
+	for i := range revisions {
+		feed.Add(s.feedItem(ctx, &revisions[i], ""))
+	}
+
+	logger.Info("Gallery feed built", "entries", len(feed.Items))
+	return withLatestUpdate(feed), nil
+}
+
+// feedItem turns a revision into a feed entry with its thumbnail, served by
+// /art/{artID}/thumbnail, as image enclosure. Revisions without a thumbnail get no
+// enclosure. The caption of a collection item takes precedence over the revision comment.
+func (s *feedService) feedItem(ctx context.Context, rev *model.Revision, caption string) *feeds.Item {
+	artURL := s.url("/art/" + rev.ArtID)
+	thumbnailURL := artURL + "/thumbnail"
+
+	title := rev.ArtProject.Title
+	if title == "" {
+		title = fmt.Sprintf("Revision %d", rev.Version)
+	}
+
+	description := rev.Comment
+	if caption != "" {
+		description = caption
+	}
+
+	item := &feeds.Item{
+		Id:          artURL,
+		Title:       title,
+		Link:        &feeds.Link{Href: artURL},
+		Description: description,
+		Created:     rev.CreatedAt,
+		Updated:     rev.CreatedAt,
+	}
+
+	thumbnail, err := s.thumbnailService.Thumbnail(ctx, rev)
+	switch {
+	case err == nil:
+		item.Enclosure = &feeds.Enclosure{
+			Url:    thumbnailURL,
+			Type:   thumbnail.ContentType,
+			Length: strconv.FormatInt(thumbnail.Size, 10),
+		}
+		item.Content = fmt.Sprintf(`<p><a href="%s"><img src="%s" alt="%s"></a></p>`,
+			html.EscapeString(artURL), html.EscapeString(thumbnailURL), html.EscapeString(title))
+	case !errors.Is(err, model.ErrNoThumbnail):
+		logctx.From(ctx).Error("Failed to get thumbnail, leaving out the enclosure",
+			"method", "feedItem", "revisionID", rev.ID, "error", err)
+	}
+
+	if description != "" {
+		item.Content += "<p>" + html.EscapeString(description) + "</p>"
+	}
+
+	return item
+}
+
+func (s *feedService) url(path string) string {
+	return s.publicURL + path
+}
+
+// isPublished reports whether the revision is the published revision of its art project.
+func isPublished(rev *model.Revision) bool {
+	published := rev.ArtProject.PublishedRevisionID
+	return published != nil && *published == rev.ID
+}
+
+// withLatestUpdate moves the feed update time to its newest entry.
+func withLatestUpdate(feed *feeds.Feed) *feeds.Feed {
+	var latest time.Time
+	for _, item := range feed.Items {
+		if item.Updated.After(latest) {
+			latest = item.Updated
+		}
+	}
+
+	if latest.After(feed.Updated) {
+		feed.Updated = latest
+	}
+
+	return feed
+}
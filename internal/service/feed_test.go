@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+func TestFeedService_CollectionFeed(t *testing.T) {
+	collections := mocks.NewCollectionService(t)
+	thumbnails := mocks.NewThumbnailService(t)
+	feedService := service.NewFeedService(collections, nil, nil, thumbnails, "https://mirai.example")
+
+	publishedID := uuid.New()
+	documentID := uuid.New()
+	draftID := uuid.New()
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	collection := &model.Collection{
+		CollectionID: "public-id",
+		Title:        "Characters",
+		CreatedAt:    updated.Add(-48 * time.Hour),
+		UpdatedAt:    updated.Add(-48 * time.Hour),
+		User:         model.User{Username: "artist"},
+	}
+	items := []model.CollectionArtProject{
+		{
+			RevisionID: publishedID,
+			Caption:    "Final lineart",
+			Revision: model.Revision{
+				ID:        publishedID,
+				ArtID:     "art-1",
+				Comment:   "v3",
+				Size:      2048,
+				CreatedAt: updated,
+				ArtProject: model.ArtProject{
+					Title:               "Knight",
+					ContentType:         "image/png",
+					PublishedRevisionID: &publishedID,
+				},
+			},
+		},
+		{
+			RevisionID: documentID,
+			Revision: model.Revision{
+				ID:        documentID,
+				ArtID:     "art-3",
+				Comment:   "Notes",
+				CreatedAt: updated.Add(-time.Hour),
+				ArtProject: model.ArtProject{
+					Title:               "Notes",
+					ContentType:         "text/plain",
+					PublishedRevisionID: &documentID,
+				},
+			},
+		},
+		{
+			RevisionID: draftID,
+			Revision: model.Revision{
+				ID:         draftID,
+				ArtID:      "art-2",
+				CreatedAt:  updated.Add(time.Hour),
+				ArtProject: model.ArtProject{Title: "Draft"},
+			},
+		},
+	}
+
+	collections.On("FindByPublicID", mock.Anything, "public-id").Return(collection, nil).Once()
+	collections.On("GetRevisionsByPublicCollectionID", mock.Anything, "public-id").Return(items, nil).Once()
+	thumbnails.On("Thumbnail", mock.Anything, &items[0].Revision).
+		Return(&model.Thumbnail{FilePath: "/data/v1.thumb.jpg", ContentType: "image/jpeg", Size: 512}, nil).Once()
+	thumbnails.On("Thumbnail", mock.Anything, &items[1].Revision).Return(nil, model.ErrNoThumbnail).Once()
+
+	feed, err := feedService.CollectionFeed(context.Background(), "public-id")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Characters", feed.Title)
+	assert.Equal(t, "https://mirai.example/collection/public-id", feed.Link.Href)
+	assert.True(t, feed.Updated.Equal(updated), "feed is updated with its newest entry")
+
+	require.Len(t, feed.Items, 2, "unpublished revisions are left out")
+	item := feed.Items[0]
+	assert.Equal(t, "Knight", item.Title)
+	assert.Equal(t, "Final lineart", item.Description)
+	assert.Equal(t, "https://mirai.example/art/art-1", item.Link.Href)
+	require.NotNil(t, item.Enclosure)
+	assert.Equal(t, "https://mirai.example/art/art-1/thumbnail", item.Enclosure.Url)
+	assert.Equal(t, "image/jpeg", item.Enclosure.Type)
+	assert.Equal(t, "512", item.Enclosure.Length)
+
+	assert.Equal(t, "Notes", feed.Items[1].Title)
+	assert.Nil(t, feed.Items[1].Enclosure, "revisions without a thumbnail have no enclosure")
+}
+
+func TestFeedService_CollectionFeedNotFound(t *testing.T) {
+	collections := mocks.NewCollectionService(t)
+	feedService := service.NewFeedService(collections, nil, nil, mocks.NewThumbnailService(t), "https://mirai.example")
+
+	collections.On("FindByPublicID", mock.Anything, "private-id").Return(nil, model.ErrCollectionNotFound).Once()
+
+	_, err := feedService.CollectionFeed(context.Background(), "private-id")
+	assert.ErrorIs(t, err, model.ErrCollectionNotFound)
+}
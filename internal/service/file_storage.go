@@ -3,9 +3,9 @@ package service
 import (
 	"context"
 	"io"
-	"log/slog"
 	"os"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/repo"
 )
@@ -28,7 +28,7 @@ func NewFileStorageService(fileStorageRepo repo.FileStorageRepository) FileStora
 }
 
 func (s *fileStorageService) SaveRevisionFile(ctx context.Context, fileData io.Reader, userID, artProjectID string, version int) (string, os.FileInfo, error) {
-	logger := slog.With("method", "SaveRevisionFile", "userID", userID, "artProjectID", artProjectID, "version", version)
+	logger := logctx.With(ctx, "method", "SaveRevisionFile", "userID", userID, "artProjectID", artProjectID, "version", version)
 
 	filePath, fileInfo, err := s.fileStorageRepo.SaveRevisionFile(ctx, fileData, userID, artProjectID, version)
 	if err != nil {
@@ -41,7 +41,7 @@ func (s *fileStorageService) SaveRevisionFile(ctx context.Context, fileData io.R
 }
 
 func (s *fileStorageService) GetRevisionFile(ctx context.Context, userID, artProjectID string, version int) (io.ReadCloser, error) {
-	logger := slog.With("method", "GetRevisionFile", "userID", userID, "artProjectID", artProjectID, "version", version)
+	logger := logctx.With(ctx, "method", "GetRevisionFile", "userID", userID, "artProjectID", artProjectID, "version", version)
 
 	file, err := s.fileStorageRepo.GetRevisionFile(ctx, userID, artProjectID, version)
 	if err != nil {
@@ -54,7 +54,7 @@ func (s *fileStorageService) GetRevisionFile(ctx context.Context, userID, artPro
 }
 
 func (s *fileStorageService) FindStashByUserID(ctx context.Context, userID string) (*model.Stash, error) {
-	logger := slog.With("method", "FindStashByUserID", "userID", userID)
+	logger := logctx.With(ctx, "method", "FindStashByUserID", "userID", userID)
 
 	stash, err := s.fileStorageRepo.FindStashByUserID(ctx, userID)
 	if err != nil {
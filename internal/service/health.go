@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// healthCheckTimeout bounds each readiness check, so a hanging dependency fails the
+// probe instead of timing it out.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck is a named check of a dependency the service needs to serve requests.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthService checks whether the service is ready to serve requests.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=HealthService --filename=health_service.go --output=../../mocks/
+type HealthService interface {
+	Readiness(ctx context.Context) *model.Readiness
+}
+
+type healthService struct {
+	checks []HealthCheck
+}
+
+// NewHealthService creates a new instance of HealthService running the given checks.
+func NewHealthService(checks ...HealthCheck) HealthService {
+	return &healthService{checks: checks}
+}
+
+// Readiness runs the checks concurrently. Failures are logged and reported without
+// their error, as the probe is public.
+func (s *healthService) Readiness(ctx context.Context) *model.Readiness {
+	logger := logctx.With(ctx, "method", "Readiness")
+
+	readiness := &model.Readiness{
+		Status: model.HealthStatusOK,
+		Checks: make(map[string]string, len(s.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range s.checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			status := model.HealthStatusOK
+			if err := check.Check(ctx); err != nil {
+				logger.Warn("Readiness check failed", "check", check.Name, "error", err)
+				status = model.HealthStatusFailing
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			readiness.Checks[check.Name] = status
+			if status != model.HealthStatusOK {
+				readiness.Status = model.HealthStatusFailing
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return readiness
+}
@@ -0,0 +1,45 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+func TestHealthService_Readiness(t *testing.T) {
+	ok := service.HealthCheck{Name: "database", Check: func(ctx context.Context) error { return nil }}
+	failing := service.HealthCheck{Name: "storage", Check: func(ctx context.Context) error { return errors.New("read-only file system") }}
+	hanging := service.HealthCheck{Name: "migrations", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	t.Run("Ready", func(t *testing.T) {
+		readiness := service.NewHealthService(ok).Readiness(context.Background())
+
+		assert.Equal(t, &model.Readiness{Status: model.HealthStatusOK, Checks: map[string]string{"database": model.HealthStatusOK}}, readiness)
+	})
+
+	t.Run("Failing check", func(t *testing.T) {
+		readiness := service.NewHealthService(ok, failing).Readiness(context.Background())
+
+		assert.Equal(t, model.HealthStatusFailing, readiness.Status)
+		assert.Equal(t, map[string]string{"database": model.HealthStatusOK, "storage": model.HealthStatusFailing}, readiness.Checks)
+	})
+
+	t.Run("Hanging check times out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		readiness := service.NewHealthService(ok, hanging).Readiness(ctx)
+
+		assert.Equal(t, model.HealthStatusFailing, readiness.Status)
+		assert.Equal(t, model.HealthStatusFailing, readiness.Checks["migrations"])
+	})
+}
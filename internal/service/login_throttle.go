@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// maxBackoffShift caps the exponent of the backoff so the delay can not overflow.
+const maxBackoffShift = 30
+
+// LoginPolicy configures how the failed logins of one throttle key are slowed down.
+type LoginPolicy struct {
+	// FreeAttempts is the number of failures allowed before the backoff starts.
+	FreeAttempts int
+	// BaseDelay is the wait after the first failure past FreeAttempts,
+	// it doubles with every further failure up to Lockout.
+	BaseDelay time.Duration
+	// MaxFailures is the number of failures that locks the key for Lockout.
+	MaxFailures int
+	Lockout     time.Duration
+	// Window is how long a failure is remembered.
+	Window time.Duration
+}
+
+var (
+	// DefaultUserLoginPolicy throttles failed logins per username.
+	DefaultUserLoginPolicy = LoginPolicy{
+		FreeAttempts: 3,
+		BaseDelay:    time.Second,
+		MaxFailures:  10,
+		Lockout:      15 * time.Minute,
+		Window:       time.Hour,
+	}
+
+	// DefaultIPLoginPolicy throttles failed logins per client IP. It is looser than the
+	// username policy since users behind a shared address count against the same key.
+	DefaultIPLoginPolicy = LoginPolicy{
+		FreeAttempts: 20,
+		BaseDelay:    time.Second,
+		MaxFailures:  100,
+		Lockout:      15 * time.Minute,
+		Window:       time.Hour,
+	}
+)
+
+// delay returns how long the key is blocked after the given number of failures.
+func (p LoginPolicy) delay(failures int) time.Duration {
+	if failures >= p.MaxFailures {
+		return p.Lockout
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	shift := min(failures-p.FreeAttempts-1, maxBackoffShift)
+	return min(p.BaseDelay<<shift, p.Lockout)
+}
+
+// LoginThrottleService tracks failed logins per username and per client IP and
+// blocks further attempts with an exponential backoff and a temporary lockout.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=LoginThrottleService --filename=login_throttle_service.go --output=../../mocks/
+type LoginThrottleService interface {
+	Check(ctx context.Context, username, ip string) (time.Duration, error)
+	RecordFailure(ctx context.Context, username, ip string) error
+	RecordSuccess(ctx context.Context, username string) error
+	Unlock(ctx context.Context, username string) error
+}
+
+type loginThrottleService struct {
+	attemptRepo repo.LoginAttemptRepository
+	userPolicy  LoginPolicy
+	ipPolicy    LoginPolicy
+}
+
+// NewLoginThrottleService creates a login throttle with separate policies for usernames and client IPs.
+func NewLoginThrottleService(lr repo.LoginAttemptRepository, userPolicy, ipPolicy LoginPolicy) LoginThrottleService {
+	return &loginThrottleService{
+		attemptRepo: lr,
+		userPolicy:  userPolicy,
+		ipPolicy:    ipPolicy,
+	}
+}
+
+// Check returns model.ErrTooManyAttempts with the time left to wait when
+// the username or the client IP is blocked.
+func (s *loginThrottleService) Check(ctx context.Context, username, ip string) (time.Duration, error) {
+	logger := logctx.With(ctx, "method", "Check", "username", username, "ip", ip)
+
+	attempts, err := s.attemptRepo.FindAttempts(ctx, model.LoginUserKey(username), model.LoginIPKey(ip))
+	if err != nil {
+		logger.Error("Failed to find login attempts", "error", err)
+		return 0, err
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, attempt := range attempts {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			wait = max(wait, attempt.LockedUntil.Sub(now))
+		}
+	}
+
+	if wait > 0 {
+		logger.Warn("Login attempt blocked", "retryAfter", wait)
+		return wait, model.ErrTooManyAttempts
+	}
+	return 0, nil
+}
+
+// RecordFailure counts a failed login for both the username and the client IP,
+// whether or not the username exists, and blocks them as the policies require.
+func (s *loginThrottleService) RecordFailure(ctx context.Context, username, ip string) error {
+	now := time.Now()
+
+	keys := []struct {
+		key    string
+		policy LoginPolicy
+	}{
+		{model.LoginUserKey(username), s.userPolicy},
+		{model.LoginIPKey(ip), s.ipPolicy},
+	}
+
+	for _, k := range keys {
+		logger := logctx.With(ctx, "method", "RecordFailure", "key", k.key)
+
+		attempt, err := s.attemptRepo.RecordFailure(ctx, k.key, now, k.policy.Window)
+		if err != nil {
+			logger.Error("Failed to record login failure", "error", err)
+			return err
+		}
+
+		delay := k.policy.delay(attempt.Failures)
+		if delay == 0 {
+			continue
+		}
+
+		if err := s.attemptRepo.LockKey(ctx, k.key, now.Add(delay)); err != nil {
+			logger.Error("Failed to lock login key", "error", err)
+			return err
+		}
+
+		if attempt.Failures >= k.policy.MaxFailures {
+			logger.Warn("Login locked out", "failures", attempt.Failures, "lockout", delay)
+		}
+	}
+
+	return nil
+}
+
+// RecordSuccess clears the failures of the username. The client IP keeps its
+// count, otherwise an attacker could reset it by logging into their own account.
+func (s *loginThrottleService) RecordSuccess(ctx context.Context, username string) error {
+	logger := logctx.With(ctx, "method", "RecordSuccess", "username", username)
+
+	if err := s.attemptRepo.ResetAttempts(ctx, model.LoginUserKey(username)); err != nil {
+		logger.Error("Failed to reset login attempts", "error", err)
+		return err
+	}
+
+	window := max(s.userPolicy.Window, s.ipPolicy.Window)
+	if _, err := s.attemptRepo.DeleteStale(ctx, time.Now().Add(-window)); err != nil {
+		logger.Warn("Failed to delete stale login attempts", "error", err)
+	}
+
+	return nil
+}
+
+// Unlock lifts the lockout of a username.
+func (s *loginThrottleService) Unlock(ctx context.Context, username string) error {
+	logger := logctx.With(ctx, "method", "Unlock", "username", username)
+
+	if err := s.attemptRepo.ResetAttempts(ctx, model.LoginUserKey(username)); err != nil {
+		logger.Error("Failed to unlock login", "error", err)
+		return err
+	}
+
+	logger.Info("Login unlocked")
+	return nil
+}
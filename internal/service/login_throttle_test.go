@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+// memoryLoginAttemptRepo keeps login attempts in a map for tests.
+type memoryLoginAttemptRepo struct {
+	attempts map[string]*model.LoginAttempt
+}
+
+func newMemoryLoginAttemptRepo() *memoryLoginAttemptRepo {
+	return &memoryLoginAttemptRepo{attempts: map[string]*model.LoginAttempt{}}
+}
+
+func (r *memoryLoginAttemptRepo) FindAttempts(ctx context.Context, keys ...string) ([]model.LoginAttempt, error) {
+	var found []model.LoginAttempt
+	for _, key := range keys {
+		if attempt, ok := r.attempts[key]; ok {
+			found = append(found, *attempt)
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryLoginAttemptRepo) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*model.LoginAttempt, error) {
+	attempt, ok := r.attempts[key]
+	if !ok || attempt.LastFailureAt.Before(at.Add(-window)) {
+		attempt = &model.LoginAttempt{Key: key}
+		r.attempts[key] = attempt
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	copied := *attempt
+	return &copied, nil
+}
+
+func (r *memoryLoginAttemptRepo) LockKey(ctx context.Context, key string, until time.Time) error {
+	r.attempts[key].LockedUntil = &until
+	return nil
+}
+
+func (r *memoryLoginAttemptRepo) ResetAttempts(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(r.attempts, key)
+	}
+	return nil
+}
+
+func (r *memoryLoginAttemptRepo) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestLoginPolicyDelay(t *testing.T) {
+	policy := LoginPolicy{
+		FreeAttempts: 3,
+		BaseDelay:    time.Second,
+		MaxFailures:  10,
+		Lockout:      time.Minute,
+	}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 3, want: 0},
+		{failures: 4, want: time.Second},
+		{failures: 5, want: 2 * time.Second},
+		{failures: 7, want: 8 * time.Second},
+		{failures: 9, want: 32 * time.Second},
+		{failures: 10, want: time.Minute},
+		{failures: 1000, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.delay(tt.failures), "failures: %d", tt.failures)
+	}
+
+	long := LoginPolicy{FreeAttempts: 0, BaseDelay: time.Second, MaxFailures: 1000, Lockout: time.Hour}
+	assert.Equal(t, time.Hour, long.delay(999), "backoff is capped at the lockout")
+}
+
+func TestLoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	policy := LoginPolicy{FreeAttempts: 2, BaseDelay: time.Minute, MaxFailures: 5, Lockout: time.Hour, Window: time.Hour}
+	ipPolicy := LoginPolicy{FreeAttempts: 100, BaseDelay: time.Minute, MaxFailures: 200, Lockout: time.Hour, Window: time.Hour}
+
+	repo := newMemoryLoginAttemptRepo()
+	s := NewLoginThrottleService(repo, policy, ipPolicy)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, s.RecordFailure(ctx, "alice", "192.0.2.1"))
+	}
+	wait, err := s.Check(ctx, "alice", "192.0.2.1")
+	require.NoError(t, err, "free attempts do not block")
+	assert.Zero(t, wait)
+
+	require.NoError(t, s.RecordFailure(ctx, "alice", "192.0.2.1"))
+	wait, err = s.Check(ctx, "alice", "192.0.2.9")
+	assert.ErrorIs(t, err, model.ErrTooManyAttempts, "the username is blocked from any address")
+	assert.InDelta(t, time.Minute, wait, float64(time.Second))
+
+	_, err = s.Check(ctx, "bob", "192.0.2.1")
+	assert.NoError(t, err, "the address is below its own limit")
+
+	require.NoError(t, s.RecordSuccess(ctx, "alice"))
+	_, err = s.Check(ctx, "alice", "192.0.2.1")
+	assert.NoError(t, err, "a successful login clears the username")
+	assert.Equal(t, 3, repo.attempts[model.LoginIPKey("192.0.2.1")].Failures, "the address keeps its failures")
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.RecordFailure(ctx, "alice", "192.0.2.1"))
+	}
+	wait, err = s.Check(ctx, "alice", "192.0.2.1")
+	assert.ErrorIs(t, err, model.ErrTooManyAttempts)
+	assert.InDelta(t, time.Hour, wait, float64(time.Second), "locked out after max failures")
+
+	require.NoError(t, s.Unlock(ctx, "alice"))
+	_, err = s.Check(ctx, "alice", "192.0.2.1")
+	assert.NoError(t, err, "unlocked by an administrator")
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// artEmbedScheme is the link scheme used to embed a published art piece in markdown,
+// e.g. ![Sketch](art:3vQB7B6MrGQZaxCuFg4oh).
+const artEmbedScheme = "art:"
+
+var (
+	markdownRenderer = goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.Footnote,
+		),
+		goldmark.WithParserOptions(
+			parser.WithASTTransformers(util.Prioritized(artEmbedTransformer{}, 100)),
+		),
+		// raw HTML is passed through and cleaned up by markdownPolicy afterwards
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	markdownPolicy = newMarkdownPolicy()
+)
+
+// RenderMarkdown converts markdown source into sanitized HTML.
+// Tables, footnotes and art embeds are supported.
+func RenderMarkdown(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+
+	return markdownPolicy.Sanitize(buf.String()), nil
+}
+
+func newMarkdownPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	// footnote references and back links rendered by goldmark
+	p.AllowAttrs("id").Matching(bluemonday.SpaceSeparatedTokens).OnElements("li", "sup")
+	p.AllowAttrs("role").Matching(bluemonday.SpaceSeparatedTokens).OnElements("a", "div")
+	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("a", "div", "img")
+	p.AllowAttrs("align").Matching(bluemonday.CellAlign).OnElements("th", "td")
+
+	return p
+}
+
+// artEmbedTransformer rewrites images pointing to art:<artID> to the public art URL.
+type artEmbedTransformer struct{}
+
+func (artEmbedTransformer) Transform(node *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		img, ok := n.(*ast.Image)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		dest := string(img.Destination)
+		if !strings.HasPrefix(dest, artEmbedScheme) {
+			return ast.WalkContinue, nil
+		}
+
+		artID := strings.TrimPrefix(dest, artEmbedScheme)
+		img.Destination = []byte("/art/" + url.PathEscape(artID))
+		img.SetAttributeString("class", []byte("art-embed"))
+
+		return ast.WalkContinue, nil
+	})
+}
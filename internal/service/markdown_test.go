@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "Heading and emphasis",
+			source:   "# Title\n\nSome *emphasis*",
+			contains: []string{"<h1", "Title</h1>", "<em>emphasis</em>"},
+		},
+		{
+			name:     "Table",
+			source:   "| a | b |\n|---|---|\n| 1 | 2 |",
+			contains: []string{"<table>", "<th>a</th>", "<td>2</td>"},
+		},
+		{
+			name:     "Footnote",
+			source:   "Text[^1]\n\n[^1]: The note",
+			contains: []string{`href="#fn:1"`, `id="fn:1"`, "The note"},
+		},
+		{
+			name:     "Art embed",
+			source:   "![Sketch](art:3vQB7B6MrGQZaxCuFg4oh)",
+			contains: []string{`src="/art/3vQB7B6MrGQZaxCuFg4oh"`, `alt="Sketch"`, `class="art-embed"`},
+		},
+		{
+			name:     "Art embed path is escaped",
+			source:   "![x](art:../../etc)",
+			contains: []string{`src="/art/..%2F..%2Fetc"`},
+		},
+		{
+			name:     "Script is stripped",
+			source:   "hello <script>alert(1)</script>",
+			contains: []string{"hello"},
+			excludes: []string{"<script", "alert(1)"},
+		},
+		{
+			name:     "Javascript links are stripped",
+			source:   "[click](javascript:alert(1))",
+			excludes: []string{"javascript:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := RenderMarkdown(tt.source)
+			require.NoError(t, err)
+
+			for _, s := range tt.contains {
+				assert.Contains(t, html, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, html, s)
+			}
+		})
+	}
+}
+
+func TestRenderWebPage(t *testing.T) {
+	t.Run("Defaults to html", func(t *testing.T) {
+		wp := &model.WebPage{Html: "<h1>Hi</h1>"}
+		require.NoError(t, renderWebPage(wp))
+		assert.Equal(t, model.WebPageFormatHTML, wp.Format)
+		assert.Equal(t, "<h1>Hi</h1>", wp.Html)
+		assert.Equal(t, "<h1>Hi</h1>", wp.Source)
+	})
+
+	t.Run("Markdown keeps source", func(t *testing.T) {
+		wp := &model.WebPage{Format: model.WebPageFormatMarkdown, Source: "**bold**"}
+		require.NoError(t, renderWebPage(wp))
+		assert.Equal(t, "**bold**", wp.Source)
+		assert.Contains(t, wp.Html, "<strong>bold</strong>")
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		wp := &model.WebPage{Format: "rst"}
+		err := renderWebPage(wp)
+		assert.ErrorIs(t, err, model.ErrInvalidInput)
+	})
+}
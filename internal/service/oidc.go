@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// OIDCProvider configures sign in through an OpenID Connect provider.
+type OIDCProvider struct {
+	Name          string
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	UsernameClaim string
+	AllowSignup   bool
+}
+
+// OIDCService signs users in through OpenID Connect providers with the authorization
+// code flow and PKCE, and links provider accounts to users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=OIDCService --filename=oidc_service.go --output=../../mocks/
+type OIDCService interface {
+	Providers() []string
+	AuthCodeURL(ctx context.Context, provider, state, nonce, verifier string) (string, error)
+	Login(ctx context.Context, provider, code, verifier, nonce string) (*model.User, error)
+	Link(ctx context.Context, userID, provider, code, verifier, nonce string) (*model.ExternalIdentity, error)
+	ListIdentities(ctx context.Context, userID string) ([]model.ExternalIdentity, error)
+	UnlinkIdentity(ctx context.Context, userID, identityID string) error
+}
+
+// oidcClient is a provider with its discovered endpoints, discovery happens on first use
+// so that an unreachable provider does not stop the service from starting.
+type oidcClient struct {
+	settings OIDCProvider
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+type oidcService struct {
+	identityRepo repo.IdentityRepository
+	userService  UserService
+
+	mu        sync.Mutex
+	providers map[string]*oidcClient
+}
+
+func NewOIDCService(idr repo.IdentityRepository, us UserService, providers []OIDCProvider) OIDCService {
+	clients := make(map[string]*oidcClient, len(providers))
+	for _, p := range providers {
+		clients[p.Name] = &oidcClient{settings: p}
+	}
+
+	return &oidcService{
+		identityRepo: idr,
+		userService:  us,
+		providers:    clients,
+	}
+}
+
+// Providers returns the names of the configured providers in alphabetical order.
+func (s *oidcService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthCodeURL returns the authorization URL of the provider with the state, the nonce
+// and the S256 challenge of the PKCE verifier.
+func (s *oidcService) AuthCodeURL(ctx context.Context, provider, state, nonce, verifier string) (string, error) {
+	client, err := s.client(ctx, provider)
+	if err != nil {
+		return "", err
+	}
+
+	return client.oauth.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Login signs in the user linked to the provider account. An unknown account gets a new
+// user with a stash when the provider allows sign up.
+func (s *oidcService) Login(ctx context.Context, provider, code, verifier, nonce string) (*model.User, error) {
+	logger := logctx.With(ctx, "method", "Login", "provider", provider)
+
+	client, err := s.client(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.exchange(ctx, client, code, verifier, nonce)
+	if err != nil {
+		logger.Warn("Failed to verify provider login", "error", err)
+		return nil, err
+	}
+
+	identity, err := s.identityRepo.FindIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		if identity.User.Disabled {
+			logger.Warn("Login attempt for disabled account", "userID", identity.UserID)
+			return nil, model.ErrAccountDisabled
+		}
+
+		logger.Info("User signed in with provider", "userID", identity.UserID)
+		return &identity.User, nil
+	}
+	if !errors.Is(err, model.ErrIdentityNotFound) {
+		logger.Error("Failed to find external identity", "error", err)
+		return nil, err
+	}
+
+	if !client.settings.AllowSignup {
+		logger.Warn("Sign up attempt with unknown identity")
+		return nil, model.ErrSignupDisabled
+	}
+
+	user, err := s.userService.ProvisionExternalUser(ctx, claims.Username, &model.ExternalIdentity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		logger.Error("Failed to provision user", "error", err)
+		return nil, err
+	}
+
+	logger.Info("User provisioned with provider", "userID", user.ID)
+	return user, nil
+}
+
+// Link adds the provider account to the identities of a user. Linking an account
+// the user already has is not an error.
+func (s *oidcService) Link(ctx context.Context, userID, provider, code, verifier, nonce string) (*model.ExternalIdentity, error) {
+	logger := logctx.With(ctx, "method", "Link", "provider", provider, "userID", userID)
+
+	client, err := s.client(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.exchange(ctx, client, code, verifier, nonce)
+	if err != nil {
+		logger.Warn("Failed to verify provider login", "error", err)
+		return nil, err
+	}
+
+	identity, err := s.identityRepo.FindIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		if identity.UserID.String() != userID {
+			logger.Warn("Identity is linked to another user")
+			return nil, model.ErrIdentityLinked
+		}
+		return identity, nil
+	}
+	if !errors.Is(err, model.ErrIdentityNotFound) {
+		logger.Error("Failed to find external identity", "error", err)
+		return nil, err
+	}
+
+	user, err := s.userService.GetUser(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return nil, err
+	}
+
+	identity = &model.ExternalIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := s.identityRepo.CreateIdentity(ctx, identity); err != nil {
+		logger.Error("Failed to link identity", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Identity linked", "identityID", identity.ID)
+	return identity, nil
+}
+
+func (s *oidcService) ListIdentities(ctx context.Context, userID string) ([]model.ExternalIdentity, error) {
+	logger := logctx.With(ctx, "method", "ListIdentities", "userID", userID)
+
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to list identities", "error", err)
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+func (s *oidcService) UnlinkIdentity(ctx context.Context, userID, identityID string) error {
+	logger := logctx.With(ctx, "method", "UnlinkIdentity", "userID", userID, "identityID", identityID)
+
+	if err := s.identityRepo.DeleteIdentity(ctx, userID, identityID); err != nil {
+		logger.Error("Failed to unlink identity", "error", err)
+		return err
+	}
+
+	logger.Info("Identity unlinked")
+	return nil
+}
+
+// client returns the provider with its discovered endpoints.
+func (s *oidcService) client(ctx context.Context, name string) (*oidcClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.providers[name]
+	if !ok {
+		return nil, model.ErrProviderNotFound
+	}
+	if client.oauth != nil {
+		return client, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, client.settings.Issuer)
+	if err != nil {
+		logctx.From(ctx).Error("Failed to discover OIDC provider", "provider", name, "error", err)
+		return nil, fmt.Errorf("failed to discover provider %s: %w", name, err)
+	}
+
+	client.oauth = &oauth2.Config{
+		ClientID:     client.settings.ClientID,
+		ClientSecret: client.settings.ClientSecret,
+		RedirectURL:  client.settings.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       client.settings.Scopes,
+	}
+	client.verifier = provider.Verifier(&oidc.Config{ClientID: client.settings.ClientID})
+
+	return client, nil
+}
+
+// exchange redeems the authorization code and verifies the ID token with its nonce.
+// Errors from the provider or the token are wrapped in model.ErrExternalLogin.
+func (s *oidcService) exchange(ctx context.Context, client *oidcClient, code, verifier, nonce string) (*model.ExternalClaims, error) {
+	token, err := client.oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrExternalLogin, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: token response without id_token", model.ErrExternalLogin)
+	}
+
+	idToken, err := client.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrExternalLogin, err)
+	}
+
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", model.ErrExternalLogin)
+	}
+
+	var raw map[string]interface{}
+	if err := idToken.Claims(&raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrExternalLogin, err)
+	}
+
+	claims := &model.ExternalClaims{
+		Provider: client.settings.Name,
+		Subject:  idToken.Subject,
+	}
+	claims.Email, _ = raw["email"].(string)
+	claims.Username, _ = raw[client.settings.UsernameClaim].(string)
+	if claims.Username == "" {
+		claims.Username, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if claims.Username == "" {
+		claims.Username = client.settings.Name + "-" + idToken.Subject
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,186 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/oidctest"
+	"github.com/mirai-box/mirai-box/internal/service"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+// memoryIdentityRepo keeps external identities in memory.
+type memoryIdentityRepo struct {
+	identities []model.ExternalIdentity
+	users      map[uuid.UUID]model.User
+}
+
+func (r *memoryIdentityRepo) FindIdentity(ctx context.Context, provider, subject string) (*model.ExternalIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			identity.User = r.users[identity.UserID]
+			return &identity, nil
+		}
+	}
+	return nil, model.ErrIdentityNotFound
+}
+
+func (r *memoryIdentityRepo) CreateIdentity(ctx context.Context, identity *model.ExternalIdentity) error {
+	identity.ID = uuid.New()
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *memoryIdentityRepo) CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.ExternalIdentity) error {
+	r.users[user.ID] = *user
+	identity.UserID = user.ID
+	return r.CreateIdentity(ctx, identity)
+}
+
+func (r *memoryIdentityRepo) ListByUser(ctx context.Context, userID string) ([]model.ExternalIdentity, error) {
+	return nil, nil
+}
+
+func (r *memoryIdentityRepo) DeleteIdentity(ctx context.Context, userID, id string) error {
+	return nil
+}
+
+func setupOIDCService(t *testing.T, allowSignup bool) (service.OIDCService, *oidctest.Provider, *mocks.UserService, *memoryIdentityRepo) {
+	provider := oidctest.NewProvider(t, "subject-1")
+	provider.SetClaims(map[string]interface{}{
+		"sub":                "subject-1",
+		"preferred_username": "alice",
+		"email":              "alice@studio.example",
+	})
+
+	repo := &memoryIdentityRepo{users: map[uuid.UUID]model.User{}}
+	userService := mocks.NewUserService(t)
+
+	s := service.NewOIDCService(repo, userService, []service.OIDCProvider{{
+		Name:          "studio",
+		Issuer:        provider.Issuer,
+		ClientID:      oidctest.ClientID,
+		ClientSecret:  oidctest.ClientSecret,
+		RedirectURL:   "http://mirai.example/login/oidc/studio/callback",
+		Scopes:        []string{"openid", "profile", "email"},
+		UsernameClaim: "preferred_username",
+		AllowSignup:   allowSignup,
+	}})
+
+	return s, provider, userService, repo
+}
+
+// authorize runs the browser part of the flow and returns the authorization code.
+func authorize(t *testing.T, s service.OIDCService, provider *oidctest.Provider, nonce, verifier string) string {
+	authURL, err := s.AuthCodeURL(context.Background(), "studio", "state-1", nonce, verifier)
+	require.NoError(t, err)
+
+	callback := provider.Authorize(t, authURL)
+	require.Equal(t, "state-1", callback.Query().Get("state"))
+	return callback.Query().Get("code")
+}
+
+func TestOIDCService_Login(t *testing.T) {
+	ctx := context.Background()
+	verifier := "verifier-0123456789-0123456789-0123456789-0123456789"
+
+	t.Run("Provisions a user on first sign in", func(t *testing.T) {
+		s, provider, userService, repo := setupOIDCService(t, true)
+
+		provisioned := &model.User{ID: uuid.New(), Username: "alice", Role: model.RoleUser}
+		userService.On("ProvisionExternalUser", mock.Anything, "alice", mock.MatchedBy(func(identity *model.ExternalIdentity) bool {
+			return identity.Provider == "studio" && identity.Subject == "subject-1" && identity.Email == "alice@studio.example"
+		})).Run(func(args mock.Arguments) {
+			_ = repo.CreateUserWithIdentity(ctx, provisioned, args.Get(2).(*model.ExternalIdentity))
+		}).Return(provisioned, nil).Once()
+
+		code := authorize(t, s, provider, "nonce-1", verifier)
+		user, err := s.Login(ctx, "studio", code, verifier, "nonce-1")
+		require.NoError(t, err)
+		assert.Equal(t, provisioned.ID, user.ID)
+
+		// The second sign in finds the linked identity.
+		code = authorize(t, s, provider, "nonce-2", verifier)
+		user, err = s.Login(ctx, "studio", code, verifier, "nonce-2")
+		require.NoError(t, err)
+		assert.Equal(t, provisioned.ID, user.ID)
+	})
+
+	t.Run("Sign up disabled", func(t *testing.T) {
+		s, provider, _, _ := setupOIDCService(t, false)
+
+		code := authorize(t, s, provider, "nonce-1", verifier)
+		_, err := s.Login(ctx, "studio", code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, model.ErrSignupDisabled)
+	})
+
+	t.Run("Wrong PKCE verifier", func(t *testing.T) {
+		s, provider, _, _ := setupOIDCService(t, true)
+
+		code := authorize(t, s, provider, "nonce-1", verifier)
+		_, err := s.Login(ctx, "studio", code, verifier+"-other", "nonce-1")
+		assert.ErrorIs(t, err, model.ErrExternalLogin)
+	})
+
+	t.Run("Nonce mismatch", func(t *testing.T) {
+		s, provider, _, _ := setupOIDCService(t, true)
+
+		code := authorize(t, s, provider, "nonce-1", verifier)
+		_, err := s.Login(ctx, "studio", code, verifier, "nonce-2")
+		assert.ErrorIs(t, err, model.ErrExternalLogin)
+	})
+
+	t.Run("Disabled account", func(t *testing.T) {
+		s, provider, _, repo := setupOIDCService(t, true)
+
+		user := model.User{ID: uuid.New(), Username: "alice", Disabled: true}
+		repo.users[user.ID] = user
+		require.NoError(t, repo.CreateIdentity(ctx, &model.ExternalIdentity{UserID: user.ID, Provider: "studio", Subject: "subject-1"}))
+
+		code := authorize(t, s, provider, "nonce-1", verifier)
+		_, err := s.Login(ctx, "studio", code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, model.ErrAccountDisabled)
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		s, _, _, _ := setupOIDCService(t, true)
+
+		_, err := s.AuthCodeURL(ctx, "other", "state", "nonce", verifier)
+		assert.ErrorIs(t, err, model.ErrProviderNotFound)
+	})
+}
+
+func TestOIDCService_Link(t *testing.T) {
+	ctx := context.Background()
+	verifier := "verifier-0123456789-0123456789-0123456789-0123456789"
+
+	t.Run("Links to the current user", func(t *testing.T) {
+		s, provider, userService, _ := setupOIDCService(t, true)
+
+		userID := uuid.New()
+		userService.On("GetUser", mock.Anything, userID.String()).Return(&model.User{ID: userID}, nil).Once()
+
+		code := authorize(t, s, provider, "nonce-1", verifier)
+		identity, err := s.Link(ctx, userID.String(), "studio", code, verifier, "nonce-1")
+		require.NoError(t, err)
+		assert.Equal(t, userID, identity.UserID)
+		assert.Equal(t, "subject-1", identity.Subject)
+	})
+
+	t.Run("Identity of another user", func(t *testing.T) {
+		s, provider, _, repo := setupOIDCService(t, true)
+
+		owner := uuid.New()
+		require.NoError(t, repo.CreateIdentity(ctx, &model.ExternalIdentity{UserID: owner, Provider: "studio", Subject: "subject-1"}))
+
+		code := authorize(t, s, provider, "nonce-1", verifier)
+		_, err := s.Link(ctx, uuid.NewString(), "studio", code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, model.ErrIdentityLinked)
+	})
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/notifier"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// resetTokenBytes is the entropy of password reset tokens.
+const resetTokenBytes = 32
+
+// PasswordPolicy is checked whenever a user chooses a password.
+type PasswordPolicy struct {
+	MinLength int
+	breached  map[string]struct{}
+}
+
+// NewPasswordPolicy creates a policy, passwords in breached are rejected regardless of case.
+func NewPasswordPolicy(minLength int, breached []string) *PasswordPolicy {
+	p := &PasswordPolicy{
+		MinLength: minLength,
+		breached:  make(map[string]struct{}, len(breached)),
+	}
+	for _, password := range breached {
+		p.breached[strings.ToLower(password)] = struct{}{}
+	}
+	return p
+}
+
+// Validate returns an error wrapping model.ErrWeakPassword when the password does not
+// meet the policy. A nil policy accepts every password.
+func (p *PasswordPolicy) Validate(password string) error {
+	if p == nil {
+		return nil
+	}
+
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w: it must be at least %d characters long", model.ErrWeakPassword, p.MinLength)
+	}
+
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return fmt.Errorf("%w: it appears in a list of breached passwords", model.ErrWeakPassword)
+	}
+
+	return nil
+}
+
+// PasswordService changes and resets the passwords of users.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=PasswordService --filename=password_service.go --output=../../mocks/
+type PasswordService interface {
+	ChangePassword(ctx context.Context, userID, currentPassword, newPassword, currentToken string) error
+	RequestReset(ctx context.Context, username string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type passwordService struct {
+	userRepo    repo.UserRepository
+	resetRepo   repo.PasswordResetRepository
+	sessionRepo repo.SessionRepository
+	notifier    notifier.Notifier
+	policy      *PasswordPolicy
+	resetTTL    time.Duration
+	publicURL   string
+}
+
+// NewPasswordService creates a password service, reset tokens are sent through n and stay valid for resetTTL.
+func NewPasswordService(
+	ur repo.UserRepository,
+	prr repo.PasswordResetRepository,
+	sr repo.SessionRepository,
+	n notifier.Notifier,
+	policy *PasswordPolicy,
+	resetTTL time.Duration,
+	publicURL string,
+) PasswordService {
+	return &passwordService{
+		userRepo:    ur,
+		resetRepo:   prr,
+		sessionRepo: sr,
+		notifier:    n,
+		policy:      policy,
+		resetTTL:    resetTTL,
+		publicURL:   publicURL,
+	}
+}
+
+// ChangePassword sets a new password after checking the current one. Every other
+// session of the user is signed out, the session of currentToken is kept.
+func (s *passwordService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword, currentToken string) error {
+	logger := logctx.With(ctx, "method", "ChangePassword", "userID", userID)
+
+	if userID == "" || currentPassword == "" || newPassword == "" {
+		logger.Warn("Invalid input parameters")
+		return model.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		logger.Warn("Current password does not match")
+		return model.ErrInvalidCredentials
+	}
+
+	if err := s.policy.Validate(newPassword); err != nil {
+		logger.Warn("New password rejected by policy", "error", err)
+		return err
+	}
+
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		logger.Error("Failed to hash password", "error", err)
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = hashedPassword
+
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		logger.Error("Failed to update user", "error", err)
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	var exceptHash string
+	if currentToken != "" {
+		exceptHash = HashToken(currentToken)
+	}
+
+	count, err := s.sessionRepo.DeleteByUser(ctx, userID, exceptHash)
+	if err != nil {
+		logger.Error("Failed to revoke sessions after password change", "error", err)
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	logger.Info("Password changed", "revokedSessions", count)
+	return nil
+}
+
+// RequestReset sends a single-use reset token to the user. Unknown and disabled
+// accounts are only logged, so the caller can not tell which usernames exist.
+func (s *passwordService) RequestReset(ctx context.Context, username string) error {
+	logger := logctx.With(ctx, "method", "RequestReset", "username", username)
+
+	if username == "" {
+		logger.Warn("Invalid input: empty username")
+		return model.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.FindUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			logger.Info("Password reset requested for unknown user")
+			return nil
+		}
+		logger.Error("Failed to find user", "error", err)
+		return err
+	}
+
+	if user.Disabled {
+		logger.Info("Password reset requested for disabled account")
+		return nil
+	}
+
+	token, err := GenerateToken(resetTokenBytes)
+	if err != nil {
+		logger.Error("Failed to generate reset token", "error", err)
+		return err
+	}
+
+	resetToken := &model.PasswordResetToken{
+		ID:        uuid.New(),
+		TokenHash: HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.resetTTL),
+	}
+
+	if err := s.resetRepo.CreateResetToken(ctx, resetToken); err != nil {
+		logger.Error("Failed to store reset token", "error", err)
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+
+	msg := notifier.Message{
+		To:      user.Username,
+		Subject: "Reset your Mirai Box password",
+		Body: fmt.Sprintf("Use this token to choose a new password, it is valid until %s:\n\n%s\n\n"+
+			"Send it with your new password to %s/password/reset/confirm.\n"+
+			"If you did not ask for a reset you can ignore this message.",
+			resetToken.ExpiresAt.UTC().Format(time.RFC1123), token, s.publicURL),
+	}
+
+	if err := s.notifier.Notify(ctx, msg); err != nil {
+		logger.Error("Failed to send reset token", "error", err)
+		return fmt.Errorf("failed to send reset token: %w", err)
+	}
+
+	logger.Info("Password reset token sent", "userID", user.ID)
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token. The token is consumed, every
+// session of the user is signed out and its API tokens are revoked.
+func (s *passwordService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	logger := logctx.With(ctx, "method", "ResetPassword")
+
+	if token == "" || newPassword == "" {
+		logger.Warn("Invalid input parameters")
+		return model.ErrInvalidInput
+	}
+
+	if err := s.policy.Validate(newPassword); err != nil {
+		logger.Warn("New password rejected by policy", "error", err)
+		return err
+	}
+
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		logger.Error("Failed to hash password", "error", err)
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := s.resetRepo.ResetPassword(ctx, HashToken(token), hashedPassword)
+	if err != nil {
+		logger.Warn("Failed to reset password", "error", err)
+		return err
+	}
+
+	logger.Info("Password reset with token", "userID", user.ID)
+	return nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := NewPasswordPolicy(8, []string{"password123", "Sunshine99"})
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "Long enough", password: "correct horse", wantErr: false},
+		{name: "Too short", password: "short", wantErr: true},
+		{name: "Length counts characters", password: "パスワードです!!", wantErr: false},
+		{name: "Breached", password: "password123", wantErr: true},
+		{name: "Breached ignores case", password: "sunshine99", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, model.ErrWeakPassword)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_Nil(t *testing.T) {
+	var policy *PasswordPolicy
+	assert.NoError(t, policy.Validate("a"))
+}
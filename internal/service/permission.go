@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// PermissionService resolves the permissions granted to a role.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=PermissionService --filename=permission_service.go --output=../../mocks/
+type PermissionService interface {
+	PermissionsForRole(ctx context.Context, role string) ([]string, error)
+}
+
+type permissionService struct {
+	permissionRepo repo.PermissionRepository
+}
+
+func NewPermissionService(pr repo.PermissionRepository) PermissionService {
+	return &permissionService{permissionRepo: pr}
+}
+
+func (s *permissionService) PermissionsForRole(ctx context.Context, role string) ([]string, error) {
+	logger := logctx.With(ctx, "method", "PermissionsForRole", "role", role)
+
+	permissions, err := s.permissionRepo.FindByRole(ctx, role)
+	if err != nil {
+		logger.Error("Failed to find role permissions", "error", err)
+		return nil, err
+	}
+
+	return permissions, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// rateLimitCleanupInterval is how often buckets that are full again get deleted.
+const rateLimitCleanupInterval = 5 * time.Minute
+
+// RateLimitService limits the requests of a key with a token bucket per route class.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=RateLimitService --filename=rate_limit_service.go --output=../../mocks/
+type RateLimitService interface {
+	Allow(ctx context.Context, class, key string) (*model.RateLimitResult, error)
+}
+
+type rateLimitService struct {
+	rateLimitRepo repo.RateLimitRepository
+	limits        map[string]model.RateLimit
+	// idle is the longest period of the limits, a bucket unused for that long is full.
+	idle time.Duration
+	now  func() time.Time
+
+	mu          sync.Mutex
+	nextCleanup time.Time
+}
+
+// NewRateLimitService creates a rate limiter with the limits of the route classes,
+// classes without a limit are not limited.
+func NewRateLimitService(rr repo.RateLimitRepository, limits map[string]model.RateLimit) RateLimitService {
+	var idle time.Duration
+	for _, limit := range limits {
+		idle = max(idle, limit.Period)
+	}
+
+	return &rateLimitService{
+		rateLimitRepo: rr,
+		limits:        limits,
+		idle:          idle,
+		now:           time.Now,
+	}
+}
+
+// Allow takes a request of the key from the budget of the route class. The result is
+// nil when the class is not limited.
+func (s *rateLimitService) Allow(ctx context.Context, class, key string) (*model.RateLimitResult, error) {
+	logger := logctx.With(ctx, "method", "Allow", "class", class, "key", key)
+
+	limit, ok := s.limits[class]
+	if !ok {
+		return nil, nil
+	}
+
+	now := s.now()
+	s.deleteStale(ctx, now)
+
+	result, err := s.rateLimitRepo.Take(ctx, key, limit, now)
+	if err != nil {
+		logger.Error("Failed to take from rate limit", "error", err)
+		return nil, err
+	}
+
+	if !result.Allowed {
+		logger.Warn("Rate limit exceeded", "retryAfter", result.RetryAfter)
+	}
+	return result, nil
+}
+
+// deleteStale deletes the unused buckets once per rateLimitCleanupInterval.
+func (s *rateLimitService) deleteStale(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Before(s.nextCleanup) {
+		s.mu.Unlock()
+		return
+	}
+	s.nextCleanup = now.Add(rateLimitCleanupInterval)
+	s.mu.Unlock()
+
+	if _, err := s.rateLimitRepo.DeleteStale(ctx, now.Add(-s.idle)); err != nil {
+		logctx.With(ctx, "method", "deleteStale").Warn("Failed to delete stale rate limit buckets", "error", err)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+func newTestRateLimitService(limits map[string]model.RateLimit) (*rateLimitService, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewRateLimitService(repo.NewMemoryRateLimitRepository(), limits).(*rateLimitService)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestRateLimitService_Allow(t *testing.T) {
+	ctx := context.Background()
+	s, now := newTestRateLimitService(map[string]model.RateLimit{
+		model.RateLimitAuth: {Requests: 3, Period: time.Minute},
+	})
+	key := model.RateLimitIPKey(model.RateLimitAuth, "192.0.2.1")
+
+	for i := 2; i >= 0; i-- {
+		result, err := s.Allow(ctx, model.RateLimitAuth, key)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := s.Allow(ctx, model.RateLimitAuth, key)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+	assert.Equal(t, time.Minute, result.Reset)
+
+	// Other keys have their own budget.
+	result, err = s.Allow(ctx, model.RateLimitAuth, model.RateLimitIPKey(model.RateLimitAuth, "192.0.2.2"))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// One token refills after a third of the period.
+	*now = now.Add(20 * time.Second)
+	result, err = s.Allow(ctx, model.RateLimitAuth, key)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = s.Allow(ctx, model.RateLimitAuth, key)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// The budget never grows past the limit.
+	*now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		result, err = s.Allow(ctx, model.RateLimitAuth, key)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+	result, err = s.Allow(ctx, model.RateLimitAuth, key)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
+
+func TestRateLimitService_Unlimited(t *testing.T) {
+	s, _ := newTestRateLimitService(map[string]model.RateLimit{
+		model.RateLimitAuth: {Requests: 1, Period: time.Minute},
+	})
+
+	result, err := s.Allow(context.Background(), model.RateLimitAPI, model.RateLimitUserKey(model.RateLimitAPI, "user"))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestRateLimitService_DeleteStale(t *testing.T) {
+	ctx := context.Background()
+	rr := repo.NewMemoryRateLimitRepository()
+	s := NewRateLimitService(rr, map[string]model.RateLimit{
+		model.RateLimitAuth: {Requests: 1, Period: time.Minute},
+	}).(*rateLimitService)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	_, err := s.Allow(ctx, model.RateLimitAuth, "auth:ip:192.0.2.1")
+	require.NoError(t, err)
+
+	now = now.Add(rateLimitCleanupInterval)
+	_, err = s.Allow(ctx, model.RateLimitAuth, "auth:ip:192.0.2.2")
+	require.NoError(t, err)
+
+	deleted, err := rr.DeleteStale(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, deleted, "the first bucket is deleted by the cleanup of the second request")
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// SessionService lists and revokes the server-side login sessions of a user.
+// Sessions are identified towards the client by the token in the session cookie.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=SessionService --filename=session_service.go --output=../../mocks/
+type SessionService interface {
+	ListSessions(ctx context.Context, userID, currentToken string) ([]model.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentToken string) (int64, error)
+	RevokeToken(ctx context.Context, token string) error
+}
+
+type sessionService struct {
+	sessionRepo repo.SessionRepository
+}
+
+func NewSessionService(sr repo.SessionRepository) SessionService {
+	return &sessionService{sessionRepo: sr}
+}
+
+// ListSessions returns the active sessions of a user, marking the one of the current request.
+func (s *sessionService) ListSessions(ctx context.Context, userID, currentToken string) ([]model.Session, error) {
+	logger := logctx.With(ctx, "method", "ListSessions", "userID", userID)
+
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to list sessions", "error", err)
+		return nil, err
+	}
+
+	var currentHash string
+	if currentToken != "" {
+		currentHash = HashToken(currentToken)
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].TokenHash == currentHash
+	}
+
+	logger.Info("Sessions listed", "count", len(sessions))
+	return sessions, nil
+}
+
+func (s *sessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	logger := logctx.With(ctx, "method", "RevokeSession", "userID", userID, "sessionID", sessionID)
+
+	if err := s.sessionRepo.DeleteSession(ctx, userID, sessionID); err != nil {
+		logger.Error("Failed to revoke session", "error", err)
+		return err
+	}
+
+	logger.Info("Session revoked")
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere except in the current session.
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userID, currentToken string) (int64, error) {
+	logger := logctx.With(ctx, "method", "RevokeOtherSessions", "userID", userID)
+
+	var currentHash string
+	if currentToken != "" {
+		currentHash = HashToken(currentToken)
+	}
+
+	count, err := s.sessionRepo.DeleteByUser(ctx, userID, currentHash)
+	if err != nil {
+		logger.Error("Failed to revoke sessions", "error", err)
+		return 0, err
+	}
+
+	logger.Info("Sessions revoked", "count", count)
+	return count, nil
+}
+
+// RevokeToken deletes the session of a cookie token, used before a new login.
+func (s *sessionService) RevokeToken(ctx context.Context, token string) error {
+	logger := logctx.With(ctx, "method", "RevokeToken")
+
+	if token == "" {
+		return nil
+	}
+
+	if err := s.sessionRepo.DeleteByTokenHash(ctx, HashToken(token)); err != nil {
+		logger.Error("Failed to revoke session", "error", err)
+		return err
+	}
+
+	return nil
+}
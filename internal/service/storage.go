@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+// orphanGracePeriod keeps files of uploads that are still being stored from being
+// reported as orphans before their revision is created.
+const orphanGracePeriod = time.Hour
+
+// StorageService checks the stored revision files and the stash counters against the database.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=StorageService --filename=storage_service.go --output=../../mocks/
+type StorageService interface {
+	Verify(ctx context.Context) (*model.StorageReport, error)
+	Repair(ctx context.Context) (*model.StorageReport, error)
+}
+
+type storageService struct {
+	userRepo        repo.UserRepository
+	artRepo         repo.ArtProjectRepository
+	fileStorageRepo repo.FileStorageRepository
+}
+
+// NewStorageService creates a new instance of StorageService.
+func NewStorageService(ur repo.UserRepository, ar repo.ArtProjectRepository, fsr repo.FileStorageRepository) StorageService {
+	return &storageService{
+		userRepo:        ur,
+		artRepo:         ar,
+		fileStorageRepo: fsr,
+	}
+}
+
+// Verify reports revisions whose file is missing or has another size than recorded,
+// orphan files no revision refers to and stashes with wrong counters.
+func (s *storageService) Verify(ctx context.Context) (*model.StorageReport, error) {
+	logger := logctx.With(ctx, "method", "Verify")
+
+	report, err := s.check(ctx)
+	if err != nil {
+		logger.Error("Failed to verify storage", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Storage verified", "revisions", report.Revisions, "files", report.Files,
+		"problems", len(report.Problems), "stashes", len(report.Stashes))
+	return report, nil
+}
+
+// Repair verifies the storage, removes orphan files and recomputes drifted stashes.
+// Missing files and size mismatches can't be repaired and stay in the report. Orphans
+// are kept while files are missing, because the storage root may have moved.
+func (s *storageService) Repair(ctx context.Context) (*model.StorageReport, error) {
+	logger := logctx.With(ctx, "method", "Repair")
+
+	report, err := s.check(ctx)
+	if err != nil {
+		logger.Error("Failed to verify storage", "error", err)
+		return nil, err
+	}
+
+	missing := 0
+	for _, problem := range report.Problems {
+		if problem.Kind == model.StorageProblemMissing {
+			missing++
+		}
+	}
+
+	if missing > 0 {
+		logger.Warn("Keeping orphan files while revision files are missing", "missing", missing)
+	} else {
+		for i := range report.Problems {
+			problem := &report.Problems[i]
+			if problem.Kind != model.StorageProblemOrphan {
+				continue
+			}
+			if err := s.fileStorageRepo.RemoveFile(ctx, problem.FilePath); err != nil {
+				return nil, fmt.Errorf("failed to remove orphan %s: %w", problem.FilePath, err)
+			}
+			problem.Repaired = true
+		}
+	}
+
+	for i := range report.Stashes {
+		stash := &report.Stashes[i]
+		if _, err := s.userRepo.RecomputeStash(ctx, stash.UserID.String()); err != nil {
+			return nil, fmt.Errorf("failed to recompute stash of user %s: %w", stash.UserID, err)
+		}
+		stash.Repaired = true
+	}
+
+	logger.Info("Storage repaired", "revisions", report.Revisions, "files", report.Files, "unrepaired", report.Unrepaired())
+	return report, nil
+}
+
+// check compares the revisions with the files under the storage root and the stash
+// counters with the art projects and revisions they hold.
+func (s *storageService) check(ctx context.Context) (*model.StorageReport, error) {
+	logger := logctx.With(ctx, "method", "check")
+
+	report := &model.StorageReport{}
+	known := map[string]struct{}{}
+
+	err := s.artRepo.WalkRevisions(ctx, func(revisions []model.Revision) error {
+		for _, revision := range revisions {
+			report.Revisions++
+			known[filepath.Clean(revision.FilePath)] = struct{}{}
+			known[filepath.Clean(repo.ThumbnailPath(revision.FilePath))] = struct{}{}
+
+			problem := model.StorageProblem{
+				RevisionID:   revision.ID,
+				ArtProjectID: revision.ArtProjectID,
+				UserID:       revision.UserID,
+				FilePath:     revision.FilePath,
+				ExpectedSize: revision.Size,
+			}
+
+			info, err := s.fileStorageRepo.StatFile(ctx, revision.FilePath)
+			switch {
+			case os.IsNotExist(err):
+				problem.Kind = model.StorageProblemMissing
+			case err != nil:
+				return fmt.Errorf("failed to check revision %s: %w", revision.ID, err)
+			case info.Size() != revision.Size:
+				problem.Kind = model.StorageProblemSizeMismatch
+				problem.ActualSize = info.Size()
+			default:
+				continue
+			}
+
+			logger.Warn("Revision file does not match", "revisionID", revision.ID, "path", revision.FilePath, "kind", problem.Kind)
+			report.Problems = append(report.Problems, problem)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Files are listed after the revisions, so a file stored meanwhile is younger than
+	// the grace period instead of an orphan.
+	cutoff := time.Now().Add(-orphanGracePeriod)
+	err = s.fileStorageRepo.WalkFiles(ctx, func(path string, info os.FileInfo) error {
+		report.Files++
+		if _, ok := known[filepath.Clean(path)]; ok || info.ModTime().After(cutoff) {
+			return nil
+		}
+
+		logger.Warn("Orphan file", "path", path, "size", info.Size())
+		report.Problems = append(report.Problems, model.StorageProblem{
+			FilePath:   path,
+			Kind:       model.StorageProblemOrphan,
+			ActualSize: info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored files: %w", err)
+	}
+
+	report.Stashes, err = s.userRepo.FindStashDrift(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stashes: %w", err)
+	}
+	for _, stash := range report.Stashes {
+		logger.Warn("Stash counters drifted", "userID", stash.UserID, "recorded", stash.Recorded, "actual", stash.Actual)
+	}
+
+	return report, nil
+}
+
+// RunStorageChecks verifies the storage every interval until ctx is done, repairing it
+// when repair is set.
+func RunStorageChecks(ctx context.Context, s StorageService, interval time.Duration, repair bool) {
+	logger := logctx.With(ctx, "method", "RunStorageChecks", "interval", interval, "repair", repair)
+	logger.Info("Starting periodic storage checks")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopping periodic storage checks")
+			return
+		case <-ticker.C:
+		}
+
+		check := s.Verify
+		if repair {
+			check = s.Repair
+		}
+
+		report, err := check(ctx)
+		if err != nil {
+			logger.Error("Periodic storage check failed", "error", err)
+			continue
+		}
+		if n := report.Unrepaired(); n > 0 {
+			logger.Warn("Storage has unrepaired problems", "unrepaired", n)
+		}
+	}
+}
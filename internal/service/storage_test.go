@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// memoryRevisionRepo walks revisions kept in memory.
+type memoryRevisionRepo struct {
+	repo.ArtProjectRepository
+	revisions []model.Revision
+}
+
+func (r *memoryRevisionRepo) WalkRevisions(ctx context.Context, fn func([]model.Revision) error) error {
+	return fn(r.revisions)
+}
+
+// memoryStashRepo reports stash drift kept in memory and records recomputed stashes.
+type memoryStashRepo struct {
+	repo.UserRepository
+	drift      []model.StashDrift
+	recomputed []string
+}
+
+func (r *memoryStashRepo) FindStashDrift(ctx context.Context) ([]model.StashDrift, error) {
+	return r.drift, nil
+}
+
+func (r *memoryStashRepo) RecomputeStash(ctx context.Context, userID string) (*model.Stash, error) {
+	r.recomputed = append(r.recomputed, userID)
+	return &model.Stash{}, nil
+}
+
+type storageFixture struct {
+	root      string
+	revisions *memoryRevisionRepo
+	stashes   *memoryStashRepo
+	service   service.StorageService
+}
+
+func setupStorageService(t *testing.T) *storageFixture {
+	root := t.TempDir()
+	f := &storageFixture{
+		root:      root,
+		revisions: &memoryRevisionRepo{},
+		stashes:   &memoryStashRepo{},
+	}
+	f.service = service.NewStorageService(f.stashes, f.revisions, repo.NewFileStorageRepository(nil, root))
+	return f
+}
+
+// writeFile stores a file under the storage root, modified the given time ago.
+func (f *storageFixture) writeFile(t *testing.T, name, content string, age time.Duration) string {
+	path := filepath.Join(f.root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	modified := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, modified, modified))
+	return path
+}
+
+func TestStorageService_Verify(t *testing.T) {
+	f := setupStorageService(t)
+
+	ok := model.Revision{ID: uuid.New(), FilePath: f.writeFile(t, "u/a/revisions/v1", "picture", 2*time.Hour), Size: 7}
+	truncated := model.Revision{ID: uuid.New(), FilePath: f.writeFile(t, "u/a/revisions/v2", "pic", 2*time.Hour), Size: 7}
+	missing := model.Revision{ID: uuid.New(), FilePath: filepath.Join(f.root, "u/a/revisions/v3"), Size: 7}
+	f.revisions.revisions = []model.Revision{ok, truncated, missing}
+	f.writeFile(t, "u/a/revisions/v1.thumb.jpg", "thumbnail", 2*time.Hour)
+
+	orphan := f.writeFile(t, "u/b/revisions/v1", "left over", 2*time.Hour)
+	f.writeFile(t, "u/c/revisions/v1", "uploading", time.Minute)
+
+	drift := model.StashDrift{UserID: uuid.New(), Recorded: model.StashCounters{Files: 4}, Actual: model.StashCounters{Files: 3}}
+	f.stashes.drift = []model.StashDrift{drift}
+
+	report, err := f.service.Verify(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 3, report.Revisions)
+	assert.EqualValues(t, 5, report.Files)
+	require.Len(t, report.Problems, 3)
+	assert.Equal(t, truncated.ID, report.Problems[0].RevisionID)
+	assert.Equal(t, model.StorageProblemSizeMismatch, report.Problems[0].Kind)
+	assert.EqualValues(t, 3, report.Problems[0].ActualSize)
+	assert.Equal(t, missing.ID, report.Problems[1].RevisionID)
+	assert.Equal(t, model.StorageProblemMissing, report.Problems[1].Kind)
+	assert.Equal(t, orphan, report.Problems[2].FilePath)
+	assert.Equal(t, model.StorageProblemOrphan, report.Problems[2].Kind)
+	assert.Equal(t, []model.StashDrift{drift}, report.Stashes)
+	assert.Equal(t, 4, report.Unrepaired())
+
+	assert.FileExists(t, orphan, "verify does not change the storage")
+	assert.Empty(t, f.stashes.recomputed)
+}
+
+func TestStorageService_Repair(t *testing.T) {
+	t.Run("Removes orphans and recomputes stashes", func(t *testing.T) {
+		f := setupStorageService(t)
+
+		kept := f.writeFile(t, "u/a/revisions/v1", "picture", 2*time.Hour)
+		f.revisions.revisions = []model.Revision{{ID: uuid.New(), FilePath: kept, Size: 7}}
+		orphan := f.writeFile(t, "u/b/revisions/v1", "left over", 2*time.Hour)
+
+		userID := uuid.New()
+		f.stashes.drift = []model.StashDrift{{UserID: userID, Recorded: model.StashCounters{ArtProjects: 2}, Actual: model.StashCounters{ArtProjects: 1}}}
+
+		report, err := f.service.Repair(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, report.Problems, 1)
+		assert.True(t, report.Problems[0].Repaired)
+		assert.True(t, report.Stashes[0].Repaired)
+		assert.Zero(t, report.Unrepaired())
+
+		assert.NoFileExists(t, orphan)
+		assert.NoDirExists(t, filepath.Join(f.root, "u/b"), "empty directories are removed")
+		assert.FileExists(t, kept)
+		assert.Equal(t, []string{userID.String()}, f.stashes.recomputed)
+	})
+
+	t.Run("Keeps orphans while files are missing", func(t *testing.T) {
+		f := setupStorageService(t)
+
+		f.revisions.revisions = []model.Revision{{ID: uuid.New(), FilePath: filepath.Join(f.root, "moved/v1"), Size: 7}}
+		orphan := f.writeFile(t, "u/a/revisions/v1", "picture", 2*time.Hour)
+
+		report, err := f.service.Repair(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Unrepaired())
+		assert.FileExists(t, orphan)
+	})
+}
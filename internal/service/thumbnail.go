@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+const (
+	// thumbnailEdge is the longest edge of a thumbnail in pixels.
+	thumbnailEdge = 320
+	// maxThumbnailPixels keeps huge images from being decoded into memory.
+	maxThumbnailPixels = 50_000_000
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=ThumbnailService --filename=thumbnail_service.go --output=../../mocks/
+type ThumbnailService interface {
+	Thumbnail(ctx context.Context, revision *model.Revision) (*model.Thumbnail, error)
+}
+
+type thumbnailService struct {
+	fileStorageRepo repo.FileStorageRepository
+}
+
+// NewThumbnailService creates a new instance of ThumbnailService.
+func NewThumbnailService(fsr repo.FileStorageRepository) ThumbnailService {
+	return &thumbnailService{fileStorageRepo: fsr}
+}
+
+// Thumbnail returns the JPEG thumbnail of a revision, rendering and storing it on first
+// use. Revisions that are no JPEG, PNG or GIF image return model.ErrNoThumbnail.
+func (s *thumbnailService) Thumbnail(ctx context.Context, revision *model.Revision) (*model.Thumbnail, error) {
+	logger := logctx.With(ctx, "method", "Thumbnail", "revisionID", revision.ID)
+
+	path := repo.ThumbnailPath(revision.FilePath)
+	info, err := s.fileStorageRepo.StatFile(ctx, path)
+	if err == nil {
+		return &model.Thumbnail{FilePath: path, ContentType: "image/jpeg", Size: info.Size()}, nil
+	}
+	if !os.IsNotExist(err) {
+		logger.Error("Failed to check thumbnail", "error", err)
+		return nil, err
+	}
+
+	img, err := s.decode(ctx, revision.FilePath)
+	if err != nil {
+		logger.Warn("Failed to decode revision image", "error", err)
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, downscale(img, thumbnailEdge), &jpeg.Options{Quality: 85}); err != nil {
+		logger.Error("Failed to encode thumbnail", "error", err)
+		return nil, err
+	}
+
+	info, err = s.fileStorageRepo.SaveFile(ctx, path, &buf)
+	if err != nil {
+		logger.Error("Failed to save thumbnail", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Thumbnail rendered", "size", info.Size())
+	return &model.Thumbnail{FilePath: path, ContentType: "image/jpeg", Size: info.Size()}, nil
+}
+
+// decode reads the image stored at path, refusing images above maxThumbnailPixels
+// before their pixels are decoded.
+func (s *thumbnailService) decode(ctx context.Context, path string) (image.Image, error) {
+	file, err := s.fileStorageRepo.OpenFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var header bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(file, &header))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrNoThumbnail, err)
+	}
+	if config.Width*config.Height > maxThumbnailPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", model.ErrNoThumbnail, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(io.MultiReader(&header, file))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrNoThumbnail, err)
+	}
+	return img, nil
+}
+
+// downscale shrinks img so that its longest edge is at most edge pixels, averaging the
+// source pixels covered by every thumbnail pixel. Transparent pixels are drawn on white,
+// because JPEG has no alpha channel.
+func downscale(img image.Image, edge int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	tw, th := w, h
+	switch {
+	case w >= h && w > edge:
+		tw, th = edge, max(1, h*edge/w)
+	case h > w && h > edge:
+		tw, th = max(1, w*edge/h), edge
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := bounds.Min.Y+y*h/th, bounds.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := bounds.Min.X+x*w/tw, bounds.Min.X+(x+1)*w/tw
+
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// RGBA returns alpha-premultiplied values, adding the missing
+					// coverage composes them over white.
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += uint64(cr + 0xffff - ca)
+					g += uint64(cg + 0xffff - ca)
+					b += uint64(cb + 0xffff - ca)
+					n++
+				}
+			}
+
+			thumb.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return thumb
+}
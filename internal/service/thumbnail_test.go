@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+func TestThumbnailService_Thumbnail(t *testing.T) {
+	root := t.TempDir()
+	thumbnails := service.NewThumbnailService(repo.NewFileStorageRepository(nil, root))
+
+	// A wide image whose right half is transparent.
+	src := image.NewNRGBA(image.Rect(0, 0, 1280, 640))
+	for y := 0; y < 640; y++ {
+		for x := 0; x < 640; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+
+	revisionPath := filepath.Join(root, "user", "art", "revisions", "v1")
+	require.NoError(t, os.MkdirAll(filepath.Dir(revisionPath), os.ModePerm))
+	file, err := os.Create(revisionPath)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(file, src))
+	require.NoError(t, file.Close())
+
+	revision := &model.Revision{ID: uuid.New(), FilePath: revisionPath}
+
+	thumbnail, err := thumbnails.Thumbnail(context.Background(), revision)
+	require.NoError(t, err)
+	assert.Equal(t, revisionPath+".thumb.jpg", thumbnail.FilePath)
+	assert.Equal(t, "image/jpeg", thumbnail.ContentType)
+
+	info, err := os.Stat(thumbnail.FilePath)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), thumbnail.Size)
+
+	file, err = os.Open(thumbnail.FilePath)
+	require.NoError(t, err)
+	defer file.Close()
+	img, err := jpeg.Decode(file)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 320, 160), img.Bounds())
+
+	red, _, _, _ := img.At(80, 80).RGBA()
+	_, green, _, _ := img.At(240, 80).RGBA()
+	assert.Greater(t, red, uint32(0xf000), "opaque pixels keep their colour")
+	assert.Greater(t, green, uint32(0xf000), "transparent pixels are drawn on white")
+
+	// The stored thumbnail is served on later calls.
+	again, err := thumbnails.Thumbnail(context.Background(), revision)
+	require.NoError(t, err)
+	assert.Equal(t, thumbnail, again)
+}
+
+func TestThumbnailService_ThumbnailNoImage(t *testing.T) {
+	root := t.TempDir()
+	thumbnails := service.NewThumbnailService(repo.NewFileStorageRepository(nil, root))
+
+	revisionPath := filepath.Join(root, "notes.txt")
+	require.NoError(t, os.WriteFile(revisionPath, []byte("not an image"), 0o600))
+
+	_, err := thumbnails.Thumbnail(context.Background(), &model.Revision{ID: uuid.New(), FilePath: revisionPath})
+	assert.ErrorIs(t, err, model.ErrNoThumbnail)
+
+	_, err = os.Stat(repo.ThumbnailPath(revisionPath))
+	assert.True(t, os.IsNotExist(err), "no thumbnail is stored")
+}
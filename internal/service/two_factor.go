@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+	"github.com/mirai-box/mirai-box/internal/totp"
+)
+
+const (
+	// totpIssuer names the account in authenticator apps.
+	totpIssuer = "Mirai Box"
+
+	// totpSkew is the number of time steps of clock drift accepted in each direction.
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 10
+
+	// loginChallengeBytes is the entropy of login challenge tokens.
+	loginChallengeBytes = 32
+
+	// loginChallengeTTL is how long the second login step can be completed.
+	loginChallengeTTL = 5 * time.Minute
+
+	// maxChallengeAttempts is the number of wrong codes after which a challenge is dropped.
+	maxChallengeAttempts = 5
+)
+
+// TwoFactorService manages TOTP two-factor authentication: enrolment, recovery
+// codes and the second step of a login.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2 --name=TwoFactorService --filename=two_factor_service.go --output=../../mocks/
+type TwoFactorService interface {
+	Status(ctx context.Context, userID string) (*model.TwoFactorStatus, error)
+	BeginEnrolment(ctx context.Context, userID string) (string, string, error)
+	ConfirmEnrolment(ctx context.Context, userID, code string) ([]string, error)
+	Disable(ctx context.Context, userID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	CreateChallenge(ctx context.Context, userID string, keepSignedIn bool) (string, time.Time, error)
+	FindChallenge(ctx context.Context, token string) (*model.LoginChallenge, error)
+	CompleteChallenge(ctx context.Context, challenge *model.LoginChallenge, code string) error
+	IsRequired(ctx context.Context, role string) (bool, error)
+}
+
+type twoFactorService struct {
+	twoFactorRepo repo.TwoFactorRepository
+	userRepo      repo.UserRepository
+	secretKey     []byte
+}
+
+// NewTwoFactorService creates a two-factor service, TOTP secrets are encrypted with secretKey.
+func NewTwoFactorService(tfr repo.TwoFactorRepository, ur repo.UserRepository, secretKey []byte) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepo: tfr,
+		userRepo:      ur,
+		secretKey:     secretKey,
+	}
+}
+
+func (s *twoFactorService) Status(ctx context.Context, userID string) (*model.TwoFactorStatus, error) {
+	logger := logctx.With(ctx, "method", "Status", "userID", userID)
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return nil, err
+	}
+
+	required, err := s.IsRequired(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &model.TwoFactorStatus{Enabled: user.TOTPEnabled, Required: required}
+	if user.TOTPEnabled {
+		status.RecoveryCodesLeft, err = s.twoFactorRepo.CountRecoveryCodes(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to count recovery codes", "error", err)
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// BeginEnrolment creates a new secret for the user and returns it with its provisioning URI.
+// Two-factor authentication is only enabled once a code is confirmed with ConfirmEnrolment.
+func (s *twoFactorService) BeginEnrolment(ctx context.Context, userID string) (string, string, error) {
+	logger := logctx.With(ctx, "method", "BeginEnrolment", "userID", userID)
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return "", "", err
+	}
+
+	if user.TOTPEnabled {
+		logger.Warn("Two-factor authentication already enabled")
+		return "", "", model.ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		logger.Error("Failed to generate secret", "error", err)
+		return "", "", err
+	}
+
+	encrypted, err := encryptSecret(secret, s.secretKey)
+	if err != nil {
+		logger.Error("Failed to encrypt secret", "error", err)
+		return "", "", err
+	}
+
+	if err := s.twoFactorRepo.SaveSecret(ctx, userID, encrypted); err != nil {
+		logger.Error("Failed to save secret", "error", err)
+		return "", "", err
+	}
+
+	logger.Info("Two-factor enrolment started")
+	return secret, totp.URI(totpIssuer, user.Username, secret), nil
+}
+
+// ConfirmEnrolment enables two-factor authentication once the user proves the
+// authenticator works, and returns the first recovery codes.
+func (s *twoFactorService) ConfirmEnrolment(ctx context.Context, userID, code string) ([]string, error) {
+	logger := logctx.With(ctx, "method", "ConfirmEnrolment", "userID", userID)
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to find user", "error", err)
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		logger.Warn("Two-factor authentication already enabled")
+		return nil, model.ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		logger.Warn("Enrolment not started")
+		return nil, fmt.Errorf("%w: enrolment has not been started", model.ErrInvalidInput)
+	}
+
+	step, err := s.validateTOTP(ctx, user, code)
+	if err != nil {
+		logger.Warn("Invalid confirmation code")
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		logger.Error("Failed to generate recovery codes", "error", err)
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.EnableTwoFactor(ctx, userID, step, hashes); err != nil {
+		logger.Error("Failed to enable two-factor authentication", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Two-factor authentication enabled")
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off, unless the role of the user requires it.
+func (s *twoFactorService) Disable(ctx context.Context, userID, code string) error {
+	logger := logctx.With(ctx, "method", "Disable", "userID", userID)
+
+	user, err := s.enabledUser(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to find enrolled user", "error", err)
+		return err
+	}
+
+	required, err := s.IsRequired(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		logger.Warn("Two-factor authentication is required for the role", "role", user.Role)
+		return model.ErrTwoFactorRequired
+	}
+
+	if err := s.verify(ctx, user, code); err != nil {
+		logger.Warn("Invalid two-factor code", "error", err)
+		return err
+	}
+
+	if err := s.twoFactorRepo.DisableTwoFactor(ctx, userID); err != nil {
+		logger.Error("Failed to disable two-factor authentication", "error", err)
+		return err
+	}
+
+	logger.Info("Two-factor authentication disabled")
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user with new ones.
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	logger := logctx.With(ctx, "method", "RegenerateRecoveryCodes", "userID", userID)
+
+	user, err := s.enabledUser(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to find enrolled user", "error", err)
+		return nil, err
+	}
+
+	if err := s.verify(ctx, user, code); err != nil {
+		logger.Warn("Invalid two-factor code", "error", err)
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		logger.Error("Failed to generate recovery codes", "error", err)
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		logger.Error("Failed to replace recovery codes", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Recovery codes regenerated")
+	return codes, nil
+}
+
+// CreateChallenge starts the second login step for a user whose password was verified.
+func (s *twoFactorService) CreateChallenge(ctx context.Context, userID string, keepSignedIn bool) (string, time.Time, error) {
+	logger := logctx.With(ctx, "method", "CreateChallenge", "userID", userID)
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		logger.Warn("Invalid user ID", "error", err)
+		return "", time.Time{}, model.ErrInvalidInput
+	}
+
+	token, err := GenerateToken(loginChallengeBytes)
+	if err != nil {
+		logger.Error("Failed to generate challenge token", "error", err)
+		return "", time.Time{}, err
+	}
+
+	challenge := &model.LoginChallenge{
+		ID:           uuid.New(),
+		TokenHash:    HashToken(token),
+		UserID:       id,
+		KeepSignedIn: keepSignedIn,
+		ExpiresAt:    time.Now().Add(loginChallengeTTL),
+		CreatedAt:    time.Now(),
+	}
+	if err := s.twoFactorRepo.CreateChallenge(ctx, challenge); err != nil {
+		logger.Error("Failed to create login challenge", "error", err)
+		return "", time.Time{}, err
+	}
+
+	logger.Info("Login challenge created")
+	return token, challenge.ExpiresAt, nil
+}
+
+// FindChallenge returns the unexpired challenge of the token with its user.
+func (s *twoFactorService) FindChallenge(ctx context.Context, token string) (*model.LoginChallenge, error) {
+	return s.twoFactorRepo.FindChallenge(ctx, HashToken(token))
+}
+
+// CompleteChallenge verifies the code for the challenge and consumes it on success.
+// A challenge is dropped after maxChallengeAttempts wrong codes. Every code is counted
+// before it is verified, so concurrent guesses can not exceed the limit.
+func (s *twoFactorService) CompleteChallenge(ctx context.Context, challenge *model.LoginChallenge, code string) error {
+	logger := logctx.With(ctx, "method", "CompleteChallenge", "challengeID", challenge.ID, "userID", challenge.UserID)
+
+	if challenge.User.Disabled {
+		logger.Warn("Login challenge of disabled account")
+		return model.ErrAccountDisabled
+	}
+
+	attempts, err := s.twoFactorRepo.AddChallengeAttempt(ctx, challenge.ID.String())
+	if err != nil {
+		logger.Warn("Failed to count challenge attempt", "error", err)
+		return err
+	}
+
+	if attempts > maxChallengeAttempts {
+		logger.Warn("Login challenge exhausted", "attempts", attempts)
+		if err := s.twoFactorRepo.DeleteChallenge(ctx, challenge.ID.String()); err != nil {
+			logger.Error("Failed to delete exhausted challenge", "error", err)
+		}
+		return model.ErrChallengeNotFound
+	}
+
+	if err := s.verify(ctx, &challenge.User, code); err != nil {
+		if !errors.Is(err, model.ErrInvalidOTP) {
+			logger.Error("Failed to verify code", "error", err)
+			return err
+		}
+
+		logger.Warn("Invalid two-factor code", "attempts", attempts)
+		if attempts >= maxChallengeAttempts {
+			if err := s.twoFactorRepo.DeleteChallenge(ctx, challenge.ID.String()); err != nil {
+				logger.Error("Failed to delete exhausted challenge", "error", err)
+			}
+		}
+		return model.ErrInvalidOTP
+	}
+
+	if err := s.twoFactorRepo.DeleteChallenge(ctx, challenge.ID.String()); err != nil {
+		logger.Error("Failed to delete completed challenge", "error", err)
+		return err
+	}
+
+	logger.Info("Login challenge completed")
+	return nil
+}
+
+// IsRequired reports whether users of the role must enrol in two-factor authentication.
+func (s *twoFactorService) IsRequired(ctx context.Context, role string) (bool, error) {
+	policy, err := s.twoFactorRepo.FindRolePolicy(ctx, role)
+	if err != nil {
+		logctx.From(ctx).Error("Failed to find role policy", "method", "IsRequired", "role", role, "error", err)
+		return false, err
+	}
+	return policy.RequireTwoFactor, nil
+}
+
+func (s *twoFactorService) enabledUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, model.ErrTwoFactorNotEnabled
+	}
+	return user, nil
+}
+
+// verify accepts a current TOTP code, once, or an unused recovery code.
+func (s *twoFactorService) verify(ctx context.Context, user *model.User, code string) error {
+	code = normalizeOTP(code)
+
+	if isTOTPCode(code) {
+		step, err := s.validateTOTP(ctx, user, code)
+		if err != nil {
+			return err
+		}
+		return s.twoFactorRepo.UseStep(ctx, user.ID.String(), step)
+	}
+
+	if code == "" {
+		return model.ErrInvalidOTP
+	}
+	return s.twoFactorRepo.UseRecoveryCode(ctx, user.ID.String(), HashToken(code))
+}
+
+// validateTOTP checks a TOTP code against the secret of the user and returns its time step.
+func (s *twoFactorService) validateTOTP(ctx context.Context, user *model.User, code string) (int64, error) {
+	secret, err := decryptSecret(user.TOTPSecret, s.secretKey)
+	if err != nil {
+		logctx.From(ctx).Error("Failed to decrypt totp secret", "userID", user.ID, "error", err)
+		return 0, err
+	}
+
+	step, ok := totp.Validate(secret, normalizeOTP(code), time.Now(), totpSkew)
+	if !ok {
+		return 0, model.ErrInvalidOTP
+	}
+	return step, nil
+}
+
+// generateRecoveryCodes returns new recovery codes and their hashes for storage.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := GenerateToken(recoveryCodeBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeOTP drops the spaces authenticator apps show inside codes.
+func normalizeOTP(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	key := make([]byte, 32)
+
+	encrypted, err := encryptSecret("JBSWY3DPEHPK3PXP", key)
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "JBSWY3DPEHPK3PXP")
+
+	plain, err := decryptSecret(encrypted, key)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", plain)
+
+	other := make([]byte, 32)
+	other[0] = 1
+	_, err = decryptSecret(encrypted, other)
+	assert.Error(t, err, "wrong key")
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+
+	seen := map[string]bool{}
+	for i, code := range codes {
+		assert.False(t, seen[code], "codes are unique")
+		seen[code] = true
+		assert.Equal(t, HashToken(code), hashes[i])
+		assert.False(t, isTOTPCode(code), "a recovery code is never mistaken for a TOTP code")
+	}
+}
+
+func TestIsTOTPCode(t *testing.T) {
+	assert.True(t, isTOTPCode("012345"))
+	assert.True(t, isTOTPCode(normalizeOTP(" 012 345 ")))
+	assert.False(t, isTOTPCode("01234"))
+	assert.False(t, isTOTPCode("01234a"))
+	assert.False(t, isTOTPCode("0123456"))
+}
+
+// challengeRepo counts challenge attempts in memory like the atomic increment of the database.
+type challengeRepo struct {
+	repo.TwoFactorRepository
+	mu       sync.Mutex
+	attempts int
+	deleted  bool
+}
+
+func (r *challengeRepo) AddChallengeAttempt(ctx context.Context, id string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.deleted {
+		return 0, model.ErrChallengeNotFound
+	}
+	r.attempts++
+	return r.attempts, nil
+}
+
+func (r *challengeRepo) DeleteChallenge(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = true
+	return nil
+}
+
+func TestTwoFactorService_CompleteChallengeAttempts(t *testing.T) {
+	challengeRepo := &challengeRepo{}
+	s := &twoFactorService{twoFactorRepo: challengeRepo}
+	challenge := &model.LoginChallenge{ID: uuid.New()}
+
+	// Concurrent wrong codes each take their own attempt.
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*maxChallengeAttempts)
+	for i := 0; i < 2*maxChallengeAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.CompleteChallenge(context.Background(), challenge, "")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	invalid := 0
+	for err := range errs {
+		switch {
+		case errors.Is(err, model.ErrInvalidOTP):
+			invalid++
+		default:
+			assert.ErrorIs(t, err, model.ErrChallengeNotFound)
+		}
+	}
+	assert.Equal(t, maxChallengeAttempts, invalid, "only maxChallengeAttempts codes are verified")
+	assert.True(t, challengeRepo.deleted, "the exhausted challenge is dropped")
+}
@@ -2,20 +2,27 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log/slog"
+	"sync"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/repo"
 )
 
+// externalPasswordBytes is the entropy of the unknown password of provisioned users.
+const externalPasswordBytes = 32
+
 //go:generate go run github.com/vektra/mockery/v2@v2 --name=UserService --filename=user_service.go --output=../../mocks/
 type UserService interface {
 	Authenticate(ctx context.Context, username, password string) (*model.User, error)
 	CreateUser(ctx context.Context, username, password, role string) (*model.User, error)
+	Register(ctx context.Context, username, password, inviteCode string) (*model.User, error)
+	ProvisionExternalUser(ctx context.Context, username string, identity *model.ExternalIdentity) (*model.User, error)
 	GetUser(ctx context.Context, id string) (*model.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
 	UpdateUser(ctx context.Context, user *model.User) error
@@ -23,18 +30,42 @@ type UserService interface {
 	GetStorageUsage(ctx context.Context, userID string) (*model.StorageUsage, error)
 	UpdateStorageUsage(ctx context.Context, storageUsage *model.StorageUsage) error
 	GetStashByUserID(ctx context.Context, userID string) (*model.Stash, error)
+	RecomputeStash(ctx context.Context, userID string) (*model.Stash, error)
 }
 
 type userService struct {
-	userRepo repo.UserRepository
+	userRepo         repo.UserRepository
+	inviteRepo       repo.InviteRepository
+	identityRepo     repo.IdentityRepository
+	passwordPolicy   *PasswordPolicy
+	registrationMode string
 }
 
-func NewUserService(repo repo.UserRepository) UserService {
-	return &userService{userRepo: repo}
+// NewUserService creates a user service, registrationMode is one of the model.RegistrationMode values.
+func NewUserService(ur repo.UserRepository, ir repo.InviteRepository, idr repo.IdentityRepository, policy *PasswordPolicy, registrationMode string) UserService {
+	return &userService{
+		userRepo:         ur,
+		inviteRepo:       ir,
+		identityRepo:     idr,
+		passwordPolicy:   policy,
+		registrationMode: registrationMode,
+	}
 }
 
+// dummyPasswordHash is compared against when the username does not exist,
+// so unknown users take as long to reject as wrong passwords.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("mirai-box-dummy-password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(fmt.Sprintf("failed to hash dummy password: %v", err))
+	}
+	return hash
+})
+
+// Authenticate checks the credentials of a user. Unknown usernames and wrong
+// passwords both return model.ErrInvalidCredentials after a bcrypt comparison.
 func (s *userService) Authenticate(ctx context.Context, username, password string) (*model.User, error) {
-	logger := slog.With("method", "Authenticate", "username", username)
+	logger := logctx.With(ctx, "method", "Authenticate", "username", username)
 
 	if username == "" || password == "" {
 		logger.Warn("Invalid input parameters")
@@ -42,22 +73,32 @@ func (s *userService) Authenticate(ctx context.Context, username, password strin
 	}
 
 	user, err := s.userRepo.FindUserByUsername(ctx, username)
-	if err != nil {
+	if err != nil && !errors.Is(err, model.ErrUserNotFound) {
 		logger.Error("Failed to find user", "error", err)
 		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	hash := dummyPasswordHash()
+	if user != nil {
+		hash = []byte(user.Password)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || user == nil {
 		logger.Warn("Invalid credentials")
 		return nil, model.ErrInvalidCredentials
 	}
 
+	if user.Disabled {
+		logger.Warn("Login attempt for disabled account")
+		return nil, model.ErrAccountDisabled
+	}
+
 	logger.Info("User authenticated successfully")
 	return user, nil
 }
 
 func (s *userService) GetUser(ctx context.Context, id string) (*model.User, error) {
-	logger := slog.With("method", "GetUser", "userID", id)
+	logger := logctx.With(ctx, "method", "GetUser", "userID", id)
 
 	if id == "" {
 		logger.Warn("Invalid input: empty id")
@@ -75,7 +116,7 @@ func (s *userService) GetUser(ctx context.Context, id string) (*model.User, erro
 }
 
 func (s *userService) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
-	logger := slog.With("method", "GetUserByUsername", "username", username)
+	logger := logctx.With(ctx, "method", "GetUserByUsername", "username", username)
 
 	if username == "" {
 		logger.Warn("Invalid input: empty username")
@@ -93,13 +134,18 @@ func (s *userService) GetUserByUsername(ctx context.Context, username string) (*
 }
 
 func (s *userService) CreateUser(ctx context.Context, username, password, role string) (*model.User, error) {
-	logger := slog.With("method", "CreateUser", "username", username, "role", role)
+	logger := logctx.With(ctx, "method", "CreateUser", "username", username, "role", role)
 
 	if username == "" || password == "" || role == "" {
 		logger.Warn("Invalid input parameters")
 		return nil, model.ErrInvalidInput
 	}
 
+	if err := s.passwordPolicy.Validate(password); err != nil {
+		logger.Warn("Password rejected by policy", "error", err)
+		return nil, err
+	}
+
 	hashedPassword, err := HashPassword(password)
 	if err != nil {
 		logger.Error("Failed to hash password", "error", err)
@@ -127,8 +173,108 @@ func (s *userService) CreateUser(ctx context.Context, username, password, role s
 	return user, nil
 }
 
+// Register creates an account through self-registration. Self-registered users always
+// get the default role; in invite mode a valid unused invite code is required.
+func (s *userService) Register(ctx context.Context, username, password, inviteCode string) (*model.User, error) {
+	logger := logctx.With(ctx, "method", "Register", "username", username, "mode", s.registrationMode)
+
+	switch s.registrationMode {
+	case model.RegistrationModeOpen:
+		return s.CreateUser(ctx, username, password, model.RoleUser)
+	case model.RegistrationModeInvite:
+	default:
+		logger.Warn("Registration attempt while registration is closed")
+		return nil, model.ErrRegistrationClosed
+	}
+
+	if username == "" || password == "" {
+		logger.Warn("Invalid input parameters")
+		return nil, model.ErrInvalidInput
+	}
+
+	if inviteCode == "" {
+		logger.Warn("Registration attempt without invite code")
+		return nil, model.ErrInvalidInviteCode
+	}
+
+	if err := s.passwordPolicy.Validate(password); err != nil {
+		logger.Warn("Password rejected by policy", "error", err)
+		return nil, err
+	}
+
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		logger.Error("Failed to hash password", "error", err)
+		return nil, err
+	}
+
+	user := &model.User{
+		ID:       uuid.New(),
+		Username: username,
+		Password: hashedPassword,
+		Role:     model.RoleUser,
+	}
+
+	if err := s.inviteRepo.RedeemInvite(ctx, HashToken(inviteCode), user); err != nil {
+		logger.Error("Failed to register with invite", "error", err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := s.createStash(ctx, user.ID); err != nil {
+		logger.Error("Failed to create stash", "error", err)
+		return nil, model.ErrStashCreationFailed
+	}
+
+	logger.Info("User registered successfully", "userID", user.ID)
+	return user, nil
+}
+
+// ProvisionExternalUser creates a user signed in through an identity provider together with
+// its external identity. The account gets the default role and a random password, which the
+// user can replace through a password reset.
+func (s *userService) ProvisionExternalUser(ctx context.Context, username string, identity *model.ExternalIdentity) (*model.User, error) {
+	logger := logctx.With(ctx, "method", "ProvisionExternalUser", "username", username, "provider", identity.Provider)
+
+	if username == "" || identity.Provider == "" || identity.Subject == "" {
+		logger.Warn("Invalid input parameters")
+		return nil, model.ErrInvalidInput
+	}
+
+	password, err := GenerateToken(externalPasswordBytes)
+	if err != nil {
+		logger.Error("Failed to generate password", "error", err)
+		return nil, err
+	}
+
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		logger.Error("Failed to hash password", "error", err)
+		return nil, err
+	}
+
+	user := &model.User{
+		ID:       uuid.New(),
+		Username: username,
+		Password: hashedPassword,
+		Role:     model.RoleUser,
+	}
+
+	if err := s.identityRepo.CreateUserWithIdentity(ctx, user, identity); err != nil {
+		logger.Error("Failed to provision user", "error", err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := s.createStash(ctx, user.ID); err != nil {
+		logger.Error("Failed to create stash", "error", err)
+		return nil, model.ErrStashCreationFailed
+	}
+
+	logger.Info("User provisioned from external identity", "userID", user.ID)
+	return user, nil
+}
+
 func (s *userService) createStash(ctx context.Context, userID uuid.UUID) error {
-	logger := slog.With("method", "createStash", "userID", userID)
+	logger := logctx.With(ctx, "method", "createStash", "userID", userID)
 
 	stash := &model.Stash{
 		ID:     uuid.New(),
@@ -145,7 +291,7 @@ func (s *userService) createStash(ctx context.Context, userID uuid.UUID) error {
 }
 
 func (s *userService) UpdateUser(ctx context.Context, user *model.User) error {
-	logger := slog.With("method", "UpdateUser", "userID", user.ID)
+	logger := logctx.With(ctx, "method", "UpdateUser", "userID", user.ID)
 
 	if user.ID == uuid.Nil {
 		logger.Warn("Invalid input parameters")
@@ -158,29 +304,23 @@ func (s *userService) UpdateUser(ctx context.Context, user *model.User) error {
 		return err
 	}
 
+	// The role is left untouched, it can only be changed by administrators.
+	// The password is changed through PasswordService, which checks the current one.
 	existingUser.Username = user.Username
-	existingUser.Role = user.Role
-
-	if user.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-		if err != nil {
-			logger.Error("Failed to hash password", "error", err)
-			return fmt.Errorf("failed to hash password: %w", err)
-		}
-		existingUser.Password = string(hashedPassword)
-	}
 
 	if err := s.userRepo.UpdateUser(ctx, existingUser); err != nil {
 		logger.Error("Failed to update user", "error", err)
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
+	user.Role = existingUser.Role
+
 	logger.Info("User updated successfully")
 	return nil
 }
 
 func (s *userService) DeleteUser(ctx context.Context, id string) error {
-	logger := slog.With("method", "DeleteUser", "userID", id)
+	logger := logctx.With(ctx, "method", "DeleteUser", "userID", id)
 
 	if id == "" {
 		logger.Warn("Invalid input: empty id")
@@ -197,7 +337,7 @@ func (s *userService) DeleteUser(ctx context.Context, id string) error {
 }
 
 func (s *userService) GetStorageUsage(ctx context.Context, userID string) (*model.StorageUsage, error) {
-	logger := slog.With("method", "GetStorageUsage", "userID", userID)
+	logger := logctx.With(ctx, "method", "GetStorageUsage", "userID", userID)
 
 	if userID == "" {
 		logger.Warn("Invalid input: empty userID")
@@ -215,7 +355,7 @@ func (s *userService) GetStorageUsage(ctx context.Context, userID string) (*mode
 }
 
 func (s *userService) UpdateStorageUsage(ctx context.Context, storageUsage *model.StorageUsage) error {
-	logger := slog.With("method", "UpdateStorageUsage", "userID", storageUsage.UserID)
+	logger := logctx.With(ctx, "method", "UpdateStorageUsage", "userID", storageUsage.UserID)
 
 	if storageUsage.UserID == uuid.Nil {
 		logger.Warn("Invalid input parameters")
@@ -232,7 +372,7 @@ func (s *userService) UpdateStorageUsage(ctx context.Context, storageUsage *mode
 }
 
 func (s *userService) GetStashByUserID(ctx context.Context, userID string) (*model.Stash, error) {
-	logger := slog.With("method", "GetStashByUserID", "userID", userID)
+	logger := logctx.With(ctx, "method", "GetStashByUserID", "userID", userID)
 
 	if userID == "" {
 		logger.Warn("Invalid input: empty userID")
@@ -248,3 +388,22 @@ func (s *userService) GetStashByUserID(ctx context.Context, userID string) (*mod
 	logger.Info("Stash retrieved successfully")
 	return stash, nil
 }
+
+// RecomputeStash recounts the art projects, files and used space of the stash of a user.
+func (s *userService) RecomputeStash(ctx context.Context, userID string) (*model.Stash, error) {
+	logger := logctx.With(ctx, "method", "RecomputeStash", "userID", userID)
+
+	if userID == "" {
+		logger.Warn("Invalid input: empty userID")
+		return nil, model.ErrInvalidInput
+	}
+
+	stash, err := s.userRepo.RecomputeStash(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to recompute stash", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Stash recomputed", "artProjects", stash.ArtProjects, "files", stash.Files, "usedSpace", stash.UsedSpace)
+	return stash, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+func TestRegister_Modes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Closed", func(t *testing.T) {
+		s := NewUserService(nil, nil, nil, NewPasswordPolicy(8, nil), model.RegistrationModeClosed)
+		_, err := s.Register(ctx, "alice", "secret", "code")
+		assert.ErrorIs(t, err, model.ErrRegistrationClosed)
+	})
+
+	t.Run("Unknown mode is closed", func(t *testing.T) {
+		s := NewUserService(nil, nil, nil, NewPasswordPolicy(8, nil), "")
+		_, err := s.Register(ctx, "alice", "secret", "")
+		assert.ErrorIs(t, err, model.ErrRegistrationClosed)
+	})
+
+	t.Run("Invite requires a code", func(t *testing.T) {
+		s := NewUserService(nil, nil, nil, NewPasswordPolicy(8, nil), model.RegistrationModeInvite)
+		_, err := s.Register(ctx, "alice", "secret", "")
+		assert.ErrorIs(t, err, model.ErrInvalidInviteCode)
+	})
+}
+
+func TestRegister_PasswordPolicy(t *testing.T) {
+	s := NewUserService(nil, nil, nil, NewPasswordPolicy(8, nil), model.RegistrationModeInvite)
+	_, err := s.Register(context.Background(), "alice", "short", "code")
+	assert.ErrorIs(t, err, model.ErrWeakPassword)
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -70,3 +72,58 @@ func HashPassword(password string) (string, error) {
 	}
 	return string(bytes), nil
 }
+
+// GenerateToken returns a base58 encoded random token of n bytes.
+func GenerateToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return base58.Encode(b), nil
+}
+
+// HashToken returns the hex encoded SHA-256 of a random token, used to store
+// tokens without keeping the token itself.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// encryptSecret encrypts a value for storage with the application secret key.
+func encryptSecret(plain string, secretKey []byte) (string, error) {
+	aead, err := chacha20poly1305.NewX(secretKey)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base58.Encode(aead.Seal(nonce, nonce, []byte(plain), nil)), nil
+}
+
+// decryptSecret decrypts a value encrypted by encryptSecret.
+func decryptSecret(encoded string, secretKey []byte) (string, error) {
+	encrypted, err := base58.Decode(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+
+	aead, err := chacha20poly1305.NewX(secretKey)
+	if err != nil {
+		return "", err
+	}
+
+	if len(encrypted) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted value length")
+	}
+
+	nonce, ciphertext := encrypted[:aead.NonceSize()], encrypted[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
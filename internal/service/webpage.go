@@ -2,11 +2,12 @@ package service
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/mirai-box/mirai-box/internal/logctx"
 	"github.com/mirai-box/mirai-box/internal/model"
 	"github.com/mirai-box/mirai-box/internal/repo"
 )
@@ -34,7 +35,7 @@ func NewWebPageService(repo repo.WebPageRepository) WebPageService {
 
 // CreateWebPage creates a new web page
 func (s *webPageService) CreateWebPage(ctx context.Context, webPage *model.WebPage) (*model.WebPage, error) {
-	slog.InfoContext(ctx, "Creating new web page",
+	logctx.From(ctx).InfoContext(ctx, "Creating new web page",
 		"userID", webPage.UserID,
 		"title", webPage.Title,
 		"pageType", webPage.PageType,
@@ -45,99 +46,131 @@ func (s *webPageService) CreateWebPage(ctx context.Context, webPage *model.WebPa
 		UserID:    webPage.UserID,
 		Title:     webPage.Title,
 		Html:      webPage.Html,
+		Format:    webPage.Format,
+		Source:    webPage.Source,
 		PageType:  webPage.PageType,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
+	if err := renderWebPage(wp); err != nil {
+		logctx.From(ctx).ErrorContext(ctx, "Failed to render web page", "error", err, "format", wp.Format)
+		return nil, err
+	}
+
 	if err := s.repo.CreateWebPage(ctx, wp); err != nil {
-		slog.ErrorContext(ctx, "Failed to create web page", "error", err)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to create web page", "error", err)
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "Web page created successfully", "pageID", wp.ID)
+	logctx.From(ctx).InfoContext(ctx, "Web page created successfully", "pageID", wp.ID)
 	return wp, nil
 }
 
 // UpdateWebPage updates an existing web page
 func (s *webPageService) UpdateWebPage(ctx context.Context, webPage *model.WebPage) (*model.WebPage, error) {
-	slog.InfoContext(ctx, "Updating web page", "pageID", webPage.ID)
+	logctx.From(ctx).InfoContext(ctx, "Updating web page", "pageID", webPage.ID)
 
 	webPage.UpdatedAt = time.Now()
 
+	if err := renderWebPage(webPage); err != nil {
+		logctx.From(ctx).ErrorContext(ctx, "Failed to render web page", "error", err, "pageID", webPage.ID, "format", webPage.Format)
+		return nil, err
+	}
+
 	if err := s.repo.UpdateWebPage(ctx, webPage); err != nil {
-		slog.ErrorContext(ctx, "Failed to update web page", "error", err, "pageID", webPage.ID)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to update web page", "error", err, "pageID", webPage.ID)
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "Web page updated successfully", "pageID", webPage.ID)
+	logctx.From(ctx).InfoContext(ctx, "Web page updated successfully", "pageID", webPage.ID)
 	return webPage, nil
 }
 
 // DeleteWebPage deletes a web page by ID
 func (s *webPageService) DeleteWebPage(ctx context.Context, id string) error {
-	slog.InfoContext(ctx, "Deleting web page", "pageID", id)
+	logctx.From(ctx).InfoContext(ctx, "Deleting web page", "pageID", id)
 
 	if err := s.repo.DeleteWebPage(ctx, id); err != nil {
-		slog.ErrorContext(ctx, "Failed to delete web page", "error", err, "pageID", id)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to delete web page", "error", err, "pageID", id)
 		return err
 	}
 
-	slog.InfoContext(ctx, "Web page deleted successfully", "pageID", id)
+	logctx.From(ctx).InfoContext(ctx, "Web page deleted successfully", "pageID", id)
 	return nil
 }
 
 // GetWebPage retrieves a web page by ID
 func (s *webPageService) GetWebPage(ctx context.Context, id string) (*model.WebPage, error) {
-	slog.InfoContext(ctx, "Getting web page", "pageID", id)
+	logctx.From(ctx).InfoContext(ctx, "Getting web page", "pageID", id)
 
 	wp, err := s.repo.FindWebPageByID(ctx, id)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to get web page", "error", err, "pageID", id)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to get web page", "error", err, "pageID", id)
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "Web page retrieved successfully", "pageID", id)
+	logctx.From(ctx).InfoContext(ctx, "Web page retrieved successfully", "pageID", id)
 	return wp, nil
 }
 
 // ListWebPages retrieves all web pages
 func (s *webPageService) ListWebPages(ctx context.Context) ([]model.WebPage, error) {
-	slog.InfoContext(ctx, "Listing all web pages")
+	logctx.From(ctx).InfoContext(ctx, "Listing all web pages")
 
 	pages, err := s.repo.FindAllWebPages(ctx)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to list web pages", "error", err)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to list web pages", "error", err)
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "Web pages listed successfully", "count", len(pages))
+	logctx.From(ctx).InfoContext(ctx, "Web pages listed successfully", "count", len(pages))
 	return pages, nil
 }
 
 // ListWebPagesByType retrieves all web pages by type
 func (s *webPageService) ListWebPagesByType(ctx context.Context, pageType string) ([]model.WebPage, error) {
-	slog.InfoContext(ctx, "Listing web pages by type", "pageType", pageType)
+	logctx.From(ctx).InfoContext(ctx, "Listing web pages by type", "pageType", pageType)
 
 	pages, err := s.repo.FindWebPagesByType(ctx, pageType)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to list web pages by type", "error", err, "pageType", pageType)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to list web pages by type", "error", err, "pageType", pageType)
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "Web pages listed successfully", "pageType", pageType, "count", len(pages))
+	logctx.From(ctx).InfoContext(ctx, "Web pages listed successfully", "pageType", pageType, "count", len(pages))
 	return pages, nil
 }
 
 func (s *webPageService) ListUserWebPages(ctx context.Context, userID string) ([]model.WebPage, error) {
-	slog.InfoContext(ctx, "Listing web pages by user", "userID", userID)
+	logctx.From(ctx).InfoContext(ctx, "Listing web pages by user", "userID", userID)
 
 	pages, err := s.repo.FindWebPagesByUserID(ctx, userID)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to list web pages by user", "error", err, "userID", userID)
+		logctx.From(ctx).ErrorContext(ctx, "Failed to list web pages by user", "error", err, "userID", userID)
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "Web pages listed successfully", "userID", userID, "count", len(pages))
+	logctx.From(ctx).InfoContext(ctx, "Web pages listed successfully", "userID", userID, "count", len(pages))
 	return pages, nil
 }
+
+// renderWebPage fills the Html of a web page from its source according to the page format.
+// Markdown pages keep their source for editing, HTML pages are stored as submitted.
+func renderWebPage(webPage *model.WebPage) error {
+	switch webPage.Format {
+	case "", model.WebPageFormatHTML:
+		webPage.Format = model.WebPageFormatHTML
+		webPage.Source = webPage.Html
+	case model.WebPageFormatMarkdown:
+		html, err := RenderMarkdown(webPage.Source)
+		if err != nil {
+			return fmt.Errorf("failed to render markdown: %w", err)
+		}
+		webPage.Html = html
+	default:
+		return fmt.Errorf("%w: unsupported web page format %q", model.ErrInvalidInput, webPage.Format)
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+// Package sessionstore implements a gorilla/sessions store that keeps
+// sessions in Postgres so that they can be listed and revoked.
+package sessionstore
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+	"github.com/mr-tron/base58"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/middleware"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+const (
+	// touchInterval limits how often the last seen time of a session is written.
+	touchInterval = time.Minute
+
+	tokenBytes = 32
+)
+
+// Store is a sessions.Store backed by the sessions table. The cookie carries
+// a signed random token, the session values never leave the server.
+type Store struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options
+
+	sessionRepo repo.SessionRepository
+}
+
+var _ sessions.Store = (*Store)(nil)
+
+// NewStore creates a Store, keyPairs are used to sign the cookie and encode the values.
+// maxAge is the lifetime of sessions that do not set their own and the longest one the
+// cookie codecs accept, so it must cover every session lifetime.
+func NewStore(sr repo.SessionRepository, maxAge time.Duration, keyPairs ...[]byte) *Store {
+	s := &Store{
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   int(maxAge.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteNoneMode,
+			Secure:   true,
+		},
+		sessionRepo: sr,
+	}
+
+	s.MaxAge(s.Options.MaxAge)
+	return s
+}
+
+// Get returns a session for the given name after adding it to the registry.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the session stored for the request cookie, or a new session when
+// the cookie is missing, invalid, expired or revoked. Only database failures
+// are returned as errors.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	logger := logctx.With(r.Context(), "method", "Store.New")
+
+	var token string
+	if err := securecookie.DecodeMulti(name, cookie.Value, &token, s.Codecs...); err != nil {
+		logger.Debug("Invalid session cookie", "error", err)
+		return session, nil
+	}
+
+	record, err := s.sessionRepo.FindByTokenHash(r.Context(), service.HashToken(token))
+	if err != nil {
+		if errors.Is(err, model.ErrSessionNotFound) {
+			return session, nil
+		}
+		return session, err
+	}
+
+	if err := securecookie.DecodeMulti(name, record.Data, &session.Values, s.Codecs...); err != nil {
+		logger.Warn("Failed to decode session values", "error", err, "sessionID", record.ID)
+		return session, nil
+	}
+
+	if now := time.Now(); now.Sub(record.LastSeenAt) > touchInterval {
+		if err := s.sessionRepo.TouchSession(r.Context(), record.ID.String(), now); err != nil {
+			logger.Warn("Failed to update session last seen time", "error", err, "sessionID", record.ID)
+		}
+	}
+
+	session.ID = token
+	session.IsNew = false
+	return session, nil
+}
+
+// Save persists the session and sets the cookie. A session with a MaxAge <= 0
+// is deleted from the database and its cookie is cleared.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	ctx := r.Context()
+	logger := logctx.With(ctx, "method", "Store.Save")
+
+	if session.Options.MaxAge <= 0 {
+		if session.ID != "" {
+			if err := s.sessionRepo.DeleteByTokenHash(ctx, service.HashToken(session.ID)); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	isNew := session.ID == ""
+	if isNew {
+		session.ID = base58.Encode(securecookie.GenerateRandomKey(tokenBytes))
+
+		// New sessions are rare enough to piggyback the cleanup of expired ones.
+		if _, err := s.sessionRepo.DeleteExpired(ctx); err != nil {
+			logger.Warn("Failed to delete expired sessions", "error", err)
+		}
+	}
+
+	data, err := securecookie.EncodeMulti(session.Name(), session.Values, s.Codecs...)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record := &model.Session{
+		ID:         uuid.New(),
+		TokenHash:  service.HashToken(session.ID),
+		Data:       data,
+		UserAgent:  truncate(r.UserAgent(), 512),
+		IP:         middleware.ClientIP(r),
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Duration(session.Options.MaxAge) * time.Second),
+	}
+	if userID, ok := session.Values[model.SessionUserIDKey].(string); ok {
+		if id, err := uuid.Parse(userID); err == nil {
+			record.UserID = &id
+		}
+	}
+
+	if isNew {
+		if err := s.sessionRepo.CreateSession(ctx, record); err != nil {
+			return err
+		}
+	} else if err := s.sessionRepo.UpdateSession(ctx, record); err != nil {
+		if !errors.Is(err, model.ErrSessionNotFound) {
+			return err
+		}
+
+		// The session was revoked during the request, saving it must not sign it in again.
+		logger.Info("Not saving revoked session")
+		opts := *session.Options
+		opts.MaxAge = -1
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", &opts))
+		return nil
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// MaxAge sets the maximum age for the store and the underlying cookie codecs.
+func (s *Store) MaxAge(age int) {
+	s.Options.MaxAge = age
+
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,235 @@
+package sessionstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+)
+
+const testMaxAge = 7 * 24 * time.Hour
+
+// memoryRepo is an in-memory repo.SessionRepository keyed by token hash.
+type memoryRepo struct {
+	mu       sync.Mutex
+	sessions map[string]model.Session
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{sessions: map[string]model.Session{}}
+}
+
+func (m *memoryRepo) CreateSession(ctx context.Context, session *model.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.TokenHash] = *session
+	return nil
+}
+
+func (m *memoryRepo) UpdateSession(ctx context.Context, session *model.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.sessions[session.TokenHash]
+	if !ok {
+		return model.ErrSessionNotFound
+	}
+	existing.UserID = session.UserID
+	existing.Data = session.Data
+	existing.LastSeenAt = session.LastSeenAt
+	existing.ExpiresAt = session.ExpiresAt
+	m.sessions[session.TokenHash] = existing
+	return nil
+}
+
+func (m *memoryRepo) FindByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[tokenHash]
+	if !ok || !session.ExpiresAt.After(time.Now()) {
+		return nil, model.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *memoryRepo) TouchSession(ctx context.Context, id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (m *memoryRepo) ListByUser(ctx context.Context, userID string) ([]model.Session, error) {
+	return nil, nil
+}
+
+func (m *memoryRepo) DeleteSession(ctx context.Context, userID, id string) error {
+	return nil
+}
+
+func (m *memoryRepo) DeleteByTokenHash(ctx context.Context, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *memoryRepo) DeleteByUser(ctx context.Context, userID, exceptTokenHash string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var count int64
+	for hash, session := range m.sessions {
+		if session.UserID != nil && session.UserID.String() == userID && hash != exceptTokenHash {
+			delete(m.sessions, hash)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// login saves a session for userID and returns the session cookie.
+func login(t *testing.T, store *Store, userID uuid.UUID) *http.Cookie {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	rec := httptest.NewRecorder()
+
+	session, err := store.New(req, model.SessionCookieName)
+	require.NoError(t, err)
+	session.Values[model.SessionUserIDKey] = userID.String()
+	require.NoError(t, session.Save(req, rec))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	return cookies[0]
+}
+
+func load(t *testing.T, store *Store, cookie *http.Cookie) (string, bool) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+
+	session, err := store.New(req, model.SessionCookieName)
+	require.NoError(t, err)
+
+	userID, ok := session.Values[model.SessionUserIDKey].(string)
+	return userID, ok && !session.IsNew
+}
+
+func TestStore_RoundTrip(t *testing.T) {
+	sessions := newMemoryRepo()
+	store := NewStore(sessions, testMaxAge, []byte("test-secret"))
+	userID := uuid.New()
+
+	cookie := login(t, store, userID)
+
+	got, ok := load(t, store, cookie)
+	assert.True(t, ok)
+	assert.Equal(t, userID.String(), got)
+
+	require.Len(t, sessions.sessions, 1)
+	for _, record := range sessions.sessions {
+		assert.Equal(t, "test-agent", record.UserAgent)
+		assert.Equal(t, userID, *record.UserID)
+		assert.NotContains(t, record.Data, userID.String(), "values are encoded")
+	}
+}
+
+func TestStore_MaxAge(t *testing.T) {
+	sessions := newMemoryRepo()
+	maxAge := 90 * 24 * time.Hour
+	store := NewStore(sessions, maxAge, []byte("test-secret"))
+
+	assert.Equal(t, int(maxAge.Seconds()), store.Options.MaxAge, "sessions default to the longest lifetime")
+
+	cookie := login(t, store, uuid.New())
+	assert.Equal(t, int(maxAge.Seconds()), cookie.MaxAge)
+	for _, record := range sessions.sessions {
+		assert.WithinDuration(t, time.Now().Add(maxAge), record.ExpiresAt, time.Minute)
+	}
+}
+
+func TestStore_Revoked(t *testing.T) {
+	sessions := newMemoryRepo()
+	store := NewStore(sessions, testMaxAge, []byte("test-secret"))
+	userID := uuid.New()
+
+	cookie := login(t, store, userID)
+
+	_, err := sessions.DeleteByUser(context.Background(), userID.String(), "")
+	require.NoError(t, err)
+
+	_, ok := load(t, store, cookie)
+	assert.False(t, ok, "revoked session is replaced by a new one")
+}
+
+func TestStore_SaveRevoked(t *testing.T) {
+	sessions := newMemoryRepo()
+	store := NewStore(sessions, testMaxAge, []byte("test-secret"))
+	userID := uuid.New()
+
+	cookie := login(t, store, userID)
+
+	req := httptest.NewRequest(http.MethodPost, "/self/password", nil)
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+
+	session, err := store.New(req, model.SessionCookieName)
+	require.NoError(t, err)
+
+	_, err = sessions.DeleteByUser(context.Background(), userID.String(), "")
+	require.NoError(t, err)
+
+	session.Values["flash"] = "saved after the revocation"
+	require.NoError(t, session.Save(req, rec))
+
+	assert.Empty(t, sessions.sessions, "saving does not bring the session back")
+	require.Len(t, rec.Result().Cookies(), 1)
+	assert.Less(t, rec.Result().Cookies()[0].MaxAge, 0)
+
+	_, ok := load(t, store, cookie)
+	assert.False(t, ok)
+}
+
+func TestStore_Delete(t *testing.T) {
+	sessions := newMemoryRepo()
+	store := NewStore(sessions, testMaxAge, []byte("test-secret"))
+
+	cookie := login(t, store, uuid.New())
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+
+	session, err := store.New(req, model.SessionCookieName)
+	require.NoError(t, err)
+	session.Options.MaxAge = -1
+	require.NoError(t, session.Save(req, rec))
+
+	assert.Empty(t, sessions.sessions)
+	require.Len(t, rec.Result().Cookies(), 1)
+	assert.Less(t, rec.Result().Cookies()[0].MaxAge, 0)
+
+	_, ok := load(t, store, cookie)
+	assert.False(t, ok)
+}
+
+func TestStore_TamperedCookie(t *testing.T) {
+	store := NewStore(newMemoryRepo(), testMaxAge, []byte("test-secret"))
+
+	cookie := login(t, store, uuid.New())
+	cookie.Value = "x" + cookie.Value
+
+	_, ok := load(t, store, cookie)
+	assert.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the base32 encoding of the RFC 6238 SHA1 test key "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode_RFC6238(t *testing.T) {
+	// The RFC lists eight digit codes, these are their last six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "time: %d", tt.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	step := Step(at)
+
+	previous, err := Code(rfcSecret, step-1)
+	require.NoError(t, err)
+
+	matched, ok := Validate(rfcSecret, "081804", at, 1)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	matched, ok = Validate(rfcSecret, previous, at, 1)
+	assert.True(t, ok, "one step of drift is accepted")
+	assert.Equal(t, step-1, matched)
+
+	_, ok = Validate(rfcSecret, previous, at, 0)
+	assert.False(t, ok, "no drift allowed")
+
+	_, ok = Validate(rfcSecret, "000000", at, 1)
+	assert.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "81804", at, 1)
+	assert.False(t, ok, "short code")
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	_, err = Code(secret, 1)
+	assert.NoError(t, err)
+
+	uri := URI("Mirai Box", "alice", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Mirai%20Box:alice?"), uri)
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=Mirai+Box")
+}
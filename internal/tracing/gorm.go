@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey keeps the span of a statement between the before and after callbacks.
+const spanKey = "tracing:span"
+
+// gormPlugin creates a client span for every statement, as child of the span in the
+// context the repository passes with WithContext.
+type gormPlugin struct{}
+
+// GormPlugin returns the gorm plugin tracing database statements, install it with db.Use.
+func GormPlugin() gorm.Plugin {
+	return gormPlugin{}
+}
+
+func (gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", startStatement("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", endStatement),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", startStatement("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", endStatement),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", startStatement("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", endStatement),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startStatement("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", endStatement),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", startStatement("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", endStatement),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startStatement("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", endStatement),
+	)
+}
+
+func startStatement(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			return
+		}
+
+		_, span := Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func endStatement(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBSQLTable(db.Statement.Table))
+	}
+	span.SetAttributes(semconv.DBStatement(db.Statement.SQL.String()))
+
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
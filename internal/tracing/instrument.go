@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/repo"
+	"github.com/mirai-box/mirai-box/internal/service"
+)
+
+// InstrumentArtProjects starts a span for every call of s, the context passed on
+// carries it to the repositories.
+func InstrumentArtProjects(s service.ArtProjectService) service.ArtProjectService {
+	return &artProjectService{next: s}
+}
+
+// InstrumentFileStorage starts a span for storing and reading revision files of r.
+func InstrumentFileStorage(r repo.FileStorageRepository) repo.FileStorageRepository {
+	return &fileStorageRepo{FileStorageRepository: r}
+}
+
+type artProjectService struct {
+	next service.ArtProjectService
+}
+
+func (s *artProjectService) CreateArtProject(ctx context.Context, artProject *model.ArtProject) (err error) {
+	ctx, span := Start(ctx, "ArtProjectService.CreateArtProject")
+	defer func() { End(span, err) }()
+	return s.next.CreateArtProject(ctx, artProject)
+}
+
+func (s *artProjectService) GetArtProject(ctx context.Context, id string) (_ *model.ArtProject, err error) {
+	ctx, span := Start(ctx, "ArtProjectService.GetArtProject")
+	defer func() { End(span, err) }()
+	return s.next.GetArtProject(ctx, id)
+}
+
+func (s *artProjectService) DeleteArtProject(ctx context.Context, id string) (err error) {
+	ctx, span := Start(ctx, "ArtProjectService.DeleteArtProject")
+	defer func() { End(span, err) }()
+	return s.next.DeleteArtProject(ctx, id)
+}
+
+func (s *artProjectService) ListArtProjects(ctx context.Context, userID string) (_ []model.ArtProject, err error) {
+	ctx, span := Start(ctx, "ArtProjectService.ListArtProjects")
+	defer func() { End(span, err) }()
+	return s.next.ListArtProjects(ctx, userID)
+}
+
+func (s *artProjectService) AddRevision(ctx context.Context, revision *model.Revision, fileData io.Reader) (err error) {
+	ctx, span := Start(ctx, "ArtProjectService.AddRevision")
+	defer func() {
+		span.SetAttributes(attribute.Int64("revision.size", revision.Size))
+		End(span, err)
+	}()
+	return s.next.AddRevision(ctx, revision, fileData)
+}
+
+func (s *artProjectService) GetLatestRevision(ctx context.Context, artProjectID string) (_ *model.Revision, err error) {
+	ctx, span := Start(ctx, "ArtProjectService.GetLatestRevision")
+	defer func() { End(span, err) }()
+	return s.next.GetLatestRevision(ctx, artProjectID)
+}
+
+func (s *artProjectService) ListRevisions(ctx context.Context, artProjectID string) (_ []model.Revision, err error) {
+	ctx, span := Start(ctx, "ArtProjectService.ListRevisions")
+	defer func() { End(span, err) }()
+	return s.next.ListRevisions(ctx, artProjectID)
+}
+
+func (s *artProjectService) FindByID(ctx context.Context, id string) (_ *model.ArtProject, err error) {
+	ctx, span := Start(ctx, "ArtProjectService.FindByID")
+	defer func() { End(span, err) }()
+	return s.next.FindByID(ctx, id)
+}
+
+func (s *artProjectService) FindByUserID(ctx context.Context, userID string) (_ []model.ArtProject, err error) {
+	ctx, span := Start(ctx, "ArtProjectService.FindByUserID")
+	defer func() { End(span, err) }()
+	return s.next.FindByUserID(ctx, userID)
+}
+
+func (s *artProjectService) GetRevisionByArtID(ctx context.Context, artID string) (_ *model.Revision, err error) {
+	ctx, span := Start(ctx, "ArtProjectService.GetRevisionByArtID")
+	defer func() { End(span, err) }()
+	return s.next.GetRevisionByArtID(ctx, artID)
+}
+
+func (s *artProjectService) GetArtProjectByRevision(ctx context.Context, userID, artProjectID, revisionID string) (_ io.ReadCloser, _ *model.ArtProject, err error) {
+	ctx, span := Start(ctx, "ArtProjectService.GetArtProjectByRevision")
+	defer func() { End(span, err) }()
+	return s.next.GetArtProjectByRevision(ctx, userID, artProjectID, revisionID)
+}
+
+// fileStorageRepo traces revision files, the stash lookup is traced by the gorm plugin.
+type fileStorageRepo struct {
+	repo.FileStorageRepository
+}
+
+func (r *fileStorageRepo) SaveRevisionFile(ctx context.Context, fileData io.Reader, userID, artProjectID string, version int) (_ string, _ os.FileInfo, err error) {
+	ctx, span := Start(ctx, "FileStorage.SaveRevisionFile", withFile(artProjectID, version))
+	defer func() { End(span, err) }()
+	return r.FileStorageRepository.SaveRevisionFile(ctx, fileData, userID, artProjectID, version)
+}
+
+func (r *fileStorageRepo) GetRevisionFile(ctx context.Context, userID, artProjectID string, version int) (_ io.ReadCloser, err error) {
+	ctx, span := Start(ctx, "FileStorage.GetRevisionFile", withFile(artProjectID, version))
+	defer func() { End(span, err) }()
+	return r.FileStorageRepository.GetRevisionFile(ctx, userID, artProjectID, version)
+}
+
+// withFile sets the attributes of a revision file on a span.
+func withFile(artProjectID string, version int) trace.SpanStartOption {
+	return trace.WithAttributes(
+		attribute.String("art_project.id", artProjectID),
+		attribute.Int("revision.version", version),
+	)
+}
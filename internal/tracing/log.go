@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// logHandler adds the trace and span ID of the context to log records.
+type logHandler struct {
+	slog.Handler
+	// bound is set once the logger carries a trace_id, like the request logger of
+	// Middleware, so that records do not get the IDs twice.
+	bound bool
+}
+
+// NewLogHandler wraps h so that records logged with a context, like logger.InfoContext,
+// carry the trace_id and span_id of the span in that context. Request loggers get the
+// IDs from Middleware.
+func NewLogHandler(h slog.Handler) slog.Handler {
+	return logHandler{Handler: h}
+}
+
+func (h logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() && !h.bound {
+		record.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	bound := h.bound
+	for _, attr := range attrs {
+		bound = bound || attr.Key == "trace_id"
+	}
+	return logHandler{Handler: h.Handler.WithAttrs(attrs), bound: bound}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{Handler: h.Handler.WithGroup(name), bound: h.bound}
+}
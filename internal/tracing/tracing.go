@@ -0,0 +1,71 @@
+// Package tracing sets up OpenTelemetry tracing and instruments the HTTP routes, the
+// gorm repositories, the art project service and the file storage with spans.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mirai-box/mirai-box/internal/buildinfo"
+	"github.com/mirai-box/mirai-box/internal/config"
+)
+
+const (
+	instrumentationName = "github.com/mirai-box/mirai-box"
+	serviceName         = "miraibox"
+)
+
+// Setup installs the W3C trace context propagator and, when an endpoint is configured,
+// a tracer provider exporting spans over OTLP/HTTP. The returned function flushes and
+// stops the exporter. Without an endpoint spans are not recorded.
+func Setup(ctx context.Context, conf *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if conf.TracingEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// Headers like credentials of the collector are read from OTEL_EXPORTER_OTLP_HEADERS.
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(conf.TracingEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(buildinfo.Get().Version),
+		semconv.DeploymentEnvironment(conf.Stage),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,179 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/mirai-box/mirai-box/internal/logctx"
+	"github.com/mirai-box/mirai-box/internal/model"
+	"github.com/mirai-box/mirai-box/internal/tracing"
+	"github.com/mirai-box/mirai-box/mocks"
+)
+
+// setupExporter records the spans of the test in memory.
+func setupExporter(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+	return exporter
+}
+
+// findSpan returns the recorded span with the given name.
+func findSpan(t *testing.T, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not recorded", "no span %q in %v", name, exporter.GetSpans())
+	return tracetest.SpanStub{}
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := setupExporter(t)
+
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Get("/art/{artID}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.Start(r.Context(), "child")
+		span.End()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/art/a1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	server := findSpan(t, exporter, "GET /art/{artID}")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String(), "continues the trace of the caller")
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, codes.Error, server.Status.Code)
+
+	child := findSpan(t, exporter, "child")
+	assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+}
+
+func TestGormPlugin(t *testing.T) {
+	exporter := setupExporter(t)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tracing.GormPlugin()))
+
+	ctx, parent := tracing.Start(context.Background(), "repository")
+	var users []model.User
+	require.NoError(t, db.WithContext(ctx).Where("role = ?", model.RoleAdmin).Find(&users).Error)
+	parent.End()
+
+	query := findSpan(t, exporter, "gorm.query")
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind)
+
+	attrs := map[string]string{}
+	for _, attr := range query.Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "users", attrs["db.sql.table"])
+	assert.Contains(t, attrs["db.statement"], `FROM "users" WHERE role = $1`)
+}
+
+func TestInstrumentArtProjects(t *testing.T) {
+	exporter := setupExporter(t)
+
+	mockService := mocks.NewArtProjectService(t)
+	s := tracing.InstrumentArtProjects(mockService)
+
+	mockService.On("AddRevision", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assert.True(t, trace.SpanContextFromContext(args.Get(0).(context.Context)).IsValid(), "the span is passed on")
+	}).Return(errors.New("disk full")).Once()
+
+	err := s.AddRevision(context.Background(), &model.Revision{}, strings.NewReader("picture"))
+	require.Error(t, err)
+
+	span := findSpan(t, exporter, "ArtProjectService.AddRevision")
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Equal(t, "disk full", span.Status.Description)
+}
+
+func TestLogHandler(t *testing.T) {
+	setupExporter(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("method", "Test")
+
+	ctx, span := tracing.Start(context.Background(), "request")
+	logger.InfoContext(ctx, "With span")
+	span.End()
+
+	var record map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+	assert.Equal(t, "Test", record["method"])
+
+	buf.Reset()
+	logger.Info("Without span")
+	assert.NotContains(t, buf.String(), "trace_id")
+}
+
+func TestMiddleware_RequestLogger(t *testing.T) {
+	setupExporter(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(logctx.NewContext(r.Context(), logger)))
+		})
+	})
+	r.Use(tracing.Middleware)
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		logctx.From(r.Context()).Info("Without context")
+		logctx.From(r.Context()).InfoContext(r.Context(), "With context")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Equal(t, 1, strings.Count(line, `"trace_id"`), line)
+
+		var record map[string]string
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+		assert.NotEmpty(t, record["span_id"])
+	}
+}
@@ -14,17 +14,47 @@ type CollectionService struct {
 	mock.Mock
 }
 
-// AddRevisionToCollection provides a mock function with given fields: ctx, collectionID, revisionID
-func (_m *CollectionService) AddRevisionToCollection(ctx context.Context, collectionID string, revisionID string) error {
-	ret := _m.Called(ctx, collectionID, revisionID)
+// AcceptInvitation provides a mock function with given fields: ctx, userID, collectionID
+func (_m *CollectionService) AcceptInvitation(ctx context.Context, userID string, collectionID string) (*model.CollectionMember, error) {
+	ret := _m.Called(ctx, userID, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptInvitation")
+	}
+
+	var r0 *model.CollectionMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.CollectionMember, error)); ok {
+		return rf(ctx, userID, collectionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.CollectionMember); ok {
+		r0 = rf(ctx, userID, collectionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CollectionMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, collectionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddRevisionToCollection provides a mock function with given fields: ctx, userID, collectionID, revisionID, caption
+func (_m *CollectionService) AddRevisionToCollection(ctx context.Context, userID string, collectionID string, revisionID string, caption string) error {
+	ret := _m.Called(ctx, userID, collectionID, revisionID, caption)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AddRevisionToCollection")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, collectionID, revisionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(ctx, userID, collectionID, revisionID, caption)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -32,9 +62,9 @@ func (_m *CollectionService) AddRevisionToCollection(ctx context.Context, collec
 	return r0
 }
 
-// CreateCollection provides a mock function with given fields: ctx, userID, title
-func (_m *CollectionService) CreateCollection(ctx context.Context, userID string, title string) (*model.Collection, error) {
-	ret := _m.Called(ctx, userID, title)
+// CreateCollection provides a mock function with given fields: ctx, userID, req
+func (_m *CollectionService) CreateCollection(ctx context.Context, userID string, req model.CollectionRequest) (*model.Collection, error) {
+	ret := _m.Called(ctx, userID, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateCollection")
@@ -42,19 +72,19 @@ func (_m *CollectionService) CreateCollection(ctx context.Context, userID string
 
 	var r0 *model.Collection
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Collection, error)); ok {
-		return rf(ctx, userID, title)
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.CollectionRequest) (*model.Collection, error)); ok {
+		return rf(ctx, userID, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Collection); ok {
-		r0 = rf(ctx, userID, title)
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.CollectionRequest) *model.Collection); ok {
+		r0 = rf(ctx, userID, req)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Collection)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, userID, title)
+	if rf, ok := ret.Get(1).(func(context.Context, string, model.CollectionRequest) error); ok {
+		r1 = rf(ctx, userID, req)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -62,17 +92,17 @@ func (_m *CollectionService) CreateCollection(ctx context.Context, userID string
 	return r0, r1
 }
 
-// DeleteCollection provides a mock function with given fields: ctx, id
-func (_m *CollectionService) DeleteCollection(ctx context.Context, id string) error {
-	ret := _m.Called(ctx, id)
+// DeleteCollection provides a mock function with given fields: ctx, userID, id
+func (_m *CollectionService) DeleteCollection(ctx context.Context, userID string, id string) error {
+	ret := _m.Called(ctx, userID, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteCollection")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -110,6 +140,36 @@ func (_m *CollectionService) FindByID(ctx context.Context, id string) (*model.Co
 	return r0, r1
 }
 
+// FindByPublicID provides a mock function with given fields: ctx, collectionPublicID
+func (_m *CollectionService) FindByPublicID(ctx context.Context, collectionPublicID string) (*model.Collection, error) {
+	ret := _m.Called(ctx, collectionPublicID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByPublicID")
+	}
+
+	var r0 *model.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Collection, error)); ok {
+		return rf(ctx, collectionPublicID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Collection); ok {
+		r0 = rf(ctx, collectionPublicID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Collection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, collectionPublicID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByUserID provides a mock function with given fields: ctx, userID
 func (_m *CollectionService) FindByUserID(ctx context.Context, userID string) ([]model.Collection, error) {
 	ret := _m.Called(ctx, userID)
@@ -140,29 +200,59 @@ func (_m *CollectionService) FindByUserID(ctx context.Context, userID string) ([
 	return r0, r1
 }
 
-// GetRevisionsByCollectionID provides a mock function with given fields: ctx, collectionID
-func (_m *CollectionService) GetRevisionsByCollectionID(ctx context.Context, collectionID string) ([]model.Revision, error) {
-	ret := _m.Called(ctx, collectionID)
+// GetCollection provides a mock function with given fields: ctx, userID, id
+func (_m *CollectionService) GetCollection(ctx context.Context, userID string, id string) (*model.Collection, error) {
+	ret := _m.Called(ctx, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollection")
+	}
+
+	var r0 *model.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Collection, error)); ok {
+		return rf(ctx, userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Collection); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Collection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRevisionsByCollectionID provides a mock function with given fields: ctx, userID, collectionID
+func (_m *CollectionService) GetRevisionsByCollectionID(ctx context.Context, userID string, collectionID string) ([]model.CollectionArtProject, error) {
+	ret := _m.Called(ctx, userID, collectionID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetRevisionsByCollectionID")
 	}
 
-	var r0 []model.Revision
+	var r0 []model.CollectionArtProject
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]model.Revision, error)); ok {
-		return rf(ctx, collectionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]model.CollectionArtProject, error)); ok {
+		return rf(ctx, userID, collectionID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []model.Revision); ok {
-		r0 = rf(ctx, collectionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []model.CollectionArtProject); ok {
+		r0 = rf(ctx, userID, collectionID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Revision)
+			r0 = ret.Get(0).([]model.CollectionArtProject)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, collectionID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, collectionID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -171,23 +261,23 @@ func (_m *CollectionService) GetRevisionsByCollectionID(ctx context.Context, col
 }
 
 // GetRevisionsByPublicCollectionID provides a mock function with given fields: ctx, collectionPublicID
-func (_m *CollectionService) GetRevisionsByPublicCollectionID(ctx context.Context, collectionPublicID string) ([]model.Revision, error) {
+func (_m *CollectionService) GetRevisionsByPublicCollectionID(ctx context.Context, collectionPublicID string) ([]model.CollectionArtProject, error) {
 	ret := _m.Called(ctx, collectionPublicID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetRevisionsByPublicCollectionID")
 	}
 
-	var r0 []model.Revision
+	var r0 []model.CollectionArtProject
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]model.Revision, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]model.CollectionArtProject, error)); ok {
 		return rf(ctx, collectionPublicID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []model.Revision); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.CollectionArtProject); ok {
 		r0 = rf(ctx, collectionPublicID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Revision)
+			r0 = ret.Get(0).([]model.CollectionArtProject)
 		}
 	}
 
@@ -200,17 +290,125 @@ func (_m *CollectionService) GetRevisionsByPublicCollectionID(ctx context.Contex
 	return r0, r1
 }
 
-// RemoveRevisionFromCollection provides a mock function with given fields: ctx, collectionID, revisionID
-func (_m *CollectionService) RemoveRevisionFromCollection(ctx context.Context, collectionID string, revisionID string) error {
-	ret := _m.Called(ctx, collectionID, revisionID)
+// InviteMember provides a mock function with given fields: ctx, userID, collectionID, req
+func (_m *CollectionService) InviteMember(ctx context.Context, userID string, collectionID string, req model.CollectionMemberRequest) (*model.CollectionMember, error) {
+	ret := _m.Called(ctx, userID, collectionID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InviteMember")
+	}
+
+	var r0 *model.CollectionMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.CollectionMemberRequest) (*model.CollectionMember, error)); ok {
+		return rf(ctx, userID, collectionID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.CollectionMemberRequest) *model.CollectionMember); ok {
+		r0 = rf(ctx, userID, collectionID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CollectionMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, model.CollectionMemberRequest) error); ok {
+		r1 = rf(ctx, userID, collectionID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListInvitations provides a mock function with given fields: ctx, userID
+func (_m *CollectionService) ListInvitations(ctx context.Context, userID string) ([]model.CollectionMember, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListInvitations")
+	}
+
+	var r0 []model.CollectionMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]model.CollectionMember, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.CollectionMember); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.CollectionMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMembers provides a mock function with given fields: ctx, userID, collectionID
+func (_m *CollectionService) ListMembers(ctx context.Context, userID string, collectionID string) ([]model.CollectionMember, error) {
+	ret := _m.Called(ctx, userID, collectionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMembers")
+	}
+
+	var r0 []model.CollectionMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]model.CollectionMember, error)); ok {
+		return rf(ctx, userID, collectionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []model.CollectionMember); ok {
+		r0 = rf(ctx, userID, collectionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.CollectionMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, collectionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: ctx, userID, collectionID, memberID
+func (_m *CollectionService) RemoveMember(ctx context.Context, userID string, collectionID string, memberID string) error {
+	ret := _m.Called(ctx, userID, collectionID, memberID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, collectionID, memberID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveRevisionFromCollection provides a mock function with given fields: ctx, userID, collectionID, revisionID
+func (_m *CollectionService) RemoveRevisionFromCollection(ctx context.Context, userID string, collectionID string, revisionID string) error {
+	ret := _m.Called(ctx, userID, collectionID, revisionID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RemoveRevisionFromCollection")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, collectionID, revisionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, collectionID, revisionID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -218,17 +416,95 @@ func (_m *CollectionService) RemoveRevisionFromCollection(ctx context.Context, c
 	return r0
 }
 
-// UpdateCollection provides a mock function with given fields: ctx, collection
-func (_m *CollectionService) UpdateCollection(ctx context.Context, collection *model.Collection) error {
-	ret := _m.Called(ctx, collection)
+// ReorderRevisions provides a mock function with given fields: ctx, userID, collectionID, revisionIDs
+func (_m *CollectionService) ReorderRevisions(ctx context.Context, userID string, collectionID string, revisionIDs []string) error {
+	ret := _m.Called(ctx, userID, collectionID, revisionIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReorderRevisions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) error); ok {
+		r0 = rf(ctx, userID, collectionID, revisionIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCollection provides a mock function with given fields: ctx, userID, id, req
+func (_m *CollectionService) UpdateCollection(ctx context.Context, userID string, id string, req model.CollectionRequest) (*model.Collection, error) {
+	ret := _m.Called(ctx, userID, id, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateCollection")
 	}
 
+	var r0 *model.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.CollectionRequest) (*model.Collection, error)); ok {
+		return rf(ctx, userID, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.CollectionRequest) *model.Collection); ok {
+		r0 = rf(ctx, userID, id, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Collection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, model.CollectionRequest) error); ok {
+		r1 = rf(ctx, userID, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateMemberRole provides a mock function with given fields: ctx, userID, collectionID, memberID, role
+func (_m *CollectionService) UpdateMemberRole(ctx context.Context, userID string, collectionID string, memberID string, role string) (*model.CollectionMember, error) {
+	ret := _m.Called(ctx, userID, collectionID, memberID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMemberRole")
+	}
+
+	var r0 *model.CollectionMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) (*model.CollectionMember, error)); ok {
+		return rf(ctx, userID, collectionID, memberID, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) *model.CollectionMember); ok {
+		r0 = rf(ctx, userID, collectionID, memberID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CollectionMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, userID, collectionID, memberID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateRevisionCaption provides a mock function with given fields: ctx, userID, collectionID, revisionID, caption
+func (_m *CollectionService) UpdateRevisionCaption(ctx context.Context, userID string, collectionID string, revisionID string, caption string) error {
+	ret := _m.Called(ctx, userID, collectionID, revisionID, caption)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRevisionCaption")
+	}
+
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *model.Collection) error); ok {
-		r0 = rf(ctx, collection)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(ctx, userID, collectionID, revisionID, caption)
 	} else {
 		r0 = ret.Error(0)
 	}
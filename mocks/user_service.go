@@ -212,6 +212,96 @@ func (_m *UserService) GetUserByUsername(ctx context.Context, username string) (
 	return r0, r1
 }
 
+// ProvisionExternalUser provides a mock function with given fields: ctx, username, identity
+func (_m *UserService) ProvisionExternalUser(ctx context.Context, username string, identity *model.ExternalIdentity) (*model.User, error) {
+	ret := _m.Called(ctx, username, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProvisionExternalUser")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.ExternalIdentity) (*model.User, error)); ok {
+		return rf(ctx, username, identity)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.ExternalIdentity) *model.User); ok {
+		r0 = rf(ctx, username, identity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.ExternalIdentity) error); ok {
+		r1 = rf(ctx, username, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecomputeStash provides a mock function with given fields: ctx, userID
+func (_m *UserService) RecomputeStash(ctx context.Context, userID string) (*model.Stash, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecomputeStash")
+	}
+
+	var r0 *model.Stash
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Stash, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Stash); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Stash)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Register provides a mock function with given fields: ctx, username, password, inviteCode
+func (_m *UserService) Register(ctx context.Context, username string, password string, inviteCode string) (*model.User, error) {
+	ret := _m.Called(ctx, username, password, inviteCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Register")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*model.User, error)); ok {
+		return rf(ctx, username, password, inviteCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *model.User); ok {
+		r0 = rf(ctx, username, password, inviteCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, username, password, inviteCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateStorageUsage provides a mock function with given fields: ctx, storageUsage
 func (_m *UserService) UpdateStorageUsage(ctx context.Context, storageUsage *model.StorageUsage) error {
 	ret := _m.Called(ctx, storageUsage)